@@ -0,0 +1,77 @@
+package video
+
+import (
+	"context"
+	"fmt"
+
+	"video-platform-microservice/gateway/internal/logger"
+	"video-platform-microservice/gateway/internal/validator"
+	"video-platform-microservice/gateway/rpc"
+	video "video-platform-microservice/gateway/kitex_gen/video"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"go.uber.org/zap"
+)
+
+// DeleteVideoHandler 删除视频（仅移除当前用户的引用，最后一个引用释放时删除物理文件）
+func DeleteVideoHandler(ctx context.Context, c *app.RequestContext) {
+	traceID, _ := c.Get("trace_id")
+	userID, _ := c.Get("user_id")
+
+	fileHash := c.Query("file_hash")
+	if err := validator.ValidateFileHash(fileHash); err != nil {
+		logger.Logger.Warn("文件哈希验证失败",
+			zap.Any("trace_id", traceID),
+			zap.String("file_hash", fileHash),
+			zap.Error(err),
+		)
+		c.JSON(consts.StatusBadRequest, map[string]interface{}{
+			"code": 400,
+			"msg":  err.Error(),
+		})
+		return
+	}
+
+	logger.Logger.Info("调用 RPC DeleteVideo",
+		zap.Any("trace_id", traceID),
+		zap.Any("user_id", userID),
+		zap.String("file_hash", fileHash),
+	)
+
+	resp, err := rpc.VideoClient.DeleteVideo(ctx, &video.DeleteVideoReq{
+		FileHash: fileHash,
+		UserId:   fmt.Sprintf("%v", userID),
+	})
+
+	if err != nil {
+		logger.Logger.Error("RPC 调用失败",
+			zap.Any("trace_id", traceID),
+			zap.Error(err),
+		)
+		c.JSON(consts.StatusInternalServerError, map[string]interface{}{
+			"code": 500,
+			"msg":  "服务暂时不可用，请稍后重试",
+		})
+		return
+	}
+
+	var httpStatus int
+	switch resp.Code {
+	case 200:
+		httpStatus = consts.StatusOK
+	case 400:
+		httpStatus = consts.StatusBadRequest
+	case 404:
+		httpStatus = consts.StatusNotFound
+	case 409:
+		httpStatus = consts.StatusConflict
+	default:
+		httpStatus = consts.StatusInternalServerError
+	}
+
+	c.JSON(httpStatus, map[string]interface{}{
+		"code": resp.Code,
+		"msg":  resp.Msg,
+	})
+}
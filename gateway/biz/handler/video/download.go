@@ -1,9 +1,15 @@
 package video
 
 import (
+"bytes"
 "context"
+"crypto/rand"
+"encoding/hex"
 "fmt"
+"io"
+"net/http"
 "strconv"
+"time"
 
 "github.com/cloudwego/hertz/pkg/app"
 "github.com/cloudwego/hertz/pkg/protocol/consts"
@@ -11,10 +17,13 @@ import (
 
 "video-platform-microservice/gateway/internal/logger"
 videogen "video-platform-microservice/gateway/kitex_gen/video"
+"video-platform-microservice/gateway/kitex_gen/video/videoservice"
 "video-platform-microservice/gateway/rpc"
 )
 
-// DownloadHandler 下载视频（支持Range，需要认证）
+// DownloadHandler 下载视频（GET 与 HEAD，需要认证）
+// 支持 Range（单个与多个区间）、If-Range、ETag/If-None-Match 与 Last-Modified/If-Modified-Since；
+// 没有 Range 请求头时兼容旧的 start/end 查询参数（[start, end) 区间）
 func DownloadHandler(ctx context.Context, c *app.RequestContext) {
 // 从JWT context获取user_id
 userID, exists := c.Get("user_id")
@@ -27,9 +36,6 @@ return
 }
 
 fileHash := c.Query("file_hash")
-startStr := c.Query("start")
-endStr := c.Query("end")
-
 if fileHash == "" {
 c.JSON(consts.StatusBadRequest, map[string]interface{}{
 "code": 400,
@@ -38,33 +44,25 @@ c.JSON(consts.StatusBadRequest, map[string]interface{}{
 return
 }
 
-var startByte, endByte int64
-if startStr != "" {
-startByte, _ = strconv.ParseInt(startStr, 10, 64)
-}
-if endStr != "" {
-endByte, _ = strconv.ParseInt(endStr, 10, 64)
+rangeHeader := string(c.GetHeader("Range"))
+if rangeHeader == "" {
+rangeHeader = legacyRange(c.Query("start"), c.Query("end"))
 }
 
 traceID, _ := c.Get("trace_id")
 logger.Logger.Info("下载视频",
-zap.String("trace_id", traceID.(string)),
-zap.String("user_id", userID.(string)),
+zap.Any("trace_id", traceID),
+zap.Any("user_id", userID),
 zap.String("file_hash", fileHash),
-zap.Int64("start", startByte),
-zap.Int64("end", endByte),
+zap.String("method", string(c.Method())),
+zap.String("range", rangeHeader),
 )
 
-// 调用 RPC 服务
-resp, err := rpc.VideoClient.DownloadChunk(ctx, &videogen.DownloadChunkReq{
-FileHash:  fileHash,
-StartByte: startByte,
-EndByte:   endByte,
-})
-
+// 1. 查询文件信息（不读取文件内容）
+info, err := downloadInfo(ctx, fileHash)
 if err != nil {
 logger.Logger.Error("RPC 调用失败",
-zap.String("trace_id", traceID.(string)),
+zap.Any("trace_id", traceID),
 zap.Error(err),
 )
 c.JSON(consts.StatusInternalServerError, map[string]interface{}{
@@ -73,25 +71,268 @@ c.JSON(consts.StatusInternalServerError, map[string]interface{}{
 })
 return
 }
-
-if resp.Code != 200 {
-c.JSON(consts.StatusBadRequest, map[string]interface{}{
-"code": resp.Code,
-"msg":  resp.Msg,
+if info.Code != 200 {
+c.JSON(transcodeHTTPStatus(info.Code), map[string]interface{}{
+"code": info.Code,
+"msg":  info.Msg,
 })
 return
 }
 
-// 设置响应头
-c.Response.Header.Set("Content-Type", "video/mp4")
+size := info.TotalSize
+etag := fileETag(fileHash)
+lastModified := time.Unix(info.LastModified, 0).UTC()
 c.Response.Header.Set("Accept-Ranges", "bytes")
-c.Response.Header.Set("Content-Length", fmt.Sprintf("%d", len(resp.Data)))
-if startByte > 0 || endByte > 0 {
-c.Response.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", startByte, startByte+int64(len(resp.Data))-1, resp.TotalSize))
-c.Status(consts.StatusPartialContent)
+c.Response.Header.Set("ETag", etag)
+c.Response.Header.Set("Last-Modified", lastModified.Format(http.TimeFormat))
+
+// 2. 条件请求：客户端缓存仍然有效时返回 304
+if notModified(string(c.GetHeader("If-None-Match")), string(c.GetHeader("If-Modified-Since")), etag, lastModified) {
+c.SetStatusCode(consts.StatusNotModified)
+return
+}
+
+// 3. 解析区间；If-Range 与当前文件不一致时忽略 Range，返回整个文件
+var ranges []byteRange
+if rangeHeader != "" && ifRangeMatches(string(c.GetHeader("If-Range")), etag, lastModified) {
+ranges, err = parseRange(rangeHeader, size)
+if err != nil {
+c.Response.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+c.JSON(consts.StatusRequestedRangeNotSatisfiable, map[string]interface{}{
+"code": 416,
+"msg":  "请求的区间超出文件范围",
+})
+return
+}
+}
+
+// 4. 组织响应体：整个文件、单个区间，或 multipart/byteranges
+body := &rangeBody{ctx: ctx, fileHash: fileHash, size: size}
+status := consts.StatusOK
+switch len(ranges) {
+case 0:
+c.SetContentType(info.ContentType)
+if size > 0 {
+body.add(nil, &byteRange{start: 0, end: size})
+}
+case 1:
+status = consts.StatusPartialContent
+c.SetContentType(info.ContentType)
+c.Response.Header.Set("Content-Range", ranges[0].contentRange(size))
+body.add(nil, &ranges[0])
+default:
+status = consts.StatusPartialContent
+boundary := multipartBoundary()
+c.SetContentType("multipart/byteranges; boundary=" + boundary)
+for i := range ranges {
+header := fmt.Sprintf("--%s\r\nContent-Type: %s\r\nContent-Range: %s\r\n\r\n", boundary, info.ContentType, ranges[i].contentRange(size))
+if i > 0 {
+header = "\r\n" + header
+}
+body.add([]byte(header), &ranges[i])
+}
+body.add([]byte(fmt.Sprintf("\r\n--%s--\r\n", boundary)), nil)
+}
+c.SetStatusCode(status)
+
+if string(c.Method()) == consts.MethodHead {
+c.Response.Header.SetContentLength(int(body.length))
+return
+}
+
+// 先打开第一段，文件读取失败时还能返回错误响应
+if err := body.advance(); err != nil && err != io.EOF {
+body.Close()
+logger.Logger.Error("打开下载流失败",
+zap.Any("trace_id", traceID),
+zap.Error(err),
+)
+c.Response.Header.Del("Content-Range")
+c.JSON(consts.StatusInternalServerError, map[string]interface{}{
+"code": 500,
+"msg":  "服务器错误",
+})
+return
+}
+
+// 响应体由 Hertz 在处理函数返回后按连接的写入速度读取，读取结束或连接断开时关闭流
+c.SetBodyStream(body, int(body.length))
+}
+
+// legacyRange 将旧的 start/end 查询参数（[start, end) 区间，end 为空或 <= 0 表示到文件末尾）转换为 Range 请求头
+func legacyRange(start, end string) string {
+if start == "" && end == "" {
+return ""
+}
+if start == "" {
+start = "0"
+}
+if e, err := strconv.ParseInt(end, 10, 64); err == nil && e > 0 {
+return fmt.Sprintf("bytes=%s-%d", start, e-1)
+}
+return "bytes=" + start + "-"
+}
+
+// multipartBoundary 随机的 multipart 分隔符
+func multipartBoundary() string {
+var b [16]byte
+rand.Read(b[:])
+return hex.EncodeToString(b[:])
+}
+
+// downloadInfo 查询文件的大小、类型与修改时间
+func downloadInfo(ctx context.Context, fileHash string) (*videogen.DownloadFrame, error) {
+ctx, cancel := context.WithCancel(ctx)
+defer cancel()
+stream, err := rpc.VideoStreamClient.DownloadStream(ctx, &videogen.DownloadStreamReq{
+FileHash: fileHash,
+HeadOnly: true,
+})
+if err != nil {
+return nil, err
+}
+return stream.Recv()
+}
+
+// openRange 打开一个区间的下载流；文件在两次请求之间发生变化时返回错误
+func openRange(ctx context.Context, fileHash string, r byteRange, size int64) (*downloadReader, error) {
+ctx, cancel := context.WithCancel(ctx)
+stream, err := rpc.VideoStreamClient.DownloadStream(ctx, &videogen.DownloadStreamReq{
+FileHash:  fileHash,
+StartByte: r.start,
+EndByte:   r.end,
+})
+var first *videogen.DownloadFrame
+if err == nil {
+first, err = stream.Recv()
+}
+if err == nil && (first.Code != 200 || first.StartByte != r.start || first.EndByte != r.end || first.TotalSize != size) {
+err = fmt.Errorf("下载失败: %d %s", first.Code, first.Msg)
+}
+if err != nil {
+cancel()
+return nil, err
+}
+return &downloadReader{stream: stream, cancel: cancel, remaining: r.length()}, nil
+}
+
+// bodySegment 响应体的一段：固定的前缀，之后是文件的一个区间（可以为空）
+type bodySegment struct {
+prefix []byte
+r      *byteRange
+}
+
+// rangeBody 依次输出各段。文件区间读到时才打开下载流，同一时间只持有一个流
+type rangeBody struct {
+ctx      context.Context
+fileHash string
+size     int64
+segments []bodySegment
+length   int64 // 响应体总长度
+cur      io.Reader
+stream   *downloadReader
+}
+
+func (b *rangeBody) add(prefix []byte, r *byteRange) {
+b.segments = append(b.segments, bodySegment{prefix: prefix, r: r})
+b.length += int64(len(prefix))
+if r != nil {
+b.length += r.length()
+}
+}
+
+// advance 切换到下一段；没有更多内容时返回 io.EOF
+func (b *rangeBody) advance() error {
+b.closeStream()
+b.cur = nil
+for b.cur == nil {
+if len(b.segments) == 0 {
+return io.EOF
+}
+seg := &b.segments[0]
+if len(seg.prefix) > 0 {
+b.cur = bytes.NewReader(seg.prefix)
+seg.prefix = nil
+continue
+}
+b.segments = b.segments[1:]
+if seg.r != nil {
+stream, err := openRange(b.ctx, b.fileHash, *seg.r, b.size)
+if err != nil {
+return err
+}
+b.stream = stream
+b.cur = stream
+}
+}
+return nil
+}
+
+func (b *rangeBody) Read(p []byte) (int, error) {
+for {
+if b.cur == nil {
+if err := b.advance(); err != nil {
+return 0, err
+}
+}
+n, err := b.cur.Read(p)
+if err == io.EOF {
+b.cur = nil
+if n == 0 {
+continue
+}
+err = nil
+}
+return n, err
+}
+}
+
+// Close 结束正在进行的下载流（客户端断开时 rpc-video 随之停止读取）
+func (b *rangeBody) Close() error {
+b.closeStream()
+return nil
+}
+
+func (b *rangeBody) closeStream() {
+if b.stream != nil {
+b.stream.Close()
+b.stream = nil
+}
+}
+
+// downloadReader 将 DownloadStream 的数据帧拼接为 io.Reader。
+// 只在 HTTP 响应需要数据时接收下一帧，gRPC 流控把客户端的读取速度传回 rpc-video
+type downloadReader struct {
+stream    videoservice.VideoService_DownloadStreamClient
+cancel    context.CancelFunc
+buf       []byte
+remaining int64 // 尚未收到的字节数
+}
+
+func (r *downloadReader) Read(p []byte) (int, error) {
+for len(r.buf) == 0 {
+if r.remaining <= 0 {
+return 0, io.EOF
+}
+frame, err := r.stream.Recv()
+if err == io.EOF {
+return 0, fmt.Errorf("下载流提前结束，还差 %d 字节", r.remaining)
+}
+if err != nil {
+return 0, err
+}
+r.buf = frame.Data
+r.remaining -= int64(len(frame.Data))
+}
+n := copy(p, r.buf)
+r.buf = r.buf[n:]
+return n, nil
 }
 
-c.Data(consts.StatusOK, "video/mp4", resp.Data)
+// Close 结束流（客户端断开时 rpc-video 随之停止读取）
+func (r *downloadReader) Close() error {
+r.cancel()
+return nil
 }
 
 // GetVideoInfoHandler 获取视频信息（需要认证）
@@ -119,14 +360,14 @@ return
 traceID, _ := c.Get("trace_id")
 logger.Logger.Info("获取视频信息",
 zap.String("trace_id", traceID.(string)),
-zap.String("user_id", userID.(string)),
+zap.Any("user_id", userID),
 zap.String("file_hash", fileHash),
 )
 
 // 调用 RPC 服务
 resp, err := rpc.VideoClient.GetVideoInfo(ctx, &videogen.GetVideoInfoReq{
 FileHash: fileHash,
-UserId:   userID.(string),
+UserId:   fmt.Sprintf("%v", userID),
 })
 
 if err != nil {
@@ -152,5 +393,12 @@ c.JSON(consts.StatusOK, map[string]interface{}{
 "url":              resp.Url,
 "transcode_urls":   resp.TranscodeUrls,
 "transcode_status": resp.TranscodeStatus,
+"playback_url":     resp.PlaybackUrl,
+"dash_url":         resp.DashUrl,
+"poster_url":       resp.PosterUrl,
+"thumbnails_vtt_url": resp.ThumbnailsVttUrl,
+"sprite_urls":      resp.SpriteUrls,
+"thumbnail_status": resp.ThumbnailStatus,
+"media":            resp.Media,
 })
 }
@@ -16,8 +16,10 @@ import (
 // InitUploadHandler 处理初始化上传请求
 func InitUploadHandler(ctx context.Context, c *app.RequestContext) {
 	var req struct {
-		FileHash string `json:"file_hash" binding:"required"`
-		Filename string `json:"filename" binding:"required"`
+		FileHash        string `json:"file_hash" binding:"required"`
+		Filename        string `json:"filename" binding:"required"`
+		FileSize        int64  `json:"file_size"`
+		ChallengeAnswer string `json:"challenge_answer"` // 对 challenge 的应答，证明持有文件内容后秒传
 	}
 
 	traceID, _ := c.Get("trace_id")
@@ -53,10 +55,15 @@ func InitUploadHandler(ctx context.Context, c *app.RequestContext) {
 		zap.String("filename", req.Filename),
 	)
 
-	resp, err := rpc.VideoClient.InitUpload(ctx, &video.InitUploadReq{
+	initReq := &video.InitUploadReq{
 		FileHash: req.FileHash,
 		Filename: req.Filename,
-	})
+		FileSize: req.FileSize,
+	}
+	if req.ChallengeAnswer != "" {
+		initReq.ChallengeAnswer = &req.ChallengeAnswer
+	}
+	resp, err := rpc.VideoClient.InitUpload(ctx, initReq)
 
 	if err != nil {
 		logger.Logger.Error("RPC 调用失败",
@@ -92,5 +99,6 @@ func InitUploadHandler(ctx context.Context, c *app.RequestContext) {
 		"status":          resp.Status,
 		"finished_chunks": resp.FinishedChunks,
 		"url":             resp.Url,
+		"challenge":       resp.Challenge,
 	})
 }
\ No newline at end of file
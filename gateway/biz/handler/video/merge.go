@@ -79,6 +79,8 @@ func MergeFileHandler(ctx context.Context, c *app.RequestContext) {
 		httpStatus = consts.StatusOK
 	case 400:
 		httpStatus = consts.StatusBadRequest
+	case 409:
+		httpStatus = consts.StatusConflict
 	default:
 		httpStatus = consts.StatusInternalServerError
 	}
@@ -90,8 +92,66 @@ func MergeFileHandler(ctx context.Context, c *app.RequestContext) {
 	)
 
 	c.JSON(httpStatus, map[string]interface{}{
-		"code": resp.Code,
-		"msg":  resp.Msg,
-		"url":  resp.Url,
+		"code":       resp.Code,
+		"msg":        resp.Msg,
+		"url":        resp.Url,
+		"bad_chunks": resp.BadChunks,
+		"job_id":     resp.JobId,
+		"status":     resp.Status,
+	})
+}
+
+// GetMergeStatusHandler 查询合并任务状态
+func GetMergeStatusHandler(ctx context.Context, c *app.RequestContext) {
+	traceID, _ := c.Get("trace_id")
+
+	jobID := c.Query("job_id")
+	if jobID == "" {
+		c.JSON(consts.StatusBadRequest, map[string]interface{}{
+			"code": 400,
+			"msg":  "job_id 不能为空",
+		})
+		return
+	}
+
+	resp, err := rpc.VideoClient.GetMergeStatus(ctx, &video.GetMergeStatusReq{
+		JobId: jobID,
+	})
+
+	if err != nil {
+		logger.Logger.Error("RPC 调用失败",
+			zap.Any("trace_id", traceID),
+			zap.Error(err),
+		)
+		c.JSON(consts.StatusInternalServerError, map[string]interface{}{
+			"code": 500,
+			"msg":  "服务暂时不可用，请稍后重试",
+		})
+		return
+	}
+
+	var httpStatus int
+	switch resp.Code {
+	case 200:
+		httpStatus = consts.StatusOK
+	case 400:
+		httpStatus = consts.StatusBadRequest
+	case 404:
+		httpStatus = consts.StatusNotFound
+	default:
+		httpStatus = consts.StatusInternalServerError
+	}
+
+	c.JSON(httpStatus, map[string]interface{}{
+		"code":         resp.Code,
+		"msg":          resp.Msg,
+		"job_id":       resp.JobId,
+		"status":       resp.Status,
+		"phase":        resp.Phase,
+		"bytes_merged": resp.BytesMerged,
+		"total_bytes":  resp.TotalBytes,
+		"url":          resp.Url,
+		"bad_chunks":   resp.BadChunks,
+		"error":        resp.Error,
 	})
 }
\ No newline at end of file
@@ -0,0 +1,262 @@
+package video
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"go.uber.org/zap"
+
+	"video-platform-microservice/gateway/internal/logger"
+	videogen "video-platform-microservice/gateway/kitex_gen/video"
+	"video-platform-microservice/gateway/rpc"
+)
+
+// processingPolicy 处理策略的 JSON 结构
+type processingPolicy struct {
+	Steps              []string `json:"steps"`    // probe, validate, thumbnails, transcode, package
+	Profiles           []string `json:"profiles"` // transcode 步骤使用的编码配置名称
+	PerTitle           bool     `json:"per_title"`
+	QualityMetrics     bool     `json:"quality_metrics"`
+	RequireVideo       bool     `json:"require_video"`
+	MaxDurationSeconds int32    `json:"max_duration_seconds"`
+	MinShortSide       int32    `json:"min_short_side"`
+	MaxShortSide       int32    `json:"max_short_side"`
+	Enabled            *bool    `json:"enabled"` // 默认启用
+}
+
+// GetPolicyHandler 查询对当前用户生效的处理策略
+func GetPolicyHandler(ctx context.Context, c *app.RequestContext) {
+	getPolicy(ctx, c, false)
+}
+
+// GetPlatformPolicyHandler 查询平台默认处理策略（管理员）
+func GetPlatformPolicyHandler(ctx context.Context, c *app.RequestContext) {
+	getPolicy(ctx, c, true)
+}
+
+// SavePolicyHandler 保存当前用户的处理策略
+func SavePolicyHandler(ctx context.Context, c *app.RequestContext) {
+	savePolicy(ctx, c, false)
+}
+
+// SavePlatformPolicyHandler 保存平台默认处理策略（管理员）
+func SavePlatformPolicyHandler(ctx context.Context, c *app.RequestContext) {
+	savePolicy(ctx, c, true)
+}
+
+// DeletePolicyHandler 删除当前用户的处理策略，之后改用平台默认策略
+func DeletePolicyHandler(ctx context.Context, c *app.RequestContext) {
+	deletePolicy(ctx, c, false)
+}
+
+// DeletePlatformPolicyHandler 删除平台默认处理策略（管理员）
+func DeletePlatformPolicyHandler(ctx context.Context, c *app.RequestContext) {
+	deletePolicy(ctx, c, true)
+}
+
+func getPolicy(ctx context.Context, c *app.RequestContext, platform bool) {
+	traceID, _ := c.Get("trace_id")
+	logger.Logger.Info("查询处理策略",
+		zap.Any("trace_id", traceID),
+		zap.Bool("platform", platform),
+	)
+
+	resp, err := rpc.VideoClient.GetProcessingPolicy(ctx, &videogen.GetProcessingPolicyReq{
+		Platform: platform,
+	})
+	if err != nil {
+		logger.Logger.Error("RPC 调用失败",
+			zap.Any("trace_id", traceID),
+			zap.Error(err),
+		)
+		c.JSON(consts.StatusInternalServerError, map[string]interface{}{
+			"code": 500,
+			"msg":  "服务器错误",
+		})
+		return
+	}
+
+	c.JSON(transcodeHTTPStatus(resp.Code), map[string]interface{}{
+		"code":   resp.Code,
+		"msg":    resp.Msg,
+		"scope":  resp.Scope,
+		"policy": resp.Policy,
+	})
+}
+
+func savePolicy(ctx context.Context, c *app.RequestContext, platform bool) {
+	var req processingPolicy
+	if err := c.BindAndValidate(&req); err != nil {
+		c.JSON(consts.StatusBadRequest, map[string]interface{}{
+			"code": 400,
+			"msg":  fmt.Sprintf("参数错误: %v", err),
+		})
+		return
+	}
+	enabled := req.Enabled == nil || *req.Enabled
+
+	traceID, _ := c.Get("trace_id")
+	username, _ := c.Get("username")
+	logger.Logger.Info("保存处理策略",
+		zap.Any("trace_id", traceID),
+		zap.Any("username", username),
+		zap.Bool("platform", platform),
+		zap.Strings("steps", req.Steps),
+	)
+
+	resp, err := rpc.VideoClient.SaveProcessingPolicy(ctx, &videogen.SaveProcessingPolicyReq{
+		Platform: platform,
+		Policy: &videogen.ProcessingPolicy{
+			Steps:              req.Steps,
+			Profiles:           req.Profiles,
+			PerTitle:           req.PerTitle,
+			QualityMetrics:     req.QualityMetrics,
+			RequireVideo:       req.RequireVideo,
+			MaxDurationSeconds: req.MaxDurationSeconds,
+			MinShortSide:       req.MinShortSide,
+			MaxShortSide:       req.MaxShortSide,
+			Enabled:            enabled,
+		},
+	})
+	if err != nil {
+		logger.Logger.Error("RPC 调用失败",
+			zap.Any("trace_id", traceID),
+			zap.Error(err),
+		)
+		c.JSON(consts.StatusInternalServerError, map[string]interface{}{
+			"code": 500,
+			"msg":  "服务器错误",
+		})
+		return
+	}
+
+	c.JSON(transcodeHTTPStatus(resp.Code), map[string]interface{}{
+		"code":   resp.Code,
+		"msg":    resp.Msg,
+		"policy": resp.Policy,
+	})
+}
+
+func deletePolicy(ctx context.Context, c *app.RequestContext, platform bool) {
+	traceID, _ := c.Get("trace_id")
+	username, _ := c.Get("username")
+	logger.Logger.Info("删除处理策略",
+		zap.Any("trace_id", traceID),
+		zap.Any("username", username),
+		zap.Bool("platform", platform),
+	)
+
+	resp, err := rpc.VideoClient.DeleteProcessingPolicy(ctx, &videogen.DeleteProcessingPolicyReq{
+		Platform: platform,
+	})
+	if err != nil {
+		logger.Logger.Error("RPC 调用失败",
+			zap.Any("trace_id", traceID),
+			zap.Error(err),
+		)
+		c.JSON(consts.StatusInternalServerError, map[string]interface{}{
+			"code": 500,
+			"msg":  "服务器错误",
+		})
+		return
+	}
+
+	c.JSON(transcodeHTTPStatus(resp.Code), map[string]interface{}{
+		"code": resp.Code,
+		"msg":  resp.Msg,
+	})
+}
+
+// GetPipelineHandler 查询上传后的处理流程：pipeline_id，或 file_hash（文件最近一次的流程）
+func GetPipelineHandler(ctx context.Context, c *app.RequestContext) {
+	pipelineID := c.Query("pipeline_id")
+	fileHash := c.Query("file_hash")
+	if pipelineID == "" && fileHash == "" {
+		c.JSON(consts.StatusBadRequest, map[string]interface{}{
+			"code": 400,
+			"msg":  "pipeline_id 和 file_hash 不能同时为空",
+		})
+		return
+	}
+
+	traceID, _ := c.Get("trace_id")
+	logger.Logger.Info("查询处理流程",
+		zap.Any("trace_id", traceID),
+		zap.String("pipeline_id", pipelineID),
+		zap.String("file_hash", fileHash),
+	)
+
+	resp, err := rpc.VideoClient.GetPipeline(ctx, &videogen.GetPipelineReq{
+		PipelineId: pipelineID,
+		FileHash:   fileHash,
+	})
+	if err != nil {
+		logger.Logger.Error("RPC 调用失败",
+			zap.Any("trace_id", traceID),
+			zap.Error(err),
+		)
+		c.JSON(consts.StatusInternalServerError, map[string]interface{}{
+			"code": 500,
+			"msg":  "服务器错误",
+		})
+		return
+	}
+
+	c.JSON(transcodeHTTPStatus(resp.Code), map[string]interface{}{
+		"code":        resp.Code,
+		"msg":         resp.Msg,
+		"pipeline_id": resp.PipelineId,
+		"file_hash":   resp.FileHash,
+		"status":      resp.Status,
+		"error":       resp.Error,
+		"steps":       resp.Steps,
+		"policy":      resp.Policy,
+		"created_at":  resp.CreatedAt,
+	})
+}
+
+// RetryPipelineHandler 重新执行处理流程中失败的步骤
+func RetryPipelineHandler(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		PipelineID string `json:"pipeline_id" binding:"required"`
+		Step       string `json:"step"` // 为空时重试失败的那一步
+	}
+	if err := c.BindAndValidate(&req); err != nil {
+		c.JSON(consts.StatusBadRequest, map[string]interface{}{
+			"code": 400,
+			"msg":  fmt.Sprintf("参数错误: %v", err),
+		})
+		return
+	}
+
+	traceID, _ := c.Get("trace_id")
+	logger.Logger.Info("重试处理流程",
+		zap.Any("trace_id", traceID),
+		zap.String("pipeline_id", req.PipelineID),
+		zap.String("step", req.Step),
+	)
+
+	resp, err := rpc.VideoClient.RetryPipeline(ctx, &videogen.RetryPipelineReq{
+		PipelineId: req.PipelineID,
+		Step:       req.Step,
+	})
+	if err != nil {
+		logger.Logger.Error("RPC 调用失败",
+			zap.Any("trace_id", traceID),
+			zap.Error(err),
+		)
+		c.JSON(consts.StatusInternalServerError, map[string]interface{}{
+			"code": 500,
+			"msg":  "服务器错误",
+		})
+		return
+	}
+
+	c.JSON(transcodeHTTPStatus(resp.Code), map[string]interface{}{
+		"code":   resp.Code,
+		"msg":    resp.Msg,
+		"status": resp.Status,
+	})
+}
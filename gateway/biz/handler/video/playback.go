@@ -0,0 +1,61 @@
+package video
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"go.uber.org/zap"
+
+	"video-platform-microservice/gateway/internal/logger"
+	videogen "video-platform-microservice/gateway/kitex_gen/video"
+	"video-platform-microservice/gateway/rpc"
+)
+
+// PlaybackFileHandler 提供 HLS 播放列表、DASH 清单与分片（需要认证）
+// GET /api/video/play/:file_hash/*path，清单中的地址都是相对路径，播放器会沿用同一前缀
+func PlaybackFileHandler(ctx context.Context, c *app.RequestContext) {
+	fileHash := c.Param("file_hash")
+	path := strings.TrimPrefix(c.Param("path"), "/")
+	if fileHash == "" || path == "" {
+		c.JSON(consts.StatusBadRequest, map[string]interface{}{
+			"code": 400,
+			"msg":  "参数错误",
+		})
+		return
+	}
+
+	traceID, _ := c.Get("trace_id")
+
+	resp, err := rpc.VideoClient.GetPlaybackFile(ctx, &videogen.GetPlaybackFileReq{
+		FileHash: fileHash,
+		Path:     path,
+	})
+	if err != nil {
+		logger.Logger.Error("RPC 调用失败",
+			zap.Any("trace_id", traceID),
+			zap.Error(err),
+		)
+		c.JSON(consts.StatusInternalServerError, map[string]interface{}{
+			"code": 500,
+			"msg":  "服务器错误",
+		})
+		return
+	}
+	if resp.Code != 200 {
+		c.JSON(transcodeHTTPStatus(resp.Code), map[string]interface{}{
+			"code": resp.Code,
+			"msg":  resp.Msg,
+		})
+		return
+	}
+
+	// 分片内容不会变化；播放列表与清单在重新转码后会更新，需要重新验证
+	if strings.HasSuffix(path, ".m3u8") || strings.HasSuffix(path, ".mpd") {
+		c.Response.Header.Set("Cache-Control", "private, no-cache")
+	} else {
+		c.Response.Header.Set("Cache-Control", "private, max-age=86400")
+	}
+	c.Data(consts.StatusOK, resp.ContentType, resp.Data)
+}
@@ -0,0 +1,184 @@
+package video
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"go.uber.org/zap"
+
+	"video-platform-microservice/gateway/internal/logger"
+	videogen "video-platform-microservice/gateway/kitex_gen/video"
+	"video-platform-microservice/gateway/rpc"
+)
+
+// transcodeProfile 编码配置的 JSON 结构
+type transcodeProfile struct {
+	Name            string `json:"name"`
+	Codec           string `json:"codec"`
+	RateControl     string `json:"rate_control"`
+	Container       string `json:"container"`
+	Width           int32  `json:"width"`
+	Height          int32  `json:"height"`
+	FitMode         string `json:"fit_mode"`
+	VideoBitrate    string `json:"video_bitrate"`
+	MaxBitrate      string `json:"max_bitrate"`
+	CRF             int32  `json:"crf"`
+	Preset          string `json:"preset"`
+	AudioCodec      string `json:"audio_codec"`
+	AudioBitrate    string `json:"audio_bitrate"`
+	AudioChannels   int32  `json:"audio_channels"`
+	AudioSampleRate int32  `json:"audio_sample_rate"`
+	Builtin         bool   `json:"builtin"`
+}
+
+func toTranscodeProfile(p *videogen.TranscodeProfile) transcodeProfile {
+	return transcodeProfile{
+		Name:            p.Name,
+		Codec:           p.Codec,
+		RateControl:     p.RateControl,
+		Container:       p.Container,
+		Width:           p.Width,
+		Height:          p.Height,
+		FitMode:         p.FitMode,
+		VideoBitrate:    p.VideoBitrate,
+		MaxBitrate:      p.MaxBitrate,
+		CRF:             p.Crf,
+		Preset:          p.Preset,
+		AudioCodec:      p.AudioCodec,
+		AudioBitrate:    p.AudioBitrate,
+		AudioChannels:   p.AudioChannels,
+		AudioSampleRate: p.AudioSampleRate,
+		Builtin:         p.Builtin,
+	}
+}
+
+// ListTranscodeProfilesHandler 列出可用的编码配置
+func ListTranscodeProfilesHandler(ctx context.Context, c *app.RequestContext) {
+	traceID, _ := c.Get("trace_id")
+
+	resp, err := rpc.VideoClient.ListTranscodeProfiles(ctx, &videogen.ListTranscodeProfilesReq{})
+	if err != nil {
+		logger.Logger.Error("RPC 调用失败",
+			zap.Any("trace_id", traceID),
+			zap.Error(err),
+		)
+		c.JSON(consts.StatusInternalServerError, map[string]interface{}{
+			"code": 500,
+			"msg":  "服务器错误",
+		})
+		return
+	}
+
+	profiles := make([]transcodeProfile, 0, len(resp.Profiles))
+	for _, p := range resp.Profiles {
+		profiles = append(profiles, toTranscodeProfile(p))
+	}
+
+	c.JSON(transcodeHTTPStatus(resp.Code), map[string]interface{}{
+		"code":     resp.Code,
+		"msg":      resp.Msg,
+		"profiles": profiles,
+	})
+}
+
+// SaveTranscodeProfileHandler 创建或更新编码配置（管理员）
+func SaveTranscodeProfileHandler(ctx context.Context, c *app.RequestContext) {
+	var req transcodeProfile
+	if err := c.BindAndValidate(&req); err != nil {
+		c.JSON(consts.StatusBadRequest, map[string]interface{}{
+			"code": 400,
+			"msg":  fmt.Sprintf("参数错误: %v", err),
+		})
+		return
+	}
+
+	traceID, _ := c.Get("trace_id")
+	username, _ := c.Get("username")
+	logger.Logger.Info("保存编码配置",
+		zap.Any("trace_id", traceID),
+		zap.Any("username", username),
+		zap.String("name", req.Name),
+	)
+
+	resp, err := rpc.VideoClient.SaveTranscodeProfile(ctx, &videogen.SaveTranscodeProfileReq{
+		Profile: &videogen.TranscodeProfile{
+			Name:            req.Name,
+			Codec:           req.Codec,
+			RateControl:     req.RateControl,
+			Container:       req.Container,
+			Width:           req.Width,
+			Height:          req.Height,
+			FitMode:         req.FitMode,
+			VideoBitrate:    req.VideoBitrate,
+			MaxBitrate:      req.MaxBitrate,
+			Crf:             req.CRF,
+			Preset:          req.Preset,
+			AudioCodec:      req.AudioCodec,
+			AudioBitrate:    req.AudioBitrate,
+			AudioChannels:   req.AudioChannels,
+			AudioSampleRate: req.AudioSampleRate,
+		},
+	})
+	if err != nil {
+		logger.Logger.Error("RPC 调用失败",
+			zap.Any("trace_id", traceID),
+			zap.Error(err),
+		)
+		c.JSON(consts.StatusInternalServerError, map[string]interface{}{
+			"code": 500,
+			"msg":  "服务器错误",
+		})
+		return
+	}
+
+	result := map[string]interface{}{
+		"code": resp.Code,
+		"msg":  resp.Msg,
+	}
+	if resp.Profile != nil {
+		result["profile"] = toTranscodeProfile(resp.Profile)
+	}
+	c.JSON(transcodeHTTPStatus(resp.Code), result)
+}
+
+// DeleteTranscodeProfileHandler 删除编码配置（管理员）
+func DeleteTranscodeProfileHandler(ctx context.Context, c *app.RequestContext) {
+	name := c.Query("name")
+	if name == "" {
+		c.JSON(consts.StatusBadRequest, map[string]interface{}{
+			"code": 400,
+			"msg":  "name 不能为空",
+		})
+		return
+	}
+
+	traceID, _ := c.Get("trace_id")
+	username, _ := c.Get("username")
+	logger.Logger.Info("删除编码配置",
+		zap.Any("trace_id", traceID),
+		zap.Any("username", username),
+		zap.String("name", name),
+	)
+
+	resp, err := rpc.VideoClient.DeleteTranscodeProfile(ctx, &videogen.DeleteTranscodeProfileReq{
+		Name: name,
+	})
+	if err != nil {
+		logger.Logger.Error("RPC 调用失败",
+			zap.Any("trace_id", traceID),
+			zap.Error(err),
+		)
+		c.JSON(consts.StatusInternalServerError, map[string]interface{}{
+			"code": 500,
+			"msg":  "服务器错误",
+		})
+		return
+	}
+
+	c.JSON(transcodeHTTPStatus(resp.Code), map[string]interface{}{
+		"code": resp.Code,
+		"msg":  resp.Msg,
+	})
+}
@@ -0,0 +1,167 @@
+package video
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// byteRange 响应中的一个字节区间 [start, end)
+type byteRange struct {
+	start int64
+	end   int64
+}
+
+func (r byteRange) length() int64 {
+	return r.end - r.start
+}
+
+// contentRange Content-Range 响应头的值
+func (r byteRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.start, r.end-1, size)
+}
+
+// errRangeNotSatisfiable Range 中的区间都不在文件范围内
+var errRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// maxRanges 一个请求最多处理的区间数，超过时忽略 Range 返回整个文件
+const maxRanges = 16
+
+// parseRange 解析 Range 请求头（RFC 9110 14.2），区间按请求中的顺序返回。
+// 返回 nil 表示忽略 Range：单位不是 bytes、语法错误、区间过多，或多个区间的总长度超过文件本身；
+// 所有区间都不可满足时返回 errRangeNotSatisfiable
+func parseRange(header string, size int64) ([]byteRange, error) {
+	unit, set, ok := strings.Cut(header, "=")
+	if !ok || !strings.EqualFold(strings.TrimSpace(unit), "bytes") {
+		return nil, nil
+	}
+	specs := strings.Split(set, ",")
+	if len(specs) > maxRanges {
+		return nil, nil
+	}
+
+	var ranges []byteRange
+	var total int64
+	satisfiable := false
+	empty := true
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		empty = false
+		first, last, ok := strings.Cut(spec, "-")
+		if !ok {
+			return nil, nil
+		}
+		first, last = strings.TrimSpace(first), strings.TrimSpace(last)
+
+		var r byteRange
+		if first == "" {
+			// 后缀区间：最后 n 个字节
+			n, ok := parseDigits(last)
+			if !ok {
+				return nil, nil
+			}
+			if n == 0 || size == 0 {
+				continue
+			}
+			if n > size {
+				n = size
+			}
+			r = byteRange{start: size - n, end: size}
+		} else {
+			start, ok := parseDigits(first)
+			if !ok {
+				return nil, nil
+			}
+			end := size
+			if last != "" {
+				n, ok := parseDigits(last)
+				if !ok || n < start {
+					return nil, nil
+				}
+				if n+1 < end {
+					end = n + 1
+				}
+			}
+			if start >= size {
+				continue
+			}
+			r = byteRange{start: start, end: end}
+		}
+		satisfiable = true
+		ranges = append(ranges, r)
+		total += r.length()
+	}
+	if empty {
+		return nil, nil
+	}
+	if !satisfiable {
+		return nil, errRangeNotSatisfiable
+	}
+	// 大量重叠的区间会让响应比文件本身还大，这时直接返回整个文件
+	if len(ranges) > 1 && total > size {
+		return nil, nil
+	}
+	return ranges, nil
+}
+
+// parseDigits 解析 1*DIGIT
+func parseDigits(s string) (int64, bool) {
+	if s == "" {
+		return 0, false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	return n, err == nil
+}
+
+// fileETag 文件内容按哈希寻址，哈希本身就是强校验器
+func fileETag(fileHash string) string {
+	return `"` + fileHash + `"`
+}
+
+// etagListMatches If-None-Match 的弱比较（RFC 9110 8.8.3.2）
+func etagListMatches(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified 按 If-None-Match（优先）或 If-Modified-Since 判断客户端的缓存是否仍然有效
+func notModified(ifNoneMatch, ifModifiedSince, etag string, lastModified time.Time) bool {
+	if ifNoneMatch != "" {
+		return etagListMatches(ifNoneMatch, etag)
+	}
+	if ifModifiedSince != "" {
+		if t, err := http.ParseTime(ifModifiedSince); err == nil {
+			return !lastModified.After(t)
+		}
+	}
+	return false
+}
+
+// ifRangeMatches If-Range 是否允许按 Range 返回部分内容（RFC 9110 13.1.5）：
+// 实体标签做强比较，日期必须与 Last-Modified 完全一致
+func ifRangeMatches(ifRange, etag string, lastModified time.Time) bool {
+	if ifRange == "" {
+		return true
+	}
+	if strings.HasPrefix(ifRange, `"`) || strings.HasPrefix(ifRange, "W/") {
+		return ifRange == etag
+	}
+	t, err := http.ParseTime(ifRange)
+	return err == nil && t.Equal(lastModified)
+}
@@ -0,0 +1,146 @@
+package video
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRange(t *testing.T) {
+	const size = 1000
+	tests := []struct {
+		name   string
+		header string
+		want   []byteRange
+		err    error
+	}{
+		{name: "single", header: "bytes=0-499", want: []byteRange{{0, 500}}},
+		{name: "open ended", header: "bytes=900-", want: []byteRange{{900, 1000}}},
+		{name: "end beyond size", header: "bytes=900-5000", want: []byteRange{{900, 1000}}},
+		{name: "suffix", header: "bytes=-100", want: []byteRange{{900, 1000}}},
+		{name: "suffix larger than file", header: "bytes=-5000", want: []byteRange{{0, 1000}}},
+		{name: "whitespace and case", header: " Bytes = 0-9 , 20-29 ", want: []byteRange{{0, 10}, {20, 30}}},
+		{name: "multiple keep request order", header: "bytes=500-599,0-99", want: []byteRange{{500, 600}, {0, 100}}},
+		{name: "unsatisfiable ranges dropped", header: "bytes=0-9,2000-3000", want: []byteRange{{0, 10}}},
+		{name: "empty elements skipped", header: "bytes=,0-9,", want: []byteRange{{0, 10}}},
+
+		{name: "start beyond size", header: "bytes=1000-", err: errRangeNotSatisfiable},
+		{name: "all unsatisfiable", header: "bytes=2000-2999,5000-", err: errRangeNotSatisfiable},
+		{name: "zero suffix", header: "bytes=-0", err: errRangeNotSatisfiable},
+
+		{name: "overlapping ranges larger than file", header: "bytes=0-999,0-999"},
+		{name: "too many ranges", header: "bytes=" + strings.Repeat("0-0,", maxRanges) + "0-0"},
+		{name: "other unit", header: "items=0-9"},
+		{name: "missing equals", header: "bytes 0-9"},
+		{name: "missing dash", header: "bytes=100"},
+		{name: "last before first", header: "bytes=500-100"},
+		{name: "non digit", header: "bytes=a-9"},
+		{name: "negative", header: "bytes=--5"},
+		{name: "plus sign", header: "bytes=+1-9"},
+		{name: "only commas", header: "bytes=,,"},
+		{name: "overflow", header: "bytes=0-99999999999999999999"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRange(tt.header, size)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("parseRange(%q) error = %v, want %v", tt.header, err, tt.err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRange(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRangeEmptyFile(t *testing.T) {
+	for _, header := range []string{"bytes=0-", "bytes=-10"} {
+		if _, err := parseRange(header, 0); !errors.Is(err, errRangeNotSatisfiable) {
+			t.Errorf("parseRange(%q, 0) error = %v, want errRangeNotSatisfiable", header, err)
+		}
+	}
+}
+
+func TestContentRange(t *testing.T) {
+	if got := (byteRange{900, 1000}).contentRange(1000); got != "bytes 900-999/1000" {
+		t.Errorf("contentRange = %q", got)
+	}
+}
+
+func TestETagMatching(t *testing.T) {
+	etag := fileETag("abc")
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	date := modified.Format(http.TimeFormat)
+	earlier := modified.Add(-time.Hour).Format(http.TimeFormat)
+	later := modified.Add(time.Hour).Format(http.TimeFormat)
+
+	t.Run("If-None-Match", func(t *testing.T) {
+		tests := []struct {
+			header string
+			want   bool
+		}{
+			{`"abc"`, true},
+			{`W/"abc"`, true}, // 弱比较忽略 W/
+			{`"x", W/"abc"`, true},
+			{`*`, true},
+			{`"abd"`, false},
+			{`abc`, false},
+			{``, false},
+		}
+		for _, tt := range tests {
+			if got := etagListMatches(tt.header, etag); got != tt.want {
+				t.Errorf("etagListMatches(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("notModified", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			ifNoneMatch string
+			ifModSince  string
+			want        bool
+		}{
+			{name: "etag matches", ifNoneMatch: `"abc"`, want: true},
+			{name: "etag differs", ifNoneMatch: `"old"`, want: false},
+			{name: "same date", ifModSince: date, want: true},
+			{name: "later date", ifModSince: later, want: true},
+			{name: "earlier date", ifModSince: earlier, want: false},
+			{name: "invalid date", ifModSince: "yesterday", want: false},
+			{name: "none", want: false},
+			// If-None-Match 存在时忽略 If-Modified-Since
+			{name: "etag differs, date fresh", ifNoneMatch: `"old"`, ifModSince: later, want: false},
+			{name: "etag matches, date stale", ifNoneMatch: `"abc"`, ifModSince: earlier, want: true},
+		}
+		for _, tt := range tests {
+			if got := notModified(tt.ifNoneMatch, tt.ifModSince, etag, modified); got != tt.want {
+				t.Errorf("%s: notModified = %v, want %v", tt.name, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("If-Range", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			ifRange string
+			want    bool
+		}{
+			{name: "absent", ifRange: "", want: true},
+			{name: "strong etag", ifRange: `"abc"`, want: true},
+			{name: "weak etag never matches", ifRange: `W/"abc"`, want: false},
+			{name: "other etag", ifRange: `"abd"`, want: false},
+			{name: "exact date", ifRange: date, want: true},
+			{name: "later date", ifRange: later, want: false},
+			{name: "earlier date", ifRange: earlier, want: false},
+			{name: "invalid date", ifRange: "yesterday", want: false},
+		}
+		for _, tt := range tests {
+			if got := ifRangeMatches(tt.ifRange, etag, modified); got != tt.want {
+				t.Errorf("%s: ifRangeMatches(%q) = %v, want %v", tt.name, tt.ifRange, got, tt.want)
+			}
+		}
+	})
+}
@@ -2,24 +2,452 @@ package video
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"mime/multipart"
+	"os"
+	"strconv"
+	"time"
 
 	"video-platform-microservice/gateway/internal/logger"
+	"video-platform-microservice/gateway/rpc"
+	video "video-platform-microservice/gateway/kitex_gen/video"
 
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
 	"go.uber.org/zap"
 )
 
+const (
+	// defaultSimpleChunkSize 简单上传切分分片的默认大小，与 rpc-video 的 CHUNK_SIZE 默认值一致
+	defaultSimpleChunkSize int64 = 2 * 1024 * 1024
+	// mergePollInterval 等待合并任务时查询状态的间隔
+	mergePollInterval = 500 * time.Millisecond
+	// mergeWaitTimeout 等待合并完成的最长时间，超时后返回 job_id 由客户端自行查询
+	mergeWaitTimeout = 10 * time.Minute
+)
+
 // SimpleUploadHandler 处理简单上传请求（单个请求上传整个文件）
+// 支持 multipart/form-data（文件字段为 file，filename 字段需位于 file 之前）或原始请求体（文件名通过 filename 查询参数传入）。
+// 网关边接收边计算 SHA-256 并暂存到本地临时文件，得到哈希后先做秒传检查，
+// 再按分片上传流程切分、上传并合并，等待合并完成后返回文件地址
 func SimpleUploadHandler(ctx context.Context, c *app.RequestContext) {
 	traceID, _ := c.Get("trace_id")
 
-	logger.Logger.Warn("简单上传功能暂未实现",
+	filename := c.Query("filename")
+	var body io.Reader
+	if boundary := string(c.Request.Header.MultipartFormBoundary()); boundary != "" {
+		form := multipart.NewReader(requestBody(c), boundary)
+		for body == nil {
+			part, err := form.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				logger.Logger.Warn("解析上传数据失败",
+					zap.Any("trace_id", traceID),
+					zap.Error(err),
+				)
+				c.JSON(consts.StatusBadRequest, map[string]interface{}{
+					"code": 400,
+					"msg":  "解析上传数据失败",
+				})
+				return
+			}
+			switch part.FormName() {
+			case "file":
+				body = part
+				if filename == "" {
+					filename = part.FileName()
+				}
+			case "filename":
+				filename = readFormValue(part)
+			}
+		}
+		if body == nil {
+			c.JSON(consts.StatusBadRequest, map[string]interface{}{
+				"code": 400,
+				"msg":  "未找到上传文件",
+			})
+			return
+		}
+	} else {
+		body = requestBody(c)
+	}
+
+	if filename == "" {
+		c.JSON(consts.StatusBadRequest, map[string]interface{}{
+			"code": 400,
+			"msg":  "文件名不能为空",
+		})
+		return
+	}
+
+	chunkSize := simpleChunkSize()
+	spool, err := spoolUpload(body, chunkSize)
+	if spool != nil {
+		defer spool.Close()
+	}
+	if err != nil {
+		logger.Logger.Warn("接收上传文件失败",
+			zap.Any("trace_id", traceID),
+			zap.Error(err),
+		)
+		c.JSON(consts.StatusBadRequest, map[string]interface{}{
+			"code": 400,
+			"msg":  "接收上传文件失败",
+		})
+		return
+	}
+	if spool.size == 0 {
+		c.JSON(consts.StatusBadRequest, map[string]interface{}{
+			"code": 400,
+			"msg":  "上传文件为空",
+		})
+		return
+	}
+
+	logger.Logger.Info("简单上传接收完成",
 		zap.Any("trace_id", traceID),
+		zap.String("file_hash", spool.fileHash),
+		zap.String("filename", filename),
+		zap.Int64("size", spool.size),
+		zap.Int("total_chunks", len(spool.chunkHashes)),
 	)
 
-	c.JSON(consts.StatusNotImplemented, map[string]interface{}{
-		"code": 501,
-		"msg":  "简单上传功能暂未实现，请使用分片上传",
+	// 1. 秒传检查
+	initResp, err := rpc.VideoClient.InitUpload(ctx, &video.InitUploadReq{
+		FileHash: spool.fileHash,
+		Filename: filename,
+		FileSize: spool.size,
 	})
-}
\ No newline at end of file
+	if err != nil {
+		logger.Logger.Error("RPC 调用失败",
+			zap.Any("trace_id", traceID),
+			zap.Error(err),
+		)
+		c.JSON(consts.StatusInternalServerError, map[string]interface{}{
+			"code": 500,
+			"msg":  "服务暂时不可用，请稍后重试",
+		})
+		return
+	}
+	if initResp.Code != 200 {
+		c.JSON(simpleUploadHTTPStatus(initResp.Code), map[string]interface{}{
+			"code": initResp.Code,
+			"msg":  initResp.Msg,
+		})
+		return
+	}
+	// 其他用户已上传过相同内容：用暂存的文件应答持有性证明，通过后即可秒传
+	if initResp.Status != "finished" && initResp.Challenge != nil {
+		answer, err := spool.answerChallenge(initResp.Challenge)
+		if err != nil {
+			logger.Logger.Warn("计算持有性证明失败",
+				zap.Any("trace_id", traceID),
+				zap.String("file_hash", spool.fileHash),
+				zap.Error(err),
+			)
+		} else if answered, err := rpc.VideoClient.InitUpload(ctx, &video.InitUploadReq{
+			FileHash:        spool.fileHash,
+			Filename:        filename,
+			FileSize:        spool.size,
+			ChallengeAnswer: &answer,
+		}); err == nil && answered.Code == 200 {
+			initResp = answered
+		}
+	}
+	if initResp.Status == "finished" {
+		logger.Logger.Info("简单上传秒传命中",
+			zap.Any("trace_id", traceID),
+			zap.String("file_hash", spool.fileHash),
+		)
+		c.JSON(consts.StatusOK, map[string]interface{}{
+			"code":      200,
+			"msg":       "秒传成功",
+			"file_hash": spool.fileHash,
+			"url":       initResp.Url,
+			"instant":   true,
+		})
+		return
+	}
+
+	// 2. 上传尚未完成的分片（之前的上传会话可能已经留下了一部分）
+	finished := make(map[string]bool, len(initResp.FinishedChunks))
+	for _, index := range initResp.FinishedChunks {
+		finished[index] = true
+	}
+	var pending []int32
+	for i := range spool.chunkHashes {
+		if !finished[strconv.Itoa(i)] {
+			pending = append(pending, int32(i))
+		}
+	}
+
+	// 3. 合并；已有分片若不是按相同大小切分的，合并校验会删除这些损坏分片，重新上传后再合并一次
+	var status *video.GetMergeStatusResp
+	for attempt := 0; attempt < 2; attempt++ {
+		if code, msg := spool.uploadChunks(ctx, pending); code != 200 {
+			logger.Logger.Error("简单上传分片失败",
+				zap.Any("trace_id", traceID),
+				zap.String("file_hash", spool.fileHash),
+				zap.Int32("code", code),
+				zap.String("msg", msg),
+			)
+			c.JSON(simpleUploadHTTPStatus(code), map[string]interface{}{
+				"code": code,
+				"msg":  msg,
+			})
+			return
+		}
+
+		status, err = mergeAndWait(ctx, spool.fileHash, filename, int32(len(spool.chunkHashes)))
+		if err != nil {
+			logger.Logger.Error("RPC 调用失败",
+				zap.Any("trace_id", traceID),
+				zap.Error(err),
+			)
+			c.JSON(consts.StatusInternalServerError, map[string]interface{}{
+				"code": 500,
+				"msg":  "服务暂时不可用，请稍后重试",
+			})
+			return
+		}
+		if status.Status != "failed" || len(status.BadChunks) == 0 {
+			break
+		}
+		logger.Logger.Warn("合并校验发现损坏分片，重新上传",
+			zap.Any("trace_id", traceID),
+			zap.String("file_hash", spool.fileHash),
+			zap.Int32s("bad_chunks", status.BadChunks),
+		)
+		pending = status.BadChunks
+	}
+
+	switch {
+	case status.Code != 200:
+		c.JSON(simpleUploadHTTPStatus(status.Code), map[string]interface{}{
+			"code": status.Code,
+			"msg":  status.Msg,
+		})
+	case status.Status == "completed":
+		logger.Logger.Info("简单上传成功",
+			zap.Any("trace_id", traceID),
+			zap.String("file_hash", spool.fileHash),
+			zap.String("url", status.Url),
+		)
+		c.JSON(consts.StatusOK, map[string]interface{}{
+			"code":      200,
+			"msg":       "上传成功",
+			"file_hash": spool.fileHash,
+			"url":       status.Url,
+			"instant":   false,
+		})
+	case status.Status == "failed":
+		c.JSON(consts.StatusInternalServerError, map[string]interface{}{
+			"code":   500,
+			"msg":    "合并失败: " + status.Error,
+			"job_id": status.JobId,
+		})
+	default:
+		// 合并仍在进行：返回任务ID，客户端通过 /api/video/merge/status 查询结果
+		c.JSON(consts.StatusAccepted, map[string]interface{}{
+			"code":      202,
+			"msg":       "文件已接收，合并进行中",
+			"file_hash": spool.fileHash,
+			"job_id":    status.JobId,
+			"status":    status.Status,
+		})
+	}
+}
+
+// simpleUploadHTTPStatus 将 RPC 响应码映射为 HTTP 状态码
+func simpleUploadHTTPStatus(code int32) int {
+	switch code {
+	case 200:
+		return consts.StatusOK
+	case 400:
+		return consts.StatusBadRequest
+	case 404:
+		return consts.StatusNotFound
+	case 409:
+		return consts.StatusConflict
+	default:
+		return consts.StatusInternalServerError
+	}
+}
+
+// simpleChunkSize 简单上传切分分片的大小，读取与 rpc-video 相同的 CHUNK_SIZE 环境变量
+func simpleChunkSize() int64 {
+	if size, err := strconv.ParseInt(os.Getenv("CHUNK_SIZE"), 10, 64); err == nil && size > 0 {
+		return size
+	}
+	return defaultSimpleChunkSize
+}
+
+// uploadSpool 暂存在本地临时文件中的上传内容，以及接收时计算的文件哈希和各分片的摘要
+type uploadSpool struct {
+	file        *os.File
+	size        int64
+	chunkSize   int64
+	fileHash    string
+	chunkHashes []string
+}
+
+// spoolUpload 将 r 写入临时文件，同时计算整个文件的 SHA-256 和每个分片的 SHA-256。
+// 返回非 nil 的 uploadSpool 时调用方负责 Close
+func spoolUpload(r io.Reader, chunkSize int64) (*uploadSpool, error) {
+	file, err := os.CreateTemp("", "simple-upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	spool := &uploadSpool{file: file, chunkSize: chunkSize}
+
+	fileHasher := sha256.New()
+	chunks := &chunkDigester{size: chunkSize}
+	spool.size, err = io.Copy(io.MultiWriter(file, fileHasher, chunks), r)
+	if err != nil {
+		return spool, err
+	}
+	spool.fileHash = hex.EncodeToString(fileHasher.Sum(nil))
+	spool.chunkHashes = chunks.finish()
+	return spool, nil
+}
+
+// Close 关闭并删除临时文件
+func (s *uploadSpool) Close() error {
+	s.file.Close()
+	return os.Remove(s.file.Name())
+}
+
+// answerChallenge 计算持有性证明的应答：hex(SHA-256(nonce + 区间内容))
+func (s *uploadSpool) answerChallenge(c *video.PossessionChallenge) (string, error) {
+	if c.Offset < 0 || c.Length < 0 || c.Offset+c.Length > s.size {
+		return "", fmt.Errorf("区间超出文件范围: %d+%d", c.Offset, c.Length)
+	}
+	h := sha256.New()
+	h.Write([]byte(c.Nonce))
+	if _, err := io.Copy(h, io.NewSectionReader(s.file, c.Offset, c.Length)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// uploadChunks 通过 UploadChunkStream 上传指定的分片，返回第一个失败的响应码与消息
+func (s *uploadSpool) uploadChunks(ctx context.Context, indices []int32) (int32, string) {
+	for _, i := range indices {
+		if int(i) < 0 || int(i) >= len(s.chunkHashes) {
+			return 500, fmt.Sprintf("分片索引越界: %d", i)
+		}
+		offset := int64(i) * s.chunkSize
+		size := s.chunkSize
+		if offset+size > s.size {
+			size = s.size - offset
+		}
+		chunkHash := s.chunkHashes[i]
+		frame := &video.UploadChunkFrame{
+			FileHash:  s.fileHash,
+			Index:     strconv.Itoa(int(i)),
+			ChunkHash: &chunkHash,
+			Size:      &size,
+		}
+		resp, _, err := streamChunk(ctx, frame, io.NewSectionReader(s.file, offset, size))
+		if err != nil {
+			return 500, "服务暂时不可用，请稍后重试"
+		}
+		if resp.Code != 200 {
+			return resp.Code, resp.Msg
+		}
+	}
+	return 200, ""
+}
+
+// chunkDigester 按固定大小切分写入的数据，逐个计算分片的 SHA-256
+type chunkDigester struct {
+	size    int64
+	hasher  hash.Hash
+	written int64
+	digests []string
+}
+
+func (d *chunkDigester) Write(p []byte) (int, error) {
+	total := len(p)
+	for len(p) > 0 {
+		if d.hasher == nil {
+			d.hasher = sha256.New()
+			d.written = 0
+		}
+		n := int64(len(p))
+		if n > d.size-d.written {
+			n = d.size - d.written
+		}
+		d.hasher.Write(p[:n])
+		d.written += n
+		p = p[n:]
+		if d.written == d.size {
+			d.digests = append(d.digests, hex.EncodeToString(d.hasher.Sum(nil)))
+			d.hasher = nil
+		}
+	}
+	return total, nil
+}
+
+// finish 返回所有分片的摘要，最后一个不足 size 的分片也计入
+func (d *chunkDigester) finish() []string {
+	if d.hasher != nil && d.written > 0 {
+		d.digests = append(d.digests, hex.EncodeToString(d.hasher.Sum(nil)))
+		d.hasher = nil
+	}
+	return d.digests
+}
+
+// mergeAndWait 创建合并任务并等待其结束，超时后返回最后一次查询到的状态
+func mergeAndWait(ctx context.Context, fileHash, filename string, totalChunks int32) (*video.GetMergeStatusResp, error) {
+	resp, err := rpc.VideoClient.MergeFile(ctx, &video.MergeFileReq{
+		FileHash:    fileHash,
+		Filename:    filename,
+		TotalChunks: totalChunks,
+	})
+	if err != nil {
+		return nil, err
+	}
+	status := &video.GetMergeStatusResp{
+		Code:      resp.Code,
+		Msg:       resp.Msg,
+		JobId:     resp.JobId,
+		Status:    resp.Status,
+		Url:       resp.Url,
+		BadChunks: resp.BadChunks,
+	}
+	if resp.Code != 200 || resp.JobId == "" {
+		return status, nil
+	}
+
+	deadline := time.Now().Add(mergeWaitTimeout)
+	for status.Status != "completed" && status.Status != "failed" {
+		if time.Now().After(deadline) {
+			logger.Logger.Warn("等待合并超时，合并任务仍在后台执行",
+				zap.String("file_hash", fileHash),
+				zap.String("job_id", status.JobId),
+			)
+			return status, nil
+		}
+		select {
+		case <-ctx.Done():
+			return status, nil
+		case <-time.After(mergePollInterval):
+		}
+		next, err := rpc.VideoClient.GetMergeStatus(ctx, &video.GetMergeStatusReq{JobId: resp.JobId})
+		if err != nil {
+			return nil, err
+		}
+		if next.Code != 200 {
+			return next, nil
+		}
+		status = next
+	}
+	return status, nil
+}
@@ -0,0 +1,143 @@
+package video
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"go.uber.org/zap"
+
+	"video-platform-microservice/gateway/internal/logger"
+	videogen "video-platform-microservice/gateway/kitex_gen/video"
+	"video-platform-microservice/gateway/rpc"
+)
+
+// maxPosterSize 上传封面的大小上限，与 rpc-video 保持一致
+const maxPosterSize = 5 * 1024 * 1024
+
+// ThumbnailFileHandler 提供封面、缩略图雪碧图与 WebVTT 缩略图轨道（需要认证）
+// GET /api/video/thumbs/:file_hash/*path
+func ThumbnailFileHandler(ctx context.Context, c *app.RequestContext) {
+	fileHash := c.Param("file_hash")
+	path := strings.TrimPrefix(c.Param("path"), "/")
+	if fileHash == "" || path == "" {
+		c.JSON(consts.StatusBadRequest, map[string]interface{}{
+			"code": 400,
+			"msg":  "参数错误",
+		})
+		return
+	}
+
+	traceID, _ := c.Get("trace_id")
+
+	resp, err := rpc.VideoClient.GetThumbnailFile(ctx, &videogen.GetThumbnailFileReq{
+		FileHash: fileHash,
+		Path:     path,
+	})
+	if err != nil {
+		logger.Logger.Error("RPC 调用失败",
+			zap.Any("trace_id", traceID),
+			zap.Error(err),
+		)
+		c.JSON(consts.StatusInternalServerError, map[string]interface{}{
+			"code": 500,
+			"msg":  "服务器错误",
+		})
+		return
+	}
+	if resp.Code != 200 {
+		c.JSON(transcodeHTTPStatus(resp.Code), map[string]interface{}{
+			"code": resp.Code,
+			"msg":  resp.Msg,
+		})
+		return
+	}
+
+	// 自定义封面地址带版本号，更换后地址随之变化；自动生成的缩略图不会变化
+	c.Response.Header.Set("Cache-Control", "private, max-age=86400")
+	c.Data(consts.StatusOK, resp.ContentType, resp.Data)
+}
+
+// SetPosterHandler 设置自定义封面（需要认证）
+// POST /api/video/poster，表单字段 file_hash 必填；上传图片 poster 或指定截取时间点 timestamp（秒），
+// 两者都不提供时恢复为自动截取的封面
+func SetPosterHandler(ctx context.Context, c *app.RequestContext) {
+	traceID, _ := c.Get("trace_id")
+
+	req := &videogen.SetPosterReq{FileHash: c.PostForm("file_hash")}
+	if req.FileHash == "" {
+		c.JSON(consts.StatusBadRequest, map[string]interface{}{
+			"code": 400,
+			"msg":  "file_hash 不能为空",
+		})
+		return
+	}
+
+	if ts := c.PostForm("timestamp"); ts != "" {
+		seconds, err := strconv.ParseFloat(ts, 64)
+		if err != nil || seconds < 0 {
+			c.JSON(consts.StatusBadRequest, map[string]interface{}{
+				"code": 400,
+				"msg":  "timestamp 格式错误",
+			})
+			return
+		}
+		req.Timestamp = &seconds
+	}
+
+	if file, err := c.FormFile("poster"); err == nil {
+		if file.Size > maxPosterSize {
+			c.JSON(consts.StatusBadRequest, map[string]interface{}{
+				"code": 400,
+				"msg":  "封面图片不能超过 5 MB",
+			})
+			return
+		}
+		src, err := file.Open()
+		if err != nil {
+			logger.Logger.Error("打开上传文件失败",
+				zap.Any("trace_id", traceID),
+				zap.Error(err),
+			)
+			c.JSON(consts.StatusInternalServerError, map[string]interface{}{
+				"code": 500,
+				"msg":  "处理上传文件失败",
+			})
+			return
+		}
+		defer src.Close()
+		if req.Image, err = io.ReadAll(io.LimitReader(src, maxPosterSize)); err != nil {
+			logger.Logger.Error("读取上传文件失败",
+				zap.Any("trace_id", traceID),
+				zap.Error(err),
+			)
+			c.JSON(consts.StatusInternalServerError, map[string]interface{}{
+				"code": 500,
+				"msg":  "处理上传文件失败",
+			})
+			return
+		}
+	}
+
+	resp, err := rpc.VideoClient.SetPoster(ctx, req)
+	if err != nil {
+		logger.Logger.Error("RPC 调用失败",
+			zap.Any("trace_id", traceID),
+			zap.Error(err),
+		)
+		c.JSON(consts.StatusInternalServerError, map[string]interface{}{
+			"code": 500,
+			"msg":  "服务器错误",
+		})
+		return
+	}
+
+	c.JSON(transcodeHTTPStatus(resp.Code), map[string]interface{}{
+		"code":       resp.Code,
+		"msg":        resp.Msg,
+		"poster_url": resp.PosterUrl,
+	})
+}
@@ -17,7 +17,10 @@ videogen "video-platform-microservice/gateway/kitex_gen/video"
 func TranscodeHandler(ctx context.Context, c *app.RequestContext) {
 var req struct {
 FileHash    string   `json:"file_hash" binding:"required"`
-Resolutions []string `json:"resolutions" binding:"required"`
+Resolutions []string `json:"resolutions"` // 内置编码配置名称，例如 "720p"
+Profiles    []string `json:"profiles"`    // 编码配置名称
+PerTitle    bool     `json:"per_title"`   // 按内容复杂度计算码率
+QualityMetrics bool  `json:"quality_metrics"` // 计算各分辨率的 PSNR/SSIM/VMAF
 UserID      string   `json:"user_id"`
 }
 
@@ -28,6 +31,13 @@ c.JSON(consts.StatusBadRequest, map[string]interface{}{
 })
 return
 }
+if len(req.Resolutions) == 0 && len(req.Profiles) == 0 {
+c.JSON(consts.StatusBadRequest, map[string]interface{}{
+"code": 400,
+"msg":  "resolutions 和 profiles 不能同时为空",
+})
+return
+}
 
 traceID, _ := c.Get("trace_id")
 logger.Logger.Info("创建转码任务",
@@ -35,6 +45,7 @@ zap.String("trace_id", traceID.(string)),
 zap.String("file_hash", req.FileHash),
 zap.String("user_id", req.UserID),
 zap.Any("resolutions", req.Resolutions),
+zap.Any("profiles", req.Profiles),
 )
 
 // 调用 RPC 服务
@@ -42,6 +53,9 @@ resp, err := rpc.VideoClient.Transcode(ctx, &videogen.TranscodeReq{
 FileHash:    req.FileHash,
 UserId:      req.UserID,
 Resolutions: req.Resolutions,
+Profiles:    req.Profiles,
+PerTitle:    req.PerTitle,
+QualityMetrics: req.QualityMetrics,
 })
 
 if err != nil {
@@ -56,7 +70,7 @@ c.JSON(consts.StatusInternalServerError, map[string]interface{}{
 return
 }
 
-c.JSON(consts.StatusOK, map[string]interface{}{
+c.JSON(transcodeHTTPStatus(resp.Code), map[string]interface{}{
 "code":    resp.Code,
 "msg":     resp.Msg,
 "task_id": resp.TaskId,
@@ -98,11 +112,147 @@ c.JSON(consts.StatusInternalServerError, map[string]interface{}{
 return
 }
 
+renditions := make([]map[string]interface{}, 0, len(resp.Renditions))
+for _, r := range resp.Renditions {
+renditions = append(renditions, map[string]interface{}{
+"name":        r.Name,
+"status":      r.Status,
+"progress":    r.Progress,
+"speed":       r.Speed,
+"eta_seconds": r.EtaSeconds,
+"error":       r.Error,
+"attempts":    r.Attempts,
+"reason":      r.Reason,
+"psnr":        r.Psnr,
+"ssim":        r.Ssim,
+"vmaf":        r.Vmaf,
+"quality_error": r.QualityError,
+})
+}
+
 c.JSON(consts.StatusOK, map[string]interface{}{
 "code":           resp.Code,
 "msg":            resp.Msg,
 "status":         resp.Status,
 "progress":       resp.Progress,
 "completed_urls": resp.CompletedUrls,
+"renditions":     renditions,
+"speed":          resp.Speed,
+"eta_seconds":    resp.EtaSeconds,
+"error":          resp.Error,
+"last_stderr":    resp.LastStderr,
+"per_title":      resp.PerTitle,
+"ladder":         resp.Ladder,
+"quality_metrics": resp.QualityMetrics,
+})
+}
+
+// CancelTranscodeHandler 取消转码任务
+func CancelTranscodeHandler(ctx context.Context, c *app.RequestContext) {
+var req struct {
+TaskID string `json:"task_id" binding:"required"`
+UserID string `json:"user_id"`
+}
+
+if err := c.BindAndValidate(&req); err != nil {
+c.JSON(consts.StatusBadRequest, map[string]interface{}{
+"code": 400,
+"msg":  fmt.Sprintf("参数错误: %v", err),
+})
+return
+}
+
+traceID, _ := c.Get("trace_id")
+logger.Logger.Info("取消转码任务",
+zap.Any("trace_id", traceID),
+zap.String("task_id", req.TaskID),
+)
+
+resp, err := rpc.VideoClient.CancelTranscode(ctx, &videogen.CancelTranscodeReq{
+TaskId: req.TaskID,
+UserId: req.UserID,
+})
+
+if err != nil {
+logger.Logger.Error("RPC 调用失败",
+zap.Any("trace_id", traceID),
+zap.Error(err),
+)
+c.JSON(consts.StatusInternalServerError, map[string]interface{}{
+"code": 500,
+"msg":  "服务器错误",
+})
+return
+}
+
+c.JSON(transcodeHTTPStatus(resp.Code), map[string]interface{}{
+"code":   resp.Code,
+"msg":    resp.Msg,
+"status": resp.Status,
+})
+}
+
+// RetryTranscodeHandler 重试失败、进入死信或已取消的转码任务
+func RetryTranscodeHandler(ctx context.Context, c *app.RequestContext) {
+var req struct {
+TaskID string `json:"task_id" binding:"required"`
+UserID string `json:"user_id"`
+}
+
+if err := c.BindAndValidate(&req); err != nil {
+c.JSON(consts.StatusBadRequest, map[string]interface{}{
+"code": 400,
+"msg":  fmt.Sprintf("参数错误: %v", err),
+})
+return
+}
+
+traceID, _ := c.Get("trace_id")
+logger.Logger.Info("重试转码任务",
+zap.Any("trace_id", traceID),
+zap.String("task_id", req.TaskID),
+)
+
+resp, err := rpc.VideoClient.RetryTranscode(ctx, &videogen.RetryTranscodeReq{
+TaskId: req.TaskID,
+UserId: req.UserID,
+})
+
+if err != nil {
+logger.Logger.Error("RPC 调用失败",
+zap.Any("trace_id", traceID),
+zap.Error(err),
+)
+c.JSON(consts.StatusInternalServerError, map[string]interface{}{
+"code": 500,
+"msg":  "服务器错误",
 })
+return
+}
+
+c.JSON(transcodeHTTPStatus(resp.Code), map[string]interface{}{
+"code":   resp.Code,
+"msg":    resp.Msg,
+"status": resp.Status,
+})
+}
+
+// transcodeHTTPStatus 将业务码映射为 HTTP 状态码
+func transcodeHTTPStatus(code int32) int {
+switch code {
+case 200:
+return consts.StatusOK
+case 400:
+return consts.StatusBadRequest
+case 404:
+return consts.StatusNotFound
+case 409:
+return consts.StatusConflict
+case 413:
+return consts.StatusRequestEntityTooLarge
+case 416:
+return consts.StatusRequestedRangeNotSatisfiable
+default:
+return consts.StatusInternalServerError
+}
 }
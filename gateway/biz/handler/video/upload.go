@@ -1,26 +1,74 @@
 package video
 
 import (
+"bytes"
 "context"
+"fmt"
 "io"
+"mime/multipart"
+"strconv"
 
 "video-platform-microservice/gateway/internal/logger"
 "video-platform-microservice/gateway/internal/validator"
 "video-platform-microservice/gateway/rpc"
 video "video-platform-microservice/gateway/kitex_gen/video"
 
+"github.com/bytedance/gopkg/cloud/metainfo"
 "github.com/cloudwego/hertz/pkg/app"
 "github.com/cloudwego/hertz/pkg/protocol/consts"
 "go.uber.org/zap"
 )
 
 // UploadChunkHandler 处理上传分片请求 (支持multipart/form-data)
+// 分片数据边读边以流的方式转发给 rpc-video；file_hash、index、chunk_hash、chunk_size 需位于 chunk 之前，也可以作为查询参数
 func UploadChunkHandler(ctx context.Context, c *app.RequestContext) {
 traceID, _ := c.Get("trace_id")
 
-// 从multipart form获取参数
-fileHash := c.PostForm("file_hash")
-index := c.PostForm("index")
+fileHash := c.Query("file_hash")
+index := c.Query("index")
+chunkHash := c.Query("chunk_hash") // 可选：分片摘要（MD5 或 SHA-256）
+chunkSize := c.Query("chunk_size") // 可选：分片字节数
+
+// 逐个读取 multipart 的字段，读到 chunk 时停止，chunk 的内容不在这里读取
+boundary := string(c.Request.Header.MultipartFormBoundary())
+if boundary == "" {
+c.JSON(consts.StatusBadRequest, map[string]interface{}{
+"code": 400,
+"msg":  "请使用 multipart/form-data 上传",
+})
+return
+}
+form := multipart.NewReader(requestBody(c), boundary)
+var chunk *multipart.Part
+for chunk == nil {
+part, err := form.NextPart()
+if err == io.EOF {
+break
+}
+if err != nil {
+logger.Logger.Warn("解析上传数据失败",
+zap.Any("trace_id", traceID),
+zap.Error(err),
+)
+c.JSON(consts.StatusBadRequest, map[string]interface{}{
+"code": 400,
+"msg":  "解析上传数据失败",
+})
+return
+}
+switch part.FormName() {
+case "chunk":
+chunk = part
+case "file_hash":
+fileHash = readFormValue(part)
+case "index":
+index = readFormValue(part)
+case "chunk_hash":
+chunkHash = readFormValue(part)
+case "chunk_size":
+chunkSize = readFormValue(part)
+}
+}
 
 // 验证必填参数
 if fileHash == "" {
@@ -59,65 +107,77 @@ c.JSON(consts.StatusBadRequest, map[string]interface{}{
 return
 }
 
-// 获取上传的文件
-file, err := c.FormFile("chunk")
-if err != nil {
-logger.Logger.Warn("获取上传文件失败",
+// 验证分片摘要格式（与文件哈希相同，支持 MD5 和 SHA-256）
+if chunkHash != "" {
+if err := validator.ValidateFileHash(chunkHash); err != nil {
+logger.Logger.Warn("分片摘要验证失败",
 zap.Any("trace_id", traceID),
+zap.String("chunk_hash", chunkHash),
 zap.Error(err),
 )
 c.JSON(consts.StatusBadRequest, map[string]interface{}{
 "code": 400,
-"msg":  "未找到上传文件",
+"msg":  "分片摘要格式不正确",
 })
 return
 }
+}
 
-// 打开文件读取数据
-src, err := file.Open()
-if err != nil {
-logger.Logger.Error("打开上传文件失败",
+if chunk == nil {
+logger.Logger.Warn("获取上传文件失败",
 zap.Any("trace_id", traceID),
-zap.Error(err),
 )
-c.JSON(consts.StatusInternalServerError, map[string]interface{}{
-"code": 500,
-"msg":  "处理上传文件失败",
+c.JSON(consts.StatusBadRequest, map[string]interface{}{
+"code": 400,
+"msg":  "未找到上传文件",
 })
 return
 }
-defer src.Close()
 
-// 读取文件内容
-data, err := io.ReadAll(src)
-if err != nil {
-logger.Logger.Error("读取文件数据失败",
+// 分片大小：chunk_size 字段优先，其次是 chunk 部分自带的 Content-Length；都没有时由 rpc-video 按实际长度保存
+if chunkSize == "" {
+chunkSize = chunk.Header.Get("Content-Length")
+}
+size := int64(-1)
+if chunkSize != "" {
+n, err := strconv.ParseInt(chunkSize, 10, 64)
+if err != nil || n <= 0 {
+logger.Logger.Warn("分片大小验证失败",
 zap.Any("trace_id", traceID),
-zap.Error(err),
+zap.String("chunk_size", chunkSize),
 )
-c.JSON(consts.StatusInternalServerError, map[string]interface{}{
-"code": 500,
-"msg":  "读取文件数据失败",
+c.JSON(consts.StatusBadRequest, map[string]interface{}{
+"code": 400,
+"msg":  "分片大小不正确",
 })
 return
 }
+size = n
+}
 
-logger.Logger.Info("调用 RPC UploadChunk",
+logger.Logger.Info("调用 RPC UploadChunkStream",
 zap.Any("trace_id", traceID),
 zap.String("file_hash", fileHash),
 zap.String("index", index),
-zap.Int("chunk_size", len(data)),
+zap.Int64("size", size),
 )
 
-resp, err := rpc.VideoClient.UploadChunk(ctx, &video.UploadChunkReq{
+frame := &video.UploadChunkFrame{
 FileHash: fileHash,
 Index:    index,
-Data:     data,
-})
+}
+if chunkHash != "" {
+frame.ChunkHash = &chunkHash
+}
+if size > 0 {
+frame.Size = &size
+}
 
+resp, sent, err := streamChunk(ctx, frame, chunk)
 if err != nil {
 logger.Logger.Error("RPC 调用失败",
 zap.Any("trace_id", traceID),
+zap.Int64("sent_bytes", sent),
 zap.Error(err),
 )
 c.JSON(consts.StatusInternalServerError, map[string]interface{}{
@@ -133,6 +193,8 @@ case 200:
 httpStatus = consts.StatusOK
 case 400:
 httpStatus = consts.StatusBadRequest
+case 409:
+httpStatus = consts.StatusConflict
 default:
 httpStatus = consts.StatusInternalServerError
 }
@@ -142,3 +204,80 @@ c.JSON(httpStatus, map[string]interface{}{
 "msg":  resp.Msg,
 })
 }
+
+// chunkFrameSize 转发分片时每帧的大小。gRPC 流控限制了在途的帧数，
+// 每个上传在网关和 rpc-video 中占用的内存只与帧大小有关，与分片大小无关
+const chunkFrameSize = 256 * 1024
+
+// streamChunk 将 r 的内容按帧发送给 rpc-video 的 UploadChunkStream，first 为携带元数据的第一帧。
+// 读取请求体失败时取消流，rpc-video 不会保存不完整的分片；返回已发送的字节数
+func streamChunk(ctx context.Context, first *video.UploadChunkFrame, r io.Reader) (*video.UploadChunkResp, int64, error) {
+ctx, cancel := context.WithCancel(ctx)
+defer cancel()
+
+// 流式调用不经过 metainfo 传递用户信息，认证用户放在第一帧中
+if userID, ok := metainfo.GetPersistentValue(ctx, "user_id"); ok {
+first.UserId = userID
+}
+
+stream, err := rpc.VideoStreamClient.UploadChunkStream(ctx)
+if err != nil {
+return nil, 0, err
+}
+
+var sent int64
+frame := first
+for {
+// 每帧使用新的缓冲：发送返回后消息仍可能被传输层引用
+buf := make([]byte, chunkFrameSize)
+n, readErr := readFrame(r, buf)
+if readErr != nil && readErr != io.EOF {
+return nil, sent, fmt.Errorf("读取上传数据失败: %w", readErr)
+}
+if n > 0 || frame == first {
+frame.Data = buf[:n]
+if err := stream.Send(frame); err != nil {
+if err != io.EOF {
+return nil, sent, err
+}
+// 服务端已提前返回（如分片已存在），响应在 CloseAndRecv 中获取
+break
+}
+sent += int64(n)
+frame = &video.UploadChunkFrame{}
+}
+if readErr == io.EOF {
+break
+}
+}
+
+resp, err := stream.CloseAndRecv()
+return resp, sent, err
+}
+
+// readFrame 读满 buf 或读到末尾；只有数据正常结束时返回 io.EOF
+func readFrame(r io.Reader, buf []byte) (int, error) {
+n := 0
+for n < len(buf) {
+m, err := r.Read(buf[n:])
+n += m
+if err != nil {
+return n, err
+}
+}
+return n, nil
+}
+
+// readFormValue 读取 multipart 中的短文本字段
+func readFormValue(part *multipart.Part) string {
+value, _ := io.ReadAll(io.LimitReader(part, 1024))
+return string(value)
+}
+
+// requestBody 返回请求体的读取流；未启用流式读取时返回已缓存的请求体
+func requestBody(c *app.RequestContext) io.Reader {
+if c.Request.IsBodyStream() {
+return c.RequestBodyStream()
+}
+return bytes.NewReader(c.Request.Body())
+}
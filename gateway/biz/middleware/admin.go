@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// AdminMiddleware 仅允许 ADMIN_USERS（逗号分隔的用户名）中的用户访问，需在 JWTAuthMiddleware 之后使用
+func AdminMiddleware() app.HandlerFunc {
+	admins := map[string]bool{}
+	for _, name := range strings.Split(os.Getenv("ADMIN_USERS"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			admins[name] = true
+		}
+	}
+
+	return func(ctx context.Context, c *app.RequestContext) {
+		username, _ := c.Get("username")
+		if name, ok := username.(string); !ok || !admins[name] {
+			c.JSON(consts.StatusForbidden, map[string]interface{}{
+				"code": 403,
+				"msg":  "需要管理员权限",
+			})
+			c.Abort()
+			return
+		}
+		c.Next(ctx)
+	}
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"io"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// MaxBufferedBodySize 非流式路由的请求体上限，需要容纳 5 MB 的封面图片和 multipart 的开销
+const MaxBufferedBodySize = 8 * 1024 * 1024
+
+// BufferBodyMiddleware 把请求体完整读入内存，超过 maxSize 时返回 413。
+// 服务端开启了 WithStreamBody，Hertz 不再校验请求体大小；
+// 除 streamPaths 中的上传路由外，其他路由都在这里恢复为有上限的缓存读取
+func BufferBodyMiddleware(maxSize int, streamPaths ...string) app.HandlerFunc {
+	stream := make(map[string]bool, len(streamPaths))
+	for _, path := range streamPaths {
+		stream[path] = true
+	}
+	return func(ctx context.Context, c *app.RequestContext) {
+		if stream[c.FullPath()] || !c.Request.IsBodyStream() {
+			c.Next(ctx)
+			return
+		}
+
+		if c.Request.Header.ContentLength() > maxSize {
+			rejectLargeBody(c)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(c.RequestBodyStream(), int64(maxSize)+1))
+		if err != nil {
+			c.JSON(consts.StatusBadRequest, map[string]interface{}{
+				"code": 400,
+				"msg":  "读取请求体失败",
+			})
+			c.Abort()
+			return
+		}
+		if len(body) > maxSize {
+			rejectLargeBody(c)
+			return
+		}
+		// chunked 请求没有 Content-Length，补上后 BindAndValidate 才会解析请求体
+		c.Request.SetBody(body)
+		c.Request.Header.SetContentLength(len(body))
+
+		c.Next(ctx)
+	}
+}
+
+// rejectLargeBody 返回 413；剩余的请求体没有读取，连接不能复用
+func rejectLargeBody(c *app.RequestContext) {
+	c.SetConnectionClose()
+	c.JSON(consts.StatusRequestEntityTooLarge, map[string]interface{}{
+		"code": 413,
+		"msg":  "请求体过大",
+	})
+	c.Abort()
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+const testMaxBody = 1024 * 1024
+
+// startServer 以与网关相同的 WithStreamBody 配置启动服务，返回地址
+func startServer(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := server.New(server.WithListener(ln), server.WithStreamBody(true), server.WithExitWaitTime(time.Second))
+	h.Use(BufferBodyMiddleware(testMaxBody, "/upload"))
+
+	h.POST("/upload", func(ctx context.Context, c *app.RequestContext) {
+		n, err := io.Copy(io.Discard, c.RequestBodyStream())
+		c.String(consts.StatusOK, "%v %d %v", c.Request.IsBodyStream(), n, err)
+	})
+	h.POST("/json", func(ctx context.Context, c *app.RequestContext) {
+		var req struct {
+			Name string `json:"name" vd:"len($)>0"`
+		}
+		if err := c.BindAndValidate(&req); err != nil {
+			c.String(consts.StatusBadRequest, err.Error())
+			return
+		}
+		c.String(consts.StatusOK, "%d", len(req.Name))
+	})
+	h.POST("/form", func(ctx context.Context, c *app.RequestContext) {
+		file, err := c.FormFile("poster")
+		if err != nil {
+			c.String(consts.StatusBadRequest, err.Error())
+			return
+		}
+		c.String(consts.StatusOK, "%s %d", c.PostForm("file_hash"), file.Size)
+	})
+
+	go h.Run() //nolint:errcheck
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = h.Shutdown(ctx)
+	})
+	return "http://" + ln.Addr().String()
+}
+
+// post 发送请求；chunked 为 true 时不带 Content-Length
+func post(t *testing.T, url, contentType string, body []byte, chunked bool) (int, string) {
+	t.Helper()
+	var r io.Reader = bytes.NewReader(body)
+	if chunked {
+		r = io.MultiReader(r) // 隐藏长度，net/http 改用 chunked 编码
+	}
+	req, err := http.NewRequest(http.MethodPost, url, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	out, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(out)
+}
+
+func TestBufferBodyMiddleware(t *testing.T) {
+	addr := startServer(t)
+
+	// 大于 Hertz 读缓冲区的请求体，确保走流式读取
+	name := strings.Repeat("a", 256*1024)
+	jsonBody := []byte(fmt.Sprintf(`{"name":%q}`, name))
+
+	var form bytes.Buffer
+	w := multipart.NewWriter(&form)
+	_ = w.WriteField("file_hash", "abc")
+	fw, _ := w.CreateFormFile("poster", "poster.jpg")
+	_, _ = fw.Write(bytes.Repeat([]byte{0xff}, 300*1024))
+	_ = w.Close()
+
+	big := bytes.Repeat([]byte("x"), 2*testMaxBody)
+
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		body        []byte
+		chunked     bool
+		status      int
+		want        string
+	}{
+		{name: "json", path: "/json", contentType: "application/json", body: jsonBody, status: 200, want: fmt.Sprint(len(name))},
+		{name: "chunked json", path: "/json", contentType: "application/json", body: jsonBody, chunked: true, status: 200, want: fmt.Sprint(len(name))},
+		{name: "multipart form", path: "/form", contentType: w.FormDataContentType(), body: form.Bytes(), status: 200, want: "abc 307200"},
+		{name: "chunked multipart form", path: "/form", contentType: w.FormDataContentType(), body: form.Bytes(), chunked: true, status: 200, want: "abc 307200"},
+		{name: "too large", path: "/json", contentType: "application/json", body: big, status: 413},
+		{name: "chunked too large", path: "/json", contentType: "application/json", body: big, chunked: true, status: 413},
+		{name: "chunked upload streams", path: "/upload", contentType: "application/octet-stream", body: big, chunked: true, status: 200, want: fmt.Sprintf("true %d <nil>", len(big))},
+		{name: "upload streams", path: "/upload", contentType: "application/octet-stream", body: big, status: 200, want: fmt.Sprintf("true %d <nil>", len(big))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := post(t, addr+tt.path, tt.contentType, tt.body, tt.chunked)
+			if status != tt.status {
+				t.Fatalf("status = %d, want %d (body %q)", status, tt.status, body)
+			}
+			if tt.want != "" && body != tt.want {
+				t.Errorf("body = %q, want %q", body, tt.want)
+			}
+		})
+	}
+}
@@ -5,8 +5,10 @@ package videoservice
 import (
 	"context"
 	"errors"
+	"fmt"
 	client "github.com/cloudwego/kitex/client"
 	kitex "github.com/cloudwego/kitex/pkg/serviceinfo"
+	streaming "github.com/cloudwego/kitex/pkg/streaming"
 	video "video-platform-microservice/gateway/kitex_gen/video"
 )
 
@@ -27,6 +29,13 @@ var serviceMethods = map[string]kitex.MethodInfo{
 		false,
 		kitex.WithStreamingMode(kitex.StreamingNone),
 	),
+	"UploadChunkStream": kitex.NewMethodInfo(
+		uploadChunkStreamHandler,
+		newVideoServiceUploadChunkStreamArgs,
+		newVideoServiceUploadChunkStreamResult,
+		false,
+		kitex.WithStreamingMode(kitex.StreamingClient),
+	),
 	"MergeFile": kitex.NewMethodInfo(
 		mergeFileHandler,
 		newVideoServiceMergeFileArgs,
@@ -34,6 +43,13 @@ var serviceMethods = map[string]kitex.MethodInfo{
 		false,
 		kitex.WithStreamingMode(kitex.StreamingNone),
 	),
+	"GetMergeStatus": kitex.NewMethodInfo(
+		getMergeStatusHandler,
+		newVideoServiceGetMergeStatusArgs,
+		newVideoServiceGetMergeStatusResult,
+		false,
+		kitex.WithStreamingMode(kitex.StreamingNone),
+	),
 	"DownloadChunk": kitex.NewMethodInfo(
 		downloadChunkHandler,
 		newVideoServiceDownloadChunkArgs,
@@ -41,6 +57,13 @@ var serviceMethods = map[string]kitex.MethodInfo{
 		false,
 		kitex.WithStreamingMode(kitex.StreamingNone),
 	),
+	"DownloadStream": kitex.NewMethodInfo(
+		downloadStreamHandler,
+		newVideoServiceDownloadStreamArgs,
+		newVideoServiceDownloadStreamResult,
+		false,
+		kitex.WithStreamingMode(kitex.StreamingServer),
+	),
 	"GetVideoInfo": kitex.NewMethodInfo(
 		getVideoInfoHandler,
 		newVideoServiceGetVideoInfoArgs,
@@ -48,6 +71,27 @@ var serviceMethods = map[string]kitex.MethodInfo{
 		false,
 		kitex.WithStreamingMode(kitex.StreamingNone),
 	),
+	"GetPlaybackFile": kitex.NewMethodInfo(
+		getPlaybackFileHandler,
+		newVideoServiceGetPlaybackFileArgs,
+		newVideoServiceGetPlaybackFileResult,
+		false,
+		kitex.WithStreamingMode(kitex.StreamingNone),
+	),
+	"GetThumbnailFile": kitex.NewMethodInfo(
+		getThumbnailFileHandler,
+		newVideoServiceGetThumbnailFileArgs,
+		newVideoServiceGetThumbnailFileResult,
+		false,
+		kitex.WithStreamingMode(kitex.StreamingNone),
+	),
+	"SetPoster": kitex.NewMethodInfo(
+		setPosterHandler,
+		newVideoServiceSetPosterArgs,
+		newVideoServiceSetPosterResult,
+		false,
+		kitex.WithStreamingMode(kitex.StreamingNone),
+	),
 	"Transcode": kitex.NewMethodInfo(
 		transcodeHandler,
 		newVideoServiceTranscodeArgs,
@@ -62,6 +106,83 @@ var serviceMethods = map[string]kitex.MethodInfo{
 		false,
 		kitex.WithStreamingMode(kitex.StreamingNone),
 	),
+	"CancelTranscode": kitex.NewMethodInfo(
+		cancelTranscodeHandler,
+		newVideoServiceCancelTranscodeArgs,
+		newVideoServiceCancelTranscodeResult,
+		false,
+		kitex.WithStreamingMode(kitex.StreamingNone),
+	),
+	"RetryTranscode": kitex.NewMethodInfo(
+		retryTranscodeHandler,
+		newVideoServiceRetryTranscodeArgs,
+		newVideoServiceRetryTranscodeResult,
+		false,
+		kitex.WithStreamingMode(kitex.StreamingNone),
+	),
+	"ListTranscodeProfiles": kitex.NewMethodInfo(
+		listTranscodeProfilesHandler,
+		newVideoServiceListTranscodeProfilesArgs,
+		newVideoServiceListTranscodeProfilesResult,
+		false,
+		kitex.WithStreamingMode(kitex.StreamingNone),
+	),
+	"SaveTranscodeProfile": kitex.NewMethodInfo(
+		saveTranscodeProfileHandler,
+		newVideoServiceSaveTranscodeProfileArgs,
+		newVideoServiceSaveTranscodeProfileResult,
+		false,
+		kitex.WithStreamingMode(kitex.StreamingNone),
+	),
+	"DeleteTranscodeProfile": kitex.NewMethodInfo(
+		deleteTranscodeProfileHandler,
+		newVideoServiceDeleteTranscodeProfileArgs,
+		newVideoServiceDeleteTranscodeProfileResult,
+		false,
+		kitex.WithStreamingMode(kitex.StreamingNone),
+	),
+	"DeleteVideo": kitex.NewMethodInfo(
+		deleteVideoHandler,
+		newVideoServiceDeleteVideoArgs,
+		newVideoServiceDeleteVideoResult,
+		false,
+		kitex.WithStreamingMode(kitex.StreamingNone),
+	),
+	"GetProcessingPolicy": kitex.NewMethodInfo(
+		getProcessingPolicyHandler,
+		newVideoServiceGetProcessingPolicyArgs,
+		newVideoServiceGetProcessingPolicyResult,
+		false,
+		kitex.WithStreamingMode(kitex.StreamingNone),
+	),
+	"SaveProcessingPolicy": kitex.NewMethodInfo(
+		saveProcessingPolicyHandler,
+		newVideoServiceSaveProcessingPolicyArgs,
+		newVideoServiceSaveProcessingPolicyResult,
+		false,
+		kitex.WithStreamingMode(kitex.StreamingNone),
+	),
+	"DeleteProcessingPolicy": kitex.NewMethodInfo(
+		deleteProcessingPolicyHandler,
+		newVideoServiceDeleteProcessingPolicyArgs,
+		newVideoServiceDeleteProcessingPolicyResult,
+		false,
+		kitex.WithStreamingMode(kitex.StreamingNone),
+	),
+	"GetPipeline": kitex.NewMethodInfo(
+		getPipelineHandler,
+		newVideoServiceGetPipelineArgs,
+		newVideoServiceGetPipelineResult,
+		false,
+		kitex.WithStreamingMode(kitex.StreamingNone),
+	),
+	"RetryPipeline": kitex.NewMethodInfo(
+		retryPipelineHandler,
+		newVideoServiceRetryPipelineArgs,
+		newVideoServiceRetryPipelineResult,
+		false,
+		kitex.WithStreamingMode(kitex.StreamingNone),
+	),
 }
 
 var (
@@ -87,7 +208,7 @@ func serviceInfoForClient() *kitex.ServiceInfo {
 
 // NewServiceInfo creates a new ServiceInfo containing all methods
 func NewServiceInfo() *kitex.ServiceInfo {
-	return newServiceInfo(false, true, true)
+	return newServiceInfo(true, true, true)
 }
 
 // NewServiceInfo creates a new ServiceInfo containing non-streaming methods
@@ -164,6 +285,58 @@ func newVideoServiceUploadChunkResult() interface{} {
 	return video.NewVideoServiceUploadChunkResult()
 }
 
+func uploadChunkStreamHandler(ctx context.Context, handler interface{}, arg, result interface{}) error {
+	st, ok := arg.(*streaming.Args)
+	if !ok {
+		return errors.New("VideoService.UploadChunkStream is a thrift streaming method, please call with Kitex StreamClient")
+	}
+	stream := &videoServiceUploadChunkStreamServer{st.Stream}
+	return handler.(video.VideoService).UploadChunkStream(stream)
+}
+
+type videoServiceUploadChunkStreamClient struct {
+	streaming.Stream
+}
+
+func (x *videoServiceUploadChunkStreamClient) DoFinish(err error) {
+	if finisher, ok := x.Stream.(streaming.WithDoFinish); ok {
+		finisher.DoFinish(err)
+	} else {
+		panic(fmt.Sprintf("streaming.WithDoFinish is not implemented by %T", x.Stream))
+	}
+}
+func (x *videoServiceUploadChunkStreamClient) Send(m *video.UploadChunkFrame) error {
+	return x.Stream.SendMsg(m)
+}
+func (x *videoServiceUploadChunkStreamClient) CloseAndRecv() (*video.UploadChunkResp, error) {
+	if err := x.Stream.Close(); err != nil {
+		return nil, err
+	}
+	m := new(video.UploadChunkResp)
+	return m, x.Stream.RecvMsg(m)
+}
+
+type videoServiceUploadChunkStreamServer struct {
+	streaming.Stream
+}
+
+func (x *videoServiceUploadChunkStreamServer) SendAndClose(m *video.UploadChunkResp) error {
+	return x.Stream.SendMsg(m)
+}
+
+func (x *videoServiceUploadChunkStreamServer) Recv() (*video.UploadChunkFrame, error) {
+	m := new(video.UploadChunkFrame)
+	return m, x.Stream.RecvMsg(m)
+}
+
+func newVideoServiceUploadChunkStreamArgs() interface{} {
+	return video.NewVideoServiceUploadChunkStreamArgs()
+}
+
+func newVideoServiceUploadChunkStreamResult() interface{} {
+	return video.NewVideoServiceUploadChunkStreamResult()
+}
+
 func mergeFileHandler(ctx context.Context, handler interface{}, arg, result interface{}) error {
 	realArg := arg.(*video.VideoServiceMergeFileArgs)
 	realResult := result.(*video.VideoServiceMergeFileResult)
@@ -182,6 +355,24 @@ func newVideoServiceMergeFileResult() interface{} {
 	return video.NewVideoServiceMergeFileResult()
 }
 
+func getMergeStatusHandler(ctx context.Context, handler interface{}, arg, result interface{}) error {
+	realArg := arg.(*video.VideoServiceGetMergeStatusArgs)
+	realResult := result.(*video.VideoServiceGetMergeStatusResult)
+	success, err := handler.(video.VideoService).GetMergeStatus(ctx, realArg.Req)
+	if err != nil {
+		return err
+	}
+	realResult.Success = success
+	return nil
+}
+func newVideoServiceGetMergeStatusArgs() interface{} {
+	return video.NewVideoServiceGetMergeStatusArgs()
+}
+
+func newVideoServiceGetMergeStatusResult() interface{} {
+	return video.NewVideoServiceGetMergeStatusResult()
+}
+
 func downloadChunkHandler(ctx context.Context, handler interface{}, arg, result interface{}) error {
 	realArg := arg.(*video.VideoServiceDownloadChunkArgs)
 	realResult := result.(*video.VideoServiceDownloadChunkResult)
@@ -200,6 +391,51 @@ func newVideoServiceDownloadChunkResult() interface{} {
 	return video.NewVideoServiceDownloadChunkResult()
 }
 
+func downloadStreamHandler(ctx context.Context, handler interface{}, arg, result interface{}) error {
+	st, ok := arg.(*streaming.Args)
+	if !ok {
+		return errors.New("VideoService.DownloadStream is a thrift streaming method, please call with Kitex StreamClient")
+	}
+	stream := &videoServiceDownloadStreamServer{st.Stream}
+	req := new(video.DownloadStreamReq)
+	if err := st.Stream.RecvMsg(req); err != nil {
+		return err
+	}
+	return handler.(video.VideoService).DownloadStream(req, stream)
+}
+
+type videoServiceDownloadStreamClient struct {
+	streaming.Stream
+}
+
+func (x *videoServiceDownloadStreamClient) DoFinish(err error) {
+	if finisher, ok := x.Stream.(streaming.WithDoFinish); ok {
+		finisher.DoFinish(err)
+	} else {
+		panic(fmt.Sprintf("streaming.WithDoFinish is not implemented by %T", x.Stream))
+	}
+}
+func (x *videoServiceDownloadStreamClient) Recv() (*video.DownloadFrame, error) {
+	m := new(video.DownloadFrame)
+	return m, x.Stream.RecvMsg(m)
+}
+
+type videoServiceDownloadStreamServer struct {
+	streaming.Stream
+}
+
+func (x *videoServiceDownloadStreamServer) Send(m *video.DownloadFrame) error {
+	return x.Stream.SendMsg(m)
+}
+
+func newVideoServiceDownloadStreamArgs() interface{} {
+	return video.NewVideoServiceDownloadStreamArgs()
+}
+
+func newVideoServiceDownloadStreamResult() interface{} {
+	return video.NewVideoServiceDownloadStreamResult()
+}
+
 func getVideoInfoHandler(ctx context.Context, handler interface{}, arg, result interface{}) error {
 	realArg := arg.(*video.VideoServiceGetVideoInfoArgs)
 	realResult := result.(*video.VideoServiceGetVideoInfoResult)
@@ -218,6 +454,60 @@ func newVideoServiceGetVideoInfoResult() interface{} {
 	return video.NewVideoServiceGetVideoInfoResult()
 }
 
+func getPlaybackFileHandler(ctx context.Context, handler interface{}, arg, result interface{}) error {
+	realArg := arg.(*video.VideoServiceGetPlaybackFileArgs)
+	realResult := result.(*video.VideoServiceGetPlaybackFileResult)
+	success, err := handler.(video.VideoService).GetPlaybackFile(ctx, realArg.Req)
+	if err != nil {
+		return err
+	}
+	realResult.Success = success
+	return nil
+}
+func newVideoServiceGetPlaybackFileArgs() interface{} {
+	return video.NewVideoServiceGetPlaybackFileArgs()
+}
+
+func newVideoServiceGetPlaybackFileResult() interface{} {
+	return video.NewVideoServiceGetPlaybackFileResult()
+}
+
+func getThumbnailFileHandler(ctx context.Context, handler interface{}, arg, result interface{}) error {
+	realArg := arg.(*video.VideoServiceGetThumbnailFileArgs)
+	realResult := result.(*video.VideoServiceGetThumbnailFileResult)
+	success, err := handler.(video.VideoService).GetThumbnailFile(ctx, realArg.Req)
+	if err != nil {
+		return err
+	}
+	realResult.Success = success
+	return nil
+}
+func newVideoServiceGetThumbnailFileArgs() interface{} {
+	return video.NewVideoServiceGetThumbnailFileArgs()
+}
+
+func newVideoServiceGetThumbnailFileResult() interface{} {
+	return video.NewVideoServiceGetThumbnailFileResult()
+}
+
+func setPosterHandler(ctx context.Context, handler interface{}, arg, result interface{}) error {
+	realArg := arg.(*video.VideoServiceSetPosterArgs)
+	realResult := result.(*video.VideoServiceSetPosterResult)
+	success, err := handler.(video.VideoService).SetPoster(ctx, realArg.Req)
+	if err != nil {
+		return err
+	}
+	realResult.Success = success
+	return nil
+}
+func newVideoServiceSetPosterArgs() interface{} {
+	return video.NewVideoServiceSetPosterArgs()
+}
+
+func newVideoServiceSetPosterResult() interface{} {
+	return video.NewVideoServiceSetPosterResult()
+}
+
 func transcodeHandler(ctx context.Context, handler interface{}, arg, result interface{}) error {
 	realArg := arg.(*video.VideoServiceTranscodeArgs)
 	realResult := result.(*video.VideoServiceTranscodeResult)
@@ -254,6 +544,204 @@ func newVideoServiceGetTranscodeStatusResult() interface{} {
 	return video.NewVideoServiceGetTranscodeStatusResult()
 }
 
+func cancelTranscodeHandler(ctx context.Context, handler interface{}, arg, result interface{}) error {
+	realArg := arg.(*video.VideoServiceCancelTranscodeArgs)
+	realResult := result.(*video.VideoServiceCancelTranscodeResult)
+	success, err := handler.(video.VideoService).CancelTranscode(ctx, realArg.Req)
+	if err != nil {
+		return err
+	}
+	realResult.Success = success
+	return nil
+}
+func newVideoServiceCancelTranscodeArgs() interface{} {
+	return video.NewVideoServiceCancelTranscodeArgs()
+}
+
+func newVideoServiceCancelTranscodeResult() interface{} {
+	return video.NewVideoServiceCancelTranscodeResult()
+}
+
+func retryTranscodeHandler(ctx context.Context, handler interface{}, arg, result interface{}) error {
+	realArg := arg.(*video.VideoServiceRetryTranscodeArgs)
+	realResult := result.(*video.VideoServiceRetryTranscodeResult)
+	success, err := handler.(video.VideoService).RetryTranscode(ctx, realArg.Req)
+	if err != nil {
+		return err
+	}
+	realResult.Success = success
+	return nil
+}
+func newVideoServiceRetryTranscodeArgs() interface{} {
+	return video.NewVideoServiceRetryTranscodeArgs()
+}
+
+func newVideoServiceRetryTranscodeResult() interface{} {
+	return video.NewVideoServiceRetryTranscodeResult()
+}
+
+func listTranscodeProfilesHandler(ctx context.Context, handler interface{}, arg, result interface{}) error {
+	realArg := arg.(*video.VideoServiceListTranscodeProfilesArgs)
+	realResult := result.(*video.VideoServiceListTranscodeProfilesResult)
+	success, err := handler.(video.VideoService).ListTranscodeProfiles(ctx, realArg.Req)
+	if err != nil {
+		return err
+	}
+	realResult.Success = success
+	return nil
+}
+func newVideoServiceListTranscodeProfilesArgs() interface{} {
+	return video.NewVideoServiceListTranscodeProfilesArgs()
+}
+
+func newVideoServiceListTranscodeProfilesResult() interface{} {
+	return video.NewVideoServiceListTranscodeProfilesResult()
+}
+
+func saveTranscodeProfileHandler(ctx context.Context, handler interface{}, arg, result interface{}) error {
+	realArg := arg.(*video.VideoServiceSaveTranscodeProfileArgs)
+	realResult := result.(*video.VideoServiceSaveTranscodeProfileResult)
+	success, err := handler.(video.VideoService).SaveTranscodeProfile(ctx, realArg.Req)
+	if err != nil {
+		return err
+	}
+	realResult.Success = success
+	return nil
+}
+func newVideoServiceSaveTranscodeProfileArgs() interface{} {
+	return video.NewVideoServiceSaveTranscodeProfileArgs()
+}
+
+func newVideoServiceSaveTranscodeProfileResult() interface{} {
+	return video.NewVideoServiceSaveTranscodeProfileResult()
+}
+
+func deleteTranscodeProfileHandler(ctx context.Context, handler interface{}, arg, result interface{}) error {
+	realArg := arg.(*video.VideoServiceDeleteTranscodeProfileArgs)
+	realResult := result.(*video.VideoServiceDeleteTranscodeProfileResult)
+	success, err := handler.(video.VideoService).DeleteTranscodeProfile(ctx, realArg.Req)
+	if err != nil {
+		return err
+	}
+	realResult.Success = success
+	return nil
+}
+func newVideoServiceDeleteTranscodeProfileArgs() interface{} {
+	return video.NewVideoServiceDeleteTranscodeProfileArgs()
+}
+
+func newVideoServiceDeleteTranscodeProfileResult() interface{} {
+	return video.NewVideoServiceDeleteTranscodeProfileResult()
+}
+
+func deleteVideoHandler(ctx context.Context, handler interface{}, arg, result interface{}) error {
+	realArg := arg.(*video.VideoServiceDeleteVideoArgs)
+	realResult := result.(*video.VideoServiceDeleteVideoResult)
+	success, err := handler.(video.VideoService).DeleteVideo(ctx, realArg.Req)
+	if err != nil {
+		return err
+	}
+	realResult.Success = success
+	return nil
+}
+func newVideoServiceDeleteVideoArgs() interface{} {
+	return video.NewVideoServiceDeleteVideoArgs()
+}
+
+func newVideoServiceDeleteVideoResult() interface{} {
+	return video.NewVideoServiceDeleteVideoResult()
+}
+
+func getProcessingPolicyHandler(ctx context.Context, handler interface{}, arg, result interface{}) error {
+	realArg := arg.(*video.VideoServiceGetProcessingPolicyArgs)
+	realResult := result.(*video.VideoServiceGetProcessingPolicyResult)
+	success, err := handler.(video.VideoService).GetProcessingPolicy(ctx, realArg.Req)
+	if err != nil {
+		return err
+	}
+	realResult.Success = success
+	return nil
+}
+func newVideoServiceGetProcessingPolicyArgs() interface{} {
+	return video.NewVideoServiceGetProcessingPolicyArgs()
+}
+
+func newVideoServiceGetProcessingPolicyResult() interface{} {
+	return video.NewVideoServiceGetProcessingPolicyResult()
+}
+
+func saveProcessingPolicyHandler(ctx context.Context, handler interface{}, arg, result interface{}) error {
+	realArg := arg.(*video.VideoServiceSaveProcessingPolicyArgs)
+	realResult := result.(*video.VideoServiceSaveProcessingPolicyResult)
+	success, err := handler.(video.VideoService).SaveProcessingPolicy(ctx, realArg.Req)
+	if err != nil {
+		return err
+	}
+	realResult.Success = success
+	return nil
+}
+func newVideoServiceSaveProcessingPolicyArgs() interface{} {
+	return video.NewVideoServiceSaveProcessingPolicyArgs()
+}
+
+func newVideoServiceSaveProcessingPolicyResult() interface{} {
+	return video.NewVideoServiceSaveProcessingPolicyResult()
+}
+
+func deleteProcessingPolicyHandler(ctx context.Context, handler interface{}, arg, result interface{}) error {
+	realArg := arg.(*video.VideoServiceDeleteProcessingPolicyArgs)
+	realResult := result.(*video.VideoServiceDeleteProcessingPolicyResult)
+	success, err := handler.(video.VideoService).DeleteProcessingPolicy(ctx, realArg.Req)
+	if err != nil {
+		return err
+	}
+	realResult.Success = success
+	return nil
+}
+func newVideoServiceDeleteProcessingPolicyArgs() interface{} {
+	return video.NewVideoServiceDeleteProcessingPolicyArgs()
+}
+
+func newVideoServiceDeleteProcessingPolicyResult() interface{} {
+	return video.NewVideoServiceDeleteProcessingPolicyResult()
+}
+
+func getPipelineHandler(ctx context.Context, handler interface{}, arg, result interface{}) error {
+	realArg := arg.(*video.VideoServiceGetPipelineArgs)
+	realResult := result.(*video.VideoServiceGetPipelineResult)
+	success, err := handler.(video.VideoService).GetPipeline(ctx, realArg.Req)
+	if err != nil {
+		return err
+	}
+	realResult.Success = success
+	return nil
+}
+func newVideoServiceGetPipelineArgs() interface{} {
+	return video.NewVideoServiceGetPipelineArgs()
+}
+
+func newVideoServiceGetPipelineResult() interface{} {
+	return video.NewVideoServiceGetPipelineResult()
+}
+
+func retryPipelineHandler(ctx context.Context, handler interface{}, arg, result interface{}) error {
+	realArg := arg.(*video.VideoServiceRetryPipelineArgs)
+	realResult := result.(*video.VideoServiceRetryPipelineResult)
+	success, err := handler.(video.VideoService).RetryPipeline(ctx, realArg.Req)
+	if err != nil {
+		return err
+	}
+	realResult.Success = success
+	return nil
+}
+func newVideoServiceRetryPipelineArgs() interface{} {
+	return video.NewVideoServiceRetryPipelineArgs()
+}
+
+func newVideoServiceRetryPipelineResult() interface{} {
+	return video.NewVideoServiceRetryPipelineResult()
+}
+
 type kClient struct {
 	c client.Client
 }
@@ -284,6 +772,20 @@ func (p *kClient) UploadChunk(ctx context.Context, req *video.UploadChunkReq) (r
 	return _result.GetSuccess(), nil
 }
 
+func (p *kClient) UploadChunkStream(ctx context.Context) (VideoService_UploadChunkStreamClient, error) {
+	streamClient, ok := p.c.(client.Streaming)
+	if !ok {
+		return nil, fmt.Errorf("client not support streaming")
+	}
+	res := new(streaming.Result)
+	err := streamClient.Stream(ctx, "UploadChunkStream", nil, res)
+	if err != nil {
+		return nil, err
+	}
+	stream := &videoServiceUploadChunkStreamClient{res.Stream}
+	return stream, nil
+}
+
 func (p *kClient) MergeFile(ctx context.Context, req *video.MergeFileReq) (r *video.MergeFileResp, err error) {
 	var _args video.VideoServiceMergeFileArgs
 	_args.Req = req
@@ -294,6 +796,16 @@ func (p *kClient) MergeFile(ctx context.Context, req *video.MergeFileReq) (r *vi
 	return _result.GetSuccess(), nil
 }
 
+func (p *kClient) GetMergeStatus(ctx context.Context, req *video.GetMergeStatusReq) (r *video.GetMergeStatusResp, err error) {
+	var _args video.VideoServiceGetMergeStatusArgs
+	_args.Req = req
+	var _result video.VideoServiceGetMergeStatusResult
+	if err = p.c.Call(ctx, "GetMergeStatus", &_args, &_result); err != nil {
+		return
+	}
+	return _result.GetSuccess(), nil
+}
+
 func (p *kClient) DownloadChunk(ctx context.Context, req *video.DownloadChunkReq) (r *video.DownloadChunkResp, err error) {
 	var _args video.VideoServiceDownloadChunkArgs
 	_args.Req = req
@@ -304,6 +816,27 @@ func (p *kClient) DownloadChunk(ctx context.Context, req *video.DownloadChunkReq
 	return _result.GetSuccess(), nil
 }
 
+func (p *kClient) DownloadStream(ctx context.Context, req *video.DownloadStreamReq) (VideoService_DownloadStreamClient, error) {
+	streamClient, ok := p.c.(client.Streaming)
+	if !ok {
+		return nil, fmt.Errorf("client not support streaming")
+	}
+	res := new(streaming.Result)
+	err := streamClient.Stream(ctx, "DownloadStream", nil, res)
+	if err != nil {
+		return nil, err
+	}
+	stream := &videoServiceDownloadStreamClient{res.Stream}
+
+	if err := stream.Stream.SendMsg(req); err != nil {
+		return nil, err
+	}
+	if err := stream.Stream.Close(); err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
 func (p *kClient) GetVideoInfo(ctx context.Context, req *video.GetVideoInfoReq) (r *video.GetVideoInfoResp, err error) {
 	var _args video.VideoServiceGetVideoInfoArgs
 	_args.Req = req
@@ -314,6 +847,36 @@ func (p *kClient) GetVideoInfo(ctx context.Context, req *video.GetVideoInfoReq)
 	return _result.GetSuccess(), nil
 }
 
+func (p *kClient) GetPlaybackFile(ctx context.Context, req *video.GetPlaybackFileReq) (r *video.GetPlaybackFileResp, err error) {
+	var _args video.VideoServiceGetPlaybackFileArgs
+	_args.Req = req
+	var _result video.VideoServiceGetPlaybackFileResult
+	if err = p.c.Call(ctx, "GetPlaybackFile", &_args, &_result); err != nil {
+		return
+	}
+	return _result.GetSuccess(), nil
+}
+
+func (p *kClient) GetThumbnailFile(ctx context.Context, req *video.GetThumbnailFileReq) (r *video.GetThumbnailFileResp, err error) {
+	var _args video.VideoServiceGetThumbnailFileArgs
+	_args.Req = req
+	var _result video.VideoServiceGetThumbnailFileResult
+	if err = p.c.Call(ctx, "GetThumbnailFile", &_args, &_result); err != nil {
+		return
+	}
+	return _result.GetSuccess(), nil
+}
+
+func (p *kClient) SetPoster(ctx context.Context, req *video.SetPosterReq) (r *video.SetPosterResp, err error) {
+	var _args video.VideoServiceSetPosterArgs
+	_args.Req = req
+	var _result video.VideoServiceSetPosterResult
+	if err = p.c.Call(ctx, "SetPoster", &_args, &_result); err != nil {
+		return
+	}
+	return _result.GetSuccess(), nil
+}
+
 func (p *kClient) Transcode(ctx context.Context, req *video.TranscodeReq) (r *video.TranscodeResp, err error) {
 	var _args video.VideoServiceTranscodeArgs
 	_args.Req = req
@@ -333,3 +896,113 @@ func (p *kClient) GetTranscodeStatus(ctx context.Context, req *video.GetTranscod
 	}
 	return _result.GetSuccess(), nil
 }
+
+func (p *kClient) CancelTranscode(ctx context.Context, req *video.CancelTranscodeReq) (r *video.CancelTranscodeResp, err error) {
+	var _args video.VideoServiceCancelTranscodeArgs
+	_args.Req = req
+	var _result video.VideoServiceCancelTranscodeResult
+	if err = p.c.Call(ctx, "CancelTranscode", &_args, &_result); err != nil {
+		return
+	}
+	return _result.GetSuccess(), nil
+}
+
+func (p *kClient) RetryTranscode(ctx context.Context, req *video.RetryTranscodeReq) (r *video.RetryTranscodeResp, err error) {
+	var _args video.VideoServiceRetryTranscodeArgs
+	_args.Req = req
+	var _result video.VideoServiceRetryTranscodeResult
+	if err = p.c.Call(ctx, "RetryTranscode", &_args, &_result); err != nil {
+		return
+	}
+	return _result.GetSuccess(), nil
+}
+
+func (p *kClient) ListTranscodeProfiles(ctx context.Context, req *video.ListTranscodeProfilesReq) (r *video.ListTranscodeProfilesResp, err error) {
+	var _args video.VideoServiceListTranscodeProfilesArgs
+	_args.Req = req
+	var _result video.VideoServiceListTranscodeProfilesResult
+	if err = p.c.Call(ctx, "ListTranscodeProfiles", &_args, &_result); err != nil {
+		return
+	}
+	return _result.GetSuccess(), nil
+}
+
+func (p *kClient) SaveTranscodeProfile(ctx context.Context, req *video.SaveTranscodeProfileReq) (r *video.SaveTranscodeProfileResp, err error) {
+	var _args video.VideoServiceSaveTranscodeProfileArgs
+	_args.Req = req
+	var _result video.VideoServiceSaveTranscodeProfileResult
+	if err = p.c.Call(ctx, "SaveTranscodeProfile", &_args, &_result); err != nil {
+		return
+	}
+	return _result.GetSuccess(), nil
+}
+
+func (p *kClient) DeleteTranscodeProfile(ctx context.Context, req *video.DeleteTranscodeProfileReq) (r *video.DeleteTranscodeProfileResp, err error) {
+	var _args video.VideoServiceDeleteTranscodeProfileArgs
+	_args.Req = req
+	var _result video.VideoServiceDeleteTranscodeProfileResult
+	if err = p.c.Call(ctx, "DeleteTranscodeProfile", &_args, &_result); err != nil {
+		return
+	}
+	return _result.GetSuccess(), nil
+}
+
+func (p *kClient) DeleteVideo(ctx context.Context, req *video.DeleteVideoReq) (r *video.DeleteVideoResp, err error) {
+	var _args video.VideoServiceDeleteVideoArgs
+	_args.Req = req
+	var _result video.VideoServiceDeleteVideoResult
+	if err = p.c.Call(ctx, "DeleteVideo", &_args, &_result); err != nil {
+		return
+	}
+	return _result.GetSuccess(), nil
+}
+
+func (p *kClient) GetProcessingPolicy(ctx context.Context, req *video.GetProcessingPolicyReq) (r *video.GetProcessingPolicyResp, err error) {
+	var _args video.VideoServiceGetProcessingPolicyArgs
+	_args.Req = req
+	var _result video.VideoServiceGetProcessingPolicyResult
+	if err = p.c.Call(ctx, "GetProcessingPolicy", &_args, &_result); err != nil {
+		return
+	}
+	return _result.GetSuccess(), nil
+}
+
+func (p *kClient) SaveProcessingPolicy(ctx context.Context, req *video.SaveProcessingPolicyReq) (r *video.SaveProcessingPolicyResp, err error) {
+	var _args video.VideoServiceSaveProcessingPolicyArgs
+	_args.Req = req
+	var _result video.VideoServiceSaveProcessingPolicyResult
+	if err = p.c.Call(ctx, "SaveProcessingPolicy", &_args, &_result); err != nil {
+		return
+	}
+	return _result.GetSuccess(), nil
+}
+
+func (p *kClient) DeleteProcessingPolicy(ctx context.Context, req *video.DeleteProcessingPolicyReq) (r *video.DeleteProcessingPolicyResp, err error) {
+	var _args video.VideoServiceDeleteProcessingPolicyArgs
+	_args.Req = req
+	var _result video.VideoServiceDeleteProcessingPolicyResult
+	if err = p.c.Call(ctx, "DeleteProcessingPolicy", &_args, &_result); err != nil {
+		return
+	}
+	return _result.GetSuccess(), nil
+}
+
+func (p *kClient) GetPipeline(ctx context.Context, req *video.GetPipelineReq) (r *video.GetPipelineResp, err error) {
+	var _args video.VideoServiceGetPipelineArgs
+	_args.Req = req
+	var _result video.VideoServiceGetPipelineResult
+	if err = p.c.Call(ctx, "GetPipeline", &_args, &_result); err != nil {
+		return
+	}
+	return _result.GetSuccess(), nil
+}
+
+func (p *kClient) RetryPipeline(ctx context.Context, req *video.RetryPipelineReq) (r *video.RetryPipelineResp, err error) {
+	var _args video.VideoServiceRetryPipelineArgs
+	_args.Req = req
+	var _result video.VideoServiceRetryPipelineResult
+	if err = p.c.Call(ctx, "RetryPipeline", &_args, &_result); err != nil {
+		return
+	}
+	return _result.GetSuccess(), nil
+}
@@ -34,7 +34,9 @@ commonlogger.Logger.Fatal("JWT 初始化失败", zap.Error(err))
 //初始化 RPC 客户端
 rpc.InitRPC()
 
-h := server.Default(server.WithHostPorts(":8080"))
+// 请求体以流的方式读取：上传的分片边读边转发给 rpc-video，不在网关内存中缓存整个请求。
+// 该选项对整个服务生效，非上传路由由 BufferBodyMiddleware 缓存请求体并限制大小（见 router.go）
+h := server.Default(server.WithHostPorts(":8080"), server.WithStreamBody(true))
 
 register(h)
 
@@ -13,6 +13,8 @@ videoHandler "video-platform-microservice/gateway/biz/handler/video"
 func customizedRegister(r *server.Hertz) {
 // 全局中间件：请求追踪 ID
 r.Use(middleware.TraceIDMiddleware())
+// 只有上传路由按流读取请求体，其他路由缓存整个请求体并限制大小
+r.Use(middleware.BufferBodyMiddleware(middleware.MaxBufferedBodySize, "/api/video/upload_chunk", "/api/video/upload"))
 
 r.GET("/ping", handler.Ping)
 
@@ -31,18 +33,44 @@ protected.GET("/profile", userHandler.GetProfileHandler)
 
 // 视频下载和信息查看（需要认证）
 protected.GET("/video/download", videoHandler.DownloadHandler)
+protected.HEAD("/video/download", videoHandler.DownloadHandler)
 protected.GET("/video/info", videoHandler.GetVideoInfoHandler)
+protected.GET("/video/play/:file_hash/*path", videoHandler.PlaybackFileHandler) // HLS/DASH 播放清单与分片
+protected.GET("/video/thumbs/:file_hash/*path", videoHandler.ThumbnailFileHandler) // 封面、雪碧图与 WebVTT 缩略图轨道
+protected.POST("/video/poster", videoHandler.SetPosterHandler) // 设置自定义封面
+protected.DELETE("/video", videoHandler.DeleteVideoHandler) // 删除视频
 
 // 视频上传相关
 protected.POST("/video/init", videoHandler.InitUploadHandler)         // 初始化上传
 protected.POST("/video/upload_chunk", videoHandler.UploadChunkHandler) // 上传分片
-protected.POST("/video/merge", videoHandler.MergeFileHandler)         // 合并文件
+protected.POST("/video/merge", videoHandler.MergeFileHandler)         // 合并文件（异步）
+protected.GET("/video/merge/status", videoHandler.GetMergeStatusHandler) // 查询合并任务状态
 protected.POST("/video/upload", videoHandler.SimpleUploadHandler)     // 简单上传
 protected.POST("/video/hash", videoHandler.CalculateFileHashHandler)  // 计算 Hash
 
 // 转码相关
 protected.POST("/video/transcode", videoHandler.TranscodeHandler)                  // 创建转码任务
 protected.GET("/video/transcode/status", videoHandler.GetTranscodeStatusHandler) // 查询转码状态
+protected.POST("/video/transcode/cancel", videoHandler.CancelTranscodeHandler)    // 取消转码任务
+protected.POST("/video/transcode/retry", videoHandler.RetryTranscodeHandler)      // 重试转码任务
+protected.GET("/video/transcode/profiles", videoHandler.ListTranscodeProfilesHandler) // 列出编码配置
+
+// 上传后的自动处理
+protected.GET("/video/policy", videoHandler.GetPolicyHandler)            // 查询生效的处理策略
+protected.POST("/video/policy", videoHandler.SavePolicyHandler)          // 保存用户的处理策略
+protected.DELETE("/video/policy", videoHandler.DeletePolicyHandler)      // 删除用户的处理策略（改用平台默认策略）
+protected.GET("/video/pipeline", videoHandler.GetPipelineHandler)        // 查询处理流程
+protected.POST("/video/pipeline/retry", videoHandler.RetryPipelineHandler) // 重试失败的步骤
+
+// 管理员接口（ADMIN_USERS 中的用户）
+admin := protected.Group("/admin", middleware.AdminMiddleware())
+{
+admin.POST("/transcode/profiles", videoHandler.SaveTranscodeProfileHandler)     // 创建或更新编码配置
+admin.DELETE("/transcode/profiles", videoHandler.DeleteTranscodeProfileHandler) // 删除编码配置
+admin.GET("/policy", videoHandler.GetPlatformPolicyHandler)       // 查询平台默认处理策略
+admin.POST("/policy", videoHandler.SavePlatformPolicyHandler)     // 保存平台默认处理策略
+admin.DELETE("/policy", videoHandler.DeletePlatformPolicyHandler) // 删除平台默认处理策略
+}
 }
 }
 }
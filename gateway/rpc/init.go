@@ -7,12 +7,16 @@ import (
 "video-platform-microservice/gateway/kitex_gen/video/videoservice"
 
 "github.com/cloudwego/kitex/client"
+"github.com/cloudwego/kitex/client/streamclient"
 etcd "github.com/kitex-contrib/registry-etcd"
 )
 
 var UserClient userservice.Client
 var VideoClient videoservice.Client
 
+// VideoStreamClient Video 服务的流式接口（gRPC 传输），用于分片的流式上传
+var VideoStreamClient videoservice.StreamClient
+
 // InitRPC 初始化所有 RPC 客户端
 func InitRPC() {
 // 创建 Etcd 服务发现解析器
@@ -32,6 +36,10 @@ VideoClient, err = videoservice.NewClient("video", client.WithResolver(r))
 if err != nil {
 log.Fatalf("初始化 Video 客户端失败: %v", err)
 }
+VideoStreamClient, err = videoservice.NewStreamClient("video", streamclient.WithResolver(r))
+if err != nil {
+log.Fatalf("初始化 Video 流式客户端失败: %v", err)
+}
 
 log.Println("✅ RPC 客户端初始化成功 (User + Video)")
 }
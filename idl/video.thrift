@@ -1,5 +1,12 @@
 namespace go video
 
+// 跨用户秒传的持有性证明：对文件 [offset, offset+length) 区间计算 hex(SHA-256(nonce + 区间内容))
+struct PossessionChallenge {
+    1: string nonce
+    2: i64 offset
+    3: i64 length
+}
+
 struct InitUploadReq {
     1: string file_hash
     2: string filename // 可选
@@ -8,6 +15,7 @@ struct InitUploadReq {
     5: i32 width       // 视频宽度（分辨率）
     6: i32 height      // 视频高度（分辨率）
     7: string request_id // 请求ID，用于幂等性
+    8: optional string challenge_answer // 对上一次返回的 challenge 的应答
 }
 
 struct InitUploadResp {
@@ -16,6 +24,7 @@ struct InitUploadResp {
     3: string status // "uploading" or "finished"
     4: list<string> finished_chunks
     5: string url
+    6: optional PossessionChallenge challenge // 其他用户已上传过相同内容时返回，应答正确即可秒传，否则按正常流程上传
 }
 
 struct UploadChunkReq {
@@ -23,6 +32,7 @@ struct UploadChunkReq {
     2: string index
     3: binary data // 核心：通过 RPC 传输分片数据
     4: string user_id  // 用户ID
+    5: optional string chunk_hash // 分片摘要（MD5 或 SHA-256 十六进制），可选，提供时服务端校验
 }
 
 struct UploadChunkResp {
@@ -30,6 +40,16 @@ struct UploadChunkResp {
     2: string msg
 }
 
+// UploadChunkStream 的一帧：第一帧携带分片的元数据，之后的帧只携带数据
+struct UploadChunkFrame {
+    1: string file_hash
+    2: string index
+    3: binary data
+    4: string user_id
+    5: optional string chunk_hash // 分片摘要（MD5 或 SHA-256 十六进制），可选，提供时服务端校验
+    6: optional i64 size          // 分片总字节数，可选，提供时服务端校验长度
+}
+
 struct MergeFileReq {
     1: string file_hash
     2: string filename
@@ -44,6 +64,28 @@ struct MergeFileResp {
     1: i32 code
     2: string msg
     3: string url
+    4: list<i32> bad_chunks // 完整性校验失败时，摘要不匹配的分片索引
+    5: string job_id        // 异步合并任务ID，通过 GetMergeStatus 查询进度
+    6: string status        // 合并任务状态: "queued", "running", "completed", "failed"
+}
+
+// 查询合并任务状态
+struct GetMergeStatusReq {
+    1: string job_id
+    2: string user_id
+}
+
+struct GetMergeStatusResp {
+    1: i32 code
+    2: string msg
+    3: string job_id
+    4: string status       // "queued", "running", "completed", "failed"
+    5: string phase        // "queued", "verifying", "merging", "probing", "saving", "done"
+    6: i64 bytes_merged
+    7: i64 total_bytes
+    8: string url          // 完成后的文件 URL
+    9: list<i32> bad_chunks
+    10: string error
 }
 
 // 下载视频分片
@@ -51,7 +93,7 @@ struct DownloadChunkReq {
     1: string file_hash
     2: i32 chunk_index
     3: i64 start_byte    // 起始字节
-    4: i64 end_byte      // 结束字节
+    4: i64 end_byte      // 结束字节（不含），<= 0 表示读到文件末尾；单次最多返回 32MB，更大的区间用 DownloadStream
 }
 
 struct DownloadChunkResp {
@@ -61,12 +103,50 @@ struct DownloadChunkResp {
     4: i64 total_size
 }
 
+// DownloadStream 的请求：下载 [start_byte, end_byte) 区间，end_byte <= 0 表示读到文件末尾
+struct DownloadStreamReq {
+    1: string file_hash
+    2: i64 start_byte
+    3: i64 end_byte
+    4: bool head_only // 只返回第一帧（文件信息），不打开文件、不发送数据
+}
+
+// DownloadStream 的一帧：第一帧携带状态与实际的区间，之后的帧只携带数据
+struct DownloadFrame {
+    1: i32 code
+    2: string msg
+    3: binary data
+    4: i64 total_size
+    5: i64 start_byte
+    6: i64 end_byte // 不含
+    7: string filename
+    8: i64 last_modified // 文件的上传时间（Unix 秒）；内容按哈希寻址，上传后不会改变
+    9: string content_type
+}
+
 // 获取视频信息
 struct GetVideoInfoReq {
     1: string file_hash
     2: string user_id
 }
 
+// 媒体信息（ffprobe 探测结果）
+struct MediaInfo {
+    1: double duration              // 时长（秒）
+    2: string container             // 封装格式：mp4, mov, mkv, webm ...
+    3: string video_codec           // 视频编码：h264, hevc, vp9, av1 ...，没有视频流时为空
+    4: string audio_codec           // 音频编码：aac, opus ...，没有音频流时为空
+    5: i64 bitrate                  // 总码率（bit/s）
+    6: double frame_rate            // 平均帧率
+    7: i32 rotation                 // 播放时需顺时针旋转的角度：0, 90, 180, 270
+    8: i32 audio_channels
+    9: i32 audio_sample_rate
+    10: bool hdr
+    11: string hdr_format           // hdr10, hlg, dolby_vision，SDR 时为空
+    12: i32 stream_count            // 音视频、字幕等流的总数
+    13: bool faststart              // MP4/MOV 的 moov 位于 mdat 之前，可以边下载边播放
+}
+
 struct GetVideoInfoResp {
     1: i32 code
     2: string msg
@@ -78,14 +158,66 @@ struct GetVideoInfoResp {
     8: string url
     9: list<string> transcode_urls  // 转码后的URL列表
     10: string transcode_status     // "pending", "processing", "completed", "failed"
+    11: string playback_url         // HLS 主播放列表地址，转码打包完成前为空
+    12: string dash_url             // DASH 清单地址，未启用 DASH 打包时为空
+    13: string poster_url           // 封面：自定义封面优先，否则为自动截取的封面
+    14: string thumbnails_vtt_url   // WebVTT 缩略图轨道（拖动进度条预览）
+    15: list<string> sprite_urls    // 缩略图雪碧图
+    16: string thumbnail_status     // "pending", "processing", "completed", "failed"
+    17: MediaInfo media             // 合并时探测的媒体信息，未探测时 stream_count 为 0
+}
+
+// 获取缩略图文件（封面、雪碧图、WebVTT）
+struct GetThumbnailFileReq {
+    1: string file_hash
+    2: string user_id
+    3: string path       // 例如 "poster.jpg"、"sprite_000.jpg"、"thumbnails.vtt"
+}
+
+struct GetThumbnailFileResp {
+    1: i32 code
+    2: string msg
+    3: binary data
+    4: string content_type
+}
+
+// 设置封面：上传图片或指定截取时间点；两者都为空时恢复为自动截取的封面
+struct SetPosterReq {
+    1: string file_hash
+    2: string user_id
+    3: optional double timestamp  // 截取时间点（秒）
+    4: binary image               // JPEG / PNG / WebP
+}
+
+struct SetPosterResp {
+    1: i32 code
+    2: string msg
+    3: string poster_url
+}
+
+// 获取播放文件（播放列表、初始化分片、媒体分片）
+struct GetPlaybackFileReq {
+    1: string file_hash
+    2: string user_id
+    3: string path       // 相对于视频播放目录的路径，例如 "master.m3u8"、"manifest.mpd"、"720p/seg_00001.m4s"
+}
+
+struct GetPlaybackFileResp {
+    1: i32 code
+    2: string msg
+    3: binary data
+    4: string content_type
 }
 
 // 转码请求
 struct TranscodeReq {
     1: string file_hash
     2: string user_id
-    3: list<string> resolutions  // 例如 ["720p", "480p", "360p"]
+    3: list<string> resolutions  // 例如 ["720p", "480p", "360p"]，即同名的内置编码配置
     4: string request_id // 请求ID，用于幂等性
+    5: list<string> profiles     // 编码配置名称，与 resolutions 合并后按顺序转码
+    6: bool per_title            // 先对采样片段做快速 CRF 编码，按内容复杂度计算各分辨率的码率
+    7: bool quality_metrics      // 每个分辨率完成后与源视频比较，计算 PSNR/SSIM（ffmpeg 支持时还有 VMAF）
 }
 
 struct TranscodeResp {
@@ -99,20 +231,250 @@ struct GetTranscodeStatusReq {
     1: string task_id
 }
 
+// 单个分辨率的转码进度
+struct RenditionProgress {
+    1: string name
+    2: string status      // "pending", "processing", "retrying", "completed", "failed", "skipped"
+    3: i32 progress       // 0-100
+    4: double speed       // 编码速度（相对实时的倍数）
+    5: i64 eta_seconds    // 预计剩余秒数，-1 表示未知
+    6: string error
+    7: i32 attempts       // 已尝试次数（失败后按指数退避自动重试）
+    8: string reason      // 跳过原因（高于源分辨率不放大），或对配置的调整说明
+    9: optional double psnr   // 平均 PSNR（dB），相同画面记为 100
+    10: optional double ssim  // 0-1
+    11: optional double vmaf  // 0-100
+    12: string quality_error  // 质量指标计算失败的原因，不影响转码结果
+}
+
+// 码率阶梯中的一个分辨率
+struct LadderRung {
+    1: string name
+    2: i32 height
+    3: i64 probe_bitrate       // 采样编码在参考质量下的平均码率（bit/s）
+    4: string default_bitrate  // 配置中的固定码率
+    5: string video_bitrate    // 实际使用的目标码率
+    6: string max_bitrate      // 实际使用的峰值码率，cbr 为空
+    7: string skipped          // 未分析的原因（例如 crf 配置），此时使用配置码率
+}
+
 struct GetTranscodeStatusResp {
     1: i32 code
     2: string msg
     3: string status
     4: i32 progress  // 0-100
     5: list<string> completed_urls
+    6: list<RenditionProgress> renditions
+    7: double speed       // 当前编码速度（相对实时的倍数）
+    8: i64 eta_seconds    // 整个任务的预计剩余秒数，-1 表示未知
+    9: string error
+    10: string last_stderr // 最后一次失败的 ffmpeg 输出（末尾部分）
+    11: bool per_title
+    12: list<LadderRung> ladder // 按内容复杂度计算出的码率阶梯，分析完成前为空
+    13: bool quality_metrics
+}
+
+// 取消转码：终止正在运行的 ffmpeg 并清理已生成的输出
+struct CancelTranscodeReq {
+    1: string task_id
+    2: string user_id
+}
+
+struct CancelTranscodeResp {
+    1: i32 code
+    2: string msg
+    3: string status
+}
+
+// 重试转码：重新执行失败、进入死信或已取消的任务（已完成的分辨率不会重复转码）
+struct RetryTranscodeReq {
+    1: string task_id
+    2: string user_id
+}
+
+struct RetryTranscodeResp {
+    1: i32 code
+    2: string msg
+    3: string status
+}
+
+// 编码配置
+struct TranscodeProfile {
+    1: string name
+    2: string codec            // "h264", "h265", "vp9", "av1"
+    3: string rate_control     // "cbr", "vbr", "crf", "two_pass"
+    4: string container        // "mp4", "webm", "mkv"
+    5: i32 width               // 0 表示按高度等比缩放
+    6: i32 height
+    7: string video_bitrate    // 例如 "2500k"，cbr/vbr/two_pass 必填
+    8: string max_bitrate      // vbr/crf 的码率上限，可为空
+    9: i32 crf                 // crf 模式的质量参数
+    10: string preset          // 编码器预设，例如 "medium"（h264/h265）、"good"（vp9）、"8"（av1）
+    11: string audio_codec     // "aac", "opus", "none"
+    12: string audio_bitrate   // 例如 "128k"
+    13: i32 audio_channels     // 0 表示保持源声道数
+    14: i32 audio_sample_rate  // 0 表示保持源采样率
+    15: bool builtin           // 内置配置不可删除
+    16: string fit_mode        // 宽高比与源不一致时："fit"（等比缩放，默认）、"pad"（补黑边）、"crop"（裁剪）
+}
+
+struct ListTranscodeProfilesReq {
+}
+
+struct ListTranscodeProfilesResp {
+    1: i32 code
+    2: string msg
+    3: list<TranscodeProfile> profiles
+}
+
+// 创建或更新编码配置（管理员）
+struct SaveTranscodeProfileReq {
+    1: TranscodeProfile profile
+}
+
+struct SaveTranscodeProfileResp {
+    1: i32 code
+    2: string msg
+    3: TranscodeProfile profile
+}
+
+// 删除编码配置（管理员）
+struct DeleteTranscodeProfileReq {
+    1: string name
+}
+
+struct DeleteTranscodeProfileResp {
+    1: i32 code
+    2: string msg
+}
+
+// 上传后的处理策略：合并完成后按顺序自动执行启用的步骤
+// 步骤：probe（探测媒体信息）、validate（校验）、thumbnails（缩略图）、transcode（转码）、package（打包 HLS）
+struct ProcessingPolicy {
+    1: list<string> steps
+    2: list<string> profiles       // transcode 步骤使用的编码配置名称
+    3: bool per_title
+    4: bool quality_metrics
+    5: bool require_video          // validate：必须包含视频流
+    6: i32 max_duration_seconds    // validate：最长时长，0 表示不限制
+    7: i32 min_short_side          // validate：画面短边的最小值，0 表示不限制
+    8: i32 max_short_side          // validate：画面短边的最大值，0 表示不限制
+    9: bool enabled                // 停用的用户策略表示不做自动处理（不回退到平台默认策略）
+}
+
+// 查询处理策略：platform 为 true 时查询平台默认策略（管理员），否则查询对用户生效的策略
+struct GetProcessingPolicyReq {
+    1: string user_id
+    2: bool platform
+}
+
+struct GetProcessingPolicyResp {
+    1: i32 code
+    2: string msg
+    3: ProcessingPolicy policy
+    4: string scope  // "user" 用户自己的策略，"platform" 平台默认策略，"" 没有策略
+}
+
+// 保存处理策略：platform 为 true 时保存平台默认策略（管理员）
+struct SaveProcessingPolicyReq {
+    1: string user_id
+    2: bool platform
+    3: ProcessingPolicy policy
+}
+
+struct SaveProcessingPolicyResp {
+    1: i32 code
+    2: string msg
+    3: ProcessingPolicy policy
+}
+
+// 删除处理策略：删除用户策略后改用平台默认策略
+struct DeleteProcessingPolicyReq {
+    1: string user_id
+    2: bool platform
+}
+
+struct DeleteProcessingPolicyResp {
+    1: i32 code
+    2: string msg
+}
+
+struct PipelineStep {
+    1: string name
+    2: string status       // "pending", "running", "completed", "failed"
+    3: string error
+    4: i32 attempts
+    5: string output       // 步骤产出：媒体信息摘要、转码任务ID、播放地址等
+    6: i64 started_at      // Unix 秒，0 表示未开始
+    7: i64 finished_at
+}
+
+// 查询处理流程：指定 pipeline_id，或按 file_hash 查询文件最近一次的流程
+struct GetPipelineReq {
+    1: string file_hash
+    2: string user_id
+    3: string pipeline_id
+}
+
+struct GetPipelineResp {
+    1: i32 code
+    2: string msg
+    3: string pipeline_id
+    4: string file_hash
+    5: string status       // "queued", "running", "completed", "failed"
+    6: string error
+    7: list<PipelineStep> steps
+    8: ProcessingPolicy policy  // 创建流程时的策略快照
+    9: i64 created_at
+}
+
+// 重新执行失败的步骤，之前已完成的步骤不会重做；step 为空时重试失败的那一步
+struct RetryPipelineReq {
+    1: string pipeline_id
+    2: string user_id
+    3: string step
+}
+
+struct RetryPipelineResp {
+    1: i32 code
+    2: string msg
+    3: string status
+}
+
+// 删除视频（释放对物理文件的引用）
+struct DeleteVideoReq {
+    1: string file_hash
+    2: string user_id
+}
+
+struct DeleteVideoResp {
+    1: i32 code
+    2: string msg
 }
 
 service VideoService {
     InitUploadResp InitUpload(1: InitUploadReq req)
     UploadChunkResp UploadChunk(1: UploadChunkReq req)
+    UploadChunkResp UploadChunkStream(1: UploadChunkFrame req) (streaming.mode="client") // 流式上传分片，数据按帧写入存储
     MergeFileResp MergeFile(1: MergeFileReq req)
+    GetMergeStatusResp GetMergeStatus(1: GetMergeStatusReq req)
     DownloadChunkResp DownloadChunk(1: DownloadChunkReq req)
+    DownloadFrame DownloadStream(1: DownloadStreamReq req) (streaming.mode="server") // 流式下载，数据按帧发送
     GetVideoInfoResp GetVideoInfo(1: GetVideoInfoReq req)
+    GetPlaybackFileResp GetPlaybackFile(1: GetPlaybackFileReq req)
+    GetThumbnailFileResp GetThumbnailFile(1: GetThumbnailFileReq req)
+    SetPosterResp SetPoster(1: SetPosterReq req)
     TranscodeResp Transcode(1: TranscodeReq req)
     GetTranscodeStatusResp GetTranscodeStatus(1: GetTranscodeStatusReq req)
+    CancelTranscodeResp CancelTranscode(1: CancelTranscodeReq req)
+    RetryTranscodeResp RetryTranscode(1: RetryTranscodeReq req)
+    ListTranscodeProfilesResp ListTranscodeProfiles(1: ListTranscodeProfilesReq req)
+    SaveTranscodeProfileResp SaveTranscodeProfile(1: SaveTranscodeProfileReq req)
+    DeleteTranscodeProfileResp DeleteTranscodeProfile(1: DeleteTranscodeProfileReq req)
+    DeleteVideoResp DeleteVideo(1: DeleteVideoReq req)
+    GetProcessingPolicyResp GetProcessingPolicy(1: GetProcessingPolicyReq req)
+    SaveProcessingPolicyResp SaveProcessingPolicy(1: SaveProcessingPolicyReq req)
+    DeleteProcessingPolicyResp DeleteProcessingPolicy(1: DeleteProcessingPolicyReq req)
+    GetPipelineResp GetPipeline(1: GetPipelineReq req)
+    RetryPipelineResp RetryPipeline(1: RetryPipelineReq req)
 }
@@ -0,0 +1,65 @@
+// transcode-worker 独立的转码工作者进程
+// 与 rpc-video 共享数据库（transcode_tasks 队列）和存储后端，可以部署任意多个副本；
+// rpc-video 配置 TRANSCODE_MODE=standalone 后只负责创建任务，转码全部由这里执行
+package main
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"video-platform-microservice/rpc-video/internal/db"
+	"video-platform-microservice/rpc-video/internal/storage"
+	"video-platform-microservice/rpc-video/internal/transcode"
+
+	"github.com/see1youagain/video-platform-microservice/common/config"
+	commonDb "github.com/see1youagain/video-platform-microservice/common/db"
+	"github.com/see1youagain/video-platform-microservice/common/logger"
+)
+
+// shutdownTimeout 收到退出信号后等待执行中任务完成的最长时间，超时的任务放回队列
+const shutdownTimeout = 5 * time.Minute
+
+func main() {
+	// 加载配置
+	if _, err := config.Load(); err != nil {
+		log.Fatalf("❌ 配置加载失败: %v", err)
+	}
+
+	tcfg, err := transcode.LoadConfig()
+	if err != nil {
+		log.Fatalf("❌ 转码配置加载失败: %v", err)
+	}
+
+	// 初始化日志
+	if err := logger.Init(); err != nil {
+		log.Fatalf("❌ 日志初始化失败: %v", err)
+	}
+
+	// 初始化数据库
+	if err := commonDb.InitDB(); err != nil {
+		log.Fatalf("❌ 数据库初始化失败: %v", err)
+	}
+
+	// 初始化数据库表
+	if err := db.Init(); err != nil {
+		log.Fatalf("❌ 数据库表初始化失败: %v", err)
+	}
+
+	// 初始化存储（需与 rpc-video 使用同一存储后端）
+	if err := storage.InitStorage(); err != nil {
+		log.Fatalf("❌ 存储初始化失败: %v", err)
+	}
+
+	transcode.InitTranscodeManager(tcfg)
+	log.Printf("🚀 转码工作者已启动 (workers: %d)", tcfg.Workers)
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	log.Printf("收到退出信号，等待执行中的转码任务...")
+	transcode.Shutdown(shutdownTimeout)
+}
@@ -1,19 +1,27 @@
 package main
 
 import (
+"bytes"
 "context"
 	"github.com/bytedance/gopkg/cloud/metainfo"
 "encoding/json"
+"errors"
 "fmt"
+"io"
 "log"
+"time"
 
 "video-platform-microservice/rpc-video/internal/db"
+"video-platform-microservice/rpc-video/internal/integrity"
+"video-platform-microservice/rpc-video/internal/merge"
+"video-platform-microservice/rpc-video/internal/pipeline"
 "video-platform-microservice/rpc-video/internal/redis"
 "video-platform-microservice/rpc-video/internal/storage"
 "video-platform-microservice/rpc-video/internal/transcode"
 video "video-platform-microservice/rpc-video/kitex_gen/video"
 
 commonRedis "github.com/redis/go-redis/v9"
+"gorm.io/gorm"
 )
 
 // VideoServiceImpl implements the last service interface defined in the IDL.
@@ -50,10 +58,7 @@ resp.Msg = "file_hash 不能为空"
 return resp, nil
 }
 
-userID := req.UserId
-if userID == "" {
-userID = "anonymous"
-}
+userID := getUserIDFromContext(ctx, req.UserId)
 
 log.Printf("[InitUpload] FileHash: %s, Filename: %s, UserID: %s", req.FileHash, req.Filename, userID)
 // 0. 幂等性检查：如果request_id已存在，直接返回之前的结果
@@ -103,6 +108,40 @@ resp.Url = fileURL
 return resp, nil
 }
 
+// 2.1 跨用户秒传：其他用户已上传过相同内容，当前用户需先证明持有文件内容，
+// 应答服务端随机选取区间的摘要后才为其增加一条引用；否则按正常流程上传，合并时校验整个文件
+possessionKey := fmt.Sprintf("%s:%s", userID, req.FileHash)
+var challenge *video.PossessionChallenge
+if blob, err := db.GetBlob(req.FileHash); err != nil {
+log.Printf("[InitUpload] 查询物理文件失败: %v", err)
+} else if blob != nil && (req.FileSize <= 0 || req.FileSize == blob.Size) {
+if answer := req.GetChallengeAnswer(); answer != "" {
+expected, _ := redis.TakePossessionAnswer(ctx, possessionKey)
+if integrity.AnswerMatches(answer, expected) {
+file, err := linkBlob(ctx, req.FileHash, userID, req.Filename, req.RequestId)
+if err != nil {
+log.Printf("[InitUpload] 关联物理文件失败: %v", err)
+} else if file != nil {
+log.Printf("[InitUpload] 秒传命中(跨用户): %s", req.FileHash)
+redis.SetTombstone(ctx, cacheKey, file.URL)
+resp.Code = 200
+resp.Msg = "文件已存在（秒传）"
+resp.Status = "finished"
+resp.Url = file.URL
+return resp, nil
+}
+} else {
+log.Printf("[InitUpload] 持有性证明失败，需要上传文件: %s", req.FileHash)
+}
+} else if c, err := newPossessionChallenge(ctx, blob); err != nil {
+log.Printf("[InitUpload] 生成持有性证明失败: %v", err)
+} else if err := redis.SetPossessionAnswer(ctx, possessionKey, c.answer); err != nil {
+log.Printf("[InitUpload] 保存持有性证明失败: %v", err)
+} else {
+challenge = c.challenge
+}
+}
+
 // 3. 检查 Redis 上传状态
 statusKey := fmt.Sprintf("upload:%s:%s", userID, req.FileHash)
 status, err := redis.GetUploadStatus(ctx, statusKey)
@@ -118,6 +157,7 @@ resp.Code = 200
 resp.Msg = "断点续传"
 resp.Status = "uploading"
 resp.FinishedChunks = finishedChunks
+resp.Challenge = challenge
 return resp, nil
 }
 
@@ -134,9 +174,49 @@ resp.Code = 200
 resp.Msg = "初始化成功"
 resp.Status = "uploading"
 resp.FinishedChunks = []string{}
+resp.Challenge = challenge
 return resp, nil
 }
 
+// possessionChallenge 持有性证明及其期望的应答
+type possessionChallenge struct {
+challenge *video.PossessionChallenge
+answer    string
+}
+
+// newPossessionChallenge 为已存在的物理文件生成持有性证明
+func newPossessionChallenge(ctx context.Context, blob *db.Blob) (*possessionChallenge, error) {
+key, err := storage.Resolve(blob.StorageKey, blob.StorageBackend)
+if err != nil {
+return nil, err
+}
+c, err := integrity.NewPossessionChallenge(blob.Size)
+if err != nil {
+return nil, err
+}
+answer, err := c.ExpectedAnswer(ctx, key)
+if err != nil {
+return nil, err
+}
+return &possessionChallenge{
+challenge: &video.PossessionChallenge{Nonce: c.Nonce, Offset: c.Offset, Length: c.Length},
+answer:    answer,
+}, nil
+}
+
+// blobLockWait 等待物理文件锁的时间；合并大文件时可能长时间持有，超时后返回 409 由客户端重试
+const blobLockWait = 10 * time.Second
+
+// linkBlob 在物理文件锁下为用户关联已有的物理文件，避免关联到正在被删除的对象
+func linkBlob(ctx context.Context, fileHash, userID, filename, requestID string) (*db.File, error) {
+blobLock, err := redis.LockBlob(ctx, fileHash, blobLockWait)
+if err != nil {
+return nil, fmt.Errorf("获取物理文件锁失败: %w", err)
+}
+defer blobLock.Unlock()
+return db.LinkBlob(fileHash, userID, filename, requestID)
+}
+
 // UploadChunk 上传分片
 func (s *VideoServiceImpl) UploadChunk(ctx context.Context, req *video.UploadChunkReq) (resp *video.UploadChunkResp, err error) {
 resp = &video.UploadChunkResp{}
@@ -148,45 +228,159 @@ resp.Msg = "参数不完整"
 return resp, nil
 }
 
-userID := req.UserId
-if userID == "" {
-userID = "anonymous"
-}
+userID := getUserIDFromContext(ctx, req.UserId)
 
 log.Printf("[UploadChunk] FileHash: %s, Index: %s, Size: %d bytes, UserID: %s", 
 req.FileHash, req.Index, len(req.Data), userID)
 
-// 1. 检查分片是否已存在（去重）
-chunkKey := fmt.Sprintf("%s_%s_%s", userID, req.FileHash, req.Index)
-if storage.ChunkExists(chunkKey, req.Index) {
-log.Printf("[UploadChunk] 分片已存在，跳过: %s", chunkKey)
-resp.Code = 200
-resp.Msg = "分片已存在"
+// 0. 分片摘要校验（可选）
+chunkHash := req.GetChunkHash()
+if chunkHash != "" {
+if err := integrity.VerifyBytes(req.Data, chunkHash); err != nil {
+log.Printf("[UploadChunk] 分片校验失败: %s_%s, %v", req.FileHash, req.Index, err)
+resp.Code = 400
+resp.Msg = fmt.Sprintf("分片校验失败: %v", err)
 return resp, nil
 }
+}
 
-// 2. 保存分片到磁盘
-if err := storage.SaveChunk(chunkKey, req.Index, req.Data); err != nil {
-log.Printf("[UploadChunk] 保存分片失败: %v", err)
-resp.Code = 500
-resp.Msg = fmt.Sprintf("保存分片失败: %v", err)
+code, msg := storeChunk(ctx, userID, req.FileHash, req.Index, chunkHash, bytes.NewReader(req.Data), int64(len(req.Data)))
+resp.Code = code
+resp.Msg = msg
 return resp, nil
 }
 
-// 3. 记录已完成的分片到 Redis
-statusKey := fmt.Sprintf("upload:%s:%s", userID, req.FileHash)
-if err := redis.AddFinishedChunk(ctx, statusKey, req.Index); err != nil {
+// UploadChunkStream 流式上传分片：第一帧携带元数据，数据逐帧写入存储，
+// 内存占用只与帧大小有关，与分片大小无关
+func (s *VideoServiceImpl) UploadChunkStream(stream video.VideoService_UploadChunkStreamServer) (err error) {
+resp := &video.UploadChunkResp{}
+ctx := stream.Context()
+
+first, err := stream.Recv()
+if err != nil {
+return err
+}
+if first.FileHash == "" || first.Index == "" || len(first.Data) == 0 {
+resp.Code = 400
+resp.Msg = "参数不完整"
+return stream.SendAndClose(resp)
+}
+
+userID := getUserIDFromContext(ctx, first.UserId)
+size := first.GetSize()
+if !first.IsSetSize() {
+size = -1
+}
+
+log.Printf("[UploadChunkStream] FileHash: %s, Index: %s, Size: %d bytes, UserID: %s",
+first.FileHash, first.Index, size, userID)
+
+var data io.Reader = &frameReader{stream: stream, buf: first.Data, size: size}
+chunkHash := first.GetChunkHash()
+if chunkHash != "" {
+if data, err = integrity.NewVerifyingReader(data, chunkHash); err != nil {
+resp.Code = 400
+resp.Msg = fmt.Sprintf("分片校验失败: %v", err)
+return stream.SendAndClose(resp)
+}
+}
+
+resp.Code, resp.Msg = storeChunk(ctx, userID, first.FileHash, first.Index, chunkHash, data, size)
+return stream.SendAndClose(resp)
+}
+
+// errChunkSize 流式上传的分片长度与声明的不一致
+var errChunkSize = errors.New("分片长度不匹配")
+
+// frameReader 将 UploadChunkStream 的后续帧拼接为 io.Reader，每次只持有一帧的数据
+type frameReader struct {
+stream video.VideoService_UploadChunkStreamServer
+buf    []byte
+size   int64 // 声明的分片大小，未知时为 -1
+n      int64
+}
+
+func (r *frameReader) Read(p []byte) (int, error) {
+for len(r.buf) == 0 {
+frame, err := r.stream.Recv()
+if err == io.EOF {
+if r.size >= 0 && r.n != r.size {
+return 0, fmt.Errorf("%w: 声明 %d, 实际 %d", errChunkSize, r.size, r.n)
+}
+return 0, io.EOF
+}
+if err != nil {
+return 0, err
+}
+r.buf = frame.Data
+}
+n := copy(p, r.buf)
+r.buf = r.buf[n:]
+r.n += int64(n)
+if r.size >= 0 && r.n > r.size {
+return n, fmt.Errorf("%w: 超过声明的 %d 字节", errChunkSize, r.size)
+}
+return n, nil
+}
+
+// storeChunk 在上传会话的共享租约下写入分片并记录到 Redis，返回响应码与消息
+func storeChunk(ctx context.Context, userID, fileHash, index, chunkHash string, data io.Reader, size int64) (int32, string) {
+// 1. 获取上传会话的共享租约：合并进行中时拒绝写入，避免合并读取到写了一半的分片
+uploadLock, err := redis.TryLockUploadShared(ctx, redis.UploadSession(userID, fileHash))
+if err == redis.ErrLockHeld {
+log.Printf("[UploadChunk] 合并进行中，拒绝写入分片: %s_%s", fileHash, index)
+return 409, "合并进行中，暂不接受分片"
+}
+if err != nil {
+log.Printf("[UploadChunk] 获取上传锁失败: %v", err)
+return 500, "获取上传锁失败"
+}
+defer uploadLock.Unlock()
+
+// 2. 检查分片是否已存在（去重）
+// 分片前缀需与 MergeFile 保持一致：userID_fileHash
+chunkPrefix := fmt.Sprintf("%s_%s", userID, fileHash)
+chunkKey := storage.GetChunkKey(chunkPrefix, index)
+if storage.ChunkExists(chunkPrefix, index) {
+log.Printf("[UploadChunk] 分片已存在，跳过: %s", chunkKey)
+return 200, "分片已存在"
+}
+
+// 3. 保存分片到存储后端
+// 写入跟随共享租约：租约丢失时合并可能已经开始，此时中止写入；请求取消时同样中止
+writeCtx, cancel := context.WithCancel(uploadLock.Context())
+defer cancel()
+defer context.AfterFunc(ctx, cancel)()
+if err := storage.SaveChunkFrom(writeCtx, chunkPrefix, index, data, size); err != nil {
+if uploadLock.Context().Err() != nil {
+log.Printf("[UploadChunk] 上传租约已丢失，中止写入分片: %s_%s", fileHash, index)
+return 409, "上传会话已被合并占用，请稍后重试"
+}
+if errors.Is(err, integrity.ErrDigestMismatch) || errors.Is(err, errChunkSize) {
+log.Printf("[UploadChunk] 分片校验失败: %s_%s, %v", fileHash, index, err)
+return 400, fmt.Sprintf("分片校验失败: %v", err)
+}
+log.Printf("[UploadChunk] 保存分片失败: %v", err)
+return 500, fmt.Sprintf("保存分片失败: %v", err)
+}
+
+// 4. 记录已完成的分片（及其摘要）到 Redis
+statusKey := fmt.Sprintf("upload:%s:%s", userID, fileHash)
+if chunkHash != "" {
+if err := redis.SetChunkDigest(ctx, statusKey, index, chunkHash); err != nil {
+log.Printf("[UploadChunk] Redis 记录分片摘要失败: %v", err)
+}
+}
+if err := redis.AddFinishedChunk(ctx, statusKey, index); err != nil {
 log.Printf("[UploadChunk] Redis 记录失败: %v", err)
 // 不影响主流程，继续
 }
 
 log.Printf("[UploadChunk] 分片上传成功: %s", chunkKey)
-resp.Code = 200
-resp.Msg = "分片上传成功"
-return resp, nil
+return 200, "分片上传成功"
 }
 
-// MergeFile 合并文件
+// MergeFile 合并文件：创建异步合并任务后立即返回任务ID，通过 GetMergeStatus 查询进度
 func (s *VideoServiceImpl) MergeFile(ctx context.Context, req *video.MergeFileReq) (resp *video.MergeFileResp, err error) {
 resp = &video.MergeFileResp{}
 
@@ -197,15 +391,63 @@ resp.Msg = "参数不完整"
 return resp, nil
 }
 
-userID := req.UserId
-if userID == "" {
-userID = "anonymous"
-}
+userID := getUserIDFromContext(ctx, req.UserId)
 
 log.Printf("[MergeFile] FileHash: %s, Filename: %s, TotalChunks: %d, UserID: %s", 
 req.FileHash, req.Filename, req.TotalChunks, userID)
 
-// 1. 检查所有分片是否已上传
+// 0. 幂等性检查：相同 request_id 直接返回已有任务
+if req.RequestId != "" {
+existingJob, err := db.CheckMergeJobByRequestID(req.RequestId)
+if err != nil {
+log.Printf("[MergeFile] 幂等性检查失败: %v", err)
+} else if existingJob != nil {
+log.Printf("[MergeFile] 幂等性命中，RequestID: %s, JobID: %s", req.RequestId, existingJob.JobID)
+fillMergeFileResp(resp, existingJob)
+resp.Msg = "合并任务已创建（幂等性）"
+return resp, nil
+}
+}
+
+// 1. 获取上传会话的排他锁，保证同一文件只会创建一个合并任务
+// 锁被占用说明有合并正在创建或执行（或仍有分片在写入），直接返回"合并进行中"
+uploadLock, err := redis.TryLockUploadExclusive(ctx, redis.UploadSession(userID, req.FileHash))
+if err == redis.ErrLockHeld {
+activeJob, _ := db.GetActiveMergeJob(req.FileHash, userID)
+if activeJob != nil {
+log.Printf("[MergeFile] 合并进行中: %s", activeJob.JobID)
+fillMergeFileResp(resp, activeJob)
+} else {
+log.Printf("[MergeFile] 上传会话被占用: %s", req.FileHash)
+resp.Code = 409
+}
+resp.Msg = "合并进行中"
+return resp, nil
+}
+if err != nil {
+log.Printf("[MergeFile] 获取上传锁失败: %v", err)
+resp.Code = 500
+resp.Msg = "获取上传锁失败"
+return resp, nil
+}
+defer uploadLock.Unlock()
+
+// 2. 同一文件已有进行中的合并任务
+activeJob, err := db.GetActiveMergeJob(req.FileHash, userID)
+if err != nil {
+log.Printf("[MergeFile] 查询合并任务失败: %v", err)
+resp.Code = 500
+resp.Msg = "数据库查询失败"
+return resp, nil
+}
+if activeJob != nil {
+log.Printf("[MergeFile] 已有进行中的合并任务: %s", activeJob.JobID)
+fillMergeFileResp(resp, activeJob)
+resp.Msg = "合并进行中"
+return resp, nil
+}
+
+// 3. 检查所有分片是否已上传
 statusKey := fmt.Sprintf("upload:%s:%s", userID, req.FileHash)
 finishedChunks, err := redis.GetFinishedChunks(ctx, statusKey)
 if err != nil {
@@ -222,49 +464,64 @@ resp.Msg = fmt.Sprintf("分片未上传完整: %d/%d", len(finishedChunks), req.
 return resp, nil
 }
 
-// 2. 合并分片
-chunkPrefix := fmt.Sprintf("%s_%s", userID, req.FileHash)
-if err := storage.MergeChunks(chunkPrefix, req.Filename, int(req.TotalChunks)); err != nil {
-log.Printf("[MergeFile] 合并失败: %v", err)
+// 4. 创建合并任务（校验、合并、提取分辨率、写数据库均在后台执行）
+job, err := merge.CreateJob(req.FileHash, userID, req.Filename, req.TotalChunks, req.Width, req.Height, req.RequestId)
+if err != nil {
+log.Printf("[MergeFile] 创建合并任务失败: %v", err)
 resp.Code = 500
-resp.Msg = fmt.Sprintf("合并失败: %v", err)
+resp.Msg = fmt.Sprintf("创建合并任务失败: %v", err)
 return resp, nil
 }
 
-// 3. 提取视频分辨率（如果有ffprobe）
-filePath := storage.GetFilePath(chunkPrefix, req.Filename)
-width, height, err := transcode.ExtractResolution(filePath)
-if err != nil {
-log.Printf("[MergeFile] 提取分辨率失败: %v", err)
-// 使用传入的分辨率或默认值
-width = req.Width
-height = req.Height
+fillMergeFileResp(resp, job)
+resp.Msg = "合并任务已创建"
+return resp, nil
 }
 
-// 4. 生成文件 URL
-fileURL := storage.GetFileURL(chunkPrefix, req.Filename)
-
-// 5. 更新数据库
-fileSize, _ := storage.GetFileSize(chunkPrefix, req.Filename)
-if err := db.CreateFileWithMetadata(req.FileHash, userID, req.Filename, fileSize, fileURL, width, height, req.RequestId); err != nil {
-log.Printf("[MergeFile] 数据库创建失败: %v", err)
+// fillMergeFileResp 根据合并任务填充响应
+func fillMergeFileResp(resp *video.MergeFileResp, job *db.MergeJob) {
+resp.Code = 200
+resp.JobId = job.JobID
+resp.Status = job.Status
+resp.Url = job.URL
+resp.BadChunks = merge.BadChunks(job)
 }
 
-// 6. 设置墓碑（永久缓存）
-cacheKey := fmt.Sprintf("tombstone:%s:%s", userID, req.FileHash)
-if err := redis.SetTombstone(ctx, cacheKey, fileURL); err != nil {
-log.Printf("[MergeFile] 设置墓碑失败: %v", err)
+// GetMergeStatus 查询合并任务状态
+func (s *VideoServiceImpl) GetMergeStatus(ctx context.Context, req *video.GetMergeStatusReq) (resp *video.GetMergeStatusResp, err error) {
+resp = &video.GetMergeStatusResp{}
+
+if req.JobId == "" {
+resp.Code = 400
+resp.Msg = "job_id 不能为空"
+return resp, nil
 }
 
-// 7. 清理上传缓存
-if err := redis.DeleteUploadCache(ctx, statusKey); err != nil {
-log.Printf("[MergeFile] 清理缓存失败: %v", err)
+userID := getUserIDFromContext(ctx, req.UserId)
+
+job, err := db.GetMergeJob(req.JobId)
+if err != nil {
+log.Printf("[GetMergeStatus] 查询失败: %v", err)
+resp.Code = 500
+resp.Msg = "数据库查询失败"
+return resp, nil
+}
+if job == nil || job.UserID != userID {
+resp.Code = 404
+resp.Msg = "任务不存在"
+return resp, nil
 }
 
-log.Printf("[MergeFile] 文件合并成功: %s -> %s (分辨率: %dx%d)", req.FileHash, fileURL, width, height)
 resp.Code = 200
-resp.Msg = "文件合并成功"
-resp.Url = fileURL
+resp.Msg = "查询成功"
+resp.JobId = job.JobID
+resp.Status = job.Status
+resp.Phase = job.Phase
+resp.BytesMerged = job.BytesMerged
+resp.TotalBytes = job.TotalBytes
+resp.Url = job.URL
+resp.BadChunks = merge.BadChunks(job)
+resp.Error = job.Error
 return resp, nil
 }
 
@@ -288,9 +545,20 @@ resp.Msg = "文件不存在"
 return resp, nil
 }
 
-// 读取文件分片
-chunkPrefix := fmt.Sprintf("%s_%s", file.UserID, req.FileHash)
-data, totalSize, err := storage.ReadFileChunk(chunkPrefix, file.Filename, req.StartByte, req.EndByte)
+storageKey, err := storage.Resolve(file.StorageKey, file.StorageBackend)
+if err != nil {
+log.Printf("[DownloadChunk] 存储位置无效: %s, %v", req.FileHash, err)
+resp.Code = 404
+resp.Msg = "文件不存在"
+return resp, nil
+}
+
+// 读取文件分片：单次最多返回 MaxReadSize 字节，客户端根据 total_size 继续请求后续区间
+endByte := req.EndByte
+if start := max(req.StartByte, 0); endByte <= 0 || endByte-start > storage.MaxReadSize {
+endByte = start + storage.MaxReadSize
+}
+data, totalSize, err := storage.ReadFileChunk(ctx, storageKey, req.StartByte, endByte)
 if err != nil {
 log.Printf("[DownloadChunk] 读取失败: %v", err)
 resp.Code = 500
@@ -307,6 +575,105 @@ log.Printf("[DownloadChunk] 读取成功: %d bytes", len(data))
 return resp, nil
 }
 
+// downloadFrameSize DownloadStream 每帧的数据大小。发送受 gRPC 流控约束，
+// 网关写 HTTP 响应的速度决定读取存储的速度，内存占用与下载区间的大小无关
+const downloadFrameSize = 256 * 1024
+
+// DownloadStream 流式下载文件：第一帧返回状态、文件信息与实际的区间，之后逐帧发送数据
+func (s *VideoServiceImpl) DownloadStream(req *video.DownloadStreamReq, stream video.VideoService_DownloadStreamServer) (err error) {
+ctx := stream.Context()
+first := &video.DownloadFrame{}
+
+if req.FileHash == "" {
+first.Code = 400
+first.Msg = "file_hash 不能为空"
+return stream.Send(first)
+}
+
+log.Printf("[DownloadStream] FileHash: %s, Range: %d-%d", req.FileHash, req.StartByte, req.EndByte)
+
+file, err := db.GetFileByHash(req.FileHash)
+if err != nil || file == nil {
+first.Code = 404
+first.Msg = "文件不存在"
+return stream.Send(first)
+}
+
+storageKey, err := storage.Resolve(file.StorageKey, file.StorageBackend)
+if err != nil {
+log.Printf("[DownloadStream] 存储位置无效: %s, %v", req.FileHash, err)
+first.Code = 404
+first.Msg = "文件不存在"
+return stream.Send(first)
+}
+first.Filename = file.Filename
+first.LastModified = file.CreatedAt.Unix()
+first.ContentType = transcode.SourceContentType(file.Filename, file.Media.Container)
+
+if req.HeadOnly {
+totalSize, err := storage.GetFileSize(storageKey)
+if err != nil {
+log.Printf("[DownloadStream] 读取文件信息失败: %v", err)
+first.Code = 404
+first.Msg = "文件不存在"
+return stream.Send(first)
+}
+first.Code = 200
+first.Msg = "读取成功"
+first.TotalSize = totalSize
+first.EndByte = totalSize
+return stream.Send(first)
+}
+
+r, err := storage.OpenFileRange(ctx, storageKey, req.StartByte, req.EndByte)
+if errors.Is(err, storage.ErrInvalidRange) {
+first.Code = 416
+first.Msg = err.Error()
+first.TotalSize = r.TotalSize
+return stream.Send(first)
+}
+if err != nil {
+log.Printf("[DownloadStream] 打开失败: %v", err)
+first.Code = 500
+first.Msg = fmt.Sprintf("读取失败: %v", err)
+return stream.Send(first)
+}
+defer r.Close()
+
+first.Code = 200
+first.Msg = "读取成功"
+first.TotalSize = r.TotalSize
+first.StartByte = r.Start
+first.EndByte = r.End
+if err := stream.Send(first); err != nil {
+return err
+}
+
+// 每帧使用新的缓冲：发送返回后消息仍可能被传输层引用
+var sent int64
+for {
+buf := make([]byte, downloadFrameSize)
+n, readErr := io.ReadFull(r, buf)
+if n > 0 {
+if err := stream.Send(&video.DownloadFrame{Data: buf[:n]}); err != nil {
+log.Printf("[DownloadStream] 发送中断: %s, 已发送 %d bytes, %v", req.FileHash, sent, err)
+return err
+}
+sent += int64(n)
+}
+if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+break
+}
+if readErr != nil {
+log.Printf("[DownloadStream] 读取失败: %s, 已发送 %d bytes, %v", req.FileHash, sent, readErr)
+return readErr
+}
+}
+
+log.Printf("[DownloadStream] 发送完成: %s, %d bytes", req.FileHash, sent)
+return nil
+}
+
 // GetVideoInfo 获取视频信息
 func (s *VideoServiceImpl) GetVideoInfo(ctx context.Context, req *video.GetVideoInfoReq) (resp *video.GetVideoInfoResp, err error) {
 resp = &video.GetVideoInfoResp{}
@@ -317,10 +684,7 @@ resp.Msg = "file_hash 不能为空"
 return resp, nil
 }
 
-userID := req.UserId
-if userID == "" {
-userID = "anonymous"
-}
+userID := getUserIDFromContext(ctx, req.UserId)
 
 log.Printf("[GetVideoInfo] FileHash: %s, UserID: %s", req.FileHash, userID)
 
@@ -354,92 +718,915 @@ resp.Height = file.Height
 resp.Url = file.URL
 resp.TranscodeUrls = transcodeURLs
 resp.TranscodeStatus = file.TranscodeStatus
+resp.PlaybackUrl = file.PlaybackURL
+resp.DashUrl = file.DashURL
+resp.PosterUrl = file.PosterURL
+resp.Media = toMediaResp(file)
+
+// 缩略图按物理文件生成，所有引用者共享
+if blob, err := db.GetBlob(file.FileHash); err == nil && blob != nil {
+resp.ThumbnailStatus = blob.ThumbnailStatus
+if blob.ThumbnailStatus == transcode.ThumbnailCompleted {
+if resp.PosterUrl == "" {
+resp.PosterUrl = transcode.DefaultPosterURL(file.FileHash)
+}
+resp.ThumbnailsVttUrl = transcode.ThumbnailsVTTURL(file.FileHash)
+resp.SpriteUrls = transcode.SpriteURLs(file.FileHash, blob.SpriteCount)
+}
+}
 
 return resp, nil
 }
 
-// Transcode 创建转码任务
-func (s *VideoServiceImpl) Transcode(ctx context.Context, req *video.TranscodeReq) (resp *video.TranscodeResp, err error) {
-resp = &video.TranscodeResp{}
+// toMediaResp 文件记录中的媒体信息，未探测过时各字段为零值
+func toMediaResp(file *db.File) *video.MediaInfo {
+m := file.Media
+return &video.MediaInfo{
+Duration:        float64(m.DurationMs) / 1000,
+Container:       m.Container,
+VideoCodec:      m.VideoCodec,
+AudioCodec:      m.AudioCodec,
+Bitrate:         m.Bitrate,
+FrameRate:       m.FrameRate,
+Rotation:        m.Rotation,
+AudioChannels:   m.AudioChannels,
+AudioSampleRate: m.AudioSampleRate,
+Hdr:             m.HDR,
+HdrFormat:       m.HDRFormat,
+StreamCount:     m.StreamCount,
+Faststart:       m.Faststart,
+}
+}
+
+// GetPlaybackFile 读取播放文件（HLS 播放列表、DASH 清单与分片），只允许访问自己的视频
+func (s *VideoServiceImpl) GetPlaybackFile(ctx context.Context, req *video.GetPlaybackFileReq) (resp *video.GetPlaybackFileResp, err error) {
+resp = &video.GetPlaybackFileResp{}
 
-if req.FileHash == "" || len(req.Resolutions) == 0 {
+if req.FileHash == "" || !transcode.ValidPlaybackPath(req.Path) {
 resp.Code = 400
-resp.Msg = "参数不完整"
+resp.Msg = "参数错误"
 return resp, nil
 }
 
-userID := req.UserId
-if userID == "" {
-userID = "anonymous"
+userID := getUserIDFromContext(ctx, req.UserId)
+
+// 1. 校验文件归属
+file, err := db.GetFileByHashAndUser(req.FileHash, userID)
+if err != nil {
+resp.Code = 500
+resp.Msg = "数据库查询失败"
+return resp, nil
+}
+if file == nil || file.PlaybackURL == "" || !transcode.PlaybackAccessible(req.FileHash, file.PlaybackURL, req.Path) {
+resp.Code = 404
+resp.Msg = "文件不存在"
+return resp, nil
 }
 
-log.Printf("[Transcode] FileHash: %s, UserID: %s, Resolutions: %v", req.FileHash, userID, req.Resolutions)
-// 0. 幂等性检查：如果request_id已存在，直接返回之前的结果
-if req.RequestId != "" {
-existingTask, err := db.CheckTranscodeTaskByRequestID(req.RequestId)
+// 2. 读取播放文件
+data, _, err := storage.ReadFileChunk(ctx, transcode.StreamKey(req.FileHash, req.Path), 0, 0)
+if errors.Is(err, storage.ErrRangeTooLarge) {
+log.Printf("[GetPlaybackFile] 文件过大: %s/%s, error: %v", req.FileHash, req.Path, err)
+resp.Code = 413
+resp.Msg = "文件过大"
+return resp, nil
+}
 if err != nil {
-log.Printf("[Transcode] 幂等性检查失败: %v", err)
-} else if existingTask != nil {
-log.Printf("[Transcode] 幂等性命中，RequestID: %s, TaskID: %s", req.RequestId, existingTask.TaskID)
-resp.Code = 200
-resp.Msg = "转码任务已创建（幂等性）"
-resp.TaskId = existingTask.TaskID
+log.Printf("[GetPlaybackFile] 读取失败: %s/%s, error: %v", req.FileHash, req.Path, err)
+resp.Code = 404
+resp.Msg = "文件不存在"
 return resp, nil
 }
+
+resp.Code = 200
+resp.Msg = "成功"
+resp.Data = data
+resp.ContentType = transcode.PlaybackContentType(req.Path)
+return resp, nil
 }
 
+// GetThumbnailFile 读取封面、雪碧图和 WebVTT 缩略图轨道，只允许访问自己的视频
+func (s *VideoServiceImpl) GetThumbnailFile(ctx context.Context, req *video.GetThumbnailFileReq) (resp *video.GetThumbnailFileResp, err error) {
+resp = &video.GetThumbnailFileResp{}
 
-// 检查文件是否存在
+userID := getUserIDFromContext(ctx, req.UserId)
+
+if req.FileHash == "" || !transcode.ThumbnailAccessible(req.Path, userID) {
+resp.Code = 400
+resp.Msg = "参数错误"
+return resp, nil
+}
+
+// 1. 校验文件归属
 file, err := db.GetFileByHashAndUser(req.FileHash, userID)
-if err != nil || file == nil {
+if err != nil {
+resp.Code = 500
+resp.Msg = "数据库查询失败"
+return resp, nil
+}
+if file == nil {
 resp.Code = 404
 resp.Msg = "文件不存在"
 return resp, nil
 }
 
-// 创建转码任务
-taskID, err := transcode.CreateTask(req.FileHash, userID, req.Resolutions)
+// 2. 读取缩略图
+data, _, err := storage.ReadFileChunk(ctx, transcode.ThumbnailKey(req.FileHash, req.Path), 0, 0)
+if errors.Is(err, storage.ErrRangeTooLarge) {
+log.Printf("[GetThumbnailFile] 文件过大: %s/%s, error: %v", req.FileHash, req.Path, err)
+resp.Code = 413
+resp.Msg = "文件过大"
+return resp, nil
+}
 if err != nil {
-log.Printf("[Transcode] 创建任务失败: %v", err)
-resp.Code = 500
-resp.Msg = fmt.Sprintf("创建任务失败: %v", err)
+log.Printf("[GetThumbnailFile] 读取失败: %s/%s, error: %v", req.FileHash, req.Path, err)
+resp.Code = 404
+resp.Msg = "文件不存在"
 return resp, nil
 }
 
 resp.Code = 200
-resp.Msg = "转码任务已创建"
-resp.TaskId = taskID
-
-log.Printf("[Transcode] 任务创建成功: %s", taskID)
+resp.Msg = "成功"
+resp.Data = data
+resp.ContentType = transcode.ThumbnailContentType(req.Path)
 return resp, nil
 }
 
-// GetTranscodeStatus 获取转码状态
-func (s *VideoServiceImpl) GetTranscodeStatus(ctx context.Context, req *video.GetTranscodeStatusReq) (resp *video.GetTranscodeStatusResp, err error) {
-resp = &video.GetTranscodeStatusResp{}
+// SetPoster 设置自定义封面：上传图片或指定截取时间点；两者都为空时恢复默认封面
+func (s *VideoServiceImpl) SetPoster(ctx context.Context, req *video.SetPosterReq) (resp *video.SetPosterResp, err error) {
+resp = &video.SetPosterResp{}
 
-if req.TaskId == "" {
+if req.FileHash == "" {
 resp.Code = 400
-resp.Msg = "task_id 不能为空"
+resp.Msg = "file_hash 不能为空"
+return resp, nil
+}
+if len(req.Image) > 0 && req.Timestamp != nil {
+resp.Code = 400
+resp.Msg = "image 和 timestamp 只能指定一个"
+return resp, nil
+}
+if req.Timestamp != nil && *req.Timestamp < 0 {
+resp.Code = 400
+resp.Msg = "timestamp 不能为负数"
 return resp, nil
 }
 
-log.Printf("[GetTranscodeStatus] TaskID: %s", req.TaskId)
+userID := getUserIDFromContext(ctx, req.UserId)
 
-// 获取任务状态
-status, err := transcode.GetTaskStatus(req.TaskId)
+log.Printf("[SetPoster] FileHash: %s, UserID: %s", req.FileHash, userID)
+
+file, err := db.GetFileByHashAndUser(req.FileHash, userID)
 if err != nil {
-log.Printf("[GetTranscodeStatus] 查询失败: %v", err)
+resp.Code = 500
+resp.Msg = "数据库查询失败"
+return resp, nil
+}
+if file == nil {
 resp.Code = 404
-resp.Msg = "任务不存在"
+resp.Msg = "文件不存在"
 return resp, nil
 }
 
-resp.Code = 200
-resp.Msg = "查询成功"
-resp.Status = status.Status
-resp.Progress = status.Progress
-resp.CompletedUrls = status.CompletedURLs
-
+var posterURL string
+switch {
+case len(req.Image) > 0:
+posterURL, err = transcode.SetPosterImage(ctx, req.FileHash, userID, req.Image)
+case req.Timestamp != nil:
+at := time.Duration(*req.Timestamp * float64(time.Second))
+posterURL, err = transcode.SetPosterAt(ctx, req.FileHash, userID, at)
+default:
+err = transcode.ResetPoster(ctx, req.FileHash, userID)
+}
+if errors.Is(err, transcode.ErrInvalidPoster) {
+resp.Code = 400
+resp.Msg = err.Error()
+return resp, nil
+}
+if err != nil {
+log.Printf("[SetPoster] 设置封面失败: %v", err)
+resp.Code = 500
+resp.Msg = "设置封面失败"
+return resp, nil
+}
+
+resp.Code = 200
+resp.Msg = "设置成功"
+resp.PosterUrl = posterURL
+return resp, nil
+}
+
+// Transcode 创建转码任务
+func (s *VideoServiceImpl) Transcode(ctx context.Context, req *video.TranscodeReq) (resp *video.TranscodeResp, err error) {
+resp = &video.TranscodeResp{}
+
+// resolutions 中的分辨率名称即内置编码配置，与 profiles 合并去重
+profileNames := []string{}
+seen := map[string]bool{}
+for _, name := range append(append([]string{}, req.Resolutions...), req.Profiles...) {
+if name != "" && !seen[name] {
+seen[name] = true
+profileNames = append(profileNames, name)
+}
+}
+
+if req.FileHash == "" || len(profileNames) == 0 {
+resp.Code = 400
+resp.Msg = "参数不完整"
+return resp, nil
+}
+
+userID := getUserIDFromContext(ctx, req.UserId)
+
+log.Printf("[Transcode] FileHash: %s, UserID: %s, Profiles: %v", req.FileHash, userID, profileNames)
+// 0. 幂等性检查：如果request_id已存在，直接返回之前的结果
+if req.RequestId != "" {
+existingTask, err := db.CheckTranscodeTaskByRequestID(req.RequestId)
+if err != nil {
+log.Printf("[Transcode] 幂等性检查失败: %v", err)
+} else if existingTask != nil {
+log.Printf("[Transcode] 幂等性命中，RequestID: %s, TaskID: %s", req.RequestId, existingTask.TaskID)
+resp.Code = 200
+resp.Msg = "转码任务已创建（幂等性）"
+resp.TaskId = existingTask.TaskID
+return resp, nil
+}
+}
+
+
+// 检查文件是否存在
+file, err := db.GetFileByHashAndUser(req.FileHash, userID)
+if err != nil || file == nil {
+resp.Code = 404
+resp.Msg = "文件不存在"
+return resp, nil
+}
+
+// 创建转码任务
+taskID, err := transcode.CreateTask(req.FileHash, userID, profileNames, transcode.TaskOptions{
+PerTitle:       req.PerTitle,
+QualityMetrics: req.QualityMetrics,
+})
+if errors.Is(err, transcode.ErrUnknownProfile) {
+resp.Code = 400
+resp.Msg = err.Error()
+return resp, nil
+}
+if err != nil {
+log.Printf("[Transcode] 创建任务失败: %v", err)
+resp.Code = 500
+resp.Msg = fmt.Sprintf("创建任务失败: %v", err)
+return resp, nil
+}
+
+resp.Code = 200
+resp.Msg = "转码任务已创建"
+resp.TaskId = taskID
+
+log.Printf("[Transcode] 任务创建成功: %s", taskID)
+return resp, nil
+}
+
+// GetTranscodeStatus 获取转码状态
+func (s *VideoServiceImpl) GetTranscodeStatus(ctx context.Context, req *video.GetTranscodeStatusReq) (resp *video.GetTranscodeStatusResp, err error) {
+resp = &video.GetTranscodeStatusResp{}
+
+if req.TaskId == "" {
+resp.Code = 400
+resp.Msg = "task_id 不能为空"
+return resp, nil
+}
+
+log.Printf("[GetTranscodeStatus] TaskID: %s", req.TaskId)
+
+// 获取任务状态
+status, err := transcode.GetTaskStatus(req.TaskId)
+if err != nil {
+log.Printf("[GetTranscodeStatus] 查询失败: %v", err)
+resp.Code = 404
+resp.Msg = "任务不存在"
+return resp, nil
+}
+
+resp.Code = 200
+resp.Msg = "查询成功"
+resp.Status = status.Status
+resp.Progress = status.Progress
+resp.CompletedUrls = status.CompletedURLs
+resp.Speed = status.Speed
+resp.EtaSeconds = status.ETASeconds
+resp.Error = status.Error
+resp.LastStderr = status.LastStderr
+resp.PerTitle = status.PerTitle
+resp.QualityMetrics = status.QualityMetrics
+for _, r := range status.Ladder {
+resp.Ladder = append(resp.Ladder, &video.LadderRung{
+Name:           r.Name,
+Height:         r.Height,
+ProbeBitrate:   r.ProbeBitrate,
+DefaultBitrate: r.DefaultBitrate,
+VideoBitrate:   r.VideoBitrate,
+MaxBitrate:     r.MaxBitrate,
+Skipped:        r.SkippedAnalysis,
+})
+}
+resp.Renditions = make([]*video.RenditionProgress, 0, len(status.Renditions))
+for _, r := range status.Renditions {
+rendition := &video.RenditionProgress{
+Name:         r.Name,
+Status:       r.Status,
+Progress:     r.Progress,
+Speed:        r.Speed,
+EtaSeconds:   r.ETASeconds,
+Error:        r.Error,
+Attempts:     r.Attempts,
+Reason:       r.Reason,
+QualityError: r.QualityError,
+}
+if r.Quality != nil {
+rendition.Psnr, rendition.Ssim, rendition.Vmaf = r.Quality.PSNR, r.Quality.SSIM, r.Quality.VMAF
+}
+resp.Renditions = append(resp.Renditions, rendition)
+}
+
+return resp, nil
+}
+
+// CancelTranscode 取消转码任务：终止正在执行的 ffmpeg 并删除已生成的输出
+func (s *VideoServiceImpl) CancelTranscode(ctx context.Context, req *video.CancelTranscodeReq) (resp *video.CancelTranscodeResp, err error) {
+resp = &video.CancelTranscodeResp{}
+
+if req.TaskId == "" {
+resp.Code = 400
+resp.Msg = "task_id 不能为空"
+return resp, nil
+}
+
+userID := getUserIDFromContext(ctx, req.UserId)
+
+log.Printf("[CancelTranscode] TaskID: %s, UserID: %s", req.TaskId, userID)
+
+// 1. 校验任务归属
+task, err := db.GetTranscodeTask(req.TaskId)
+if err != nil {
+log.Printf("[CancelTranscode] 查询失败: %v", err)
+resp.Code = 500
+resp.Msg = "查询任务失败"
+return resp, nil
+}
+if task == nil || task.UserID != userID {
+resp.Code = 404
+resp.Msg = "任务不存在"
+return resp, nil
+}
+
+// 2. 取消任务
+previous, err := transcode.CancelTask(req.TaskId)
+if err == gorm.ErrRecordNotFound {
+resp.Code = 404
+resp.Msg = "任务不存在"
+return resp, nil
+}
+if err != nil && previous == "" {
+log.Printf("[CancelTranscode] 取消失败: %v", err)
+resp.Code = 500
+resp.Msg = "取消失败"
+return resp, nil
+}
+if err != nil {
+resp.Code = 409
+resp.Msg = err.Error()
+resp.Status = previous
+return resp, nil
+}
+
+resp.Code = 200
+resp.Msg = "已取消"
+resp.Status = transcode.StatusCancelled
+return resp, nil
+}
+
+// RetryTranscode 重新执行失败、进入死信或已取消的转码任务
+func (s *VideoServiceImpl) RetryTranscode(ctx context.Context, req *video.RetryTranscodeReq) (resp *video.RetryTranscodeResp, err error) {
+resp = &video.RetryTranscodeResp{}
+
+if req.TaskId == "" {
+resp.Code = 400
+resp.Msg = "task_id 不能为空"
+return resp, nil
+}
+
+userID := getUserIDFromContext(ctx, req.UserId)
+
+log.Printf("[RetryTranscode] TaskID: %s, UserID: %s", req.TaskId, userID)
+
+// 1. 校验任务归属
+task, err := db.GetTranscodeTask(req.TaskId)
+if err != nil {
+log.Printf("[RetryTranscode] 查询失败: %v", err)
+resp.Code = 500
+resp.Msg = "查询任务失败"
+return resp, nil
+}
+if task == nil || task.UserID != userID {
+resp.Code = 404
+resp.Msg = "任务不存在"
+return resp, nil
+}
+
+// 2. 重新入队
+if err := transcode.RetryTask(req.TaskId); err != nil {
+log.Printf("[RetryTranscode] 重试失败: %v", err)
+resp.Code = 409
+resp.Msg = err.Error()
+resp.Status = task.Status
+return resp, nil
+}
+db.UpdateFileTranscodeStatus(task.FileHash, task.UserID, transcode.StatusPending, task.ResultURLs)
+
+resp.Code = 200
+resp.Msg = "已重新加入转码队列"
+resp.Status = transcode.StatusPending
+return resp, nil
+}
+
+// ListTranscodeProfiles 列出所有编码配置
+func (s *VideoServiceImpl) ListTranscodeProfiles(ctx context.Context, req *video.ListTranscodeProfilesReq) (resp *video.ListTranscodeProfilesResp, err error) {
+resp = &video.ListTranscodeProfilesResp{}
+
+records, err := db.ListTranscodeProfiles()
+if err != nil {
+log.Printf("[ListTranscodeProfiles] 查询失败: %v", err)
+resp.Code = 500
+resp.Msg = "查询失败"
+return resp, nil
+}
+
+resp.Code = 200
+resp.Msg = "查询成功"
+resp.Profiles = make([]*video.TranscodeProfile, 0, len(records))
+for i := range records {
+resp.Profiles = append(resp.Profiles, toProfileResp(transcode.ProfileFromDB(&records[i])))
+}
+return resp, nil
+}
+
+// SaveTranscodeProfile 创建或更新编码配置（管理员权限由网关校验）
+func (s *VideoServiceImpl) SaveTranscodeProfile(ctx context.Context, req *video.SaveTranscodeProfileReq) (resp *video.SaveTranscodeProfileResp, err error) {
+resp = &video.SaveTranscodeProfileResp{}
+
+if req.Profile == nil {
+resp.Code = 400
+resp.Msg = "profile 不能为空"
+return resp, nil
+}
+
+profile := fromProfileReq(req.Profile)
+profile.Normalize()
+if err := profile.Validate(); err != nil {
+resp.Code = 400
+resp.Msg = fmt.Sprintf("编码配置无效: %v", err)
+return resp, nil
+}
+
+log.Printf("[SaveTranscodeProfile] Name: %s, Codec: %s, RateControl: %s", profile.Name, profile.Codec, profile.RateControl)
+
+if err := db.SaveTranscodeProfile(profile.ToDB()); err != nil {
+log.Printf("[SaveTranscodeProfile] 保存失败: %v", err)
+resp.Code = 500
+resp.Msg = "保存失败"
+return resp, nil
+}
+
+// 重新读取以返回 builtin 等服务端维护的字段
+saved, err := db.GetTranscodeProfile(profile.Name)
+if err != nil || saved == nil {
+log.Printf("[SaveTranscodeProfile] 读取失败: %v", err)
+resp.Code = 500
+resp.Msg = "保存失败"
+return resp, nil
+}
+
+resp.Code = 200
+resp.Msg = "保存成功"
+resp.Profile = toProfileResp(transcode.ProfileFromDB(saved))
+return resp, nil
+}
+
+// DeleteTranscodeProfile 删除编码配置（管理员权限由网关校验），内置配置不可删除
+func (s *VideoServiceImpl) DeleteTranscodeProfile(ctx context.Context, req *video.DeleteTranscodeProfileReq) (resp *video.DeleteTranscodeProfileResp, err error) {
+resp = &video.DeleteTranscodeProfileResp{}
+
+if req.Name == "" {
+resp.Code = 400
+resp.Msg = "name 不能为空"
+return resp, nil
+}
+
+log.Printf("[DeleteTranscodeProfile] Name: %s", req.Name)
+
+builtIn, err := db.DeleteTranscodeProfile(req.Name)
+if err == gorm.ErrRecordNotFound {
+resp.Code = 404
+resp.Msg = "编码配置不存在"
+return resp, nil
+}
+if err != nil {
+log.Printf("[DeleteTranscodeProfile] 删除失败: %v", err)
+resp.Code = 500
+resp.Msg = "删除失败"
+return resp, nil
+}
+if builtIn {
+resp.Code = 409
+resp.Msg = "内置编码配置不可删除"
+return resp, nil
+}
+
+resp.Code = 200
+resp.Msg = "删除成功"
+return resp, nil
+}
+
+// toProfileResp 编码配置转换为 RPC 结构
+func toProfileResp(p transcode.Profile) *video.TranscodeProfile {
+return &video.TranscodeProfile{
+Name:            p.Name,
+Codec:           p.Codec,
+RateControl:     p.RateControl,
+Container:       p.Container,
+Width:           p.Width,
+Height:          p.Height,
+FitMode:         p.FitMode,
+VideoBitrate:    p.VideoBitrate,
+MaxBitrate:      p.MaxBitrate,
+Crf:             p.CRF,
+Preset:          p.Preset,
+AudioCodec:      p.AudioCodec,
+AudioBitrate:    p.AudioBitrate,
+AudioChannels:   p.AudioChannels,
+AudioSampleRate: p.AudioSampleRate,
+Builtin:         p.BuiltIn,
+}
+}
+
+// fromProfileReq RPC 结构转换为编码配置，builtin 由服务端维护，忽略请求中的值
+func fromProfileReq(p *video.TranscodeProfile) transcode.Profile {
+return transcode.Profile{
+Name:            p.Name,
+Codec:           p.Codec,
+RateControl:     p.RateControl,
+Container:       p.Container,
+Width:           p.Width,
+Height:          p.Height,
+FitMode:         p.FitMode,
+VideoBitrate:    p.VideoBitrate,
+MaxBitrate:      p.MaxBitrate,
+CRF:             p.Crf,
+Preset:          p.Preset,
+AudioCodec:      p.AudioCodec,
+AudioBitrate:    p.AudioBitrate,
+AudioChannels:   p.AudioChannels,
+AudioSampleRate: p.AudioSampleRate,
+}
+}
+
+// DeleteVideo 删除视频：移除用户的文件记录，最后一个引用释放时删除物理文件
+func (s *VideoServiceImpl) DeleteVideo(ctx context.Context, req *video.DeleteVideoReq) (resp *video.DeleteVideoResp, err error) {
+resp = &video.DeleteVideoResp{}
+
+if req.FileHash == "" {
+resp.Code = 400
+resp.Msg = "file_hash 不能为空"
+return resp, nil
+}
+
+userID := getUserIDFromContext(ctx, req.UserId)
+
+log.Printf("[DeleteVideo] FileHash: %s, UserID: %s", req.FileHash, userID)
+
+// 删除记录前取出用户自己的播放目录
+file, err := db.GetFileByHashAndUser(req.FileHash, userID)
+if err != nil {
+log.Printf("[DeleteVideo] 数据库查询失败: %v", err)
+resp.Code = 500
+resp.Msg = "删除失败"
+return resp, nil
+}
+
+// 持有物理文件锁直到物理对象删除完成：期间合并和秒传不能为该内容新增引用
+blobLock, err := redis.LockBlob(ctx, req.FileHash, blobLockWait)
+if err == redis.ErrLockHeld {
+resp.Code = 409
+resp.Msg = "文件正在处理中，请稍后重试"
+return resp, nil
+}
+if err != nil {
+log.Printf("[DeleteVideo] 获取物理文件锁失败: %v", err)
+resp.Code = 500
+resp.Msg = "删除失败"
+return resp, nil
+}
+defer blobLock.Unlock()
+
+released, err := db.DecrementRefCount(req.FileHash, userID)
+if err == gorm.ErrRecordNotFound {
+resp.Code = 404
+resp.Msg = "文件不存在"
+return resp, nil
+}
+if err != nil {
+log.Printf("[DeleteVideo] 数据库删除失败: %v", err)
+resp.Code = 500
+resp.Msg = "删除失败"
+return resp, nil
+}
+
+// 清理用户级秒传缓存
+cacheKey := fmt.Sprintf("tombstone:%s:%s", userID, req.FileHash)
+if err := redis.DeleteTombstone(ctx, cacheKey); err != nil {
+log.Printf("[DeleteVideo] 清理墓碑失败: %v", err)
+}
+
+// 清理用户的自定义封面、播放文件和转码输出
+transcode.DeleteUserPoster(req.FileHash, userID)
+if file != nil {
+if err := transcode.DeleteStreamVersion(ctx, req.FileHash, transcode.StreamVersion(req.FileHash, file.PlaybackURL)); err != nil {
+log.Printf("[DeleteVideo] 删除播放文件失败: %s, %v", req.FileHash, err)
+}
+}
+if err := transcode.DeleteUserOutputs(req.FileHash, userID); err != nil {
+log.Printf("[DeleteVideo] 删除转码输出失败: %s, %v", req.FileHash, err)
+}
+
+// 最后一个引用已释放，删除物理文件及其缩略图和播放文件
+if released != nil {
+storageKey, err := storage.Resolve(released.StorageKey, released.StorageBackend)
+if err == nil {
+err = storage.DeleteObject(storageKey)
+}
+if err != nil {
+log.Printf("[DeleteVideo] 删除物理文件失败: %s, %v", released.StorageKey, err)
+} else {
+log.Printf("[DeleteVideo] 物理文件已删除: %s", released.StorageKey)
+}
+if err := transcode.DeleteThumbnails(ctx, req.FileHash); err != nil {
+log.Printf("[DeleteVideo] 删除缩略图失败: %s, %v", req.FileHash, err)
+}
+if err := storage.DeletePrefix(ctx, transcode.StreamKey(req.FileHash, "")); err != nil {
+log.Printf("[DeleteVideo] 删除播放文件失败: %s, %v", req.FileHash, err)
+}
+}
+
+resp.Code = 200
+resp.Msg = "删除成功"
+return resp, nil
+}
+
+// policyOwner 策略所属的用户，平台默认策略为空字符串
+func policyOwner(ctx context.Context, userID string, platform bool) string {
+if platform {
+return ""
+}
+return getUserIDFromContext(ctx, userID)
+}
+
+// GetProcessingPolicy 查询处理策略：用户自己的策略优先，没有时返回平台默认策略
+func (s *VideoServiceImpl) GetProcessingPolicy(ctx context.Context, req *video.GetProcessingPolicyReq) (resp *video.GetProcessingPolicyResp, err error) {
+resp = &video.GetProcessingPolicyResp{}
+
+owner := policyOwner(ctx, req.UserId, req.Platform)
+if !req.Platform && owner == "" {
+resp.Code = 400
+resp.Msg = "user_id 不能为空"
+return resp, nil
+}
+
+log.Printf("[GetProcessingPolicy] UserID: %s, Platform: %v", owner, req.Platform)
+
+record, err := db.GetProcessingPolicy(owner)
+scope := "user"
+if owner == "" {
+scope = "platform"
+}
+if err == nil && record == nil && owner != "" {
+record, err = db.GetProcessingPolicy("")
+scope = "platform"
+}
+if err != nil {
+log.Printf("[GetProcessingPolicy] 查询失败: %v", err)
+resp.Code = 500
+resp.Msg = "查询失败"
+return resp, nil
+}
+
+resp.Code = 200
+resp.Msg = "success"
+if record == nil {
+resp.Msg = "没有处理策略"
+return resp, nil
+}
+resp.Scope = scope
+resp.Policy = toPolicyResp(pipeline.PolicyFromDB(record), record.Enabled)
+return resp, nil
+}
+
+// SaveProcessingPolicy 保存用户的处理策略，或平台默认策略（管理员权限由网关校验）
+func (s *VideoServiceImpl) SaveProcessingPolicy(ctx context.Context, req *video.SaveProcessingPolicyReq) (resp *video.SaveProcessingPolicyResp, err error) {
+resp = &video.SaveProcessingPolicyResp{}
+
+owner := policyOwner(ctx, req.UserId, req.Platform)
+if !req.Platform && owner == "" {
+resp.Code = 400
+resp.Msg = "user_id 不能为空"
+return resp, nil
+}
+if req.Policy == nil {
+resp.Code = 400
+resp.Msg = "policy 不能为空"
+return resp, nil
+}
+
+policy := &pipeline.Policy{
+Steps:              req.Policy.Steps,
+Profiles:           req.Policy.Profiles,
+PerTitle:           req.Policy.PerTitle,
+QualityMetrics:     req.Policy.QualityMetrics,
+RequireVideo:       req.Policy.RequireVideo,
+MaxDurationSeconds: req.Policy.MaxDurationSeconds,
+MinShortSide:       req.Policy.MinShortSide,
+MaxShortSide:       req.Policy.MaxShortSide,
+}
+if err := policy.Validate(); err != nil {
+resp.Code = 400
+resp.Msg = fmt.Sprintf("处理策略无效: %v", err)
+return resp, nil
+}
+
+log.Printf("[SaveProcessingPolicy] UserID: %s, Platform: %v, Steps: %v", owner, req.Platform, policy.Steps)
+
+if err := db.SaveProcessingPolicy(policy.ToDB(owner, req.Policy.Enabled)); err != nil {
+log.Printf("[SaveProcessingPolicy] 保存失败: %v", err)
+resp.Code = 500
+resp.Msg = "保存失败"
+return resp, nil
+}
+
+resp.Code = 200
+resp.Msg = "保存成功"
+resp.Policy = toPolicyResp(policy, req.Policy.Enabled)
+return resp, nil
+}
+
+// DeleteProcessingPolicy 删除处理策略；删除用户策略后改用平台默认策略
+func (s *VideoServiceImpl) DeleteProcessingPolicy(ctx context.Context, req *video.DeleteProcessingPolicyReq) (resp *video.DeleteProcessingPolicyResp, err error) {
+resp = &video.DeleteProcessingPolicyResp{}
+
+owner := policyOwner(ctx, req.UserId, req.Platform)
+if !req.Platform && owner == "" {
+resp.Code = 400
+resp.Msg = "user_id 不能为空"
+return resp, nil
+}
+
+log.Printf("[DeleteProcessingPolicy] UserID: %s, Platform: %v", owner, req.Platform)
+
+deleted, err := db.DeleteProcessingPolicy(owner)
+if err != nil {
+log.Printf("[DeleteProcessingPolicy] 删除失败: %v", err)
+resp.Code = 500
+resp.Msg = "删除失败"
+return resp, nil
+}
+if !deleted {
+resp.Code = 404
+resp.Msg = "处理策略不存在"
+return resp, nil
+}
+
+resp.Code = 200
+resp.Msg = "删除成功"
+return resp, nil
+}
+
+func toPolicyResp(p *pipeline.Policy, enabled bool) *video.ProcessingPolicy {
+return &video.ProcessingPolicy{
+Steps:              p.Steps,
+Profiles:           p.Profiles,
+PerTitle:           p.PerTitle,
+QualityMetrics:     p.QualityMetrics,
+RequireVideo:       p.RequireVideo,
+MaxDurationSeconds: p.MaxDurationSeconds,
+MinShortSide:       p.MinShortSide,
+MaxShortSide:       p.MaxShortSide,
+Enabled:            enabled,
+}
+}
+
+// GetPipeline 查询处理流程及各步骤的状态
+func (s *VideoServiceImpl) GetPipeline(ctx context.Context, req *video.GetPipelineReq) (resp *video.GetPipelineResp, err error) {
+resp = &video.GetPipelineResp{}
+
+if req.PipelineId == "" && req.FileHash == "" {
+resp.Code = 400
+resp.Msg = "pipeline_id 和 file_hash 不能同时为空"
+return resp, nil
+}
+
+userID := getUserIDFromContext(ctx, req.UserId)
+
+log.Printf("[GetPipeline] PipelineID: %s, FileHash: %s, UserID: %s", req.PipelineId, req.FileHash, userID)
+
+var p *db.Pipeline
+if req.PipelineId != "" {
+p, err = db.GetPipeline(req.PipelineId)
+} else {
+p, err = db.GetLatestPipeline(req.FileHash, userID)
+}
+if err != nil {
+log.Printf("[GetPipeline] 查询失败: %v", err)
+resp.Code = 500
+resp.Msg = "查询失败"
+return resp, nil
+}
+if p == nil || p.UserID != userID {
+resp.Code = 404
+resp.Msg = "处理流程不存在"
+return resp, nil
+}
+
+var policy pipeline.Policy
+json.Unmarshal([]byte(p.Policy), &policy)
+
+resp.Code = 200
+resp.Msg = "success"
+resp.PipelineId = p.PipelineID
+resp.FileHash = p.FileHash
+resp.Status = p.Status
+resp.Error = p.Error
+resp.Policy = toPolicyResp(&policy, true)
+resp.CreatedAt = p.CreatedAt.Unix()
+for _, step := range pipeline.Steps(p) {
+item := &video.PipelineStep{
+Name:     step.Name,
+Status:   step.Status,
+Error:    step.Error,
+Attempts: step.Attempts,
+Output:   step.Output,
+}
+if step.StartedAt != nil {
+item.StartedAt = step.StartedAt.Unix()
+}
+if step.FinishedAt != nil {
+item.FinishedAt = step.FinishedAt.Unix()
+}
+resp.Steps = append(resp.Steps, item)
+}
+return resp, nil
+}
+
+// RetryPipeline 重新执行处理流程中失败的步骤，已完成的步骤不会重做
+func (s *VideoServiceImpl) RetryPipeline(ctx context.Context, req *video.RetryPipelineReq) (resp *video.RetryPipelineResp, err error) {
+resp = &video.RetryPipelineResp{}
+
+if req.PipelineId == "" {
+resp.Code = 400
+resp.Msg = "pipeline_id 不能为空"
+return resp, nil
+}
+
+userID := getUserIDFromContext(ctx, req.UserId)
+
+log.Printf("[RetryPipeline] PipelineID: %s, Step: %s, UserID: %s", req.PipelineId, req.Step, userID)
+
+// 1. 校验流程归属
+p, err := db.GetPipeline(req.PipelineId)
+if err != nil {
+log.Printf("[RetryPipeline] 查询失败: %v", err)
+resp.Code = 500
+resp.Msg = "查询失败"
+return resp, nil
+}
+if p == nil || p.UserID != userID {
+resp.Code = 404
+resp.Msg = "处理流程不存在"
+return resp, nil
+}
+
+// 2. 重新入队
+if err := pipeline.Retry(req.PipelineId, req.Step); err != nil {
+log.Printf("[RetryPipeline] 重试失败: %v", err)
+resp.Code = 409
+if !errors.Is(err, pipeline.ErrNotRetryable) {
+resp.Code = 500
+}
+resp.Msg = err.Error()
+resp.Status = p.Status
+return resp, nil
+}
+
+resp.Code = 200
+resp.Msg = "已重新加入处理队列"
+resp.Status = pipeline.StatusQueued
 return resp, nil
 }
@@ -0,0 +1,159 @@
+package db
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MergeJob represents an asynchronous chunk merge job
+type MergeJob struct {
+	ID             uint       `gorm:"primaryKey"`
+	JobID          string     `gorm:"uniqueIndex;size:64;not null"`
+	FileHash       string     `gorm:"size:64;not null;index:idx_merge_user_file"`
+	UserID         string     `gorm:"size:64;not null;index:idx_merge_user_file"`
+	Filename       string     `gorm:"size:255;not null"`
+	TotalChunks    int32      `gorm:"not null"`
+	Width          int32      `gorm:"default:0"` // 客户端提供的分辨率，ffprobe 不可用时使用
+	Height         int32      `gorm:"default:0"`
+	RequestID      string     `gorm:"index;size:64"`
+	Status         string     `gorm:"size:20;default:'queued'"` // queued, running, completed, failed
+	Phase          string     `gorm:"size:20;default:'queued'"` // queued, verifying, merging, probing, saving, done
+	BytesMerged    int64      `gorm:"default:0"`
+	TotalBytes     int64      `gorm:"default:0"`
+	URL            string     `gorm:"size:512"`
+	BadChunks      string     `gorm:"type:text"` // JSON格式
+	Error          string     `gorm:"type:text"`
+	Attempts       int32      `gorm:"default:0"` // 被领取的次数：等待锁失败重新入队、实例退出后被接手都会再次领取
+	WorkerID       string     `gorm:"size:128"`  // 持有租约的实例
+	LeaseExpiresAt *time.Time `gorm:"index"`     // 租约到期时间，执行中的实例通过心跳续期
+	CreatedAt      time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime"`
+}
+
+// TableName specifies the table name for MergeJob model
+func (MergeJob) TableName() string {
+	return "merge_jobs"
+}
+
+// CreateMergeJob creates a new merge job
+func CreateMergeJob(job *MergeJob) error {
+	return GetDB().Create(job).Error
+}
+
+// GetMergeJob retrieves a merge job by job ID
+func GetMergeJob(jobID string) (*MergeJob, error) {
+	var job MergeJob
+	err := GetDB().Where("job_id = ?", jobID).First(&job).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// CheckMergeJobByRequestID checks if a merge job with the same request ID already exists
+func CheckMergeJobByRequestID(requestID string) (*MergeJob, error) {
+	if requestID == "" {
+		return nil, nil
+	}
+
+	var job MergeJob
+	err := GetDB().Where("request_id = ?", requestID).First(&job).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetActiveMergeJob retrieves the queued or running merge job for a user's file, if any
+func GetActiveMergeJob(fileHash, userID string) (*MergeJob, error) {
+	var job MergeJob
+	err := GetDB().Where("file_hash = ? AND user_id = ? AND status IN ?", fileHash, userID, []string{"queued", "running"}).
+		Order("id DESC").First(&job).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetReclaimableMergeJobs gets merge jobs no live instance is working on: running jobs whose lease expired,
+// and queued jobs not touched since staleBefore (the instance that queued them may have exited)
+func GetReclaimableMergeJobs(staleBefore time.Time) ([]MergeJob, error) {
+	var jobs []MergeJob
+	err := GetDB().
+		Where("(status = ? AND (lease_expires_at IS NULL OR lease_expires_at < ?)) OR (status = ? AND updated_at < ?)",
+			"running", time.Now(), "queued", staleBefore).
+		Order("id").Find(&jobs).Error
+	return jobs, err
+}
+
+// ClaimMergeJob atomically moves a queued job, or a running job whose lease expired, to running,
+// grants the instance a lease and counts the attempt; only one instance can claim a job at a time
+func ClaimMergeJob(jobID, workerID string, lease time.Duration) (bool, error) {
+	now := time.Now()
+	result := GetDB().Model(&MergeJob{}).
+		Where("job_id = ? AND (status = ? OR (status = ? AND (lease_expires_at IS NULL OR lease_expires_at < ?)))",
+			jobID, "queued", "running", now).
+		Updates(map[string]interface{}{
+			"status":           "running",
+			"worker_id":        workerID,
+			"lease_expires_at": now.Add(lease),
+			"attempts":         gorm.Expr("attempts + ?", 1),
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// HeartbeatMergeJob extends the lease; returns false if the instance no longer holds it
+func HeartbeatMergeJob(jobID, workerID string, lease time.Duration) (bool, error) {
+	result := GetDB().Model(&MergeJob{}).
+		Where("job_id = ? AND worker_id = ? AND status = ?", jobID, workerID, "running").
+		Update("lease_expires_at", time.Now().Add(lease))
+	return result.RowsAffected == 1, result.Error
+}
+
+// ReleaseMergeJob gives a claimed job back to the queue, recording why it could not run this time
+func ReleaseMergeJob(jobID, workerID, reason string) error {
+	return GetDB().Model(&MergeJob{}).
+		Where("job_id = ? AND worker_id = ? AND status = ?", jobID, workerID, "running").
+		Updates(map[string]interface{}{
+			"status":           "queued",
+			"error":            reason,
+			"worker_id":        "",
+			"lease_expires_at": nil,
+		}).Error
+}
+
+// FinishMergeJob records the final result and releases the lease;
+// only the instance holding the lease can finish the job
+func FinishMergeJob(jobID, workerID string, updates map[string]interface{}) (bool, error) {
+	updates["worker_id"] = ""
+	updates["lease_expires_at"] = nil
+	result := GetDB().Model(&MergeJob{}).
+		Where("job_id = ? AND worker_id = ? AND status = ?", jobID, workerID, "running").
+		Updates(updates)
+	return result.RowsAffected == 1, result.Error
+}
+
+// UpdateMergeJob updates arbitrary merge job columns
+func UpdateMergeJob(jobID string, updates map[string]interface{}) error {
+	return GetDB().Model(&MergeJob{}).
+		Where("job_id = ?", jobID).
+		Updates(updates).Error
+}
+
+// UpdateMergeJobProgress updates the phase and merged bytes of a merge job
+func UpdateMergeJobProgress(jobID, phase string, bytesMerged int64) error {
+	return UpdateMergeJob(jobID, map[string]interface{}{
+		"phase":        phase,
+		"bytes_merged": bytesMerged,
+	})
+}
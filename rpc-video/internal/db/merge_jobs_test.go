@@ -0,0 +1,207 @@
+package db
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	commonDb "github.com/see1youagain/video-platform-microservice/common/db"
+	"gorm.io/gorm/logger"
+)
+
+var (
+	dbOnce sync.Once
+	dbErr  error
+)
+
+// setupDB 连接测试库并清空合并任务表。
+// 任务的领取与租约依赖数据库的条件更新，没有设置 TEST_DB_NAME 时跳过
+func setupDB(t *testing.T) {
+	t.Helper()
+	name := os.Getenv("TEST_DB_NAME")
+	if name == "" {
+		t.Skip("set TEST_DB_NAME (and DB_HOST, DB_PORT, DB_USER, DB_PASSWORD) to run merge job tests against MySQL")
+	}
+	dbOnce.Do(func() {
+		dbErr = commonDb.InitDBWithConfig(commonDb.Config{
+			Host:     getenv("DB_HOST", "127.0.0.1"),
+			Port:     getenv("DB_PORT", "3306"),
+			User:     getenv("DB_USER", "root"),
+			Password: os.Getenv("DB_PASSWORD"),
+			DBName:   name,
+			LogLevel: logger.Silent,
+		})
+		if dbErr == nil {
+			dbErr = Init()
+		}
+	})
+	if dbErr != nil {
+		t.Fatalf("init database: %v", dbErr)
+	}
+	if err := GetDB().Where("1 = 1").Delete(&MergeJob{}).Error; err != nil {
+		t.Fatalf("clear merge jobs: %v", err)
+	}
+}
+
+func getenv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func newMergeJob(t *testing.T) string {
+	t.Helper()
+	job := &MergeJob{
+		JobID:       uuid.New().String(),
+		FileHash:    "0123456789abcdef0123456789abcdef",
+		UserID:      "user-1",
+		Filename:    "video.mp4",
+		TotalChunks: 2,
+		Status:      "queued",
+		Phase:       "queued",
+	}
+	if err := CreateMergeJob(job); err != nil {
+		t.Fatal(err)
+	}
+	return job.JobID
+}
+
+func mustGetMergeJob(t *testing.T, jobID string) *MergeJob {
+	t.Helper()
+	job, err := GetMergeJob(jobID)
+	if err != nil || job == nil {
+		t.Fatalf("GetMergeJob(%s) = %v, %v", jobID, job, err)
+	}
+	return job
+}
+
+// expireLease 把租约改到过去，模拟持有者退出后租约过期
+func expireLease(t *testing.T, jobID string) {
+	t.Helper()
+	if err := UpdateMergeJob(jobID, map[string]interface{}{"lease_expires_at": time.Now().Add(-time.Second)}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func reclaimable(t *testing.T, staleBefore time.Time) map[string]bool {
+	t.Helper()
+	jobs, err := GetReclaimableMergeJobs(staleBefore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		ids[job.JobID] = true
+	}
+	return ids
+}
+
+func TestClaimMergeJob(t *testing.T) {
+	setupDB(t)
+	jobID := newMergeJob(t)
+
+	ok, err := ClaimMergeJob(jobID, "worker-a", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("first claim = %v, %v, want true", ok, err)
+	}
+	if ok, _ := ClaimMergeJob(jobID, "worker-b", time.Minute); ok {
+		t.Fatal("second worker claimed a job with a live lease")
+	}
+	job := mustGetMergeJob(t, jobID)
+	if job.Status != "running" || job.WorkerID != "worker-a" || job.Attempts != 1 || job.LeaseExpiresAt == nil {
+		t.Errorf("claimed job = status %q, worker %q, attempts %d, lease %v", job.Status, job.WorkerID, job.Attempts, job.LeaseExpiresAt)
+	}
+
+	if ok, _ := HeartbeatMergeJob(jobID, "worker-b", time.Minute); ok {
+		t.Error("heartbeat succeeded for a worker without the lease")
+	}
+	if ok, err := HeartbeatMergeJob(jobID, "worker-a", time.Minute); err != nil || !ok {
+		t.Errorf("heartbeat = %v, %v, want true", ok, err)
+	}
+
+	// 释放后重新排队，再次领取时计入尝试次数
+	if err := ReleaseMergeJob(jobID, "worker-a", "lock busy"); err != nil {
+		t.Fatal(err)
+	}
+	job = mustGetMergeJob(t, jobID)
+	if job.Status != "queued" || job.WorkerID != "" || job.LeaseExpiresAt != nil || job.Error != "lock busy" {
+		t.Errorf("released job = status %q, worker %q, lease %v, error %q", job.Status, job.WorkerID, job.LeaseExpiresAt, job.Error)
+	}
+	if ok, err := ClaimMergeJob(jobID, "worker-b", time.Minute); err != nil || !ok {
+		t.Fatalf("claim after release = %v, %v, want true", ok, err)
+	}
+	if job := mustGetMergeJob(t, jobID); job.Attempts != 2 {
+		t.Errorf("attempts = %d, want 2", job.Attempts)
+	}
+}
+
+func TestReclaimExpiredLease(t *testing.T) {
+	setupDB(t)
+	jobID := newMergeJob(t)
+	if ok, _ := ClaimMergeJob(jobID, "worker-a", time.Minute); !ok {
+		t.Fatal("claim failed")
+	}
+	if reclaimable(t, time.Now().Add(-time.Minute))[jobID] {
+		t.Fatal("job with a live lease is reclaimable")
+	}
+
+	expireLease(t, jobID)
+	if !reclaimable(t, time.Now().Add(-time.Minute))[jobID] {
+		t.Fatal("job with an expired lease is not reclaimable")
+	}
+	if ok, err := ClaimMergeJob(jobID, "worker-b", time.Minute); err != nil || !ok {
+		t.Fatalf("reclaim = %v, %v, want true", ok, err)
+	}
+
+	// 原持有者的心跳与结果都不再生效
+	if ok, _ := HeartbeatMergeJob(jobID, "worker-a", time.Minute); ok {
+		t.Error("stale worker renewed a reclaimed lease")
+	}
+	if ok, _ := FinishMergeJob(jobID, "worker-a", map[string]interface{}{"status": "failed"}); ok {
+		t.Error("stale worker finished a reclaimed job")
+	}
+	if ok, err := FinishMergeJob(jobID, "worker-b", map[string]interface{}{"status": "completed"}); err != nil || !ok {
+		t.Fatalf("finish = %v, %v, want true", ok, err)
+	}
+	job := mustGetMergeJob(t, jobID)
+	if job.Status != "completed" || job.WorkerID != "" || job.LeaseExpiresAt != nil || job.Attempts != 2 {
+		t.Errorf("finished job = status %q, worker %q, lease %v, attempts %d", job.Status, job.WorkerID, job.LeaseExpiresAt, job.Attempts)
+	}
+	if ok, _ := ClaimMergeJob(jobID, "worker-c", time.Minute); ok {
+		t.Error("completed job was claimed again")
+	}
+}
+
+func TestRecoverAfterRestart(t *testing.T) {
+	setupDB(t)
+	running := newMergeJob(t) // 执行中的实例退出，租约过期
+	live := newMergeJob(t)    // 其他实例仍在执行
+	queued := newMergeJob(t)  // 入队的实例退出，任务一直没有被领取
+	fresh := newMergeJob(t)   // 刚入队，由入队的实例处理
+	for _, id := range []string{running, live} {
+		if ok, _ := ClaimMergeJob(id, "worker-a", time.Minute); !ok {
+			t.Fatal("claim failed")
+		}
+	}
+	expireLease(t, running)
+
+	// 重启后的实例只接手没有实例在处理的任务
+	ids := reclaimable(t, time.Now().Add(time.Second))
+	if !ids[running] || !ids[queued] || ids[live] {
+		t.Errorf("reclaimable = %v, want running %s and queued %s but not live %s", ids, running, queued, live)
+	}
+	if ids := reclaimable(t, time.Now().Add(-time.Hour)); ids[queued] || ids[fresh] {
+		t.Errorf("recently queued jobs are reclaimable: %v", ids)
+	}
+	for _, id := range []string{running, queued} {
+		if ok, err := ClaimMergeJob(id, "worker-restarted", time.Minute); err != nil || !ok {
+			t.Errorf("claim %s after restart = %v, %v", id, ok, err)
+		}
+	}
+	if ok, _ := ClaimMergeJob(live, "worker-restarted", time.Minute); ok {
+		t.Error("restarted worker took over a job with a live lease")
+	}
+}
@@ -3,10 +3,12 @@ package db
 import (
 "fmt"
 "log"
+"path/filepath"
 "time"
 
 commonDb "github.com/see1youagain/video-platform-microservice/common/db"
 "gorm.io/gorm"
+"gorm.io/gorm/clause"
 )
 
 // GetDB returns the database instance
@@ -22,13 +24,51 @@ UserID          string    `gorm:"uniqueIndex:idx_user_file;size:64;not null;inde
 Filename        string    `gorm:"size:255;not null"`
 FileSize        int64     `gorm:"not null"`
 URL             string    `gorm:"size:512;not null"`
+StorageKey      string    `gorm:"size:512"`           // 物理文件在存储后端中的对象 key（与 Blob 一致）
+StorageBackend  string    `gorm:"size:16"`            // 存储后端：local, s3
 Status          string    `gorm:"size:20;default:'uploading'"`
-RefCount        int32     `gorm:"default:1"`           // 引用计数，用于处理删除
+RefCount        int32     `gorm:"default:1"`           // 已废弃：物理文件的引用计数由 Blob.RefCount 维护
 RequestID       string    `gorm:"index;size:64"`       // 请求ID，用于幂等性
 Width           int32     `gorm:"default:0"`           // 视频宽度
 Height          int32     `gorm:"default:0"`           // 视频高度
 TranscodeStatus string    `gorm:"size:20;default:'pending'"` // 转码状态
 TranscodeURLs   string    `gorm:"type:text"`          // JSON格式存储转码URL列表
+PlaybackURL     string    `gorm:"size:512"`           // HLS 主播放列表地址
+DashURL         string    `gorm:"size:512"`           // DASH 清单地址
+PosterURL       string    `gorm:"size:512"`           // 自定义封面地址，为空时使用自动截取的封面
+Media           MediaMetadata `gorm:"embedded"`       // 合并时探测的媒体信息
+CreatedAt       time.Time `gorm:"autoCreateTime"`
+UpdatedAt       time.Time `gorm:"autoUpdateTime"`
+}
+
+// MediaMetadata holds the probed media properties of a file, stored as video_files columns.
+// StreamCount is 0 for files that have not been probed.
+type MediaMetadata struct {
+DurationMs      int64   `gorm:"default:0"`
+Container       string  `gorm:"size:32"`
+VideoCodec      string  `gorm:"size:32"`
+AudioCodec      string  `gorm:"size:32"`
+Bitrate         int64   `gorm:"default:0"` // bit/s
+FrameRate       float64 `gorm:"default:0"`
+Rotation        int32   `gorm:"default:0"` // 顺时针旋转角度
+AudioChannels   int32   `gorm:"default:0"`
+AudioSampleRate int32   `gorm:"default:0"`
+HDR             bool    `gorm:"column:hdr;default:false"`
+HDRFormat       string  `gorm:"column:hdr_format;size:32"`
+StreamCount     int32   `gorm:"default:0"`
+Faststart       bool    `gorm:"default:false"` // MP4/MOV 的 moov 位于 mdat 之前，可以边下载边播放
+}
+
+// Blob represents a physical, content-addressed file shared by every user who uploaded the same content
+type Blob struct {
+ID              uint      `gorm:"primaryKey"`
+FileHash        string    `gorm:"uniqueIndex;size:64;not null"`
+StorageKey      string    `gorm:"size:512;not null"` // 存储后端中的对象 key
+StorageBackend  string    `gorm:"size:16"`           // 存储后端：local, s3
+Size            int64     `gorm:"not null"`
+RefCount        int32     `gorm:"default:0"` // 引用此 blob 的 video_files 行数
+ThumbnailStatus string    `gorm:"size:20;default:'pending';index"` // 封面与缩略图的生成状态
+SpriteCount     int32     `gorm:"default:0"`                       // 缩略图雪碧图数量
 CreatedAt       time.Time `gorm:"autoCreateTime"`
 UpdatedAt       time.Time `gorm:"autoUpdateTime"`
 }
@@ -39,11 +79,23 @@ ID          uint      `gorm:"primaryKey"`
 TaskID      string    `gorm:"uniqueIndex;size:64;not null"`
 FileHash    string    `gorm:"size:64;not null;index"`
 UserID      string    `gorm:"size:64;not null;index"`
-Resolutions string    `gorm:"type:text"` // JSON格式
+Resolutions string    `gorm:"type:text"` // JSON格式，编码配置名称列表
+Profiles    string    `gorm:"type:text"` // JSON格式，创建任务时的编码配置快照
+PerTitle    bool      `gorm:"default:false"` // 按内容复杂度计算码率阶梯
+Ladder      string    `gorm:"type:text"` // JSON格式，按内容复杂度计算出的码率阶梯
+QualityMetrics bool   `gorm:"default:false"` // 每个分辨率完成后计算 PSNR/SSIM/VMAF
 Status      string    `gorm:"size:20;default:'pending'"`
 Progress    int32     `gorm:"default:0"`
 ResultURLs  string    `gorm:"type:text"` // JSON格式
 RequestID   string    `gorm:"index;size:64"` // 用于幂等性
+WorkerID       string     `gorm:"size:128"`          // 持有租约的工作者
+LeaseExpiresAt *time.Time `gorm:"index"`             // 租约到期时间，工作者通过心跳续期
+Attempts       int32      `gorm:"default:0"`         // 已领取次数
+Error          string     `gorm:"type:text"`
+LastStderr     string     `gorm:"type:text"`         // 最后一次失败的 ffmpeg stderr（末尾部分）
+Renditions     string     `gorm:"type:text"`         // JSON格式，各分辨率的状态与进度
+Speed          float64    `gorm:"default:0"`         // 当前编码速度（相对实时的倍数）
+ETASeconds     int64      `gorm:"column:eta_seconds;default:-1"` // 预计剩余秒数，-1 表示未知
 CreatedAt   time.Time `gorm:"autoCreateTime"`
 UpdatedAt   time.Time `gorm:"autoUpdateTime"`
 }
@@ -53,6 +105,11 @@ func (File) TableName() string {
 return "video_files"
 }
 
+// TableName specifies the table name for Blob model
+func (Blob) TableName() string {
+return "video_blobs"
+}
+
 // TableName specifies the table name for TranscodeTask model
 func (TranscodeTask) TableName() string {
 return "transcode_tasks"
@@ -60,9 +117,12 @@ return "transcode_tasks"
 
 // Init initializes database tables
 func Init() error {
-if err := GetDB().AutoMigrate(&File{}, &TranscodeTask{}); err != nil {
+if err := GetDB().AutoMigrate(&File{}, &Blob{}, &TranscodeTask{}, &MergeJob{}, &TranscodeProfile{}, &ProcessingPolicy{}, &Pipeline{}); err != nil {
 return fmt.Errorf("failed to auto migrate: %w", err)
 }
+if err := backfillBlobs(); err != nil {
+return fmt.Errorf("failed to backfill blobs: %w", err)
+}
 log.Println("✅ Database tables initialized")
 return nil
 }
@@ -124,21 +184,199 @@ RefCount: 1,
 return GetDB().Create(file).Error
 }
 
-// CreateFileWithMetadata creates a file record with video metadata and request ID for idempotency
-func CreateFileWithMetadata(fileHash, userID, filename string, fileSize int64, url string, width, height int32, requestID string) error {
+// CreateFileWithMetadata creates a file record with video metadata and request ID for idempotency,
+// and takes a reference on the blob stored at storageKey in backend in the same transaction
+func CreateFileWithMetadata(fileHash, userID, filename string, fileSize int64, url string, width, height int32, media MediaMetadata, requestID, storageKey, backend string) error {
+return GetDB().Transaction(func(tx *gorm.DB) error {
 file := &File{
 FileHash:  fileHash,
 UserID:    userID,
 Filename:  filename,
 FileSize:  fileSize,
 URL:       url,
+StorageKey:     storageKey,
+StorageBackend: backend,
 Status:    "finished",
 Width:     width,
 Height:    height,
+Media:     media,
 RefCount:  1,
 RequestID: requestID,
 }
-return GetDB().Create(file).Error
+if err := tx.Create(file).Error; err != nil {
+return err
+}
+return acquireBlob(tx, fileHash, storageKey, backend, fileSize)
+})
+}
+
+// LinkBlob creates a file record for userID that references an existing blob (cross-user instant upload).
+// It returns nil, nil when no live blob exists for fileHash.
+func LinkBlob(fileHash, userID, filename, requestID string) (*File, error) {
+var file *File
+err := GetDB().Transaction(func(tx *gorm.DB) error {
+var blob Blob
+err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+Where("file_hash = ? AND ref_count > 0", fileHash).First(&blob).Error
+if err == gorm.ErrRecordNotFound {
+return nil
+}
+if err != nil {
+return err
+}
+
+// 复用已有记录中的元数据（分辨率、媒体信息、URL）
+var source File
+if err := tx.Where("file_hash = ? AND status = ?", fileHash, "finished").First(&source).Error; err != nil {
+if err == gorm.ErrRecordNotFound {
+return nil
+}
+return err
+}
+
+file = &File{
+FileHash:  fileHash,
+UserID:    userID,
+Filename:  filename,
+FileSize:  blob.Size,
+URL:       source.URL,
+StorageKey:     blob.StorageKey,
+StorageBackend: blob.StorageBackend,
+Status:    "finished",
+Width:     source.Width,
+Height:    source.Height,
+Media:     source.Media,
+RefCount:  1,
+RequestID: requestID,
+}
+if err := tx.Create(file).Error; err != nil {
+return err
+}
+return tx.Model(&Blob{}).Where("id = ?", blob.ID).
+UpdateColumn("ref_count", gorm.Expr("ref_count + ?", 1)).Error
+})
+if err != nil {
+return nil, err
+}
+return file, nil
+}
+
+// acquireBlob creates the blob row on first reference or increments its reference count
+func acquireBlob(tx *gorm.DB, fileHash, storageKey, backend string, size int64) error {
+blob := &Blob{FileHash: fileHash, StorageKey: storageKey, StorageBackend: backend, Size: size, RefCount: 1}
+return tx.Clauses(clause.OnConflict{
+Columns:   []clause.Column{{Name: "file_hash"}},
+DoUpdates: clause.Assignments(map[string]interface{}{"ref_count": gorm.Expr("ref_count + ?", 1)}),
+}).Create(blob).Error
+}
+
+// GetBlob retrieves the blob for a content hash
+func GetBlob(fileHash string) (*Blob, error) {
+var blob Blob
+err := GetDB().Where("file_hash = ?", fileHash).First(&blob).Error
+if err == gorm.ErrRecordNotFound {
+return nil, nil
+}
+if err != nil {
+return nil, err
+}
+return &blob, nil
+}
+
+// UpdateBlob updates arbitrary blob columns
+func UpdateBlob(fileHash string, updates map[string]interface{}) error {
+return GetDB().Model(&Blob{}).
+Where("file_hash = ?", fileHash).
+Updates(updates).Error
+}
+
+// ClaimBlobThumbnails marks the blob's thumbnails as processing unless they are already processing or completed
+func ClaimBlobThumbnails(fileHash string) (bool, error) {
+result := GetDB().Model(&Blob{}).
+Where("file_hash = ? AND thumbnail_status NOT IN ?", fileHash, []string{"processing", "completed"}).
+Update("thumbnail_status", "processing")
+return result.RowsAffected > 0, result.Error
+}
+
+// ResetStaleBlobThumbnails moves thumbnails left processing by a previous run back to pending
+func ResetStaleBlobThumbnails() error {
+return GetDB().Model(&Blob{}).
+Where("thumbnail_status = ?", "processing").
+Update("thumbnail_status", "pending").Error
+}
+
+// GetPendingThumbnailBlobs gets blobs whose thumbnails have not been generated yet
+func GetPendingThumbnailBlobs(limit int) ([]Blob, error) {
+var blobs []Blob
+err := GetDB().Where("thumbnail_status = ?", "pending").Order("id").Limit(limit).Find(&blobs).Error
+return blobs, err
+}
+
+// backfillBlobs creates blob rows for files uploaded before content addressing existed.
+// Legacy files were stored as files/<userID>_<fileHash><ext>, so the first such object becomes the blob.
+func backfillBlobs() error {
+var rows []struct {
+FileHash string
+Total    int32
+}
+err := GetDB().Model(&File{}).
+Select("video_files.file_hash AS file_hash, COUNT(*) AS total").
+Joins("LEFT JOIN video_blobs ON video_blobs.file_hash = video_files.file_hash").
+Where("video_files.status = ? AND video_blobs.id IS NULL", "finished").
+Group("video_files.file_hash").
+Scan(&rows).Error
+if err != nil {
+return err
+}
+
+for _, row := range rows {
+var first File
+if err := GetDB().Where("file_hash = ? AND status = ?", row.FileHash, "finished").Order("id").First(&first).Error; err != nil {
+return err
+}
+blob := &Blob{
+FileHash:   row.FileHash,
+StorageKey: fmt.Sprintf("files/%s_%s%s", first.UserID, first.FileHash, filepath.Ext(first.Filename)),
+Size:       first.FileSize,
+RefCount:   row.Total,
+}
+if err := GetDB().Create(blob).Error; err != nil {
+return err
+}
+}
+if len(rows) > 0 {
+log.Printf("✅ Backfilled %d blobs", len(rows))
+}
+return nil
+}
+
+// BackfillStorageLocations records the storage backend of blobs created before it was tracked,
+// and copies each blob's key, backend and size onto the file rows that reference it.
+// Every object created before the backend column existed lives in the backend the service runs with.
+func BackfillStorageLocations(backend string) error {
+blobs := GetDB().Model(&Blob{}).Where("storage_backend = '' OR storage_backend IS NULL").
+Update("storage_backend", backend)
+if blobs.Error != nil {
+return blobs.Error
+}
+
+blob := func(column string) *gorm.DB {
+return GetDB().Model(&Blob{}).Select(column).Where("video_blobs.file_hash = video_files.file_hash")
+}
+files := GetDB().Model(&File{}).
+Where("(storage_key = '' OR storage_key IS NULL) AND EXISTS (?)", blob("1")).
+Updates(map[string]interface{}{
+"storage_key":     blob("storage_key"),
+"storage_backend": blob("storage_backend"),
+"file_size":       blob("size"),
+})
+if files.Error != nil {
+return files.Error
+}
+if blobs.RowsAffected > 0 || files.RowsAffected > 0 {
+log.Printf("✅ Backfilled storage locations (blobs: %d, files: %d)", blobs.RowsAffected, files.RowsAffected)
+}
+return nil
 }
 
 // UpdateFileStatus updates file status and URL
@@ -161,45 +399,51 @@ Updates(map[string]interface{}{
 }).Error
 }
 
-// IncrementRefCount increments the reference count of a file
+// IncrementRefCount increments the reference count of the blob behind a file
 func IncrementRefCount(fileHash, userID string) error {
-return GetDB().Model(&File{}).
-Where("file_hash = ? AND user_id = ?", fileHash, userID).
+return GetDB().Model(&Blob{}).
+Where("file_hash = ?", fileHash).
 UpdateColumn("ref_count", gorm.Expr("ref_count + ?", 1)).Error
 }
 
-// DecrementRefCount decrements the reference count and deletes if reaches 0
-func DecrementRefCount(fileHash, userID string) error {
-tx := GetDB().Begin()
-defer func() {
-if r := recover(); r != nil {
-tx.Rollback()
-}
-}()
-
-// Decrement ref count
-result := tx.Model(&File{}).
-Where("file_hash = ? AND user_id = ?", fileHash, userID).
-UpdateColumn("ref_count", gorm.Expr("ref_count - ?", 1))
-
+// DecrementRefCount removes a user's file record and releases its blob reference in one transaction.
+// When the last reference goes away the blob row is deleted and returned so the caller can
+// delete the physical object; otherwise the returned blob is nil.
+func DecrementRefCount(fileHash, userID string) (*Blob, error) {
+var released *Blob
+err := GetDB().Transaction(func(tx *gorm.DB) error {
+result := tx.Where("file_hash = ? AND user_id = ?", fileHash, userID).Delete(&File{})
 if result.Error != nil {
-tx.Rollback()
 return result.Error
 }
+if result.RowsAffected == 0 {
+return gorm.ErrRecordNotFound
+}
 
-// Check if ref count is 0
-var file File
-if err := tx.Where("file_hash = ? AND user_id = ? AND ref_count <= 0", fileHash, userID).First(&file).Error; err == nil {
-// Delete the file record
-if err := tx.Delete(&file).Error; err != nil {
-tx.Rollback()
+var blob Blob
+err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("file_hash = ?", fileHash).First(&blob).Error
+if err == gorm.ErrRecordNotFound {
+return nil
+}
+if err != nil {
 return err
 }
-// TODO: Also delete physical file from storage
-log.Printf("File deleted due to zero ref count: %s (user: %s)", fileHash, userID)
+
+if blob.RefCount > 1 {
+return tx.Model(&blob).UpdateColumn("ref_count", gorm.Expr("ref_count - ?", 1)).Error
 }
 
-return tx.Commit().Error
+if err := tx.Delete(&blob).Error; err != nil {
+return err
+}
+released = &blob
+log.Printf("Blob released due to zero ref count: %s (last user: %s)", fileHash, userID)
+return nil
+})
+if err != nil {
+return nil, err
+}
+return released, nil
 }
 
 // GetFileByHashAndUser retrieves a file record by hash and user
@@ -229,12 +473,15 @@ return &file, nil
 }
 
 // CreateTranscodeTask creates a new transcode task with request ID for idempotency
-func CreateTranscodeTask(taskID, fileHash, userID, resolutions, requestID string) error {
+func CreateTranscodeTask(taskID, fileHash, userID, resolutions, profiles string, perTitle, qualityMetrics bool, requestID string) error {
 task := &TranscodeTask{
 TaskID:      taskID,
 FileHash:    fileHash,
 UserID:      userID,
 Resolutions: resolutions,
+Profiles:    profiles,
+PerTitle:    perTitle,
+QualityMetrics: qualityMetrics,
 Status:      "pending",
 Progress:    0,
 RequestID:   requestID,
@@ -259,15 +506,19 @@ return nil, err
 return &task, nil
 }
 
-// UpdateTranscodeTaskProgress updates task progress and status
-func UpdateTranscodeTaskProgress(taskID string, status string, progress int32, resultURLs string) error {
-updates := map[string]interface{}{
-"status":   status,
-"progress": progress,
+// UpdateTranscodeTaskProgress updates the progress of a task held by workerID, together with
+// any extra columns (per-rendition state, speed, ETA). Embedded and standalone workers report
+// progress through it; it returns false once the task is cancelled or reclaimed by another worker.
+func UpdateTranscodeTaskProgress(taskID, workerID string, progress int32, extra map[string]interface{}) (bool, error) {
+updates := map[string]interface{}{"progress": progress}
+for column, value := range extra {
+updates[column] = value
 }
-if resultURLs != "" {
-updates["result_urls"] = resultURLs
+return UpdateRunningTranscodeTask(taskID, workerID, updates)
 }
+
+// UpdateTranscodeTask updates arbitrary transcode task columns
+func UpdateTranscodeTask(taskID string, updates map[string]interface{}) error {
 return GetDB().Model(&TranscodeTask{}).
 Where("task_id = ?", taskID).
 Updates(updates).Error
@@ -289,10 +540,38 @@ return &task, nil
 // GetPendingTranscodeTasks gets all pending transcode tasks
 func GetPendingTranscodeTasks(limit int) ([]TranscodeTask, error) {
 var tasks []TranscodeTask
-err := GetDB().Where("status = ?", "pending").Limit(limit).Find(&tasks).Error
+err := GetDB().Where("status = ?", "pending").Order("id").Limit(limit).Find(&tasks).Error
+return tasks, err
+}
+
+// GetTranscodeTasksWithoutLocations gets tasks whose renditions were recorded before
+// storage keys were tracked (rendition JSON without a storage_key)
+func GetTranscodeTasksWithoutLocations() ([]TranscodeTask, error) {
+var tasks []TranscodeTask
+err := GetDB().Where("renditions LIKE ? AND renditions NOT LIKE ?", `%"url":%`, `%"storage_key":%`).
+Order("id").Find(&tasks).Error
+return tasks, err
+}
+
+// GetUserTranscodeTasks gets every transcode task a user created for a file
+func GetUserTranscodeTasks(fileHash, userID string) ([]TranscodeTask, error) {
+var tasks []TranscodeTask
+err := GetDB().Where("file_hash = ? AND user_id = ?", fileHash, userID).Order("id").Find(&tasks).Error
 return tasks, err
 }
 
+// CountTranscodeOutputReferences counts tasks other than excludeTaskIDs whose renditions record storageKey.
+// Outputs written before keys were unique per task (files/<hash>_<profile>.<ext>) are shared by every task of the file
+func CountTranscodeOutputReferences(storageKey string, excludeTaskIDs []string) (int64, error) {
+query := GetDB().Model(&TranscodeTask{}).Where("renditions LIKE ?", `%"storage_key":"`+storageKey+`"%`)
+if len(excludeTaskIDs) > 0 {
+query = query.Where("task_id NOT IN ?", excludeTaskIDs)
+}
+var count int64
+err := query.Count(&count).Error
+return count, err
+}
+
 // DeleteFile soft deletes a file record
 func DeleteFile(fileHash string) error {
 return GetDB().Where("file_hash = ?", fileHash).Delete(&File{}).Error
@@ -317,3 +596,39 @@ return GetDB().Model(&File{}).
 Where("file_hash = ? AND user_id = ?", fileHash, userID).
 Updates(updates).Error
 }
+
+// UpdateFileMedia stores probed media metadata on every file record referencing fileHash
+func UpdateFileMedia(fileHash string, width, height int32, media MediaMetadata) error {
+return GetDB().Model(&File{}).
+Where("file_hash = ?", fileHash).
+Select("width", "height", "duration_ms", "container", "video_codec", "audio_codec", "bitrate",
+"frame_rate", "rotation", "audio_channels", "audio_sample_rate", "hdr", "hdr_format", "stream_count", "faststart").
+Updates(&File{Width: width, Height: height, Media: media}).Error
+}
+
+// UpdateFilePoster updates the custom poster URL of a file; an empty URL restores the generated poster
+func UpdateFilePoster(fileHash, userID, posterURL string) error {
+return GetDB().Model(&File{}).
+Where("file_hash = ? AND user_id = ?", fileHash, userID).
+Update("poster_url", posterURL).Error
+}
+
+// SwitchFileStreamURLs points a file at a newly packaged HLS master playlist and DASH manifest
+// and returns the playback URL it replaced, so the caller can delete the superseded stream files
+func SwitchFileStreamURLs(fileHash, userID, playbackURL, dashURL string) (string, error) {
+var previous string
+err := GetDB().Transaction(func(tx *gorm.DB) error {
+var file File
+err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+Where("file_hash = ? AND user_id = ?", fileHash, userID).First(&file).Error
+if err != nil {
+return err
+}
+previous = file.PlaybackURL
+return tx.Model(&file).Updates(map[string]interface{}{
+"playback_url": playbackURL,
+"dash_url":     dashURL,
+}).Error
+})
+return previous, err
+}
@@ -0,0 +1,148 @@
+package db
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProcessingPolicy describes the processing that runs automatically after an upload is merged.
+// The row with an empty UserID is the platform default; a user's own row takes precedence over it.
+type ProcessingPolicy struct {
+	ID                 uint      `gorm:"primaryKey"`
+	UserID             string    `gorm:"uniqueIndex;size:64;not null;default:''"` // 空字符串表示平台默认策略
+	Enabled            bool      `gorm:"not null"`                                // 停用后按没有策略处理（不回退到平台默认策略）
+	Steps              string    `gorm:"type:text"`                               // JSON格式，启用的步骤：probe, validate, thumbnails, transcode, package
+	Profiles           string    `gorm:"type:text"`                               // JSON格式，transcode 步骤使用的编码配置名称
+	PerTitle           bool      `gorm:"default:false"`
+	QualityMetrics     bool      `gorm:"default:false"`
+	RequireVideo       bool      `gorm:"default:false"` // validate：必须包含视频流
+	MaxDurationSeconds int32     `gorm:"default:0"`     // validate：最长时长，0 表示不限制
+	MinShortSide       int32     `gorm:"default:0"`     // validate：画面短边的最小值，0 表示不限制
+	MaxShortSide       int32     `gorm:"default:0"`     // validate：画面短边的最大值，0 表示不限制
+	CreatedAt          time.Time `gorm:"autoCreateTime"`
+	UpdatedAt          time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName specifies the table name for ProcessingPolicy model
+func (ProcessingPolicy) TableName() string {
+	return "processing_policies"
+}
+
+// Pipeline records one run of a processing policy for an uploaded file
+type Pipeline struct {
+	ID         uint      `gorm:"primaryKey"`
+	PipelineID string    `gorm:"uniqueIndex;size:64;not null"`
+	FileHash   string    `gorm:"size:64;not null;index:idx_pipeline_user_file"`
+	UserID     string    `gorm:"size:64;not null;index:idx_pipeline_user_file"`
+	MergeJobID string    `gorm:"size:64;index"`            // 触发流程的合并任务
+	Status     string    `gorm:"size:20;default:'queued'"` // queued, running, completed, failed
+	Policy     string    `gorm:"type:text"`                // JSON格式，创建时的策略快照
+	Steps      string    `gorm:"type:text"`                // JSON格式，各步骤的状态
+	Error      string    `gorm:"type:text"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName specifies the table name for Pipeline model
+func (Pipeline) TableName() string {
+	return "pipelines"
+}
+
+// GetProcessingPolicy retrieves the policy of a user, or the platform default when userID is empty
+func GetProcessingPolicy(userID string) (*ProcessingPolicy, error) {
+	var policy ProcessingPolicy
+	err := GetDB().Where("user_id = ?", userID).First(&policy).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// SaveProcessingPolicy creates or replaces the policy of policy.UserID
+func SaveProcessingPolicy(policy *ProcessingPolicy) error {
+	return GetDB().Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"enabled", "steps", "profiles", "per_title", "quality_metrics",
+			"require_video", "max_duration_seconds", "min_short_side", "max_short_side", "updated_at",
+		}),
+	}).Create(policy).Error
+}
+
+// DeleteProcessingPolicy deletes the policy of a user, or the platform default when userID is empty
+func DeleteProcessingPolicy(userID string) (bool, error) {
+	result := GetDB().Where("user_id = ?", userID).Delete(&ProcessingPolicy{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// CreatePipeline creates a new pipeline record
+func CreatePipeline(pipeline *Pipeline) error {
+	return GetDB().Create(pipeline).Error
+}
+
+// GetPipeline retrieves a pipeline by ID
+func GetPipeline(pipelineID string) (*Pipeline, error) {
+	var pipeline Pipeline
+	err := GetDB().Where("pipeline_id = ?", pipelineID).First(&pipeline).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pipeline, nil
+}
+
+// GetPipelineByMergeJob retrieves the pipeline started by a merge job
+func GetPipelineByMergeJob(mergeJobID string) (*Pipeline, error) {
+	var pipeline Pipeline
+	err := GetDB().Where("merge_job_id = ?", mergeJobID).First(&pipeline).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pipeline, nil
+}
+
+// GetLatestPipeline retrieves the most recent pipeline of a user's file
+func GetLatestPipeline(fileHash, userID string) (*Pipeline, error) {
+	var pipeline Pipeline
+	err := GetDB().Where("file_hash = ? AND user_id = ?", fileHash, userID).
+		Order("id DESC").First(&pipeline).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pipeline, nil
+}
+
+// GetUnfinishedPipelines gets pipelines that were queued or running when the service stopped
+func GetUnfinishedPipelines() ([]Pipeline, error) {
+	var pipelines []Pipeline
+	err := GetDB().Where("status IN ?", []string{"queued", "running"}).Order("id").Find(&pipelines).Error
+	return pipelines, err
+}
+
+// UpdatePipeline updates pipeline fields
+func UpdatePipeline(pipelineID string, updates map[string]interface{}) error {
+	return GetDB().Model(&Pipeline{}).
+		Where("pipeline_id = ?", pipelineID).
+		Updates(updates).Error
+}
+
+// TransitionPipeline updates a pipeline only while its status is one of from; reports whether it did
+func TransitionPipeline(pipelineID string, from []string, updates map[string]interface{}) (bool, error) {
+	result := GetDB().Model(&Pipeline{}).
+		Where("pipeline_id = ? AND status IN ?", pipelineID, from).
+		Updates(updates)
+	return result.RowsAffected == 1, result.Error
+}
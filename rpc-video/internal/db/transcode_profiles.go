@@ -0,0 +1,95 @@
+package db
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TranscodeProfile represents a named encoding profile
+type TranscodeProfile struct {
+	ID              uint      `gorm:"primaryKey"`
+	Name            string    `gorm:"uniqueIndex;size:64;not null"`
+	Codec           string    `gorm:"size:16;not null"` // h264, h265, vp9, av1
+	RateControl     string    `gorm:"size:16;not null"` // cbr, vbr, crf, two_pass
+	Container       string    `gorm:"size:16;not null"` // mp4, webm, mkv
+	Width           int32     `gorm:"default:0"`        // 0 表示按高度等比缩放
+	Height          int32     `gorm:"not null"`
+	FitMode         string    `gorm:"size:8;default:'fit'"` // fit, pad, crop
+	VideoBitrate    string    `gorm:"size:16"`
+	MaxBitrate      string    `gorm:"size:16"`
+	CRF             int32     `gorm:"column:crf;default:0"`
+	Preset          string    `gorm:"size:32"`
+	AudioCodec      string    `gorm:"size:16;default:'aac'"` // aac, opus, none
+	AudioBitrate    string    `gorm:"size:16"`
+	AudioChannels   int32     `gorm:"default:0"`
+	AudioSampleRate int32     `gorm:"default:0"`
+	BuiltIn         bool      `gorm:"default:false"` // 内置配置不可删除
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName specifies the table name for TranscodeProfile model
+func (TranscodeProfile) TableName() string {
+	return "transcode_profiles"
+}
+
+// ListTranscodeProfiles lists all encoding profiles ordered by name
+func ListTranscodeProfiles() ([]TranscodeProfile, error) {
+	var profiles []TranscodeProfile
+	err := GetDB().Order("built_in DESC, name").Find(&profiles).Error
+	return profiles, err
+}
+
+// GetTranscodeProfile retrieves an encoding profile by name
+func GetTranscodeProfile(name string) (*TranscodeProfile, error) {
+	var profile TranscodeProfile
+	err := GetDB().Where("name = ?", name).First(&profile).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// SaveTranscodeProfile creates or updates an encoding profile by name.
+// The built_in flag of an existing profile is preserved.
+func SaveTranscodeProfile(profile *TranscodeProfile) error {
+	return GetDB().Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"codec", "rate_control", "container", "width", "height", "fit_mode",
+			"video_bitrate", "max_bitrate", "crf", "preset",
+			"audio_codec", "audio_bitrate", "audio_channels", "audio_sample_rate",
+			"updated_at",
+		}),
+	}).Create(profile).Error
+}
+
+// EnsureTranscodeProfiles inserts the given profiles if no profile with the same name exists
+func EnsureTranscodeProfiles(profiles []TranscodeProfile) error {
+	if len(profiles) == 0 {
+		return nil
+	}
+	return GetDB().Clauses(clause.OnConflict{DoNothing: true}).Create(&profiles).Error
+}
+
+// DeleteTranscodeProfile deletes a non built-in encoding profile.
+// Returns gorm.ErrRecordNotFound if the profile does not exist.
+func DeleteTranscodeProfile(name string) (builtIn bool, err error) {
+	err = GetDB().Transaction(func(tx *gorm.DB) error {
+		var profile TranscodeProfile
+		if err := tx.Where("name = ?", name).First(&profile).Error; err != nil {
+			return err
+		}
+		if profile.BuiltIn {
+			builtIn = true
+			return nil
+		}
+		return tx.Delete(&profile).Error
+	})
+	return builtIn, err
+}
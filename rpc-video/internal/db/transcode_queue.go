@@ -0,0 +1,144 @@
+package db
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 转码任务以 transcode_tasks 表作为持久化队列：
+// pending 的任务由工作者通过条件更新领取（同一任务只会被一个工作者领取成功），
+// 领取后工作者持有租约并定期心跳续期；租约过期的 processing 任务会被重新放回队列。
+
+// ClaimTranscodeTask atomically moves a pending task to processing and grants the worker a lease
+func ClaimTranscodeTask(taskID, workerID string, lease time.Duration) (bool, error) {
+	expiresAt := time.Now().Add(lease)
+	result := GetDB().Model(&TranscodeTask{}).
+		Where("task_id = ? AND status = ?", taskID, "pending").
+		Updates(map[string]interface{}{
+			"status":           "processing",
+			"worker_id":        workerID,
+			"lease_expires_at": expiresAt,
+			"attempts":         gorm.Expr("attempts + 1"),
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// HeartbeatTranscodeTask extends the lease; returns false if the worker no longer holds it
+func HeartbeatTranscodeTask(taskID, workerID string, lease time.Duration) (bool, error) {
+	result := GetDB().Model(&TranscodeTask{}).
+		Where("task_id = ? AND worker_id = ? AND status = ?", taskID, workerID, "processing").
+		Update("lease_expires_at", time.Now().Add(lease))
+	return result.RowsAffected == 1, result.Error
+}
+
+// UpdateRunningTranscodeTask updates a task only while the given worker still holds it,
+// so progress from a cancelled or reclaimed run never overwrites the current state
+func UpdateRunningTranscodeTask(taskID, workerID string, updates map[string]interface{}) (bool, error) {
+	result := GetDB().Model(&TranscodeTask{}).
+		Where("task_id = ? AND worker_id = ? AND status = ?", taskID, workerID, "processing").
+		Updates(updates)
+	return result.RowsAffected == 1, result.Error
+}
+
+// GetTranscodeTaskStatus returns only the status column of a task
+func GetTranscodeTaskStatus(taskID string) (string, error) {
+	var statuses []string
+	err := GetDB().Model(&TranscodeTask{}).Where("task_id = ?", taskID).Limit(1).Pluck("status", &statuses).Error
+	if err != nil || len(statuses) == 0 {
+		return "", err
+	}
+	return statuses[0], nil
+}
+
+// FinishTranscodeTask records the final result and releases the lease
+// only the worker holding the lease can finish the task
+func FinishTranscodeTask(taskID, workerID string, updates map[string]interface{}) (bool, error) {
+	updates["worker_id"] = ""
+	updates["lease_expires_at"] = nil
+	result := GetDB().Model(&TranscodeTask{}).
+		Where("task_id = ? AND worker_id = ? AND status = ?", taskID, workerID, "processing").
+		Updates(updates)
+	return result.RowsAffected == 1, result.Error
+}
+
+// ReleaseTranscodeTask gives a claimed task back to the queue (worker shutting down)
+func ReleaseTranscodeTask(taskID, workerID string) error {
+	return GetDB().Model(&TranscodeTask{}).
+		Where("task_id = ? AND worker_id = ? AND status = ?", taskID, workerID, "processing").
+		Updates(map[string]interface{}{
+			"status":           "pending",
+			"worker_id":        "",
+			"lease_expires_at": nil,
+			"attempts":         gorm.Expr("attempts - 1"),
+		}).Error
+}
+
+// CancelTranscodeTask marks a pending or processing task as cancelled and returns the status it had before;
+// the worker holding a processing task notices the change and stops ffmpeg
+func CancelTranscodeTask(taskID string) (string, error) {
+	var previous string
+	err := GetDB().Transaction(func(tx *gorm.DB) error {
+		var task TranscodeTask
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("task_id = ?", taskID).First(&task).Error; err != nil {
+			return err
+		}
+		previous = task.Status
+		if task.Status != "pending" && task.Status != "processing" {
+			return nil
+		}
+		return tx.Model(&TranscodeTask{}).Where("id = ?", task.ID).
+			Updates(map[string]interface{}{
+				"status":           "cancelled",
+				"speed":            0,
+				"eta_seconds":      0,
+				"lease_expires_at": nil,
+			}).Error
+	})
+	return previous, err
+}
+
+// RetryTranscodeTask puts a failed, dead-lettered or cancelled task back to the queue with fresh attempts
+func RetryTranscodeTask(taskID string) (bool, error) {
+	result := GetDB().Model(&TranscodeTask{}).
+		Where("task_id = ? AND status IN ?", taskID, []string{"failed", "dead_letter", "cancelled"}).
+		Updates(map[string]interface{}{
+			"status":           "pending",
+			"worker_id":        "",
+			"lease_expires_at": nil,
+			"attempts":         0,
+			"error":            "",
+			"speed":            0,
+			"eta_seconds":      -1,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// RequeueExpiredTranscodeTasks puts processing tasks whose lease expired (crashed worker) back to pending;
+// tasks that have already been claimed maxAttempts times are moved to the dead-letter state instead
+func RequeueExpiredTranscodeTasks(maxAttempts int32) (requeued int64, deadLettered int64, err error) {
+	expired := GetDB().Model(&TranscodeTask{}).
+		Where("status = ? AND (lease_expires_at IS NULL OR lease_expires_at < ?)", "processing", time.Now())
+
+	result := expired.Session(&gorm.Session{}).Where("attempts >= ?", maxAttempts).
+		Updates(map[string]interface{}{
+			"status":           "dead_letter",
+			"worker_id":        "",
+			"lease_expires_at": nil,
+			"error":            "工作者多次中断，超过最大重试次数",
+		})
+	if result.Error != nil {
+		return 0, 0, result.Error
+	}
+	deadLettered = result.RowsAffected
+
+	result = expired.Session(&gorm.Session{}).Where("attempts < ?", maxAttempts).
+		Updates(map[string]interface{}{
+			"status":           "pending",
+			"worker_id":        "",
+			"lease_expires_at": nil,
+		})
+	return result.RowsAffected, deadLettered, result.Error
+}
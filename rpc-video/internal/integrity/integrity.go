@@ -0,0 +1,178 @@
+package integrity
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"video-platform-microservice/rpc-video/internal/storage"
+)
+
+// ErrHashMismatch 合并后的文件哈希与声明的 file_hash 不一致
+var ErrHashMismatch = fmt.Errorf("文件哈希不匹配")
+
+// ErrDigestMismatch 流式读取的数据与声明的摘要不一致
+var ErrDigestMismatch = fmt.Errorf("摘要不匹配")
+
+// NewHasher 根据十六进制摘要的长度选择算法：32 位为 MD5，64 位为 SHA-256
+func NewHasher(hexDigest string) (hash.Hash, error) {
+	switch len(hexDigest) {
+	case 32:
+		return md5.New(), nil
+	case 64:
+		return sha256.New(), nil
+	default:
+		return nil, fmt.Errorf("不支持的摘要格式: %s", hexDigest)
+	}
+}
+
+// VerifyBytes 校验数据的摘要
+func VerifyBytes(data []byte, expected string) error {
+	h, err := NewHasher(expected)
+	if err != nil {
+		return err
+	}
+	h.Write(data)
+	if actual := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("摘要不匹配: 期望 %s, 实际 %s", expected, actual)
+	}
+	return nil
+}
+
+// verifyingReader 读取时计算摘要，读到末尾时与期望值比较
+type verifyingReader struct {
+	r        io.Reader
+	h        hash.Hash
+	expected string
+}
+
+// NewVerifyingReader 包装 r：数据读完时摘要不一致则以错误代替 io.EOF 返回，
+// 写入存储的一方据此放弃写入，不会留下内容错误的对象
+func NewVerifyingReader(r io.Reader, expected string) (io.Reader, error) {
+	h, err := NewHasher(expected)
+	if err != nil {
+		return nil, err
+	}
+	return &verifyingReader{r: r, h: h, expected: expected}, nil
+}
+
+func (v *verifyingReader) Read(p []byte) (int, error) {
+	n, err := v.r.Read(p)
+	v.h.Write(p[:n])
+	if err == io.EOF {
+		if actual := hex.EncodeToString(v.h.Sum(nil)); !strings.EqualFold(actual, v.expected) {
+			return n, fmt.Errorf("%w: 期望 %s, 实际 %s", ErrDigestMismatch, v.expected, actual)
+		}
+	}
+	return n, err
+}
+
+// VerifyChunks 按顺序流式读取所有分片，同时计算整个文件的哈希和各分片的哈希
+// chunkDigests 为客户端上传时提供的分片摘要（index -> digest），可以为空或不完整；
+// 整体哈希不匹配时返回 ErrHashMismatch，以及摘要不一致的分片索引
+func VerifyChunks(ctx context.Context, chunkPrefix string, totalChunks int, fileHash string, chunkDigests map[string]string) ([]int32, error) {
+	whole, err := NewHasher(fileHash)
+	if err != nil {
+		return nil, err
+	}
+
+	backend := storage.GetBackend()
+	var badChunks []int32
+	for i := 0; i < totalChunks; i++ {
+		index := strconv.Itoa(i)
+		writers := []io.Writer{whole}
+
+		var chunkHasher hash.Hash
+		expected := chunkDigests[index]
+		if expected != "" {
+			if chunkHasher, err = NewHasher(expected); err == nil {
+				writers = append(writers, chunkHasher)
+			}
+		}
+
+		reader, err := backend.GetRange(ctx, storage.GetChunkKey(chunkPrefix, index), 0, -1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open chunk %d: %w", i, err)
+		}
+		_, err = io.Copy(io.MultiWriter(writers...), reader)
+		reader.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chunk %d: %w", i, err)
+		}
+
+		if chunkHasher != nil && !strings.EqualFold(hex.EncodeToString(chunkHasher.Sum(nil)), expected) {
+			badChunks = append(badChunks, int32(i))
+		}
+	}
+
+	if !strings.EqualFold(hex.EncodeToString(whole.Sum(nil)), fileHash) {
+		return badChunks, ErrHashMismatch
+	}
+	return nil, nil
+}
+
+// possessionChallengeSize 持有性证明的区间长度
+const possessionChallengeSize = 64 * 1024
+
+// PossessionChallenge 跨用户秒传时要求客户端证明持有文件内容：
+// 对服务端随机选取的区间计算 hex(SHA-256(Nonce + 区间内容))，只知道文件哈希和大小无法作答
+type PossessionChallenge struct {
+	Nonce  string
+	Offset int64
+	Length int64
+}
+
+// NewPossessionChallenge 在 size 字节的文件中随机选取区间
+func NewPossessionChallenge(size int64) (*PossessionChallenge, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	length := int64(possessionChallengeSize)
+	if length > size {
+		length = size
+	}
+	offset, err := rand.Int(rand.Reader, big.NewInt(size-length+1))
+	if err != nil {
+		return nil, err
+	}
+	return &PossessionChallenge{Nonce: hex.EncodeToString(nonce), Offset: offset.Int64(), Length: length}, nil
+}
+
+// Answer 读取 r（区间内容）计算应答
+func (c *PossessionChallenge) Answer(r io.Reader) (string, error) {
+	h := sha256.New()
+	h.Write([]byte(c.Nonce))
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return "", err
+	}
+	if n != c.Length {
+		return "", fmt.Errorf("区间长度不足: 期望 %d, 实际 %d", c.Length, n)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ExpectedAnswer 读取存储中的对象计算期望的应答
+func (c *PossessionChallenge) ExpectedAnswer(ctx context.Context, key string) (string, error) {
+	r, err := storage.OpenFileRange(ctx, key, c.Offset, c.Offset+c.Length)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	return c.Answer(r)
+}
+
+// AnswerMatches 以常数时间比较应答
+func AnswerMatches(answer, expected string) bool {
+	return expected != "" && subtle.ConstantTimeCompare([]byte(strings.ToLower(answer)), []byte(expected)) == 1
+}
@@ -0,0 +1,193 @@
+package integrity
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"video-platform-microservice/rpc-video/internal/storage"
+)
+
+func TestPossessionChallenge(t *testing.T) {
+	storage.SetBackend(storage.NewLocalBackend(t.TempDir()))
+	ctx := context.Background()
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), 10000) // 160000 字节，大于区间长度
+	if err := storage.GetBackend().Put(ctx, "blobs/x", bytes.NewReader(data), int64(len(data))); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 20; i++ {
+		c, err := NewPossessionChallenge(int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Length != possessionChallengeSize || c.Offset < 0 || c.Offset+c.Length > int64(len(data)) {
+			t.Fatalf("challenge out of range: %+v", c)
+		}
+
+		expected, err := c.ExpectedAnswer(ctx, "blobs/x")
+		if err != nil {
+			t.Fatal(err)
+		}
+		h := sha256.New()
+		h.Write([]byte(c.Nonce))
+		h.Write(data[c.Offset : c.Offset+c.Length])
+		answer := hex.EncodeToString(h.Sum(nil))
+		if !AnswerMatches(answer, expected) {
+			t.Fatalf("answer %s does not match expected %s", answer, expected)
+		}
+
+		// 只知道文件哈希无法作答：对整个文件或其他区间计算的摘要都不匹配
+		whole := sha256.Sum256(data)
+		if AnswerMatches(hex.EncodeToString(whole[:]), expected) {
+			t.Fatal("whole-file hash accepted as answer")
+		}
+	}
+}
+
+func TestPossessionChallengeSmallFile(t *testing.T) {
+	c, err := NewPossessionChallenge(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Offset != 0 || c.Length != 10 {
+		t.Errorf("challenge = %+v, want the whole 10-byte file", c)
+	}
+	if _, err := c.Answer(bytes.NewReader([]byte("short"))); err == nil {
+		t.Error("Answer accepted a truncated range")
+	}
+	if AnswerMatches("", "") {
+		t.Error("empty expected answer must never match")
+	}
+}
+
+func md5Hex(b []byte) string {
+	sum := md5.Sum(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestNewHasher(t *testing.T) {
+	data := []byte("hello")
+	tests := []struct {
+		digest string
+		want   string
+	}{
+		{digest: md5Hex(data), want: md5Hex(data)},
+		{digest: sha256Hex(data), want: sha256Hex(data)},
+		{digest: strings.ToUpper(sha256Hex(data)), want: sha256Hex(data)},
+	}
+	for _, tt := range tests {
+		h, err := NewHasher(tt.digest)
+		if err != nil {
+			t.Fatalf("NewHasher(%s): %v", tt.digest, err)
+		}
+		h.Write(data)
+		if got := hex.EncodeToString(h.Sum(nil)); got != tt.want {
+			t.Errorf("NewHasher(%s) computed %s, want %s", tt.digest, got, tt.want)
+		}
+		if err := VerifyBytes(data, tt.digest); err != nil {
+			t.Errorf("VerifyBytes(%s): %v", tt.digest, err)
+		}
+	}
+	for _, digest := range []string{"", "abc", sha256Hex(data)[:40], sha256Hex(data) + "00"} {
+		if _, err := NewHasher(digest); err == nil {
+			t.Errorf("NewHasher(%q) accepted an unsupported digest", digest)
+		}
+	}
+}
+
+func TestVerifyChunks(t *testing.T) {
+	storage.SetBackend(storage.NewLocalBackend(t.TempDir()))
+	ctx := context.Background()
+
+	chunks := [][]byte{[]byte("first chunk "), []byte("second chunk "), []byte("third chunk")}
+	fileHash := sha256Hex(bytes.Join(chunks, nil))
+	digests := map[string]string{
+		"0": md5Hex(chunks[0]),
+		"1": sha256Hex(chunks[1]),
+		"2": md5Hex(chunks[2]),
+	}
+
+	// store 写入分片，corrupt 中的分片被改写
+	store := func(t *testing.T, prefix string, corrupt ...int) {
+		t.Helper()
+		for i, data := range chunks {
+			for _, c := range corrupt {
+				if c == i {
+					data = bytes.ToUpper(data)
+				}
+			}
+			key := storage.GetChunkKey(prefix, strconv.Itoa(i))
+			if err := storage.GetBackend().Put(ctx, key, bytes.NewReader(data), int64(len(data))); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	without := func(indices ...string) map[string]string {
+		m := maps.Clone(digests)
+		for _, i := range indices {
+			delete(m, i)
+		}
+		return m
+	}
+
+	tests := []struct {
+		name     string
+		fileHash string
+		corrupt  []int
+		digests  map[string]string
+		bad      []int32
+		err      error
+	}{
+		{name: "intact", fileHash: fileHash, digests: digests},
+		{name: "intact md5 file hash", fileHash: md5Hex(bytes.Join(chunks, nil)), digests: digests},
+		{name: "intact without digests", fileHash: fileHash},
+		{name: "single bad chunk", fileHash: fileHash, corrupt: []int{1}, digests: digests, bad: []int32{1}, err: ErrHashMismatch},
+		{name: "two bad chunks", fileHash: fileHash, corrupt: []int{0, 2}, digests: digests, bad: []int32{0, 2}, err: ErrHashMismatch},
+		// 缺少摘要的分片只能由整体哈希发现，无法定位
+		{name: "missing digest falls back to file hash", fileHash: fileHash, corrupt: []int{1}, digests: without("1"), err: ErrHashMismatch},
+		{name: "missing digests located where available", fileHash: fileHash, corrupt: []int{0, 1}, digests: without("1"), bad: []int32{0}, err: ErrHashMismatch},
+		{name: "no digests", fileHash: fileHash, corrupt: []int{2}, err: ErrHashMismatch},
+		{name: "malformed digest ignored", fileHash: fileHash, corrupt: []int{2}, digests: map[string]string{"2": "bogus"}, err: ErrHashMismatch},
+		// 分片都与摘要一致但整体哈希不符（例如声明了错误的 file_hash）
+		{name: "wrong file hash", fileHash: sha256Hex([]byte("other")), digests: digests, err: ErrHashMismatch},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefix := "u_" + strconv.Itoa(i)
+			store(t, prefix, tt.corrupt...)
+			bad, err := VerifyChunks(ctx, prefix, len(chunks), tt.fileHash, tt.digests)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("VerifyChunks error = %v, want %v", err, tt.err)
+			}
+			if !slices.Equal(bad, tt.bad) {
+				t.Errorf("bad chunks = %v, want %v", bad, tt.bad)
+			}
+		})
+	}
+
+	t.Run("unsupported file hash", func(t *testing.T) {
+		if _, err := VerifyChunks(ctx, "u_0", len(chunks), "abc", nil); err == nil || errors.Is(err, ErrHashMismatch) {
+			t.Errorf("VerifyChunks error = %v, want an unsupported digest error", err)
+		}
+	})
+	t.Run("missing chunk", func(t *testing.T) {
+		if _, err := VerifyChunks(ctx, "u_0", len(chunks)+1, fileHash, nil); err == nil || errors.Is(err, ErrHashMismatch) {
+			t.Errorf("VerifyChunks error = %v, want a read error", err)
+		}
+	})
+}
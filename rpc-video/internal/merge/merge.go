@@ -0,0 +1,442 @@
+package merge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"video-platform-microservice/rpc-video/internal/db"
+	"video-platform-microservice/rpc-video/internal/integrity"
+	"video-platform-microservice/rpc-video/internal/pipeline"
+	"video-platform-microservice/rpc-video/internal/redis"
+	"video-platform-microservice/rpc-video/internal/storage"
+	"video-platform-microservice/rpc-video/internal/transcode"
+)
+
+// 合并任务状态
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// 合并任务阶段
+const (
+	PhaseQueued    = "queued"
+	PhaseVerifying = "verifying"
+	PhaseMerging   = "merging"
+	PhaseProbing   = "probing"
+	PhaseSaving    = "saving"
+	PhaseDone      = "done"
+)
+
+const (
+	// progressInterval 合并进度写入数据库的最小间隔
+	progressInterval = time.Second
+	// lockWait 等待上传会话排他锁（正在写入的分片结束）的最长时间，超时后任务稍后重试
+	lockWait = 30 * time.Second
+	// maxAttempts 任务最多被领取的次数，超过后标记为失败，避免锁一直被占用时无限重试
+	maxAttempts = 10
+	// leaseTTL 任务租约时长，执行中的实例每 heartbeatInterval 续期一次
+	leaseTTL          = 60 * time.Second
+	heartbeatInterval = leaseTTL / 3
+	// reapInterval 检查租约过期任务的间隔
+	reapInterval = leaseTTL
+)
+
+// Manager 合并任务管理器
+// 任务持久化在 merge_jobs 表中，内存队列只保存任务ID。执行前通过条件更新领取任务并持有租约，
+// 同一任务只会由一个实例执行；实例退出后租约过期，任务由其他实例（或重启后的实例）接手
+type Manager struct {
+	queue    chan string
+	workers  int
+	workerID string
+}
+
+var manager *Manager
+
+// InitMergeManager 初始化合并管理器，并恢复重启前未完成的任务
+func InitMergeManager(workers int) error {
+	if workers <= 0 {
+		workers = 2 // 默认2个工作协程
+	}
+
+	hostname, _ := os.Hostname()
+	manager = &Manager{
+		queue:    make(chan string, 100),
+		workers:  workers,
+		workerID: fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.New().String()[:8]),
+	}
+
+	for i := 0; i < workers; i++ {
+		go manager.worker()
+	}
+
+	// 只恢复没有实例在执行的任务：其他实例仍持有租约的任务不受影响
+	recovered, err := manager.reap()
+	if err != nil {
+		return fmt.Errorf("failed to load unfinished merge jobs: %w", err)
+	}
+	go manager.reaper()
+
+	log.Printf("✅ 合并管理器已启动 (workers: %d, id: %s, 恢复任务: %d)", workers, manager.workerID, recovered)
+	return nil
+}
+
+// CreateJob 创建合并任务并加入队列
+func CreateJob(fileHash, userID, filename string, totalChunks, width, height int32, requestID string) (*db.MergeJob, error) {
+	job := &db.MergeJob{
+		JobID:       uuid.New().String(),
+		FileHash:    fileHash,
+		UserID:      userID,
+		Filename:    filename,
+		TotalChunks: totalChunks,
+		Width:       width,
+		Height:      height,
+		RequestID:   requestID,
+		Status:      StatusQueued,
+		Phase:       PhaseQueued,
+	}
+	if err := db.CreateMergeJob(job); err != nil {
+		return nil, err
+	}
+
+	manager.enqueue(job.JobID)
+
+	log.Printf("合并任务已创建: %s (file: %s, user: %s, chunks: %d)", job.JobID, fileHash, userID, totalChunks)
+	return job, nil
+}
+
+// BadChunks 解析任务中记录的损坏分片
+func BadChunks(job *db.MergeJob) []int32 {
+	var badChunks []int32
+	if job.BadChunks != "" {
+		json.Unmarshal([]byte(job.BadChunks), &badChunks)
+	}
+	return badChunks
+}
+
+// enqueue 加入队列；队列已满时不阻塞调用方（任务已持久化，只是稍后执行）
+func (m *Manager) enqueue(jobID string) {
+	select {
+	case m.queue <- jobID:
+	default:
+		go func() { m.queue <- jobID }()
+	}
+}
+
+// worker 工作协程
+func (m *Manager) worker() {
+	for jobID := range m.queue {
+		m.processJob(jobID)
+	}
+}
+
+// reaper 定期接手租约过期的任务
+func (m *Manager) reaper() {
+	ticker := time.NewTicker(reapInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if _, err := m.reap(); err != nil {
+			log.Printf("❌ 回收过期合并任务失败: %v", err)
+		}
+	}
+}
+
+// reap 将租约过期的执行中任务，以及长时间未被领取的排队任务加入本实例的队列；
+// 多个实例可能同时加入同一任务，执行前的领取保证只有一个实例会执行
+func (m *Manager) reap() (int, error) {
+	jobs, err := db.GetReclaimableMergeJobs(time.Now().Add(-leaseTTL))
+	if err != nil {
+		return 0, err
+	}
+	for _, job := range jobs {
+		m.enqueue(job.JobID)
+	}
+	if len(jobs) > 0 {
+		log.Printf("回收合并任务: %d 个", len(jobs))
+	}
+	return len(jobs), nil
+}
+
+// processJob 执行单个合并任务并记录结果
+func (m *Manager) processJob(jobID string) {
+	ok, err := db.ClaimMergeJob(jobID, m.workerID, leaseTTL)
+	if err != nil {
+		// 任务仍在排队，由 reaper 稍后重新加入队列
+		log.Printf("❌ 领取合并任务失败: %s, error: %v", jobID, err)
+		return
+	}
+	if !ok {
+		// 任务已完成，或由其他实例执行中
+		return
+	}
+	job, err := db.GetMergeJob(jobID)
+	if err != nil || job == nil {
+		log.Printf("❌ 合并任务不存在: %s, error: %v", jobID, err)
+		return
+	}
+	if job.Attempts > maxAttempts {
+		log.Printf("❌ 合并任务重试次数已耗尽: %s (%d 次), 最后一次错误: %s", jobID, job.Attempts-1, job.Error)
+		db.FinishMergeJob(jobID, m.workerID, map[string]interface{}{
+			"status": StatusFailed,
+			"error":  fmt.Sprintf("尝试 %d 次后仍未完成合并: %s", job.Attempts-1, job.Error),
+		})
+		return
+	}
+
+	// 任务租约丢失时停止执行，包括等待锁的期间
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.heartbeat(ctx, cancel, jobID)
+
+	// 合并期间持有上传会话的排他锁：拒绝新的分片写入，并保证同一文件只有一个任务在合并
+	lock, err := redis.LockUploadExclusive(ctx, redis.UploadSession(job.UserID, job.FileHash), lockWait)
+	if err != nil {
+		log.Printf("[Merge] 获取上传锁失败，稍后重试: %s, error: %v", jobID, err)
+		m.retryLater(ctx, cancel, jobID, fmt.Sprintf("获取上传锁失败: %v", err))
+		return
+	}
+	defer lock.Unlock()
+
+	// 同时持有物理文件锁：合并查询到物理文件后到写入引用前，该物理文件不能被删除
+	blobLock, err := redis.LockBlob(ctx, job.FileHash, lockWait)
+	if err != nil {
+		log.Printf("[Merge] 获取物理文件锁失败，稍后重试: %s, error: %v", jobID, err)
+		m.retryLater(ctx, cancel, jobID, fmt.Sprintf("获取物理文件锁失败: %v", err))
+		return
+	}
+	defer blobLock.Unlock()
+
+	// 上传锁或物理文件锁丢失时同样停止执行，进行中的合并随 ctx 取消
+	defer context.AfterFunc(lock.Context(), cancel)()
+	defer context.AfterFunc(blobLock.Context(), cancel)()
+
+	log.Printf("开始处理合并任务: %s", jobID)
+	url, badChunks, err := m.run(ctx, job)
+	if err != nil {
+		log.Printf("❌ 合并失败: %s, error: %v", jobID, err)
+		updates := map[string]interface{}{
+			"status": StatusFailed,
+			"error":  err.Error(),
+		}
+		if len(badChunks) > 0 {
+			badChunksJSON, _ := json.Marshal(badChunks)
+			updates["bad_chunks"] = string(badChunksJSON)
+		}
+		if ok, _ := db.FinishMergeJob(jobID, m.workerID, updates); !ok {
+			log.Printf("[Merge] 租约已丢失，放弃记录结果: %s", jobID)
+		}
+		return
+	}
+
+	ok, err = db.FinishMergeJob(jobID, m.workerID, map[string]interface{}{
+		"status": StatusCompleted,
+		"phase":  PhaseDone,
+		"url":    url,
+		"error":  "",
+	})
+	if err != nil || !ok {
+		log.Printf("[Merge] 租约已丢失，放弃记录结果: %s, error: %v", jobID, err)
+		return
+	}
+	log.Printf("✅ 合并完成: %s -> %s", jobID, url)
+	startProcessing(job)
+}
+
+// retryLater 释放租约并在 lockWait 后重新入队，重新领取时计入尝试次数；
+// 租约已丢失时由新的持有者负责，不再入队
+func (m *Manager) retryLater(ctx context.Context, cancel context.CancelFunc, jobID, reason string) {
+	lost := ctx.Err() != nil
+	cancel()
+	if lost {
+		return
+	}
+	db.ReleaseMergeJob(jobID, m.workerID, reason)
+	time.AfterFunc(lockWait, func() { m.enqueue(jobID) })
+}
+
+// heartbeat 定期续期租约；租约被其他实例接手时终止执行
+func (m *Manager) heartbeat(ctx context.Context, cancel context.CancelFunc, jobID string) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ok, err := db.HeartbeatMergeJob(jobID, m.workerID, leaseTTL)
+			if err != nil {
+				// 数据库暂时不可用时继续执行，租约过期前还有机会续期
+				log.Printf("⚠️ 合并任务心跳失败: %s, error: %v", jobID, err)
+				continue
+			}
+			if !ok {
+				log.Printf("[Merge] 合并任务租约已丢失: %s", jobID)
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// startProcessing 按用户或平台的处理策略启动后续处理流程；没有生效的策略时只生成缩略图
+func startProcessing(job *db.MergeJob) {
+	p, err := pipeline.Start(job.FileHash, job.UserID, job.JobID)
+	if err != nil {
+		log.Printf("[Merge] 创建处理流程失败: %s, error: %v", job.JobID, err)
+	}
+	if p == nil {
+		transcode.GenerateThumbnailsAsync(job.FileHash) // 已生成过缩略图的物理文件会直接跳过
+	}
+}
+
+// run 校验 -> 合并 -> 提取分辨率 -> 写数据库
+// 每一步都可以安全重做：分片在结果持久化后才删除，因此任务中断后可以从头重新执行
+func (m *Manager) run(ctx context.Context, job *db.MergeJob) (string, []int32, error) {
+	statusKey := fmt.Sprintf("upload:%s:%s", job.UserID, job.FileHash)
+	chunkPrefix := fmt.Sprintf("%s_%s", job.UserID, job.FileHash)
+
+	// 0. 上次执行已经写入数据库（在标记完成前中断）
+	existing, err := db.GetFileByHashAndUser(job.FileHash, job.UserID)
+	if err != nil {
+		return "", nil, fmt.Errorf("数据库查询失败: %w", err)
+	}
+	if existing != nil && existing.Status == "finished" {
+		m.cleanup(ctx, job, statusKey, chunkPrefix, existing.URL)
+		return existing.URL, nil, nil
+	}
+
+	// 1. 合并分片到内容寻址的物理文件；相同内容已存在时直接复用
+	blob, err := db.GetBlob(job.FileHash)
+	if err != nil {
+		return "", nil, fmt.Errorf("查询物理文件失败: %w", err)
+	}
+
+	// 即使物理文件已存在也要校验上传的分片：引用已有内容的前提是用户确实上传了这些内容
+	totalBytes, err := storage.GetChunksSize(chunkPrefix, int(job.TotalChunks))
+	if err != nil {
+		return "", nil, err
+	}
+	db.UpdateMergeJob(job.JobID, map[string]interface{}{
+		"phase":        PhaseVerifying,
+		"bytes_merged": 0,
+		"total_bytes":  totalBytes,
+	})
+	if badChunks, err := verifyChunks(ctx, statusKey, chunkPrefix, job); err != nil {
+		return "", badChunks, err
+	}
+
+	storageKey := storage.GetBlobKey(job.FileHash, job.Filename)
+	if blob != nil {
+		log.Printf("[Merge] 物理文件已存在，跳过合并: %s", blob.StorageKey)
+		if storageKey, err = storage.Resolve(blob.StorageKey, blob.StorageBackend); err != nil {
+			return "", nil, err
+		}
+	} else {
+		db.UpdateMergeJobProgress(job.JobID, PhaseMerging, 0)
+		var mu sync.Mutex
+		lastUpdate := time.Now()
+		progress := func(merged int64) {
+			mu.Lock()
+			defer mu.Unlock()
+			if time.Since(lastUpdate) >= progressInterval || merged == totalBytes {
+				lastUpdate = time.Now()
+				db.UpdateMergeJobProgress(job.JobID, PhaseMerging, merged)
+			}
+		}
+		if err := storage.MergeChunks(ctx, chunkPrefix, storageKey, int(job.TotalChunks), progress); err != nil {
+			return "", nil, fmt.Errorf("合并失败: %w", err)
+		}
+		db.UpdateMergeJobProgress(job.JobID, PhaseMerging, totalBytes)
+	}
+
+	// 2. 探测媒体信息（分辨率、时长、编码等）
+	db.UpdateMergeJob(job.JobID, map[string]interface{}{"phase": PhaseProbing})
+	var media *transcode.MediaInfo
+	filePath, cleanup, err := storage.FetchLocal(ctx, storageKey)
+	if err == nil {
+		media, err = transcode.Probe(filePath)
+		cleanup()
+	}
+	width, height := job.Width, job.Height
+	var metadata db.MediaMetadata
+	if err != nil {
+		// 探测失败时使用客户端传入的分辨率，媒体信息留空，转码时再补探测
+		log.Printf("[Merge] 探测媒体信息失败: %v", err)
+	} else {
+		width, height = media.Width, media.Height
+		metadata = media.ToDB()
+	}
+
+	// 3. 更新数据库（文件记录与物理文件引用计数在同一事务中）
+	// 锁已丢失时不再写入，其他实例可能已经接手这个上传会话
+	if err := ctx.Err(); err != nil {
+		return "", nil, fmt.Errorf("上传锁已丢失: %w", err)
+	}
+	db.UpdateMergeJob(job.JobID, map[string]interface{}{"phase": PhaseSaving})
+	fileURL := storage.GetFileURL(job.FileHash, job.Filename)
+	fileSize, _ := storage.GetFileSize(storageKey)
+	if err := db.CreateFileWithMetadata(job.FileHash, job.UserID, job.Filename, fileSize, fileURL, width, height, metadata, job.RequestID, storageKey, storage.BackendName()); err != nil {
+		return "", nil, fmt.Errorf("数据库创建失败: %w", err)
+	}
+
+	m.cleanup(ctx, job, statusKey, chunkPrefix, fileURL)
+	log.Printf("[Merge] 文件合并成功: %s -> %s (分辨率: %dx%d)", job.FileHash, fileURL, width, height)
+	return fileURL, nil, nil
+}
+
+// cleanup 设置墓碑并清理上传缓存与分片
+func (m *Manager) cleanup(ctx context.Context, job *db.MergeJob, statusKey, chunkPrefix, fileURL string) {
+	// 设置墓碑（永久缓存）
+	cacheKey := fmt.Sprintf("tombstone:%s:%s", job.UserID, job.FileHash)
+	if err := redis.SetTombstone(ctx, cacheKey, fileURL); err != nil {
+		log.Printf("[Merge] 设置墓碑失败: %v", err)
+	}
+
+	// 清理上传缓存
+	if err := redis.DeleteUploadCache(ctx, statusKey); err != nil {
+		log.Printf("[Merge] 清理缓存失败: %v", err)
+	}
+
+	storage.DeleteChunks(chunkPrefix, int(job.TotalChunks))
+}
+
+// verifyChunks 合并前流式校验整个文件的哈希，防止损坏或恶意分片以错误的哈希"完成"上传而污染秒传
+// 校验失败时删除摘要不匹配的分片并从已完成集合中移除，客户端重新上传这些分片即可
+func verifyChunks(ctx context.Context, statusKey, chunkPrefix string, job *db.MergeJob) ([]int32, error) {
+	chunkDigests, err := redis.GetChunkDigests(ctx, statusKey)
+	if err != nil {
+		log.Printf("[Merge] 获取分片摘要失败: %v", err)
+	}
+
+	badChunks, err := integrity.VerifyChunks(ctx, chunkPrefix, int(job.TotalChunks), job.FileHash, chunkDigests)
+	if err == nil {
+		return nil, nil
+	}
+	if err != integrity.ErrHashMismatch {
+		return nil, fmt.Errorf("完整性校验失败: %v", err)
+	}
+
+	log.Printf("[Merge] 文件哈希不匹配: %s, 损坏分片: %v", job.FileHash, badChunks)
+	if len(badChunks) == 0 {
+		return nil, fmt.Errorf("文件哈希不匹配，且缺少分片摘要，无法定位损坏的分片")
+	}
+
+	indices := make([]string, len(badChunks))
+	for i, index := range badChunks {
+		indices[i] = strconv.Itoa(int(index))
+		storage.DeleteObject(storage.GetChunkKey(chunkPrefix, indices[i]))
+	}
+	if err := redis.RemoveFinishedChunks(ctx, statusKey, indices...); err != nil {
+		log.Printf("[Merge] 移除损坏分片记录失败: %v", err)
+	}
+	return badChunks, fmt.Errorf("文件哈希不匹配，%d 个分片校验失败，请重新上传", len(badChunks))
+}
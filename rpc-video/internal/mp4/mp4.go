@@ -0,0 +1,392 @@
+// Package mp4 解析 ISO-BMFF（MP4/MOV）文件的 moov 元数据，不依赖 ffprobe。
+// 只读取 ftyp、moov 及其中的 mvhd/trak/tkhd/mdhd/hdlr/stsd/stts，mdat 只记录位置和大小
+package mp4
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrNotMP4 文件不是 ISO-BMFF 格式
+var ErrNotMP4 = errors.New("不是 MP4/MOV 文件")
+
+const (
+	maxDepth       = 16               // box 嵌套深度上限，防止构造的文件无限递归
+	maxLeafBoxSize = 16 * 1024 * 1024 // 单个叶子 box 的读取上限（stts 等表）
+)
+
+// 轨道类型（hdlr handler_type）
+const (
+	TrackVideo = "vide"
+	TrackAudio = "soun"
+)
+
+// Track 轨道信息
+type Track struct {
+	ID        uint32
+	Type      string // vide, soun, 其他 handler 原样保留（如 text、meta）
+	FourCC    string // 第一个样本描述的编码，例如 avc1、hvc1、mp4a
+	Timescale uint32
+	Duration  time.Duration
+	Samples   uint64 // 样本（帧）总数，来自 stts
+
+	// 视频
+	Width     uint32 // 样本描述中的编码尺寸
+	Height    uint32
+	Rotation  int     // 由 tkhd 矩阵得出的顺时针旋转角度：0, 90, 180, 270
+	Transfer  uint16  // colr nclx 的 transfer_characteristics，0 表示未声明
+	FrameRate float64 // Samples / Duration
+
+	// 音频
+	Channels   uint16
+	SampleRate uint32
+}
+
+// Info 文件信息
+type Info struct {
+	MajorBrand string
+	Duration   time.Duration // mvhd 中的时长
+	Tracks     []Track
+	MdatSize   int64 // 所有 mdat 的总大小，可用于估算码率
+	Faststart  bool  // moov 位于 mdat 之前，可以边下载边播放
+}
+
+// Video 第一个视频轨道
+func (i *Info) Video() *Track {
+	return i.track(TrackVideo)
+}
+
+// Audio 第一个音频轨道
+func (i *Info) Audio() *Track {
+	return i.track(TrackAudio)
+}
+
+func (i *Info) track(kind string) *Track {
+	for n := range i.Tracks {
+		if i.Tracks[n].Type == kind {
+			return &i.Tracks[n]
+		}
+	}
+	return nil
+}
+
+// ParseFile 解析本地文件
+func ParseFile(path string) (*Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return Parse(f, stat.Size())
+}
+
+// Parse 解析 ISO-BMFF 文件
+func Parse(r io.ReaderAt, size int64) (*Info, error) {
+	info := &Info{}
+	var moovOffset, mdatOffset int64 = -1, -1
+	var sawFtyp bool
+
+	err := walk(r, 0, size, 0, func(b box) error {
+		switch b.typ {
+		case "ftyp":
+			sawFtyp = true
+			data, err := b.read(r, 8)
+			if err != nil {
+				return err
+			}
+			if len(data) >= 4 {
+				info.MajorBrand = string(data[:4])
+			}
+		case "moov":
+			if moovOffset < 0 {
+				moovOffset = b.offset
+			}
+			return parseMoov(r, b, info)
+		case "mdat":
+			if mdatOffset < 0 {
+				mdatOffset = b.offset
+			}
+			info.MdatSize += b.size
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// 老的 QuickTime 文件可能没有 ftyp，以 moov 为准
+	if moovOffset < 0 {
+		if !sawFtyp {
+			return nil, ErrNotMP4
+		}
+		return nil, fmt.Errorf("缺少 moov box")
+	}
+	info.Faststart = mdatOffset < 0 || moovOffset < mdatOffset
+	return info, nil
+}
+
+// topLevelBoxes 文件开头可能出现的 box，用于快速识别非 MP4 文件
+var topLevelBoxes = map[string]bool{
+	"ftyp": true, "moov": true, "mdat": true, "free": true, "skip": true, "wide": true, "pnot": true,
+}
+
+// box 一个 box 的位置；offset 为 box 起始位置，dataOffset 为跳过头部后的内容位置
+type box struct {
+	typ        string
+	offset     int64
+	dataOffset int64
+	size       int64 // 包含头部
+}
+
+func (b box) end() int64 {
+	return b.offset + b.size
+}
+
+// read 读取 box 内容，最多 limit 字节
+func (b box) read(r io.ReaderAt, limit int64) ([]byte, error) {
+	n := b.end() - b.dataOffset
+	if n > limit {
+		n = limit
+	}
+	buf := make([]byte, n)
+	if _, err := r.ReadAt(buf, b.dataOffset); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// walk 依次遍历 [start, end) 内的 box
+func walk(r io.ReaderAt, start, end int64, depth int, fn func(box) error) error {
+	if depth > maxDepth {
+		return fmt.Errorf("box 嵌套过深")
+	}
+	var header [16]byte
+	for offset := start; offset+8 <= end; {
+		if _, err := r.ReadAt(header[:8], offset); err != nil {
+			return fmt.Errorf("读取 box 头失败: %w", err)
+		}
+		b := box{
+			typ:        string(header[4:8]),
+			offset:     offset,
+			dataOffset: offset + 8,
+			size:       int64(binary.BigEndian.Uint32(header[:4])),
+		}
+		switch b.size {
+		case 0: // 延伸到父 box（或文件）末尾
+			b.size = end - offset
+		case 1: // 64 位长度
+			if _, err := r.ReadAt(header[8:16], offset+8); err != nil {
+				return fmt.Errorf("读取 box 头失败: %w", err)
+			}
+			b.size = int64(binary.BigEndian.Uint64(header[8:16]))
+			b.dataOffset += 8
+		}
+		if depth == 0 && offset == 0 && !topLevelBoxes[b.typ] {
+			return ErrNotMP4
+		}
+		if b.size < b.dataOffset-offset || b.end() > end {
+			return fmt.Errorf("box %q 长度无效: %d", b.typ, b.size)
+		}
+		if err := fn(b); err != nil {
+			return err
+		}
+		offset = b.end()
+	}
+	return nil
+}
+
+// walkChildren 遍历容器 box 的子 box，skip 为子 box 之前的固定字段长度，depth 为父 box 的深度
+func walkChildren(r io.ReaderAt, parent box, skip int64, depth int, fn func(box) error) error {
+	return walk(r, parent.dataOffset+skip, parent.end(), depth+1, fn)
+}
+
+func parseMoov(r io.ReaderAt, moov box, info *Info) error {
+	return walkChildren(r, moov, 0, 0, func(b box) error {
+		switch b.typ {
+		case "mvhd":
+			data, err := b.read(r, 32)
+			if err != nil {
+				return err
+			}
+			timescale, duration, ok := parseTimes(data)
+			if ok {
+				info.Duration = scaleDuration(duration, timescale)
+			}
+		case "trak":
+			track := Track{}
+			if err := parseTrak(r, b, 1, &track); err != nil {
+				return err
+			}
+			if track.Duration > 0 && track.Samples > 0 {
+				track.FrameRate = math.Round(float64(track.Samples)/track.Duration.Seconds()*1000) / 1000
+			}
+			info.Tracks = append(info.Tracks, track)
+		}
+		return nil
+	})
+}
+
+// parseTrak 解析 trak 及其中的 mdia/minf/stbl 容器
+func parseTrak(r io.ReaderAt, parent box, depth int, track *Track) error {
+	return walkChildren(r, parent, 0, depth, func(b box) error {
+		switch b.typ {
+		case "tkhd":
+			data, err := b.read(r, 92)
+			if err != nil {
+				return err
+			}
+			parseTkhd(data, track)
+		case "mdia", "minf", "stbl":
+			return parseTrak(r, b, depth+1, track)
+		case "mdhd":
+			data, err := b.read(r, 32)
+			if err != nil {
+				return err
+			}
+			if timescale, duration, ok := parseTimes(data); ok {
+				track.Timescale = timescale
+				track.Duration = scaleDuration(duration, timescale)
+			}
+		case "hdlr":
+			data, err := b.read(r, 12)
+			if err != nil {
+				return err
+			}
+			// QuickTime 的 minf 中还有数据引用的 hdlr（dhlr/alis），只取 mdia 中的第一个
+			if len(data) >= 12 && track.Type == "" {
+				track.Type = string(data[8:12])
+			}
+		case "stsd":
+			return parseStsd(r, b, depth+1, track)
+		case "stts":
+			data, err := b.read(r, maxLeafBoxSize)
+			if err != nil {
+				return err
+			}
+			track.Samples = parseStts(data)
+		}
+		return nil
+	})
+}
+
+// parseTimes 解析 mvhd/mdhd 的 timescale 与 duration（version 0 为 32 位，version 1 为 64 位）
+func parseTimes(data []byte) (timescale uint32, duration uint64, ok bool) {
+	if len(data) < 4 {
+		return 0, 0, false
+	}
+	if data[0] == 1 {
+		if len(data) < 32 {
+			return 0, 0, false
+		}
+		return binary.BigEndian.Uint32(data[20:24]), binary.BigEndian.Uint64(data[24:32]), true
+	}
+	if len(data) < 20 {
+		return 0, 0, false
+	}
+	return binary.BigEndian.Uint32(data[12:16]), uint64(binary.BigEndian.Uint32(data[16:20])), true
+}
+
+func scaleDuration(duration uint64, timescale uint32) time.Duration {
+	// 全 1 表示时长未知
+	if timescale == 0 || duration == math.MaxUint32 || duration == math.MaxUint64 {
+		return 0
+	}
+	return time.Duration(float64(duration) / float64(timescale) * float64(time.Second))
+}
+
+// parseTkhd 从 tkhd 中读取轨道 ID 与变换矩阵
+func parseTkhd(data []byte, track *Track) {
+	// version 0: creation(4) modification(4) track_id(4) reserved(4) duration(4)
+	// version 1: creation(8) modification(8) track_id(4) reserved(4) duration(8)
+	idOffset, matrixOffset := 12, 40
+	if len(data) > 0 && data[0] == 1 {
+		idOffset, matrixOffset = 20, 52
+	}
+	if len(data) >= idOffset+4 {
+		track.ID = binary.BigEndian.Uint32(data[idOffset:])
+	}
+	// 之后是 reserved(8) layer(2) alternate_group(2) volume(2) reserved(2)，再是 3x3 矩阵
+	if len(data) >= matrixOffset+36 {
+		a := int32(binary.BigEndian.Uint32(data[matrixOffset:]))
+		b := int32(binary.BigEndian.Uint32(data[matrixOffset+4:]))
+		degrees := int(math.Round(math.Atan2(float64(b), float64(a)) * 180 / math.Pi))
+		track.Rotation = ((degrees % 360) + 360) % 360 / 90 * 90
+	}
+}
+
+// parseStsd 读取第一个样本描述：编码 FourCC，视频的尺寸与色彩信息，音频的声道与采样率
+func parseStsd(r io.ReaderAt, stsd box, depth int, track *Track) error {
+	// version/flags(4) entry_count(4)
+	return walkChildren(r, stsd, 8, depth, func(entry box) error {
+		if track.FourCC != "" {
+			return nil
+		}
+		track.FourCC = strings.TrimRight(entry.typ, "\x00")
+		switch track.Type {
+		case TrackVideo:
+			// VisualSampleEntry: reserved(6) data_reference_index(2) pre_defined/reserved(16) width(2) height(2) ...，子 box 从第 78 字节开始
+			data, err := entry.read(r, 28)
+			if err != nil {
+				return err
+			}
+			if len(data) >= 28 {
+				track.Width = uint32(binary.BigEndian.Uint16(data[24:26]))
+				track.Height = uint32(binary.BigEndian.Uint16(data[26:28]))
+			}
+			return walkChildren(r, entry, 78, depth+1, func(b box) error {
+				if b.typ != "colr" {
+					return nil
+				}
+				data, err := b.read(r, 10)
+				if err != nil {
+					return err
+				}
+				// colour_type(4) colour_primaries(2) transfer_characteristics(2) matrix_coefficients(2)
+				if len(data) >= 8 && string(data[:4]) == "nclx" {
+					track.Transfer = binary.BigEndian.Uint16(data[6:8])
+				}
+				return nil
+			})
+		case TrackAudio:
+			// AudioSampleEntry: reserved(6) data_reference_index(2) version(2) reserved(6) channelcount(2) samplesize(2) pre_defined(2) reserved(2) samplerate(4, 16.16)
+			data, err := entry.read(r, 44)
+			if err != nil {
+				return err
+			}
+			if len(data) < 28 {
+				return nil
+			}
+			if binary.BigEndian.Uint16(data[8:10]) == 2 && len(data) >= 44 {
+				// QuickTime 音频描述第 2 版：采样率为 float64，声道数为 uint32
+				track.SampleRate = uint32(math.Float64frombits(binary.BigEndian.Uint64(data[32:40])))
+				track.Channels = uint16(binary.BigEndian.Uint32(data[40:44]))
+				return nil
+			}
+			track.Channels = binary.BigEndian.Uint16(data[16:18])
+			track.SampleRate = binary.BigEndian.Uint32(data[24:28]) >> 16
+		}
+		return nil
+	})
+}
+
+// parseStts 累加 stts 中的样本数
+func parseStts(data []byte) uint64 {
+	if len(data) < 8 {
+		return 0
+	}
+	count := binary.BigEndian.Uint32(data[4:8])
+	var samples uint64
+	for i, off := uint32(0), 8; i < count && off+8 <= len(data); i, off = i+1, off+8 {
+		samples += uint64(binary.BigEndian.Uint32(data[off:]))
+	}
+	return samples
+}
@@ -0,0 +1,137 @@
+package mp4
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func u16(v uint16) []byte { return binary.BigEndian.AppendUint16(nil, v) }
+func u32(v uint32) []byte { return binary.BigEndian.AppendUint32(nil, v) }
+
+// mkbox 拼接一个 32 位长度的 box
+func mkbox(typ string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	return append(append(u32(uint32(8+len(body))), typ...), body...)
+}
+
+// mkbox64 拼接一个 64 位长度（size == 1 + largesize）的 box
+func mkbox64(typ string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	b := append(u32(1), typ...)
+	b = binary.BigEndian.AppendUint64(b, uint64(16+len(body)))
+	return append(b, body...)
+}
+
+// withSize 改写 box 头中的 32 位长度
+func withSize(b []byte, size uint32) []byte {
+	b = bytes.Clone(b)
+	binary.BigEndian.PutUint32(b, size)
+	return b
+}
+
+func zeros(n int) []byte { return make([]byte, n) }
+
+func ftyp() []byte {
+	return mkbox("ftyp", []byte("isom"), u32(512), []byte("isomiso2avc1mp41"))
+}
+
+// mvhd version 0：时长 duration/timescale 秒
+func mvhd(timescale, duration uint32) []byte {
+	return mkbox("mvhd", zeros(12), u32(timescale), u32(duration), zeros(80))
+}
+
+// videoTrak 一个 avc1 视频轨道，samples 帧均匀分布在 duration/timescale 秒内
+func videoTrak(width, height uint16, timescale, duration, samples uint32) []byte {
+	identity := bytes.Join([][]byte{u32(0x10000), u32(0), u32(0), u32(0), u32(0x10000), u32(0), u32(0), u32(0), u32(0x40000000)}, nil)
+	tkhd := mkbox("tkhd", zeros(12), u32(1), zeros(4), u32(duration), zeros(16), identity, u32(uint32(width)<<16), u32(uint32(height)<<16))
+	mdhd := mkbox("mdhd", zeros(12), u32(timescale), u32(duration), zeros(4))
+	hdlr := mkbox("hdlr", zeros(8), []byte(TrackVideo), zeros(12), []byte("VideoHandler\x00"))
+	avc1 := mkbox("avc1", zeros(24), u16(width), u16(height), zeros(50))
+	stsd := mkbox("stsd", zeros(4), u32(1), avc1)
+	stts := mkbox("stts", zeros(4), u32(1), u32(samples), u32(duration/samples))
+	return mkbox("trak", tkhd, mkbox("mdia", mdhd, hdlr, mkbox("minf", mkbox("stbl", stsd, stts))))
+}
+
+func moov() []byte {
+	return mkbox("moov", mvhd(1000, 10000), videoTrak(1920, 1080, 30000, 300000, 300))
+}
+
+func file(boxes ...[]byte) []byte {
+	return bytes.Join(boxes, nil)
+}
+
+func TestParse(t *testing.T) {
+	mdat := mkbox("mdat", zeros(1000))
+	mdat64 := mkbox64("mdat", zeros(1000))
+
+	tests := []struct {
+		name      string
+		data      []byte
+		faststart bool
+		mdatSize  int64
+		err       string // 期望错误包含的内容，为空时期望成功
+		notMP4    bool
+	}{
+		{name: "moov before mdat", data: file(ftyp(), moov(), mdat), faststart: true, mdatSize: 1008},
+		{name: "moov after mdat", data: file(ftyp(), mdat, moov()), faststart: false, mdatSize: 1008},
+		{name: "no mdat", data: file(ftyp(), moov()), faststart: true},
+		{name: "free box between", data: file(ftyp(), mkbox("free", zeros(16)), moov(), mdat), faststart: true, mdatSize: 1008},
+		{name: "64-bit mdat before moov", data: file(ftyp(), mdat64, moov()), faststart: false, mdatSize: 1016},
+		{name: "64-bit mdat after moov", data: file(ftyp(), moov(), mdat64), faststart: true, mdatSize: 1016},
+		{name: "64-bit moov", data: file(ftyp(), mkbox64("moov", mvhd(1000, 10000), videoTrak(1920, 1080, 30000, 300000, 300)), mdat), faststart: true, mdatSize: 1008},
+		{name: "size 0 extends to end of file", data: file(ftyp(), moov(), withSize(mdat, 0)), faststart: true, mdatSize: 1008},
+		{name: "quicktime without ftyp", data: file(moov(), mdat), faststart: true, mdatSize: 1008},
+
+		{name: "truncated moov", data: file(ftyp(), mdat, moov())[:len(ftyp())+len(mdat)+100], err: "长度无效"},
+		{name: "truncated mdat", data: file(ftyp(), moov(), mdat)[:len(ftyp())+len(moov())+500], err: "长度无效"},
+		{name: "truncated 64-bit header", data: file(ftyp(), moov(), mdat64[:12]), err: "读取 box 头失败"},
+		{name: "size smaller than header", data: file(ftyp(), withSize(moov(), 4), mdat), err: "长度无效"},
+		{name: "size beyond end of file", data: file(ftyp(), moov(), withSize(mdat, 1<<30)), err: "长度无效"},
+		{name: "64-bit size smaller than header", data: file(ftyp(), moov(), append(append(u32(1), "mdat"...), binary.BigEndian.AppendUint64(nil, 12)...)), err: "长度无效"},
+		{name: "64-bit size overflows", data: file(ftyp(), moov(), append(append(u32(1), "mdat"...), binary.BigEndian.AppendUint64(nil, 1<<63)...)), err: "长度无效"},
+		{name: "child larger than moov", data: file(ftyp(), mkbox("moov", withSize(mvhd(1000, 10000), 200)), mdat), err: "长度无效"},
+		{name: "missing moov", data: file(ftyp(), mdat), err: "缺少 moov"},
+		{name: "not mp4", data: []byte("RIFF\x00\x00\x00\x00AVI LIST"), notMP4: true},
+		{name: "too short", data: []byte("ftyp"), notMP4: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := Parse(bytes.NewReader(tt.data), int64(len(tt.data)))
+			switch {
+			case tt.notMP4:
+				if !errors.Is(err, ErrNotMP4) {
+					t.Fatalf("Parse error = %v, want ErrNotMP4", err)
+				}
+				return
+			case tt.err != "":
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Parse error = %v, want %q", err, tt.err)
+				}
+				return
+			case err != nil:
+				t.Fatalf("Parse: %v", err)
+			}
+
+			if info.Faststart != tt.faststart {
+				t.Errorf("Faststart = %v, want %v", info.Faststart, tt.faststart)
+			}
+			if info.MdatSize != tt.mdatSize {
+				t.Errorf("MdatSize = %d, want %d", info.MdatSize, tt.mdatSize)
+			}
+			if info.Duration != 10*time.Second {
+				t.Errorf("Duration = %v, want 10s", info.Duration)
+			}
+			v := info.Video()
+			if v == nil {
+				t.Fatal("no video track")
+			}
+			if v.FourCC != "avc1" || v.Width != 1920 || v.Height != 1080 || v.Samples != 300 || v.FrameRate != 30 || v.Rotation != 0 {
+				t.Errorf("video track = %+v", *v)
+			}
+		})
+	}
+}
@@ -0,0 +1,366 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"video-platform-microservice/rpc-video/internal/db"
+	"video-platform-microservice/rpc-video/internal/transcode"
+)
+
+// 流程状态
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// 步骤状态
+const (
+	StepPending   = "pending"
+	StepRunning   = "running"
+	StepCompleted = "completed"
+	StepFailed    = "failed"
+)
+
+// 步骤名称，按此顺序执行
+const (
+	StepProbe      = "probe"      // 探测媒体信息（合并时已探测的直接复用）
+	StepValidate   = "validate"   // 按策略校验时长、分辨率与视频流
+	StepThumbnails = "thumbnails" // 封面、雪碧图与 WebVTT 缩略图
+	StepTranscode  = "transcode"  // 按策略的编码配置转码
+	StepPackage    = "package"    // 打包为 HLS（及 DASH）
+)
+
+var stepOrder = []string{StepProbe, StepValidate, StepThumbnails, StepTranscode, StepPackage}
+
+// ErrNotRetryable 流程或步骤当前的状态不能重试
+var ErrNotRetryable = errors.New("只有失败的步骤可以重试")
+
+// Policy 处理策略
+type Policy struct {
+	Steps              []string `json:"steps"`
+	Profiles           []string `json:"profiles"`
+	PerTitle           bool     `json:"per_title"`
+	QualityMetrics     bool     `json:"quality_metrics"`
+	RequireVideo       bool     `json:"require_video"`
+	MaxDurationSeconds int32    `json:"max_duration_seconds"`
+	MinShortSide       int32    `json:"min_short_side"`
+	MaxShortSide       int32    `json:"max_short_side"`
+}
+
+// has 策略是否启用了 step
+func (p *Policy) has(step string) bool {
+	for _, s := range p.Steps {
+		if s == step {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate 校验策略：步骤名称有效，依赖的步骤已启用，编码配置存在。步骤按固定顺序重排
+func (p *Policy) Validate() error {
+	seen := map[string]bool{}
+	for _, s := range p.Steps {
+		known := false
+		for _, name := range stepOrder {
+			known = known || s == name
+		}
+		if !known {
+			return fmt.Errorf("未知的步骤: %s", s)
+		}
+		seen[s] = true
+	}
+	if len(seen) == 0 {
+		return fmt.Errorf("steps 不能为空")
+	}
+	ordered := make([]string, 0, len(seen))
+	for _, name := range stepOrder {
+		if seen[name] {
+			ordered = append(ordered, name)
+		}
+	}
+	p.Steps = ordered
+
+	if p.has(StepValidate) && !p.has(StepProbe) {
+		return fmt.Errorf("validate 步骤需要同时启用 probe")
+	}
+	if p.has(StepPackage) && !p.has(StepTranscode) {
+		return fmt.Errorf("package 步骤需要同时启用 transcode")
+	}
+	if p.has(StepTranscode) {
+		if len(p.Profiles) == 0 {
+			return fmt.Errorf("transcode 步骤需要指定 profiles")
+		}
+		if _, err := transcode.ResolveProfiles(p.Profiles); err != nil {
+			return err
+		}
+	}
+	if p.MaxDurationSeconds < 0 || p.MinShortSide < 0 || p.MaxShortSide < 0 {
+		return fmt.Errorf("校验限制不能为负数")
+	}
+	if p.MaxShortSide > 0 && p.MinShortSide > p.MaxShortSide {
+		return fmt.Errorf("min_short_side 不能大于 max_short_side")
+	}
+	return nil
+}
+
+// ToDB 转换为数据库记录
+func (p *Policy) ToDB(userID string, enabled bool) *db.ProcessingPolicy {
+	steps, _ := json.Marshal(p.Steps)
+	profiles, _ := json.Marshal(p.Profiles)
+	return &db.ProcessingPolicy{
+		UserID:             userID,
+		Enabled:            enabled,
+		Steps:              string(steps),
+		Profiles:           string(profiles),
+		PerTitle:           p.PerTitle,
+		QualityMetrics:     p.QualityMetrics,
+		RequireVideo:       p.RequireVideo,
+		MaxDurationSeconds: p.MaxDurationSeconds,
+		MinShortSide:       p.MinShortSide,
+		MaxShortSide:       p.MaxShortSide,
+	}
+}
+
+// PolicyFromDB 读取数据库中的策略
+func PolicyFromDB(r *db.ProcessingPolicy) *Policy {
+	p := &Policy{
+		PerTitle:           r.PerTitle,
+		QualityMetrics:     r.QualityMetrics,
+		RequireVideo:       r.RequireVideo,
+		MaxDurationSeconds: r.MaxDurationSeconds,
+		MinShortSide:       r.MinShortSide,
+		MaxShortSide:       r.MaxShortSide,
+	}
+	json.Unmarshal([]byte(r.Steps), &p.Steps)
+	json.Unmarshal([]byte(r.Profiles), &p.Profiles)
+	return p
+}
+
+// ResolvePolicy 返回对用户生效的策略：用户自己的策略优先，没有时使用平台默认策略。
+// 生效的策略已停用或都不存在时返回 nil
+func ResolvePolicy(userID string) (*Policy, error) {
+	record, err := db.GetProcessingPolicy(userID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil && userID != "" {
+		if record, err = db.GetProcessingPolicy(""); err != nil {
+			return nil, err
+		}
+	}
+	if record == nil || !record.Enabled {
+		return nil, nil
+	}
+	return PolicyFromDB(record), nil
+}
+
+// StepStatus 单个步骤的执行记录
+type StepStatus struct {
+	Name       string     `json:"name"`
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	Attempts   int32      `json:"attempts"`
+	Output     string     `json:"output,omitempty"` // 步骤产出：媒体信息摘要、转码任务ID、播放地址等
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Steps 解析流程中各步骤的状态
+func Steps(p *db.Pipeline) []StepStatus {
+	var steps []StepStatus
+	if p.Steps != "" {
+		json.Unmarshal([]byte(p.Steps), &steps)
+	}
+	return steps
+}
+
+// Manager 流程管理器。
+// 流程持久化在 pipelines 表中，服务重启后从数据库恢复；每个流程在自己的协程中执行，
+// 耗时的步骤由缩略图和转码各自的并发限制约束
+type Manager struct {
+	mu      sync.Mutex
+	running map[string]bool
+}
+
+var manager *Manager
+
+// InitPipelineManager 初始化流程管理器，并恢复重启前未完成的流程
+func InitPipelineManager() error {
+	manager = &Manager{running: map[string]bool{}}
+
+	pipelines, err := db.GetUnfinishedPipelines()
+	if err != nil {
+		return fmt.Errorf("failed to load unfinished pipelines: %w", err)
+	}
+	for _, p := range pipelines {
+		db.UpdatePipeline(p.PipelineID, map[string]interface{}{"status": StatusQueued})
+		manager.enqueue(p.PipelineID)
+	}
+
+	log.Printf("✅ 流程管理器已启动 (恢复流程: %d)", len(pipelines))
+	return nil
+}
+
+// Start 按用户生效的处理策略为合并完成的文件创建流程并开始执行。
+// 没有生效的策略时返回 nil；同一个合并任务只创建一次流程
+func Start(fileHash, userID, mergeJobID string) (*db.Pipeline, error) {
+	if existing, err := db.GetPipelineByMergeJob(mergeJobID); err != nil || existing != nil {
+		return existing, err
+	}
+	policy, err := ResolvePolicy(userID)
+	if err != nil || policy == nil {
+		return nil, err
+	}
+
+	steps := make([]StepStatus, 0, len(policy.Steps))
+	for _, name := range policy.Steps {
+		steps = append(steps, StepStatus{Name: name, Status: StepPending})
+	}
+	policyJSON, _ := json.Marshal(policy)
+	stepsJSON, _ := json.Marshal(steps)
+	p := &db.Pipeline{
+		PipelineID: uuid.New().String(),
+		FileHash:   fileHash,
+		UserID:     userID,
+		MergeJobID: mergeJobID,
+		Status:     StatusQueued,
+		Policy:     string(policyJSON),
+		Steps:      string(stepsJSON),
+	}
+	if err := db.CreatePipeline(p); err != nil {
+		return nil, err
+	}
+
+	manager.enqueue(p.PipelineID)
+	log.Printf("处理流程已创建: %s (file: %s, user: %s, steps: %v)", p.PipelineID, fileHash, userID, policy.Steps)
+	return p, nil
+}
+
+// Retry 重新执行失败的步骤，之前已完成的步骤不会重做。step 为空时重试失败的那一步
+func Retry(pipelineID, step string) error {
+	p, err := db.GetPipeline(pipelineID)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return fmt.Errorf("流程不存在: %s", pipelineID)
+	}
+	if p.Status != StatusFailed {
+		return ErrNotRetryable
+	}
+
+	steps := Steps(p)
+	found := false
+	for i := range steps {
+		if steps[i].Status != StepFailed || (step != "" && steps[i].Name != step) {
+			continue
+		}
+		steps[i].Status = StepPending
+		steps[i].Error = ""
+		found = true
+	}
+	if !found {
+		return ErrNotRetryable
+	}
+
+	stepsJSON, _ := json.Marshal(steps)
+	ok, err := db.TransitionPipeline(pipelineID, []string{StatusFailed}, map[string]interface{}{
+		"status": StatusQueued,
+		"steps":  string(stepsJSON),
+		"error":  "",
+	})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotRetryable
+	}
+
+	manager.enqueue(pipelineID)
+	log.Printf("处理流程已重新入队: %s", pipelineID)
+	return nil
+}
+
+// enqueue 在新协程中执行流程；同一流程在本实例中只执行一份
+func (m *Manager) enqueue(pipelineID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.running[pipelineID] {
+		return
+	}
+	m.running[pipelineID] = true
+	go func() {
+		defer func() {
+			m.mu.Lock()
+			delete(m.running, pipelineID)
+			m.mu.Unlock()
+		}()
+		m.process(pipelineID)
+	}()
+}
+
+// process 按顺序执行未完成的步骤，任一步骤失败时流程停止在该步骤
+func (m *Manager) process(pipelineID string) {
+	ok, err := db.TransitionPipeline(pipelineID, []string{StatusQueued}, map[string]interface{}{"status": StatusRunning})
+	if err != nil || !ok {
+		return
+	}
+	p, err := db.GetPipeline(pipelineID)
+	if err != nil || p == nil {
+		log.Printf("❌ 处理流程不存在: %s, error: %v", pipelineID, err)
+		return
+	}
+	var policy Policy
+	json.Unmarshal([]byte(p.Policy), &policy)
+	steps := Steps(p)
+	save := func() {
+		stepsJSON, _ := json.Marshal(steps)
+		db.UpdatePipeline(pipelineID, map[string]interface{}{"steps": string(stepsJSON)})
+	}
+
+	ctx := context.Background()
+	for i := range steps {
+		step := &steps[i]
+		if step.Status == StepCompleted {
+			continue
+		}
+
+		now := time.Now()
+		step.Status = StepRunning
+		step.Attempts++
+		step.StartedAt, step.FinishedAt = &now, nil
+		save()
+		log.Printf("处理流程 %s: %s (第 %d 次)", pipelineID, step.Name, step.Attempts)
+
+		err := runStep(ctx, p, &policy, steps, step, save)
+		finished := time.Now()
+		step.FinishedAt = &finished
+		if err != nil {
+			log.Printf("❌ 处理流程 %s 在 %s 步骤失败: %v", pipelineID, step.Name, err)
+			step.Status = StepFailed
+			step.Error = err.Error()
+			stepsJSON, _ := json.Marshal(steps)
+			db.UpdatePipeline(pipelineID, map[string]interface{}{
+				"status": StatusFailed,
+				"steps":  string(stepsJSON),
+				"error":  fmt.Sprintf("%s: %v", step.Name, err),
+			})
+			return
+		}
+		step.Status = StepCompleted
+		save()
+	}
+
+	db.UpdatePipeline(pipelineID, map[string]interface{}{"status": StatusCompleted})
+	log.Printf("✅ 处理流程完成: %s", pipelineID)
+}
@@ -0,0 +1,281 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"video-platform-microservice/rpc-video/internal/db"
+	"video-platform-microservice/rpc-video/internal/storage"
+	"video-platform-microservice/rpc-video/internal/transcode"
+)
+
+// waitInterval 等待缩略图与转码任务完成时的轮询间隔
+const waitInterval = 3 * time.Second
+
+// runStep 执行单个步骤，成功时把产出写入 step.Output
+func runStep(ctx context.Context, p *db.Pipeline, policy *Policy, steps []StepStatus, step *StepStatus, save func()) error {
+	var output string
+	var err error
+	switch step.Name {
+	case StepProbe:
+		output, err = probeStep(ctx, p)
+	case StepValidate:
+		output, err = validateStep(p, policy)
+	case StepThumbnails:
+		output, err = thumbnailsStep(ctx, p)
+	case StepTranscode:
+		output, err = transcodeStep(ctx, p, policy, step, save)
+	case StepPackage:
+		output, err = packageStep(ctx, p, steps, step)
+	default:
+		err = fmt.Errorf("未知的步骤: %s", step.Name)
+	}
+	if err != nil {
+		return err
+	}
+	step.Output = output
+	return nil
+}
+
+// probeStep 确保文件记录中有媒体信息：合并时已探测的直接复用，否则重新探测并回写
+func probeStep(ctx context.Context, p *db.Pipeline) (string, error) {
+	file, err := db.GetFileByHashAndUser(p.FileHash, p.UserID)
+	if err != nil {
+		return "", err
+	}
+	if file == nil {
+		return "", fmt.Errorf("文件不存在: %s", p.FileHash)
+	}
+	if media := transcode.MediaInfoFromDB(file); media != nil {
+		return mediaSummary(media), nil
+	}
+
+	sourceKey, err := storage.Resolve(file.StorageKey, file.StorageBackend)
+	if err != nil {
+		return "", fmt.Errorf("源文件不可读: %s: %w", p.FileHash, err)
+	}
+	sourcePath, cleanup, err := storage.FetchLocal(ctx, sourceKey)
+	if err != nil {
+		return "", fmt.Errorf("源文件不存在: %s: %w", sourceKey, err)
+	}
+	defer cleanup()
+
+	media, err := transcode.Probe(sourcePath)
+	if err != nil {
+		return "", err
+	}
+	if err := db.UpdateFileMedia(p.FileHash, media.Width, media.Height, media.ToDB()); err != nil {
+		return "", err
+	}
+	return mediaSummary(media), nil
+}
+
+// mediaSummary 媒体信息摘要，例如 "mp4 1920x1080 h264/aac 12.5s"
+func mediaSummary(media *transcode.MediaInfo) string {
+	width, height := media.DisplaySize()
+	return fmt.Sprintf("%s %dx%d %s/%s %.1fs", media.Container, width, height,
+		media.VideoCodec, media.AudioCodec, media.Duration.Seconds())
+}
+
+// validateStep 按策略校验媒体信息
+func validateStep(p *db.Pipeline, policy *Policy) (string, error) {
+	file, err := db.GetFileByHashAndUser(p.FileHash, p.UserID)
+	if err != nil {
+		return "", err
+	}
+	if file == nil {
+		return "", fmt.Errorf("文件不存在: %s", p.FileHash)
+	}
+	media := transcode.MediaInfoFromDB(file)
+	if media == nil {
+		return "", fmt.Errorf("缺少媒体信息")
+	}
+
+	if policy.RequireVideo && !media.HasVideo() {
+		return "", fmt.Errorf("不包含视频流")
+	}
+	if limit := time.Duration(policy.MaxDurationSeconds) * time.Second; limit > 0 && media.Duration > limit {
+		return "", fmt.Errorf("时长 %.1fs 超过上限 %ds", media.Duration.Seconds(), policy.MaxDurationSeconds)
+	}
+	if media.HasVideo() {
+		width, height := media.DisplaySize()
+		short := width
+		if height < short {
+			short = height
+		}
+		if policy.MinShortSide > 0 && short < policy.MinShortSide {
+			return "", fmt.Errorf("分辨率 %dx%d 低于下限 %dp", width, height, policy.MinShortSide)
+		}
+		if policy.MaxShortSide > 0 && short > policy.MaxShortSide {
+			return "", fmt.Errorf("分辨率 %dx%d 超过上限 %dp", width, height, policy.MaxShortSide)
+		}
+	}
+	return "ok", nil
+}
+
+// thumbnailsStep 生成缩略图；同一物理文件已生成过的直接完成，其他协程正在生成时等待其结束
+func thumbnailsStep(ctx context.Context, p *db.Pipeline) (string, error) {
+	for {
+		if err := transcode.GenerateThumbnails(ctx, p.FileHash); err != nil {
+			return "", err
+		}
+		blob, err := db.GetBlob(p.FileHash)
+		if err != nil {
+			return "", err
+		}
+		if blob == nil {
+			return "", fmt.Errorf("源文件不存在: %s", p.FileHash)
+		}
+		switch blob.ThumbnailStatus {
+		case transcode.ThumbnailCompleted:
+			return transcode.DefaultPosterURL(p.FileHash), nil
+		case transcode.ThumbnailFailed:
+			return "", fmt.Errorf("缩略图生成失败")
+		}
+		if err := sleep(ctx, waitInterval); err != nil {
+			return "", err
+		}
+	}
+}
+
+// transcodeStep 创建转码任务并等待其结束，产出为任务ID。
+// 重试时复用之前的任务（RetryTask 不会重新转码已完成的分辨率）；
+// 所有分辨率都已完成、只有打包失败时本步骤算作完成，由 package 步骤处理
+func transcodeStep(ctx context.Context, p *db.Pipeline, policy *Policy, step *StepStatus, save func()) (string, error) {
+	taskID := step.Output
+	if taskID == "" {
+		id, err := transcode.CreateTask(p.FileHash, p.UserID, policy.Profiles, transcode.TaskOptions{
+			PerTitle:       policy.PerTitle,
+			QualityMetrics: policy.QualityMetrics,
+		})
+		if err != nil {
+			return "", err
+		}
+		// 先记录任务ID，服务重启后继续等待同一个任务而不是重复创建
+		taskID = id
+		step.Output = id
+		save()
+	} else if step.Attempts > 1 {
+		if err := retryTask(taskID); err != nil {
+			return "", err
+		}
+	}
+
+	status, err := waitTask(ctx, taskID)
+	if err != nil {
+		return "", err
+	}
+	switch status.Status {
+	case transcode.StatusCompleted:
+		return taskID, nil
+	case transcode.StatusCancelled:
+		return "", fmt.Errorf("转码任务已取消: %s", taskID)
+	}
+	if renditionsDone(status) {
+		return taskID, nil
+	}
+	return "", fmt.Errorf("转码任务 %s 失败: %s", taskID, status.Error)
+}
+
+// packageStep 确认播放清单已生成，产出为 HLS 主播放列表地址。
+// 打包在转码任务的最后执行；重试时重新执行任务，已完成的分辨率直接复用，只重新打包
+func packageStep(ctx context.Context, p *db.Pipeline, steps []StepStatus, step *StepStatus) (string, error) {
+	var taskID string
+	for _, s := range steps {
+		if s.Name == StepTranscode {
+			taskID = s.Output
+		}
+	}
+	if taskID == "" {
+		return "", fmt.Errorf("缺少转码任务")
+	}
+
+	if step.Attempts > 1 {
+		if err := retryTask(taskID); err != nil {
+			return "", err
+		}
+	}
+	status, err := waitTask(ctx, taskID)
+	if err != nil {
+		return "", err
+	}
+	if status.Status != transcode.StatusCompleted {
+		return "", fmt.Errorf("打包失败: %s", status.Error)
+	}
+
+	file, err := db.GetFileByHashAndUser(p.FileHash, p.UserID)
+	if err != nil {
+		return "", err
+	}
+	if file == nil || file.PlaybackURL == "" {
+		return "", fmt.Errorf("没有可打包为 HLS 的分辨率")
+	}
+	return file.PlaybackURL, nil
+}
+
+// retryTask 重新执行结束但未成功的转码任务；已完成或仍在执行的任务不做处理
+func retryTask(taskID string) error {
+	status, err := transcode.GetTaskStatus(taskID)
+	if err != nil {
+		return err
+	}
+	switch status.Status {
+	case transcode.StatusFailed, transcode.StatusDeadLetter, transcode.StatusCancelled:
+	default:
+		return nil
+	}
+	task, err := db.GetTranscodeTask(taskID)
+	if err != nil {
+		return err
+	}
+	if task == nil {
+		return fmt.Errorf("转码任务不存在: %s", taskID)
+	}
+	if err := transcode.RetryTask(taskID); err != nil {
+		return err
+	}
+	db.UpdateFileTranscodeStatus(task.FileHash, task.UserID, transcode.StatusPending, task.ResultURLs)
+	return nil
+}
+
+// waitTask 等待转码任务结束
+func waitTask(ctx context.Context, taskID string) (*transcode.TaskStatus, error) {
+	for {
+		status, err := transcode.GetTaskStatus(taskID)
+		if err != nil {
+			return nil, err
+		}
+		switch status.Status {
+		case transcode.StatusCompleted, transcode.StatusFailed, transcode.StatusDeadLetter, transcode.StatusCancelled:
+			return status, nil
+		}
+		if err := sleep(ctx, waitInterval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// renditionsDone 所有分辨率都已完成或跳过，且至少完成了一个
+func renditionsDone(status *transcode.TaskStatus) bool {
+	completed := 0
+	for _, r := range status.Renditions {
+		switch r.Status {
+		case transcode.RenditionCompleted:
+			completed++
+		case transcode.RenditionSkipped:
+		default:
+			return false
+		}
+	}
+	return completed > 0
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
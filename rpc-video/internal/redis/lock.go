@@ -0,0 +1,245 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	redisLib "github.com/redis/go-redis/v9"
+)
+
+const (
+	UploadLockPrefix    = "lock:upload:"  // 合并持有的排他锁
+	UploadWritersPrefix = "lock:writers:" // 分片写入持有的共享租约（ZSET：token -> 过期时间）
+	BlobLockPrefix      = "lock:blob:"    // 物理文件（file_hash）上的排他锁：删除物理文件与新增引用互斥
+	UploadLockTTL       = 30 * time.Second
+)
+
+// ErrLockHeld 锁已被其他持有者占用
+var ErrLockHeld = errors.New("lock is held by another owner")
+
+// 所有脚本都使用 Redis 服务端时间，避免多个实例之间的时钟偏差
+
+// acquireExclusiveScript 没有未过期的共享租约时才获取排他锁
+// KEYS[1]=排他锁 KEYS[2]=共享租约 ARGV[1]=token ARGV[2]=ttl(ms)
+var acquireExclusiveScript = redisLib.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+redis.call('ZREMRANGEBYSCORE', KEYS[2], '-inf', now)
+if redis.call('ZCARD', KEYS[2]) > 0 then
+	return 0
+end
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return 1
+end
+return 0
+`)
+
+// acquireSharedScript 排他锁不存在时加入共享租约
+// KEYS[1]=排他锁 KEYS[2]=共享租约 ARGV[1]=token ARGV[2]=ttl(ms)
+var acquireSharedScript = redisLib.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+redis.call('ZREMRANGEBYSCORE', KEYS[2], '-inf', now)
+redis.call('ZADD', KEYS[2], now + tonumber(ARGV[2]), ARGV[1])
+redis.call('PEXPIRE', KEYS[2], ARGV[2])
+return 1
+`)
+
+// renewExclusiveScript 仍由自己持有时续期
+var renewExclusiveScript = redisLib.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// renewSharedScript 租约未过期时续期
+var renewSharedScript = redisLib.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local expireAt = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if not expireAt or tonumber(expireAt) < now then
+	return 0
+end
+redis.call('ZADD', KEYS[1], now + tonumber(ARGV[2]), ARGV[1])
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl < tonumber(ARGV[2]) then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// releaseExclusiveScript 仅删除自己持有的排他锁
+var releaseExclusiveScript = redisLib.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// UploadLock 上传会话（user + file_hash）上的锁，LockBlob 返回的物理文件锁也使用该类型
+// 合并持有排他锁，分片写入持有共享租约：合并期间拒绝写分片，有分片正在写入时合并需要等待。
+// 持有期间后台定期续期；续期失败（例如 Redis 故障导致租约过期）时取消 Context()，
+// 持有者应停止后续写入
+type UploadLock struct {
+	key       string
+	token     string
+	exclusive bool
+	ttl       time.Duration
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// TryLockUploadExclusive 尝试获取排他锁，已被占用时返回 ErrLockHeld
+func TryLockUploadExclusive(ctx context.Context, session string) (*UploadLock, error) {
+	token := uuid.New().String()
+	keys := []string{UploadLockPrefix + session, UploadWritersPrefix + session}
+	ok, err := acquireExclusiveScript.Run(ctx, GetClient(), keys, token, UploadLockTTL.Milliseconds()).Int()
+	if err != nil {
+		return nil, err
+	}
+	if ok == 0 {
+		return nil, ErrLockHeld
+	}
+	return newUploadLock(ctx, UploadLockPrefix+session, token, true), nil
+}
+
+// LockUploadExclusive 在 wait 时间内重试获取排他锁
+func LockUploadExclusive(ctx context.Context, session string, wait time.Duration) (*UploadLock, error) {
+	deadline := time.Now().Add(wait)
+	for {
+		lock, err := TryLockUploadExclusive(ctx, session)
+		if err != ErrLockHeld || time.Now().After(deadline) {
+			return lock, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// TryLockUploadShared 尝试获取共享租约，合并进行中时返回 ErrLockHeld
+func TryLockUploadShared(ctx context.Context, session string) (*UploadLock, error) {
+	token := uuid.New().String()
+	keys := []string{UploadLockPrefix + session, UploadWritersPrefix + session}
+	ok, err := acquireSharedScript.Run(ctx, GetClient(), keys, token, UploadLockTTL.Milliseconds()).Int()
+	if err != nil {
+		return nil, err
+	}
+	if ok == 0 {
+		return nil, ErrLockHeld
+	}
+	return newUploadLock(ctx, UploadWritersPrefix+session, token, false), nil
+}
+
+// LockBlob 在 wait 时间内重试获取物理文件上的排他锁。
+// 删除最后一个引用（删除物理对象）与合并、秒传（新增引用）都需要持有该锁，
+// 避免新的引用指向即将被删除的对象
+func LockBlob(ctx context.Context, fileHash string, wait time.Duration) (*UploadLock, error) {
+	key := BlobLockPrefix + fileHash
+	deadline := time.Now().Add(wait)
+	for {
+		token := uuid.New().String()
+		ok, err := GetClient().SetNX(ctx, key, token, UploadLockTTL).Result()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return newUploadLock(ctx, key, token, true), nil
+		}
+		if time.Now().After(deadline) {
+			return nil, ErrLockHeld
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// UploadSession 上传会话标识（user + file_hash）
+func UploadSession(userID, fileHash string) string {
+	return userID + ":" + fileHash
+}
+
+func newUploadLock(parent context.Context, key, token string, exclusive bool) *UploadLock {
+	// 锁的生命周期不跟随请求 Context，由持有者显式 Unlock
+	ctx, cancel := context.WithCancel(context.WithoutCancel(parent))
+	l := &UploadLock{
+		key:       key,
+		token:     token,
+		exclusive: exclusive,
+		ttl:       UploadLockTTL,
+		ctx:       ctx,
+		cancel:    cancel,
+		stopped:   make(chan struct{}),
+	}
+	go l.renew()
+	return l
+}
+
+// Context 锁丢失或释放后被取消
+func (l *UploadLock) Context() context.Context {
+	return l.ctx
+}
+
+// renew 每 ttl/3 续期一次
+func (l *UploadLock) renew() {
+	defer close(l.stopped)
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	script := renewSharedScript
+	if l.exclusive {
+		script = renewExclusiveScript
+	}
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-ticker.C:
+			ok, err := script.Run(l.ctx, GetClient(), []string{l.key}, l.token, l.ttl.Milliseconds()).Int()
+			if l.ctx.Err() != nil {
+				return
+			}
+			if err != nil || ok == 0 {
+				log.Printf("[Lock] 续期失败，锁已丢失: %s, error: %v", l.key, err)
+				l.cancel()
+				return
+			}
+		}
+	}
+}
+
+// Unlock 停止续期并释放锁
+func (l *UploadLock) Unlock() {
+	l.once.Do(func() {
+		l.cancel()
+		<-l.stopped
+
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		var err error
+		if l.exclusive {
+			err = releaseExclusiveScript.Run(ctx, GetClient(), []string{l.key}, l.token).Err()
+		} else {
+			err = GetClient().ZRem(ctx, l.key, l.token).Err()
+		}
+		if err != nil {
+			log.Printf("[Lock] 释放锁失败: %s, error: %v", l.key, err)
+		}
+	})
+}
@@ -11,9 +11,12 @@ import (
 const (
 	UploadStatusPrefix    = "upload:status:"
 	UploadChunksPrefix    = "upload:chunks:"
+	UploadDigestsPrefix   = "upload:digests:"
 	TombstonePrefix       = "file:tombstone:"
+	PossessionPrefix      = "upload:possession:"
 	UploadStatusTTL       = 24 * time.Hour
 	TombstoneTTL          = 30 * 24 * time.Hour // 30 days
+	PossessionTTL         = 10 * time.Minute
 )
 
 // GetClient returns the Redis client from common
@@ -49,11 +52,39 @@ func GetFinishedChunks(ctx context.Context, fileHash string) ([]string, error) {
 	return GetClient().SMembers(ctx, key).Result()
 }
 
+// RemoveFinishedChunks removes chunk indices from the finished set so they get uploaded again
+func RemoveFinishedChunks(ctx context.Context, fileHash string, chunkIndices ...string) error {
+	if len(chunkIndices) == 0 {
+		return nil
+	}
+	members := make([]interface{}, len(chunkIndices))
+	for i, index := range chunkIndices {
+		members[i] = index
+	}
+	return GetClient().SRem(ctx, UploadChunksPrefix+fileHash, members...).Err()
+}
+
+// SetChunkDigest records the client-provided digest of a chunk
+func SetChunkDigest(ctx context.Context, fileHash string, chunkIndex string, digest string) error {
+	key := UploadDigestsPrefix + fileHash
+	if err := GetClient().HSet(ctx, key, chunkIndex, digest).Err(); err != nil {
+		return err
+	}
+	return GetClient().Expire(ctx, key, UploadStatusTTL).Err()
+}
+
+// GetChunkDigests gets all recorded chunk digests (index -> digest) for a file
+func GetChunkDigests(ctx context.Context, fileHash string) (map[string]string, error) {
+	key := UploadDigestsPrefix + fileHash
+	return GetClient().HGetAll(ctx, key).Result()
+}
+
 // DeleteUploadCache clears upload cache for a file
 func DeleteUploadCache(ctx context.Context, fileHash string) error {
 	statusKey := UploadStatusPrefix + fileHash
 	chunksKey := UploadChunksPrefix + fileHash
-	return GetClient().Del(ctx, statusKey, chunksKey).Err()
+	digestsKey := UploadDigestsPrefix + fileHash
+	return GetClient().Del(ctx, statusKey, chunksKey, digestsKey).Err()
 }
 
 // SetTombstone sets a tombstone for a completed file (permanent cache)
@@ -68,6 +99,24 @@ func GetTombstone(ctx context.Context, fileHash string) (string, error) {
 	return GetClient().Get(ctx, key).Result()
 }
 
+// DeleteTombstone removes the tombstone for a file
+func DeleteTombstone(ctx context.Context, fileHash string) error {
+	key := TombstonePrefix + fileHash
+	return GetClient().Del(ctx, key).Err()
+}
+
+// SetPossessionAnswer stores the expected answer to a possession challenge, replacing any earlier one
+func SetPossessionAnswer(ctx context.Context, fileHash string, answer string) error {
+	key := PossessionPrefix + fileHash
+	return GetClient().Set(ctx, key, answer, PossessionTTL).Err()
+}
+
+// TakePossessionAnswer gets and deletes the expected answer, so each challenge can be answered only once
+func TakePossessionAnswer(ctx context.Context, fileHash string) (string, error) {
+	key := PossessionPrefix + fileHash
+	return GetClient().GetDel(ctx, key).Result()
+}
+
 // IsChunkUploaded checks if a chunk is in the uploaded set
 func IsChunkUploaded(ctx context.Context, fileHash string, chunkIndex string) (bool, error) {
 	key := UploadChunksPrefix + fileHash
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrNotExist 对象不存在（与 os.ErrNotExist 相同，便于 errors.Is / os.IsNotExist 判断）
+var ErrNotExist = os.ErrNotExist
+
+// ObjectInfo 对象元信息
+type ObjectInfo struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// Backend 存储后端接口
+// key 统一使用 "/" 分隔的相对路径，例如 "chunks/xxx_0"、"files/xxx.mp4"
+type Backend interface {
+	// Name 返回后端名称（local / s3）
+	Name() string
+	// Put 写入对象，size 未知时传 -1
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+	// GetRange 读取对象的 [offset, offset+length) 区间，length < 0 表示读到末尾
+	GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+	// Stat 获取对象信息，不存在时返回 ErrNotExist
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	// Delete 删除对象，对象不存在不视为错误
+	Delete(ctx context.Context, key string) error
+	// List 列出指定前缀下的所有对象
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+	// Compose 按顺序将 srcs 拼接为 dst（分片合并），progress 可为 nil，参数为已合并的字节数
+	Compose(ctx context.Context, dst string, srcs []string, progress func(merged int64)) error
+}
+
+// progressReader 统计读取的字节数并回调
+type progressReader struct {
+	r        io.Reader
+	n        int64
+	progress func(int64)
+}
+
+func withProgress(r io.Reader, progress func(int64)) io.Reader {
+	if progress == nil {
+		return r
+	}
+	return &progressReader{r: r, progress: progress}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.n += int64(n)
+		p.progress(p.n)
+	}
+	return n, err
+}
+
+// contextReader 每次读取前检查 ctx，取消后不再读取 r
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *contextReader) Read(b []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(b)
+}
+
+// LocalPather 可选接口：后端对象可以直接映射为本地文件路径
+type LocalPather interface {
+	LocalPath(key string) string
+}
+
+// NewBackendFromEnv 根据 STORAGE_BACKEND 环境变量创建存储后端
+func NewBackendFromEnv(storagePath string) (Backend, error) {
+	switch strings.ToLower(os.Getenv("STORAGE_BACKEND")) {
+	case "", "local":
+		return NewLocalBackend(storagePath), nil
+	case "s3", "minio":
+		return NewS3Backend(S3Config{
+			Endpoint:     os.Getenv("S3_ENDPOINT"),
+			Region:       os.Getenv("S3_REGION"),
+			Bucket:       os.Getenv("S3_BUCKET"),
+			AccessKey:    os.Getenv("S3_ACCESS_KEY"),
+			SecretKey:    os.Getenv("S3_SECRET_KEY"),
+			Prefix:       os.Getenv("S3_PREFIX"),
+			VirtualHost:  os.Getenv("S3_VIRTUAL_HOST") == "true",
+			CreateBucket: os.Getenv("S3_CREATE_BUCKET") == "true",
+		})
+	default:
+		return nil, fmt.Errorf("不支持的存储后端: %s", os.Getenv("STORAGE_BACKEND"))
+	}
+}
@@ -0,0 +1,256 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LocalBackend 本地文件系统存储后端
+type LocalBackend struct {
+	root string
+}
+
+// NewLocalBackend 创建本地存储后端
+func NewLocalBackend(root string) *LocalBackend {
+	return &LocalBackend{root: root}
+}
+
+// Name 返回后端名称
+func (b *LocalBackend) Name() string {
+	return "local"
+}
+
+// ErrInvalidKey key 为空、是绝对路径或包含 ".." 段，可能指向存储根目录之外
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// checkKey 校验 key 是 root 下的相对路径
+func checkKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == ".." {
+			return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+	}
+	return nil
+}
+
+// LocalPath 返回对象对应的本地路径，key 先按根目录规范化，结果不会超出 root
+func (b *LocalBackend) LocalPath(key string) string {
+	return filepath.Join(b.root, filepath.FromSlash(path.Clean("/"+key)))
+}
+
+// Put 先写临时文件再重命名，保证读取方不会看到写了一半的对象
+func (b *LocalBackend) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	dst := b.LocalPath(key)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".tmp-"+filepath.Base(dst)+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, &contextReader{ctx: ctx, r: r}); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	// 写入期间被取消（例如持有的上传租约已丢失）时不发布对象
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+// PutFile 将本地文件移动到存储中（同一文件系统内直接 rename）
+func (b *LocalBackend) PutFile(ctx context.Context, key string, localPath string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	dst := b.LocalPath(key)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.Rename(localPath, dst); err == nil {
+		return nil
+	}
+
+	// 跨文件系统时退化为复制
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(localPath)
+	defer f.Close()
+	return b.Put(ctx, key, f, -1)
+}
+
+// GetRange 读取指定区间
+func (b *LocalBackend) GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(b.LocalPath(key))
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	if length < 0 {
+		return f, nil
+	}
+	return &limitedReadCloser{Reader: io.LimitReader(f, length), Closer: f}, nil
+}
+
+// Stat 获取对象信息
+func (b *LocalBackend) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(b.LocalPath(key))
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectInfo{Key: key, Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+// Delete 删除对象
+func (b *LocalBackend) Delete(ctx context.Context, key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	if err := os.Remove(b.LocalPath(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// List 列出前缀下的对象（按 key 排序）
+func (b *LocalBackend) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	if prefix != "" {
+		if err := checkKey(prefix); err != nil {
+			return nil, err
+		}
+	}
+
+	// 前缀可能只是文件名的一部分，因此从其所在目录开始遍历
+	dir := b.LocalPath(prefix)
+	if !strings.HasSuffix(prefix, "/") {
+		dir = filepath.Dir(dir)
+	}
+
+	var objects []ObjectInfo
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".tmp-") {
+			return nil
+		}
+		rel, err := filepath.Rel(b.root, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, ObjectInfo{Key: key, Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+// Compose 顺序拼接 srcs 写入 dst
+func (b *LocalBackend) Compose(ctx context.Context, dst string, srcs []string, progress func(int64)) error {
+	if err := checkKey(dst); err != nil {
+		return err
+	}
+	r := newSequentialReader(ctx, b, srcs)
+	defer r.Close()
+	return b.Put(ctx, dst, withProgress(r, progress), -1)
+}
+
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// sequentialReader 按顺序惰性打开多个对象，避免同时持有所有句柄
+type sequentialReader struct {
+	ctx     context.Context
+	backend Backend
+	keys    []string
+	current io.ReadCloser
+}
+
+func newSequentialReader(ctx context.Context, backend Backend, keys []string) *sequentialReader {
+	return &sequentialReader{ctx: ctx, backend: backend, keys: keys}
+}
+
+func (s *sequentialReader) Read(p []byte) (int, error) {
+	for {
+		if s.current == nil {
+			if len(s.keys) == 0 {
+				return 0, io.EOF
+			}
+			rc, err := s.backend.GetRange(s.ctx, s.keys[0], 0, -1)
+			if err != nil {
+				return 0, fmt.Errorf("failed to open %s: %w", s.keys[0], err)
+			}
+			s.current = rc
+			s.keys = s.keys[1:]
+		}
+
+		n, err := s.current.Read(p)
+		if err == io.EOF {
+			s.current.Close()
+			s.current = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (s *sequentialReader) Close() error {
+	if s.current != nil {
+		return s.current.Close()
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestLocalBackendRejectsEscapingKeys(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "root")
+	b := NewLocalBackend(root)
+	ctx := context.Background()
+
+	for _, key := range []string{"", "/etc/passwd", "../outside", "files/../../outside", "files/.."} {
+		if err := b.Put(ctx, key, strings.NewReader("x"), 1); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+		if _, err := b.GetRange(ctx, key, 0, -1); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("GetRange(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+		if err := b.Delete(ctx, key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Delete(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+		if path := b.LocalPath(key); path != root && !strings.HasPrefix(path, root+string(filepath.Separator)) {
+			t.Errorf("LocalPath(%q) = %q, outside %q", key, path, root)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(parent, "outside")); !os.IsNotExist(err) {
+		t.Errorf("file written outside the storage root")
+	}
+}
+
+func TestLocalBackendRoundTrip(t *testing.T) {
+	b := NewLocalBackend(t.TempDir())
+	ctx := context.Background()
+
+	if err := b.Put(ctx, "chunks/x_0", strings.NewReader("hello "), -1); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Put(ctx, "chunks/x_1", strings.NewReader("world"), -1); err != nil {
+		t.Fatal(err)
+	}
+	var merged int64
+	if err := b.Compose(ctx, "files/x", []string{"chunks/x_0", "chunks/x_1"}, func(n int64) { merged = n }); err != nil {
+		t.Fatal(err)
+	}
+	if merged != 11 {
+		t.Errorf("progress = %d, want 11", merged)
+	}
+
+	rc, err := b.GetRange(ctx, "files/x", 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	data, _ := io.ReadAll(rc)
+	if !bytes.Equal(data, []byte("lo wo")) {
+		t.Errorf("GetRange = %q", data)
+	}
+
+	objects, err := b.List(ctx, "chunks/x_")
+	if err != nil || len(objects) != 2 {
+		t.Fatalf("List = %v, %v", objects, err)
+	}
+}
+
+func TestLocalBackendPutCancelled(t *testing.T) {
+	b := NewLocalBackend(t.TempDir())
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// 读到一半时取消：对象不能出现在存储中
+	r := io.MultiReader(strings.NewReader("first half "), readerFunc(func(p []byte) (int, error) {
+		cancel()
+		return copy(p, "second half"), io.EOF
+	}))
+	if err := b.Put(ctx, "chunks/y_0", r, -1); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Put error = %v, want context.Canceled", err)
+	}
+	if _, err := b.Stat(context.Background(), "chunks/y_0"); !os.IsNotExist(err) {
+		t.Errorf("cancelled Put left an object behind: %v", err)
+	}
+}
+
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) {
+	return f(p)
+}
+
+func TestReadFileChunkLimit(t *testing.T) {
+	old, oldMax := backend, MaxReadSize
+	t.Cleanup(func() { backend, MaxReadSize = old, oldMax })
+	SetBackend(NewLocalBackend(t.TempDir()))
+	MaxReadSize = 4
+
+	ctx := context.Background()
+	if err := backend.Put(ctx, "files/a", strings.NewReader("0123456789"), 10); err != nil {
+		t.Fatal(err)
+	}
+	data, total, err := ReadFileChunk(ctx, "files/a", 2, 6)
+	if err != nil || string(data) != "2345" || total != 10 {
+		t.Fatalf("ReadFileChunk(2, 6) = %q, %d, %v", data, total, err)
+	}
+	if _, total, err := ReadFileChunk(ctx, "files/a", 0, 0); !errors.Is(err, ErrRangeTooLarge) || total != 10 {
+		t.Fatalf("ReadFileChunk(0, 0) total = %d, error = %v, want ErrRangeTooLarge", total, err)
+	}
+}
+
+func TestMergeChunksCancelled(t *testing.T) {
+	old := backend
+	t.Cleanup(func() { backend = old })
+	SetBackend(NewLocalBackend(t.TempDir()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	for i, data := range []string{"hello ", "world"} {
+		if err := backend.Put(ctx, GetChunkKey("u_x", strconv.Itoa(i)), strings.NewReader(data), int64(len(data))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cancel()
+	if err := MergeChunks(ctx, "u_x", "files/x", 2, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("MergeChunks error = %v, want context.Canceled", err)
+	}
+	if _, err := backend.Stat(context.Background(), "files/x"); err == nil {
+		t.Error("cancelled merge left a composed object")
+	}
+
+	if err := MergeChunks(context.Background(), "u_x", "files/x", 2, nil); err != nil {
+		t.Fatalf("MergeChunks: %v", err)
+	}
+	data, _, err := ReadFileChunk(context.Background(), "files/x", 0, 0)
+	if err != nil || string(data) != "hello world" {
+		t.Fatalf("merged = %q, %v", data, err)
+	}
+}
@@ -0,0 +1,593 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3PartSize        = 8 * 1024 * 1024 // 流式分段上传的分段大小
+	s3MinCopyPartSize = 5 * 1024 * 1024 // S3 要求除最后一段外每段至少 5MB
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3EmptySHA256     = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// S3Config S3 兼容对象存储配置（AWS S3 / MinIO 等）
+type S3Config struct {
+	Endpoint     string // 例如 http://127.0.0.1:9000
+	Region       string
+	Bucket       string
+	AccessKey    string
+	SecretKey    string
+	Prefix       string // 所有 key 的公共前缀，可为空
+	VirtualHost  bool   // true 使用 bucket.host 风格，默认 path 风格（MinIO 友好）
+	CreateBucket bool   // 启动时自动创建 bucket
+}
+
+// S3Backend S3 兼容对象存储后端（基于 SigV4 签名的 REST API，不依赖 SDK）
+type S3Backend struct {
+	cfg      S3Config
+	endpoint *url.URL
+	client   *http.Client
+}
+
+// NewS3Backend 创建 S3 存储后端
+func NewS3Backend(cfg S3Config) (*S3Backend, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("S3_ENDPOINT 和 S3_BUCKET 不能为空")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if !strings.Contains(cfg.Endpoint, "://") {
+		cfg.Endpoint = "https://" + cfg.Endpoint
+	}
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("无效的 S3_ENDPOINT: %w", err)
+	}
+	cfg.Prefix = strings.Trim(cfg.Prefix, "/")
+
+	b := &S3Backend{cfg: cfg, endpoint: endpoint, client: &http.Client{}}
+	if cfg.CreateBucket {
+		if err := b.createBucket(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// Name 返回后端名称
+func (b *S3Backend) Name() string {
+	return "s3"
+}
+
+// Put 写入对象；大小未知或超过一个分段时使用分段上传
+func (b *S3Backend) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	if size >= 0 && size <= s3PartSize {
+		return b.putObject(ctx, key, r, size)
+	}
+	return b.putMultipart(ctx, key, r)
+}
+
+// GetRange 读取指定区间
+func (b *S3Backend) GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	if length == 0 {
+		return io.NopCloser(bytes.NewReader(nil)), nil
+	}
+	req, err := b.newRequest(ctx, http.MethodGet, key, nil, nil, 0, s3EmptySHA256, nil)
+	if err != nil {
+		return nil, err
+	}
+	if length > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	} else if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		resp.Body.Close()
+		return io.NopCloser(bytes.NewReader(nil)), nil
+	}
+	if err := checkS3Response(resp, key); err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Stat 获取对象信息
+func (b *S3Backend) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	resp, err := b.do(ctx, http.MethodHead, key, nil, nil, 0, s3EmptySHA256, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	info := &ObjectInfo{Key: key, Size: resp.ContentLength}
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.ModTime = t
+	}
+	return info, nil
+}
+
+// Delete 删除对象
+func (b *S3Backend) Delete(ctx context.Context, key string) error {
+	resp, err := b.do(ctx, http.MethodDelete, key, nil, nil, 0, s3EmptySHA256, nil)
+	if err != nil {
+		if isNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// List 列出前缀下的对象（ListObjectsV2，自动翻页）
+func (b *S3Backend) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {b.fullKey(prefix)}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := b.do(ctx, http.MethodGet, "", query, nil, 0, s3EmptySHA256, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			Contents []struct {
+				Key          string
+				Size         int64
+				LastModified time.Time
+			}
+			IsTruncated           bool
+			NextContinuationToken string
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode list response: %w", err)
+		}
+
+		for _, c := range result.Contents {
+			objects = append(objects, ObjectInfo{
+				Key:     b.relativeKey(c.Key),
+				Size:    c.Size,
+				ModTime: c.LastModified,
+			})
+		}
+		if !result.IsTruncated {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// Compose 拼接多个对象
+// 除最后一个外都不小于 5MB 时走服务端 UploadPartCopy，否则流式读出再分段上传
+func (b *S3Backend) Compose(ctx context.Context, dst string, srcs []string, progress func(int64)) error {
+	// 最后一段不受 5MB 限制，但同样需要 Stat 得到大小，合并进度才能到达总字节数
+	serverSide := len(srcs) > 1
+	sizes := make([]int64, len(srcs))
+	for i, src := range srcs {
+		info, err := b.Stat(ctx, src)
+		if err != nil {
+			return fmt.Errorf("failed to stat part %d: %w", i, err)
+		}
+		sizes[i] = info.Size
+		if i < len(srcs)-1 && info.Size < s3MinCopyPartSize {
+			serverSide = false
+			break
+		}
+	}
+
+	if !serverSide {
+		r := newSequentialReader(ctx, b, srcs)
+		defer r.Close()
+		return b.putMultipart(ctx, dst, withProgress(r, progress))
+	}
+
+	uploadID, err := b.createMultipartUpload(ctx, dst)
+	if err != nil {
+		return err
+	}
+	parts := make([]s3Part, 0, len(srcs))
+	var merged int64
+	for i, src := range srcs {
+		etag, err := b.uploadPartCopy(ctx, dst, uploadID, i+1, src)
+		if err != nil {
+			b.abortMultipartUpload(ctx, dst, uploadID)
+			return fmt.Errorf("failed to copy part %d: %w", i, err)
+		}
+		parts = append(parts, s3Part{PartNumber: i + 1, ETag: etag})
+
+		if progress != nil {
+			merged += sizes[i]
+			progress(merged)
+		}
+	}
+	return b.completeMultipartUpload(ctx, dst, uploadID, parts)
+}
+
+func (b *S3Backend) putObject(ctx context.Context, key string, r io.Reader, size int64) error {
+	resp, err := b.do(ctx, http.MethodPut, key, nil, r, size, s3UnsignedPayload, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// putMultipart 以固定大小分段流式上传，内存占用不超过一个分段
+func (b *S3Backend) putMultipart(ctx context.Context, key string, r io.Reader) error {
+	buf := make([]byte, s3PartSize)
+	n, err := io.ReadFull(r, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		// 不足一个分段，直接普通上传
+		return b.putObject(ctx, key, bytes.NewReader(buf[:n]), int64(n))
+	}
+	if err != nil {
+		return err
+	}
+
+	uploadID, err := b.createMultipartUpload(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	var parts []s3Part
+	for partNumber := 1; ; partNumber++ {
+		etag, err := b.uploadPart(ctx, key, uploadID, partNumber, buf[:n])
+		if err != nil {
+			b.abortMultipartUpload(ctx, key, uploadID)
+			return err
+		}
+		parts = append(parts, s3Part{PartNumber: partNumber, ETag: etag})
+
+		n, err = io.ReadFull(r, buf)
+		if err == io.EOF {
+			break
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			b.abortMultipartUpload(ctx, key, uploadID)
+			return err
+		}
+	}
+	return b.completeMultipartUpload(ctx, key, uploadID, parts)
+}
+
+type s3Part struct {
+	PartNumber int
+	ETag       string
+}
+
+func (b *S3Backend) createMultipartUpload(ctx context.Context, key string) (string, error) {
+	resp, err := b.do(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil, 0, s3EmptySHA256, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := decodeS3Result(resp, key, &result); err != nil {
+		return "", err
+	}
+	return result.UploadID, nil
+}
+
+func (b *S3Backend) uploadPart(ctx context.Context, key, uploadID string, partNumber int, data []byte) (string, error) {
+	query := url.Values{"partNumber": {strconv.Itoa(partNumber)}, "uploadId": {uploadID}}
+	resp, err := b.do(ctx, http.MethodPut, key, query, bytes.NewReader(data), int64(len(data)), s3UnsignedPayload, nil)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	return resp.Header.Get("ETag"), nil
+}
+
+func (b *S3Backend) uploadPartCopy(ctx context.Context, key, uploadID string, partNumber int, src string) (string, error) {
+	query := url.Values{"partNumber": {strconv.Itoa(partNumber)}, "uploadId": {uploadID}}
+	headers := map[string]string{
+		"x-amz-copy-source": "/" + b.cfg.Bucket + "/" + uriEncode(b.fullKey(src), false),
+	}
+	resp, err := b.do(ctx, http.MethodPut, key, query, nil, 0, s3EmptySHA256, headers)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		ETag string
+	}
+	if err := decodeS3Result(resp, key, &result); err != nil {
+		return "", err
+	}
+	return result.ETag, nil
+}
+
+func (b *S3Backend) completeMultipartUpload(ctx context.Context, key, uploadID string, parts []s3Part) error {
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name `xml:"CompleteMultipartUpload"`
+		Parts   []s3Part `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(body)
+	resp, err := b.do(ctx, http.MethodPost, key, url.Values{"uploadId": {uploadID}},
+		bytes.NewReader(body), int64(len(body)), hex.EncodeToString(sum[:]), nil)
+	if err != nil {
+		b.abortMultipartUpload(ctx, key, uploadID)
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := decodeS3Result(resp, key, nil); err != nil {
+		b.abortMultipartUpload(ctx, key, uploadID)
+		return err
+	}
+	return nil
+}
+
+func (b *S3Backend) abortMultipartUpload(ctx context.Context, key, uploadID string) {
+	resp, err := b.do(ctx, http.MethodDelete, key, url.Values{"uploadId": {uploadID}}, nil, 0, s3EmptySHA256, nil)
+	if err == nil {
+		resp.Body.Close()
+	}
+}
+
+func (b *S3Backend) createBucket(ctx context.Context) error {
+	req, err := b.newRequest(ctx, http.MethodPut, "", nil, nil, 0, s3EmptySHA256, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to create bucket: %w", err)
+	}
+	if resp.StatusCode == http.StatusConflict {
+		resp.Body.Close()
+		return nil // BucketAlreadyOwnedByYou
+	}
+	if err := checkS3Response(resp, b.cfg.Bucket); err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// do 构造、签名并发送请求，非 2xx 响应转换为 error
+func (b *S3Backend) do(ctx context.Context, method, key string, query url.Values, body io.Reader, size int64, payloadHash string, headers map[string]string) (*http.Response, error) {
+	req, err := b.newRequest(ctx, method, key, query, body, size, payloadHash, headers)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkS3Response(resp, key); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (b *S3Backend) newRequest(ctx context.Context, method, key string, query url.Values, body io.Reader, size int64, payloadHash string, headers map[string]string) (*http.Request, error) {
+	u := *b.endpoint
+	path := b.bucketPath()
+	if key != "" {
+		path = strings.TrimSuffix(path, "/") + "/" + b.fullKey(key)
+	}
+	u.Path = path
+	u.RawPath = uriEncode(path, false)
+	u.RawQuery = canonicalQuery(query)
+	if b.cfg.VirtualHost {
+		u.Host = b.cfg.Bucket + "." + u.Host
+	}
+
+	if body == nil || size == 0 {
+		body = http.NoBody
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if size > 0 {
+		req.ContentLength = size
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	b.sign(req, payloadHash, time.Now().UTC())
+	return req, nil
+}
+
+func (b *S3Backend) bucketPath() string {
+	if b.cfg.VirtualHost {
+		return "/"
+	}
+	return "/" + b.cfg.Bucket + "/"
+}
+
+func (b *S3Backend) fullKey(key string) string {
+	if b.cfg.Prefix == "" {
+		return key
+	}
+	return b.cfg.Prefix + "/" + key
+}
+
+func (b *S3Backend) relativeKey(key string) string {
+	if b.cfg.Prefix == "" {
+		return key
+	}
+	return strings.TrimPrefix(key, b.cfg.Prefix+"/")
+}
+
+// sign AWS Signature Version 4
+func (b *S3Backend) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	// 参与签名的请求头：host + 所有 x-amz-*
+	signed := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		lk := strings.ToLower(k)
+		if strings.HasPrefix(lk, "x-amz-") {
+			signed[lk] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	names := make([]string, 0, len(signed))
+	for k := range signed {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + signed[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + b.cfg.Region + "/s3/aws4_request"
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+b.cfg.SecretKey), date)
+	key = hmacSHA256(key, b.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		b.cfg.AccessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// canonicalQuery 按 SigV4 规则排序并编码查询参数
+func canonicalQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode 按 SigV4 规则编码（仅保留 A-Za-z0-9-_.~）
+func uriEncode(s string, encodeSlash bool) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			sb.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			sb.WriteByte(c)
+		default:
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
+
+type s3Error struct {
+	Code    string
+	Message string
+}
+
+func checkS3Response(resp *http.Response, key string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrNotExist, key)
+	}
+	var s3Err s3Error
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if xml.Unmarshal(data, &s3Err) == nil && s3Err.Code != "" {
+		return fmt.Errorf("s3 %s %s: %s: %s", resp.Request.Method, key, s3Err.Code, s3Err.Message)
+	}
+	return fmt.Errorf("s3 %s %s: unexpected status %d", resp.Request.Method, key, resp.StatusCode)
+}
+
+// decodeS3Result 解析 2xx 响应的 XML body 到 v（v 为 nil 时只检查错误）。
+// UploadPartCopy 与 CompleteMultipartUpload 在返回 200 之后才出错时，错误放在 body 的 <Error> 中
+func decodeS3Result(resp *http.Response, key string, v interface{}) error {
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("s3 %s %s: failed to read response: %w", resp.Request.Method, key, err)
+	}
+	var root struct {
+		XMLName xml.Name
+		s3Error
+	}
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return fmt.Errorf("s3 %s %s: failed to decode response: %w", resp.Request.Method, key, err)
+	}
+	if root.XMLName.Local == "Error" {
+		return fmt.Errorf("s3 %s %s: %s: %s", resp.Request.Method, key, root.Code, root.Message)
+	}
+	if v == nil {
+		return nil
+	}
+	if err := xml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("s3 %s %s: failed to decode response: %w", resp.Request.Method, key, err)
+	}
+	return nil
+}
+
+func isNotExist(err error) bool {
+	return errors.Is(err, ErrNotExist)
+}
@@ -1,6 +1,9 @@
 package storage
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -9,189 +12,338 @@ import (
 )
 
 var (
-     StoragePath string
-     ChunkSize   int64 = 2 * 1024 * 1024 // 默认 2MB
+	StoragePath string
+	ChunkSize   int64 = 2 * 1024 * 1024  // 默认 2MB
+	MaxReadSize int64 = 32 * 1024 * 1024 // ReadFileChunk 单次读入内存的上限
+
+	backend Backend
 )
 
 // InitStorage 初始化存储配置
 func InitStorage() error {
-     StoragePath = os.Getenv("STORAGE_PATH")
-     if StoragePath == "" {
-          StoragePath = "/tmp/video-platform"
-     }
+	StoragePath = os.Getenv("STORAGE_PATH")
+	if StoragePath == "" {
+		StoragePath = "/tmp/video-platform"
+	}
+
+	chunkSizeStr := os.Getenv("CHUNK_SIZE")
+	if chunkSizeStr != "" {
+		size, err := strconv.ParseInt(chunkSizeStr, 10, 64)
+		if err == nil {
+			ChunkSize = size
+		}
+	}
+
+	// 创建存储目录（使用对象存储时，StoragePath 仅作为本地临时目录）
+	dirs := []string{
+		filepath.Join(StoragePath, "chunks"), // 分片临时目录
+		filepath.Join(StoragePath, "files"),  // 最终文件目录
+		filepath.Join(StoragePath, "tmp"),    // 转码等本地处理的临时目录
+	}
+
+	for _, dir := range dirs {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+	}
+
+	b, err := NewBackendFromEnv(StoragePath)
+	if err != nil {
+		return err
+	}
+	backend = b
+
+	fmt.Printf("✅ 存储初始化成功: %s (backend: %s)\n", StoragePath, backend.Name())
+	return nil
+}
+
+// GetBackend 返回当前存储后端
+func GetBackend() Backend {
+	return backend
+}
 
-     chunkSizeStr := os.Getenv("CHUNK_SIZE")
-     if chunkSizeStr != "" {
-          size, err := strconv.ParseInt(chunkSizeStr, 10, 64)
-          if err == nil {
-               ChunkSize = size
-          }
-     }
+// SetBackend 替换存储后端（测试或自定义初始化时使用）
+func SetBackend(b Backend) {
+	backend = b
+}
 
-     // 创建存储目录
-     dirs := []string{
-          filepath.Join(StoragePath, "chunks"),  // 分片临时目录
-          filepath.Join(StoragePath, "files"),   // 最终文件目录
-     }
+// ErrBackendMismatch 对象记录在其他存储后端中，当前后端无法读取
+var ErrBackendMismatch = errors.New("对象不在当前存储后端中")
 
-     for _, dir := range dirs {
-          if err := os.MkdirAll(dir, 0755); err != nil {
-               return fmt.Errorf("failed to create directory %s: %w", dir, err)
-          }
-     }
+// BackendName 当前存储后端的名称，与对象 key 一起保存在数据库中
+func BackendName() string {
+	return backend.Name()
+}
 
-     fmt.Printf("✅ 存储目录初始化成功: %s\n", StoragePath)
-     return nil
+// Resolve 校验数据库中记录的对象位置并返回 key；记录的后端与当前后端不一致时返回 ErrBackendMismatch
+func Resolve(key, backendName string) (string, error) {
+	if key == "" {
+		return "", fmt.Errorf("缺少存储位置")
+	}
+	if backendName != "" && backendName != backend.Name() {
+		return "", fmt.Errorf("%w: %s 位于 %s，当前为 %s", ErrBackendMismatch, key, backendName, backend.Name())
+	}
+	return key, nil
 }
 
-// GetChunkPath 获取分片文件路径
-func GetChunkPath(fileHash string, chunkIndex string) string {
-     return filepath.Join(StoragePath, "chunks", fmt.Sprintf("%s_%s", fileHash, chunkIndex))
+// GetChunkKey 获取分片对象 key
+func GetChunkKey(fileHash string, chunkIndex string) string {
+	return fmt.Sprintf("chunks/%s_%s", fileHash, chunkIndex)
 }
 
-// GetFilePath 获取最终文件路径
-func GetFilePath(fileHash string, filename string) string {
-     ext := filepath.Ext(filename)
-     return filepath.Join(StoragePath, "files", fileHash+ext)
+// GetFileKey 获取最终文件对象 key
+func GetFileKey(fileHash string, filename string) string {
+	return "files/" + fileHash + filepath.Ext(filename)
+}
+
+// GetBlobKey 获取内容寻址的物理文件 key，相同内容的文件只存一份
+// 按哈希前两位分目录，避免单目录下文件过多
+func GetBlobKey(fileHash string, filename string) string {
+	prefix := fileHash
+	if len(prefix) > 2 {
+		prefix = prefix[:2]
+	}
+	return fmt.Sprintf("blobs/%s/%s%s", prefix, fileHash, filepath.Ext(filename))
 }
 
 // SaveChunk 保存分片数据
 func SaveChunk(fileHash string, chunkIndex string, data []byte) error {
-     chunkPath := GetChunkPath(fileHash, chunkIndex)
-     
-     // 确保目录存在
-     if err := os.MkdirAll(filepath.Dir(chunkPath), 0755); err != nil {
-          return fmt.Errorf("failed to create chunk directory: %w", err)
-     }
-
-     // 写入文件
-     if err := os.WriteFile(chunkPath, data, 0644); err != nil {
-          return fmt.Errorf("failed to write chunk: %w", err)
-     }
+	key := GetChunkKey(fileHash, chunkIndex)
+	if err := backend.Put(context.Background(), key, bytes.NewReader(data), int64(len(data))); err != nil {
+		return fmt.Errorf("failed to write chunk: %w", err)
+	}
+	return nil
+}
 
-     return nil
+// SaveChunkFrom 从 r 流式写入分片，size 未知时传 -1；r 返回错误时不会留下分片
+func SaveChunkFrom(ctx context.Context, fileHash string, chunkIndex string, r io.Reader, size int64) error {
+	key := GetChunkKey(fileHash, chunkIndex)
+	if err := backend.Put(ctx, key, r, size); err != nil {
+		return fmt.Errorf("failed to write chunk: %w", err)
+	}
+	return nil
 }
 
 // ChunkExists 检查分片是否已存在
 func ChunkExists(fileHash string, chunkIndex string) bool {
-     chunkPath := GetChunkPath(fileHash, chunkIndex)
-     _, err := os.Stat(chunkPath)
-     return err == nil
+	_, err := backend.Stat(context.Background(), GetChunkKey(fileHash, chunkIndex))
+	return err == nil
 }
 
 // FileExists 检查文件是否已存在
 func FileExists(fileHash string, filename string) bool {
-     filePath := GetFilePath(fileHash, filename)
-     _, err := os.Stat(filePath)
-     return err == nil
-}
-
-// MergeChunks 合并分片文件
-func MergeChunks(fileHash string, filename string, totalChunks int) error {
-     finalPath := GetFilePath(fileHash, filename)
-
-     // 创建最终文件
-     finalFile, err := os.Create(finalPath)
-     if err != nil {
-          return fmt.Errorf("failed to create final file: %w", err)
-     }
-     defer finalFile.Close()
-
-     // 按顺序合并分片
-     for i := 0; i < totalChunks; i++ {
-          chunkPath := GetChunkPath(fileHash, strconv.Itoa(i))
-          
-          chunkFile, err := os.Open(chunkPath)
-          if err != nil {
-               return fmt.Errorf("failed to open chunk %d: %w", i, err)
-          }
-
-          if _, err := io.Copy(finalFile, chunkFile); err != nil {
-               chunkFile.Close()
-               return fmt.Errorf("failed to copy chunk %d: %w", i, err)
-          }
-          chunkFile.Close()
-     }
-
-     // 删除临时分片文件
-     for i := 0; i < totalChunks; i++ {
-          chunkPath := GetChunkPath(fileHash, strconv.Itoa(i))
-          os.Remove(chunkPath)
-     }
-
-     fmt.Printf("✅ 文件合并成功: %s\n", finalPath)
-     return nil
+	_, err := backend.Stat(context.Background(), GetFileKey(fileHash, filename))
+	return err == nil
+}
+
+// MergeChunks 合并分片文件，写入 finalKey；ctx 取消时中止合并
+// 分片不会被删除，调用方在合并结果持久化后再调用 DeleteChunks，以便中断后可以重新合并
+func MergeChunks(ctx context.Context, fileHash string, finalKey string, totalChunks int, progress func(merged int64)) error {
+	// 按顺序合并分片
+	chunkKeys := make([]string, totalChunks)
+	for i := 0; i < totalChunks; i++ {
+		chunkKeys[i] = GetChunkKey(fileHash, strconv.Itoa(i))
+		if _, err := backend.Stat(ctx, chunkKeys[i]); err != nil {
+			return fmt.Errorf("failed to open chunk %d: %w", i, err)
+		}
+	}
+
+	if err := backend.Compose(ctx, finalKey, chunkKeys, progress); err != nil {
+		return fmt.Errorf("failed to compose chunks: %w", err)
+	}
+
+	fmt.Printf("✅ 文件合并成功: %s\n", finalKey)
+	return nil
+}
+
+// GetChunksSize 获取所有分片的总大小
+func GetChunksSize(fileHash string, totalChunks int) (int64, error) {
+	var total int64
+	for i := 0; i < totalChunks; i++ {
+		info, err := backend.Stat(context.Background(), GetChunkKey(fileHash, strconv.Itoa(i)))
+		if err != nil {
+			return 0, fmt.Errorf("failed to stat chunk %d: %w", i, err)
+		}
+		total += info.Size
+	}
+	return total, nil
 }
 
 // GetFileURL 获取文件访问 URL
 func GetFileURL(fileHash string, filename string) string {
-     // 这里返回一个简单的路径，实际生产环境可能需要返回 CDN URL
-     return fmt.Sprintf("/files/%s%s", fileHash, filepath.Ext(filename))
+	// 这里返回一个简单的路径，实际生产环境可能需要返回 CDN URL
+	return fmt.Sprintf("/files/%s%s", fileHash, filepath.Ext(filename))
 }
 
 // DeleteChunks 删除指定文件的所有分片
 func DeleteChunks(fileHash string, totalChunks int) error {
-     for i := 0; i < totalChunks; i++ {
-          chunkPath := GetChunkPath(fileHash, strconv.Itoa(i))
-          if err := os.Remove(chunkPath); err != nil && !os.IsNotExist(err) {
-               return fmt.Errorf("failed to delete chunk %d: %w", i, err)
-          }
-     }
-     return nil
+	for i := 0; i < totalChunks; i++ {
+		if err := backend.Delete(context.Background(), GetChunkKey(fileHash, strconv.Itoa(i))); err != nil {
+			return fmt.Errorf("failed to delete chunk %d: %w", i, err)
+		}
+	}
+	return nil
 }
 
-// ReadFileChunk 读取文件分片
-func ReadFileChunk(fileHash, filename string, startByte, endByte int64) ([]byte, int64, error) {
-filePath := GetFilePath(fileHash, filename)
+// ErrInvalidRange 请求的字节范围与文件没有交集
+var ErrInvalidRange = errors.New("无效的字节范围")
 
-// 获取文件信息
-info, err := os.Stat(filePath)
-if err != nil {
-return nil, 0, fmt.Errorf("文件不存在: %w", err)
+// FileRange 已打开的文件区间 [Start, End)
+type FileRange struct {
+	io.ReadCloser
+	Start     int64
+	End       int64
+	TotalSize int64
 }
 
-totalSize := info.Size()
+// OpenFileRange 打开文件的 [startByte, endByte) 区间用于流式读取；
+// endByte <= 0 或超过文件大小时读到末尾，区间为空时返回 ErrInvalidRange
+func OpenFileRange(ctx context.Context, key string, startByte, endByte int64) (*FileRange, error) {
+	info, err := backend.Stat(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("文件不存在: %w", err)
+	}
 
-// 验证范围
-if startByte < 0 {
-startByte = 0
+	totalSize := info.Size
+	if startByte < 0 {
+		startByte = 0
+	}
+	if endByte <= 0 || endByte > totalSize {
+		endByte = totalSize
+	}
+	if startByte >= endByte {
+		return &FileRange{Start: startByte, End: endByte, TotalSize: totalSize},
+			fmt.Errorf("%w: %d-%d", ErrInvalidRange, startByte, endByte)
+	}
+
+	reader, err := backend.GetRange(ctx, key, startByte, endByte-startByte)
+	if err != nil {
+		return nil, fmt.Errorf("无法打开文件: %w", err)
+	}
+	return &FileRange{ReadCloser: reader, Start: startByte, End: endByte, TotalSize: totalSize}, nil
 }
-if endByte <= 0 || endByte > totalSize {
-endByte = totalSize
+
+// ErrRangeTooLarge 区间超过 MaxReadSize，需要缩小区间或改用流式读取
+var ErrRangeTooLarge = errors.New("读取区间过大")
+
+// ReadFileChunk 把文件的 [startByte, endByte) 区间读入内存，区间超过 MaxReadSize 时返回 ErrRangeTooLarge
+func ReadFileChunk(ctx context.Context, key string, startByte, endByte int64) ([]byte, int64, error) {
+	r, err := OpenFileRange(ctx, key, startByte, endByte)
+	if err != nil {
+		var totalSize int64
+		if r != nil {
+			totalSize = r.TotalSize
+		}
+		return nil, totalSize, err
+	}
+	defer r.Close()
+	if r.End-r.Start > MaxReadSize {
+		return nil, r.TotalSize, fmt.Errorf("%w: %d 字节，上限 %d", ErrRangeTooLarge, r.End-r.Start, MaxReadSize)
+	}
+
+	buffer := make([]byte, r.End-r.Start)
+	n, err := io.ReadFull(r, buffer)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, r.TotalSize, fmt.Errorf("读取文件失败: %w", err)
+	}
+
+	return buffer[:n], r.TotalSize, nil
 }
-if startByte >= endByte {
-return nil, totalSize, fmt.Errorf("无效的字节范围: %d-%d", startByte, endByte)
+
+// GetFileSize 获取文件大小
+func GetFileSize(key string) (int64, error) {
+	info, err := backend.Stat(context.Background(), key)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size, nil
 }
 
-// 打开文件
-file, err := os.Open(filePath)
-if err != nil {
-return nil, totalSize, fmt.Errorf("无法打开文件: %w", err)
+// DeleteObject 删除存储中的对象
+func DeleteObject(key string) error {
+	return backend.Delete(context.Background(), key)
 }
-defer file.Close()
 
-// 定位到起始位置
-if _, err := file.Seek(startByte, 0); err != nil {
-return nil, totalSize, fmt.Errorf("文件定位失败: %w", err)
+// FetchLocal 获取对象的本地可读路径（供 ffmpeg/ffprobe 使用）
+// 本地后端直接返回原路径；对象存储会下载到临时目录，调用方用完后必须调用 cleanup
+func FetchLocal(ctx context.Context, key string) (string, func(), error) {
+	if lp, ok := backend.(LocalPather); ok {
+		path := lp.LocalPath(key)
+		if _, err := os.Stat(path); err != nil {
+			return "", nil, err
+		}
+		return path, func() {}, nil
+	}
+
+	reader, err := backend.GetRange(ctx, key, 0, -1)
+	if err != nil {
+		return "", nil, err
+	}
+	defer reader.Close()
+
+	tmp, err := os.CreateTemp(filepath.Join(StoragePath, "tmp"), "fetch-*"+filepath.Ext(key))
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.Remove(tmp.Name()) }
+
+	if _, err := io.Copy(tmp, reader); err != nil {
+		tmp.Close()
+		cleanup()
+		return "", nil, fmt.Errorf("failed to download %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return tmp.Name(), cleanup, nil
 }
 
-// 读取指定范围的数据
-length := endByte - startByte
-buffer := make([]byte, length)
-n, err := io.ReadFull(file, buffer)
-if err != nil && err != io.ErrUnexpectedEOF {
-return nil, totalSize, fmt.Errorf("读取文件失败: %w", err)
+// NewLocalTempPath 在本地临时目录中分配一个输出路径（转码输出等）
+func NewLocalTempPath(pattern string) (string, error) {
+	tmp, err := os.CreateTemp(filepath.Join(StoragePath, "tmp"), pattern)
+	if err != nil {
+		return "", err
+	}
+	tmp.Close()
+	return tmp.Name(), nil
 }
 
-return buffer[:n], totalSize, nil
+// PutLocalFile 将本地文件写入存储，写入后本地文件不再保留
+func PutLocalFile(ctx context.Context, key string, localPath string) error {
+	if lb, ok := backend.(*LocalBackend); ok {
+		return lb.PutFile(ctx, key, localPath)
+	}
+
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(localPath)
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return backend.Put(ctx, key, f, info.Size())
 }
 
-// GetFileSize 获取文件大小
-func GetFileSize(fileHash, filename string) (int64, error) {
-filePath := GetFilePath(fileHash, filename)
-info, err := os.Stat(filePath)
-if err != nil {
-return 0, err
+// NewLocalTempDir 在本地临时目录中创建一个目录（HLS 打包等多文件输出），调用方用完后需删除
+func NewLocalTempDir(pattern string) (string, error) {
+	return os.MkdirTemp(filepath.Join(StoragePath, "tmp"), pattern)
 }
-return info.Size(), nil
+
+// DeletePrefix 删除指定前缀下的所有对象
+func DeletePrefix(ctx context.Context, prefix string) error {
+	objects, err := backend.List(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objects {
+		if err := backend.Delete(ctx, obj.Key); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,147 @@
+package transcode
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+)
+
+// dashManifestName DASH 清单文件名，与 HLS 主播放列表位于同一目录
+const dashManifestName = "manifest.mpd"
+
+// dashTimescale SegmentTimeline 的时间单位（毫秒）
+const dashTimescale = 1000
+
+type mpd struct {
+	XMLName                   xml.Name    `xml:"MPD"`
+	Xmlns                     string      `xml:"xmlns,attr"`
+	Profiles                  string      `xml:"profiles,attr"`
+	Type                      string      `xml:"type,attr"`
+	MediaPresentationDuration string      `xml:"mediaPresentationDuration,attr"`
+	MinBufferTime             string      `xml:"minBufferTime,attr"`
+	Periods                   []mpdPeriod `xml:"Period"`
+}
+
+type mpdPeriod struct {
+	ID             string             `xml:"id,attr"`
+	Start          string             `xml:"start,attr"`
+	AdaptationSets []mpdAdaptationSet `xml:"AdaptationSet"`
+}
+
+type mpdAdaptationSet struct {
+	ID              int                 `xml:"id,attr"`
+	MimeType        string              `xml:"mimeType,attr"`
+	StartWithSAP    int                 `xml:"startWithSAP,attr"`
+	Representations []mpdRepresentation `xml:"Representation"`
+}
+
+type mpdRepresentation struct {
+	ID          string         `xml:"id,attr"`
+	Bandwidth   int64          `xml:"bandwidth,attr"`
+	Width       int            `xml:"width,attr,omitempty"`
+	Height      int            `xml:"height,attr,omitempty"`
+	FrameRate   string         `xml:"frameRate,attr,omitempty"`
+	Codecs      string         `xml:"codecs,attr,omitempty"`
+	BaseURL     string         `xml:"BaseURL"`
+	SegmentList mpdSegmentList `xml:"SegmentList"`
+}
+
+type mpdSegmentList struct {
+	Timescale      int             `xml:"timescale,attr"`
+	Initialization mpdURL          `xml:"Initialization"`
+	Timeline       []mpdTimelineS  `xml:"SegmentTimeline>S"`
+	SegmentURLs    []mpdSegmentURL `xml:"SegmentURL"`
+}
+
+type mpdURL struct {
+	SourceURL string `xml:"sourceURL,attr"`
+}
+
+type mpdTimelineS struct {
+	T *int64 `xml:"t,attr"`
+	D int64  `xml:"d,attr"`
+	R int    `xml:"r,attr,omitempty"`
+}
+
+type mpdSegmentURL struct {
+	Media string `xml:"media,attr"`
+}
+
+// dashManifest 基于 HLS 的 fMP4 分片生成静态 DASH 清单。
+// 每个分辨率的分片同时包含音视频，对应一个复用的 Representation；
+// 各分辨率独立编码，关键帧不一定对齐，因此不声明 segmentAlignment
+func dashManifest(variants []hlsVariant) ([]byte, error) {
+	set := mpdAdaptationSet{ID: 0, MimeType: "video/mp4", StartWithSAP: 1}
+	var maxDuration, maxSegment float64
+	for _, v := range variants {
+		rep := mpdRepresentation{
+			ID:        v.name,
+			Bandwidth: v.bandwidth,
+			Width:     v.width,
+			Height:    v.height,
+			Codecs:    v.codecs,
+			BaseURL:   v.name + "/",
+			SegmentList: mpdSegmentList{
+				Timescale:      dashTimescale,
+				Initialization: mpdURL{SourceURL: hlsInitSegment},
+			},
+		}
+		if v.frameRate > 0 {
+			rep.FrameRate = dashFrameRate(v.frameRate)
+		}
+
+		var start, total int64
+		for i, seg := range v.segments {
+			d := int64(math.Round(seg.duration * dashTimescale))
+			rep.SegmentList.SegmentURLs = append(rep.SegmentList.SegmentURLs, mpdSegmentURL{Media: seg.uri})
+			// 连续相同时长的分片合并为一个 S 元素（r 为额外重复次数）
+			if n := len(rep.SegmentList.Timeline); i > 0 && rep.SegmentList.Timeline[n-1].D == d {
+				rep.SegmentList.Timeline[n-1].R++
+			} else {
+				t := start
+				rep.SegmentList.Timeline = append(rep.SegmentList.Timeline, mpdTimelineS{T: &t, D: d})
+			}
+			start += d
+			total += d
+			maxSegment = math.Max(maxSegment, seg.duration)
+		}
+		maxDuration = math.Max(maxDuration, float64(total)/dashTimescale)
+		set.Representations = append(set.Representations, rep)
+	}
+
+	doc := mpd{
+		Xmlns:                     "urn:mpeg:dash:schema:mpd:2011",
+		Profiles:                  "urn:mpeg:dash:profile:isoff-main:2011",
+		Type:                      "static",
+		MediaPresentationDuration: dashDuration(maxDuration),
+		MinBufferTime:             dashDuration(math.Ceil(maxSegment)),
+		Periods: []mpdPeriod{{
+			ID:             "0",
+			Start:          "PT0S",
+			AdaptationSets: []mpdAdaptationSet{set},
+		}},
+	}
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+// dashDuration 格式化为 xs:duration，例如 PT63.480S
+func dashDuration(seconds float64) string {
+	return fmt.Sprintf("PT%.3fS", seconds)
+}
+
+// dashFrameRate 帧率，常见的 NTSC 帧率使用分数形式
+func dashFrameRate(fps float64) string {
+	for _, base := range []float64{24, 30, 60} {
+		if math.Abs(fps-base*1000/1001) < 0.01 {
+			return fmt.Sprintf("%d/1001", int(base*1000))
+		}
+	}
+	if fps == math.Trunc(fps) {
+		return fmt.Sprintf("%d", int(fps))
+	}
+	return fmt.Sprintf("%.3f", fps)
+}
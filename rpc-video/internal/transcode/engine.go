@@ -0,0 +1,136 @@
+package transcode
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Job 单个分辨率的编码任务
+type Job struct {
+	SourcePath string
+	Media      *MediaInfo // 源视频的媒体信息，未知时为 nil
+	FileHash   string
+	TaskID     string
+	Profile    Profile
+}
+
+// OutputKey 编码输出在存储中的 key：files/<hash>_<task_id>_<name>.<ext>。
+// 按任务区分，同一文件的其他任务（其他用户或重新转码）不会覆盖或删除这个输出
+func (j Job) OutputKey() string {
+	return fmt.Sprintf("files/%s_%s_%s%s", j.FileHash, j.TaskID, j.Profile.Name, j.Profile.Ext())
+}
+
+// Output 编码输出及其在存储中的位置
+type Output struct {
+	URL        string `json:"url,omitempty"` // /files/<hash>_<task_id>_<name>.<ext>
+	StorageKey string `json:"storage_key,omitempty"`
+	Backend    string `json:"backend,omitempty"` // 写入时的存储后端
+	Size       int64  `json:"size,omitempty"`
+}
+
+// Transcoder 执行编码、打包以及码率分析、质量评估、截图等所有 ffmpeg 操作。默认实现调用 ffmpeg；
+// transcodetest 包提供按脚本输出进度与失败的实现，用于在没有 ffmpeg 的环境中运行工作者
+type Transcoder interface {
+	// Transcode 编码一个分辨率并写入存储，返回输出的地址与存储位置。
+	// onProgress 接收进度快照；返回 *FFmpegError 时其 Stderr 会作为任务的 last_stderr 保存
+	Transcode(ctx context.Context, job Job, onProgress func(Progress)) (Output, error)
+	// Package 将已完成的分辨率打包为自适应码率的播放清单，写入播放目录 version（见 NewStreamVersion）
+	Package(ctx context.Context, fileHash, version string, renditions []Rendition) (StreamURLs, error)
+	// ProbeBitrate 以参考质量快速编码采样片段，返回平均码率（bit/s），用于按内容复杂度计算码率阶梯
+	ProbeBitrate(ctx context.Context, job Job, segments []time.Duration) (int64, error)
+	// MeasureQuality 计算编码输出相对源视频的 PSNR、SSIM（及 VMAF）
+	MeasureQuality(ctx context.Context, job Job, out Output) (*QualityScores, error)
+	// ExtractFrame 截取 at 处的一帧写入 JPEG 文件 outputPath
+	ExtractFrame(ctx context.Context, sourcePath string, at time.Duration, outputPath string) error
+	// RenderSprites 每隔 interval 抽一帧，缩放为 width x height 后拼接为雪碧图，
+	// 按 SpriteName 的命名写入 dir，返回生成的张数
+	RenderSprites(ctx context.Context, sourcePath string, interval time.Duration, width, height int, dir string) (int, error)
+}
+
+// Prober 探测源文件的媒体信息
+type Prober interface {
+	Probe(filePath string) (*MediaInfo, error)
+	// ProbeStreams 获取文件中各个流的编码信息，用于生成 HLS 主播放列表的 CODECS 等属性
+	ProbeStreams(filePath string) ([]StreamInfo, error)
+}
+
+// 缩略图、合并与处理流水线不经过转码队列，使用这里的转码器与探测器；InitTranscodeManager 按配置替换
+var (
+	defaultTranscoder Transcoder = NewFFmpeg(Config{})
+	defaultProber     Prober     = FFprobe{}
+)
+
+// SetEngine 替换不经过转码队列的处理使用的转码器与探测器（测试或自定义初始化时使用），为 nil 的保持不变
+func SetEngine(t Transcoder, p Prober) {
+	if t != nil {
+		defaultTranscoder = t
+	}
+	if p != nil {
+		defaultProber = p
+	}
+}
+
+// Probe 使用当前的探测器探测媒体信息
+func Probe(filePath string) (*MediaInfo, error) {
+	return defaultProber.Probe(filePath)
+}
+
+// FFmpeg 基于 ffmpeg 的默认转码器
+type FFmpeg struct {
+	cfg    Config
+	prober Prober // 打包时读取各分辨率的流信息
+}
+
+// NewFFmpeg 创建 ffmpeg 转码器，cfg 决定 HLS 分片类型、分片时长以及是否生成 DASH，
+// cfg.Prober 为 nil 时使用 ffprobe
+func NewFFmpeg(cfg Config) *FFmpeg {
+	prober := cfg.Prober
+	if prober == nil {
+		prober = FFprobe{}
+	}
+	return &FFmpeg{cfg: cfg, prober: prober}
+}
+
+// Transcode 执行 ffmpeg 编码（两遍编码的配置执行两次）
+func (f *FFmpeg) Transcode(ctx context.Context, job Job, onProgress func(Progress)) (Output, error) {
+	return transcodeVideo(ctx, job.SourcePath, job.Media, job.OutputKey(), job.Profile, onProgress)
+}
+
+// Package 将各分辨率无损切片为 HLS（及 DASH）
+func (f *FFmpeg) Package(ctx context.Context, fileHash, version string, renditions []Rendition) (StreamURLs, error) {
+	return f.packageStreams(ctx, fileHash, version, renditions)
+}
+
+// ProbeBitrate 以最快的预设在采样片段上做 CRF 编码
+func (f *FFmpeg) ProbeBitrate(ctx context.Context, job Job, segments []time.Duration) (int64, error) {
+	return probeBitrate(ctx, job.SourcePath, job.Media, job.Profile, segments)
+}
+
+// MeasureQuality 使用 psnr、ssim（及 libvmaf）滤镜比较输出与源视频
+func (f *FFmpeg) MeasureQuality(ctx context.Context, job Job, out Output) (*QualityScores, error) {
+	return measureQuality(ctx, job.SourcePath, job.Media, job.Profile, out)
+}
+
+// ExtractFrame 截取一帧，宽度不超过 posterMaxWidth
+func (f *FFmpeg) ExtractFrame(ctx context.Context, sourcePath string, at time.Duration, outputPath string) error {
+	return extractFrame(ctx, sourcePath, at, outputPath)
+}
+
+// RenderSprites 使用 fps、scale、tile 滤镜生成雪碧图
+func (f *FFmpeg) RenderSprites(ctx context.Context, sourcePath string, interval time.Duration, width, height int, dir string) (int, error) {
+	return renderSprites(ctx, sourcePath, interval, width, height, dir)
+}
+
+// FFprobe 基于 ffprobe 的默认探测器，ffprobe 未安装时 MP4/MOV 改用内置解析器
+type FFprobe struct{}
+
+// Probe 探测媒体信息
+func (FFprobe) Probe(filePath string) (*MediaInfo, error) {
+	return ProbeMedia(filePath)
+}
+
+// ProbeStreams 获取各个流的编码信息
+func (FFprobe) ProbeStreams(filePath string) ([]StreamInfo, error) {
+	return probeStreams(filePath)
+}
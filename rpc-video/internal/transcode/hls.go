@@ -0,0 +1,490 @@
+package transcode
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"video-platform-microservice/rpc-video/internal/storage"
+)
+
+// HLS 分片类型
+const (
+	HLSSegmentFMP4   = "fmp4"
+	HLSSegmentMPEGTS = "mpegts"
+)
+
+const (
+	streamsPrefix     = "streams/"    // 播放文件在存储中的前缀：streams/<file_hash>/<version>/...
+	hlsMasterPlaylist = "master.m3u8" // 主播放列表
+	hlsMediaPlaylist  = "index.m3u8"  // 每个分辨率的媒体播放列表
+	hlsInitSegment    = "init.mp4"    // fMP4 初始化分片
+)
+
+// StreamKey 播放文件在存储中的 key，name 为相对于视频播放目录的路径
+func StreamKey(fileHash, name string) string {
+	return streamsPrefix + fileHash + "/" + name
+}
+
+// PlaybackURL 播放文件的访问地址（由网关的 /api/video/play 提供）
+func PlaybackURL(fileHash, name string) string {
+	return "/api/video/play/" + fileHash + "/" + name
+}
+
+// NewStreamVersion 为一次打包分配播放目录。同一文件的每个任务、每次打包写入各自的目录，
+// 文件记录切换到新的播放地址之后才删除旧目录，正在播放的清单和分片不会被覆盖或提前删除
+func NewStreamVersion(taskID string) string {
+	return taskID + "-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+}
+
+// StreamVersion 返回播放地址所在的播放目录；早期直接写在 streams/<file_hash>/ 下、
+// 由所有用户共享的播放文件返回空
+func StreamVersion(fileHash, playbackURL string) string {
+	rest, ok := strings.CutPrefix(playbackURL, PlaybackURL(fileHash, ""))
+	if !ok {
+		return ""
+	}
+	version, _, ok := strings.Cut(rest, "/")
+	if !ok {
+		return ""
+	}
+	return version
+}
+
+// PlaybackAccessible 播放文件是否属于 playbackURL 所在的播放目录，用户只能读取自己当前的播放文件
+func PlaybackAccessible(fileHash, playbackURL, name string) bool {
+	version := StreamVersion(fileHash, playbackURL)
+	return version == "" || strings.HasPrefix(name, version+"/")
+}
+
+// DeleteStreamVersion 删除一个播放目录；version 为空（早期共享的播放文件）时不做任何操作
+func DeleteStreamVersion(ctx context.Context, fileHash, version string) error {
+	if version == "" {
+		return nil
+	}
+	return storage.DeletePrefix(ctx, StreamKey(fileHash, version+"/"))
+}
+
+// Rendition 已完成的分辨率输出
+type Rendition struct {
+	Profile Profile
+	Output
+}
+
+// hlsSegment 媒体播放列表中的一个分片
+type hlsSegment struct {
+	uri      string
+	duration float64 // 秒
+	size     int64
+}
+
+// hlsVariant 主播放列表中的一个分辨率
+type hlsVariant struct {
+	name             string
+	bandwidth        int64 // 峰值码率（最大的单个分片）
+	averageBandwidth int64
+	width, height    int
+	frameRate        float64
+	codecs           string
+	segments         []hlsSegment
+}
+
+// StreamURLs 打包结果的访问地址
+type StreamURLs struct {
+	HLS  string // HLS 主播放列表
+	DASH string // DASH 清单，未启用时为空
+}
+
+// packageStreams 将已完成的分辨率无损切片为 HLS，生成主播放列表；启用 DASH 时基于同一组 fMP4 分片生成 .mpd。
+// 输出写入播放目录 version，不能用所选分片类型封装的分辨率会被跳过
+func (f *FFmpeg) packageStreams(ctx context.Context, fileHash, version string, outputs []Rendition) (StreamURLs, error) {
+	var urls StreamURLs
+	workDir, err := storage.NewLocalTempDir("hls-*")
+	if err != nil {
+		return urls, fmt.Errorf("创建临时目录失败: %v", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	variants := []hlsVariant{}
+	for _, out := range outputs {
+		if reason := hlsIncompatible(out.Profile, f.cfg.HLSSegmentType); reason != "" {
+			log.Printf("⚠️ 跳过 HLS 打包 %s: %s", out.Profile.Name, reason)
+			continue
+		}
+		variant, err := f.packageRendition(ctx, workDir, out)
+		if err != nil {
+			return urls, err
+		}
+		variants = append(variants, *variant)
+	}
+	if len(variants) == 0 {
+		return urls, fmt.Errorf("没有可以打包为 HLS 的分辨率")
+	}
+
+	sort.Slice(variants, func(i, j int) bool { return variants[i].bandwidth < variants[j].bandwidth })
+	manifests := []string{hlsMasterPlaylist}
+	if err := os.WriteFile(filepath.Join(workDir, hlsMasterPlaylist), []byte(masterPlaylist(variants)), 0644); err != nil {
+		return urls, err
+	}
+	if f.cfg.DASH {
+		mpd, err := dashManifest(variants)
+		if err != nil {
+			return urls, fmt.Errorf("生成 DASH 清单失败: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(workDir, dashManifestName), mpd, 0644); err != nil {
+			return urls, err
+		}
+		manifests = append(manifests, dashManifestName)
+	}
+
+	// 主播放列表与 DASH 清单最后上传，播放器不会看到引用了未上传分片的清单
+	err = filepath.Walk(workDir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Dir(p) == workDir {
+			return err
+		}
+		rel, _ := filepath.Rel(workDir, p)
+		return storage.PutLocalFile(ctx, StreamKey(fileHash, version+"/"+filepath.ToSlash(rel)), p)
+	})
+	if err != nil {
+		return urls, fmt.Errorf("上传 HLS 文件失败: %v", err)
+	}
+	for _, name := range manifests {
+		if err := storage.PutLocalFile(ctx, StreamKey(fileHash, version+"/"+name), filepath.Join(workDir, name)); err != nil {
+			return urls, fmt.Errorf("上传 %s 失败: %v", name, err)
+		}
+	}
+
+	urls.HLS = PlaybackURL(fileHash, version+"/"+hlsMasterPlaylist)
+	if f.cfg.DASH {
+		urls.DASH = PlaybackURL(fileHash, version+"/"+dashManifestName)
+	}
+	return urls, nil
+}
+
+// packageRendition 将单个分辨率切片到 workDir/<name>/
+func (f *FFmpeg) packageRendition(ctx context.Context, workDir string, out Rendition) (*hlsVariant, error) {
+	key, err := storage.Resolve(out.StorageKey, out.Backend)
+	if err != nil {
+		return nil, fmt.Errorf("读取转码结果失败: %s: %w", out.URL, err)
+	}
+	sourcePath, cleanup, err := storage.FetchLocal(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("读取转码结果失败: %s: %w", out.URL, err)
+	}
+	defer cleanup()
+
+	dir := filepath.Join(workDir, out.Profile.Name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	segmentExt := ".m4s"
+	args := []string{
+		"-i", sourcePath,
+		"-map", "0:v:0", "-map", "0:a:0?",
+		"-c", "copy",
+	}
+	if out.Profile.Codec == CodecH265 {
+		args = append(args, "-tag:v", "hvc1")
+	}
+	args = append(args,
+		"-f", "hls",
+		"-hls_time", strconv.Itoa(f.cfg.HLSSegmentSeconds),
+		"-hls_playlist_type", "vod",
+		"-hls_flags", "independent_segments",
+		"-hls_segment_type", f.cfg.HLSSegmentType,
+	)
+	if f.cfg.HLSSegmentType == HLSSegmentFMP4 {
+		args = append(args, "-hls_fmp4_init_filename", hlsInitSegment)
+	} else {
+		segmentExt = ".ts"
+	}
+	args = append(args,
+		"-hls_segment_filename", filepath.Join(dir, "seg_%05d"+segmentExt),
+		"-progress", "pipe:1",
+		"-nostats",
+		"-y",
+		filepath.Join(dir, hlsMediaPlaylist),
+	)
+	if err := runFFmpeg(ctx, args, func(Progress) {}); err != nil {
+		return nil, fmt.Errorf("HLS 切片失败 (%s): %w", out.Profile.Name, err)
+	}
+
+	variant := &hlsVariant{name: out.Profile.Name}
+	variant.segments, err = readMediaPlaylist(dir)
+	if err != nil {
+		return nil, err
+	}
+	variant.bandwidth, variant.averageBandwidth = segmentBandwidth(variant.segments)
+
+	streams, err := f.prober.ProbeStreams(sourcePath)
+	if err != nil {
+		return nil, err
+	}
+	codecs := []string{}
+	for _, s := range streams {
+		switch s.CodecType {
+		case "video":
+			if variant.width == 0 {
+				variant.width, variant.height = s.Width, s.Height
+				variant.frameRate = s.frameRate()
+				codecs = append(codecs, s.codecsAttr())
+			}
+		case "audio":
+			if len(codecs) < 2 {
+				codecs = append(codecs, s.codecsAttr())
+			}
+		}
+	}
+	variant.codecs = strings.Join(codecs, ",")
+	return variant, nil
+}
+
+// hlsIncompatible 返回不能用指定分片类型封装的原因，可以封装时返回空
+func hlsIncompatible(p Profile, segmentType string) string {
+	if segmentType == HLSSegmentMPEGTS {
+		if p.Codec != CodecH264 && p.Codec != CodecH265 {
+			return fmt.Sprintf("TS 分片不支持 %s 视频", p.Codec)
+		}
+		if p.AudioCodec == AudioOpus {
+			return "TS 分片不支持 opus 音频"
+		}
+	}
+	return ""
+}
+
+// masterPlaylist 生成主播放列表
+func masterPlaylist(variants []hlsVariant) string {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:7\n#EXT-X-INDEPENDENT-SEGMENTS\n")
+	for _, v := range variants {
+		attrs := []string{
+			fmt.Sprintf("BANDWIDTH=%d", v.bandwidth),
+			fmt.Sprintf("AVERAGE-BANDWIDTH=%d", v.averageBandwidth),
+		}
+		if v.width > 0 && v.height > 0 {
+			attrs = append(attrs, fmt.Sprintf("RESOLUTION=%dx%d", v.width, v.height))
+		}
+		if v.frameRate > 0 {
+			attrs = append(attrs, fmt.Sprintf("FRAME-RATE=%.3f", v.frameRate))
+		}
+		if v.codecs != "" {
+			attrs = append(attrs, fmt.Sprintf("CODECS=%q", v.codecs))
+		}
+		fmt.Fprintf(&b, "#EXT-X-STREAM-INF:%s\n%s\n", strings.Join(attrs, ","), path.Join(v.name, hlsMediaPlaylist))
+	}
+	return b.String()
+}
+
+// readMediaPlaylist 读取媒体播放列表中每个分片的时长和大小
+func readMediaPlaylist(dir string) ([]hlsSegment, error) {
+	f, err := os.Open(filepath.Join(dir, hlsMediaPlaylist))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var segments []hlsSegment
+	var duration float64
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#EXTINF:") {
+			value, _, _ := strings.Cut(strings.TrimPrefix(line, "#EXTINF:"), ",")
+			duration, _ = strconv.ParseFloat(value, 64)
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") || duration <= 0 {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(dir, line))
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, hlsSegment{uri: line, duration: duration, size: info.Size()})
+		duration = 0
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("媒体播放列表中没有分片")
+	}
+	return segments, nil
+}
+
+// segmentBandwidth 计算峰值（最大的单个分片）与平均码率（bit/s）
+func segmentBandwidth(segments []hlsSegment) (peak, average int64) {
+	var totalBytes int64
+	var totalSeconds float64
+	for _, seg := range segments {
+		if bps := int64(float64(seg.size*8) / seg.duration); bps > peak {
+			peak = bps
+		}
+		totalBytes += seg.size
+		totalSeconds += seg.duration
+	}
+	if totalSeconds > 0 {
+		average = int64(float64(totalBytes*8) / totalSeconds)
+	}
+	return peak, average
+}
+
+// StreamInfo ffprobe 输出的流信息
+type StreamInfo struct {
+	CodecType    string `json:"codec_type"`
+	CodecName    string `json:"codec_name"`
+	Profile      string `json:"profile"`
+	Level        int    `json:"level"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	PixFmt       string `json:"pix_fmt"`
+	AvgFrameRate string `json:"avg_frame_rate"`
+}
+
+// probeStreams 获取文件中的音视频流信息
+func probeStreams(filePath string) ([]StreamInfo, error) {
+	if _, err := exec.LookPath("ffprobe"); err != nil {
+		return nil, fmt.Errorf("ffprobe 未安装")
+	}
+	output, err := exec.Command("ffprobe",
+		"-v", "error",
+		"-show_entries", "stream=codec_type,codec_name,profile,level,width,height,pix_fmt,avg_frame_rate",
+		"-of", "json",
+		filePath,
+	).Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe 执行失败: %v", err)
+	}
+	var result struct {
+		Streams []StreamInfo `json:"streams"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("无法解析 ffprobe 输出: %v", err)
+	}
+	return result.Streams, nil
+}
+
+// frameRate 解析 "30000/1001" 形式的帧率
+func (s StreamInfo) frameRate() float64 {
+	num, den, ok := strings.Cut(s.AvgFrameRate, "/")
+	n, _ := strconv.ParseFloat(num, 64)
+	if !ok {
+		return n
+	}
+	d, _ := strconv.ParseFloat(den, 64)
+	if d == 0 {
+		return 0
+	}
+	return n / d
+}
+
+// codecsAttr 生成 RFC 6381 形式的 CODECS 属性值
+func (s StreamInfo) codecsAttr() string {
+	bitDepth := 8
+	if strings.Contains(s.PixFmt, "10") {
+		bitDepth = 10
+	}
+	switch s.CodecName {
+	case "h264":
+		profile := map[string]string{
+			"Constrained Baseline": "42E0",
+			"Baseline":             "4200",
+			"Main":                 "4D40",
+			"High":                 "6400",
+			"High 10":              "6E00",
+			"High 4:2:2":           "7A00",
+		}[s.Profile]
+		if profile == "" {
+			profile = "6400"
+		}
+		level := s.Level
+		if level <= 0 {
+			level = levelForHeight(s.Height, 30, 31, 40, 50, 51)
+		}
+		return fmt.Sprintf("avc1.%s%02X", profile, level)
+	case "hevc":
+		level := s.Level
+		if level <= 0 {
+			level = levelForHeight(s.Height, 90, 93, 120, 150, 153)
+		}
+		if s.Profile == "Main 10" {
+			return fmt.Sprintf("hvc1.2.4.L%d.B0", level)
+		}
+		return fmt.Sprintf("hvc1.1.6.L%d.B0", level)
+	case "av1":
+		level := s.Level
+		if level < 0 {
+			level = levelForHeight(s.Height, 4, 5, 8, 12, 13)
+		}
+		return fmt.Sprintf("av01.0.%02dM.%02d", level, bitDepth)
+	case "vp9":
+		// ffprobe 通常无法给出 vp9 的 level，按分辨率估算
+		return fmt.Sprintf("vp09.00.%02d.%02d", levelForHeight(s.Height, 21, 30, 31, 40, 50), bitDepth)
+	case "aac":
+		switch s.Profile {
+		case "HE-AAC":
+			return "mp4a.40.5"
+		case "HE-AACv2":
+			return "mp4a.40.29"
+		}
+		return "mp4a.40.2"
+	case "mp3":
+		return "mp4a.40.34"
+	case "opus":
+		return "Opus"
+	case "ac3":
+		return "ac-3"
+	case "eac3":
+		return "ec-3"
+	}
+	return s.CodecName
+}
+
+// levelForHeight 按分辨率估算编码 level：<=360p, <=480p, <=720p, <=1080p, <=1440p；更高时使用最后一个
+func levelForHeight(height int, levels ...int) int {
+	bounds := []int{360, 480, 720, 1080, 1440}
+	for i, bound := range bounds {
+		if height <= bound && i < len(levels) {
+			return levels[i]
+		}
+	}
+	return levels[len(levels)-1]
+}
+
+// playbackContentTypes 播放文件的 Content-Type
+var playbackContentTypes = map[string]string{
+	".m3u8": "application/vnd.apple.mpegurl",
+	".mpd":  "application/dash+xml",
+	".m4s":  "video/iso.segment",
+	".mp4":  "video/mp4",
+	".ts":   "video/mp2t",
+}
+
+// PlaybackContentType 根据扩展名返回播放文件的 Content-Type，不是播放文件时返回空
+func PlaybackContentType(name string) string {
+	return playbackContentTypes[strings.ToLower(path.Ext(name))]
+}
+
+// ValidPlaybackPath 校验播放文件路径：只允许播放目录内的相对路径
+func ValidPlaybackPath(name string) bool {
+	if name == "" || strings.HasPrefix(name, "/") || path.Clean(name) != name || strings.Contains(name, "..") {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_-./", r)) {
+			return false
+		}
+	}
+	return PlaybackContentType(name) != ""
+}
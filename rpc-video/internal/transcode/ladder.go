@@ -0,0 +1,113 @@
+package transcode
+
+import "fmt"
+
+// 缩放模式：目标宽高比与源不一致时的处理方式
+const (
+	FitScale = "fit"  // 等比缩放到目标尺寸以内，输出可能小于目标尺寸
+	FitPad   = "pad"  // 等比缩放后加黑边补齐到目标尺寸
+	FitCrop  = "crop" // 等比缩放填满目标尺寸，裁掉超出的部分
+)
+
+// RenditionSkipped 分辨率高于源视频，未转码
+const RenditionSkipped = "skipped"
+
+// targetBox 目标尺寸。配置按横屏定义（如 1280x720），竖屏源交换宽高，使 "720p" 始终表示短边 720。
+// 方向按旋转后的显示尺寸判断；ffmpeg 默认按旋转信息自动旋转画面，滤镜处理的也是显示方向的画面
+func (p Profile) targetBox(media *MediaInfo) (width, height int32, portrait bool) {
+	width, height = p.Width, p.Height
+	if media == nil {
+		return width, height, false
+	}
+	w, h := media.DisplaySize()
+	if h > w && width > height {
+		return height, width, true
+	}
+	if h > w && width == 0 {
+		return 0, height, true
+	}
+	return width, height, false
+}
+
+// shortSide 目标尺寸的短边，宽度为 0 时为高度
+func (p Profile) shortSide() int32 {
+	if p.Width > 0 && p.Width < p.Height {
+		return p.Width
+	}
+	return p.Height
+}
+
+// scaleFilter 缩放滤镜：保持宽高比，按 FitMode 补边或裁剪；输出尺寸保持偶数（yuv420p 的要求）
+func (p Profile) scaleFilter(media *MediaInfo) string {
+	width, height, portrait := p.targetBox(media)
+	if width == 0 {
+		if portrait {
+			return fmt.Sprintf("scale=%d:-2", height)
+		}
+		return fmt.Sprintf("scale=-2:%d", height)
+	}
+	switch p.FitMode {
+	case FitPad:
+		return fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=decrease:force_divisible_by=2,"+
+			"pad=%d:%d:(ow-iw)/2:(oh-ih)/2:color=black,setsar=1", width, height, width, height)
+	case FitCrop:
+		return fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=increase,"+
+			"crop=%d:%d,setsar=1", width, height, width, height)
+	default:
+		return fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=decrease:force_divisible_by=2,setsar=1", width, height)
+	}
+}
+
+// ladderStep 一个编码配置的转码计划
+type ladderStep struct {
+	profile Profile
+	skip    string // 跳过原因，为空表示需要转码
+	note    string // 对配置所做调整的说明
+}
+
+// planLadder 按源视频尺寸规划要转码的分辨率。
+// 高于源视频短边的分辨率不做放大；所有分辨率都高于源视频时，保留最低的一个并按源分辨率输出。
+// 源视频尺寸未知时全部转码
+func planLadder(profiles []Profile, media *MediaInfo) []ladderStep {
+	steps := make([]ladderStep, len(profiles))
+	for i, p := range profiles {
+		steps[i].profile = p
+	}
+	if media == nil || !media.HasVideo() {
+		return steps
+	}
+	width, height := media.DisplaySize()
+	if width <= 0 || height <= 0 {
+		return steps
+	}
+	sourceShort := width
+	if height < sourceShort {
+		sourceShort = height
+	}
+
+	lowest := -1
+	for i := range steps {
+		p := steps[i].profile
+		if p.shortSide() <= sourceShort {
+			continue
+		}
+		steps[i].skip = fmt.Sprintf("源视频 %dx%d 低于目标分辨率 %s，不放大", width, height, p.Name)
+		if lowest < 0 || p.shortSide() < steps[lowest].profile.shortSide() {
+			lowest = i
+		}
+	}
+	for _, step := range steps {
+		if step.skip == "" {
+			return steps
+		}
+	}
+
+	// 所有分辨率都高于源视频：最低的一个按源分辨率等比输出
+	if lowest >= 0 {
+		step := &steps[lowest]
+		step.profile.Width, step.profile.Height, step.profile.FitMode = 0, sourceShort&^1, FitScale
+		step.skip = ""
+		step.note = fmt.Sprintf("源视频 %dx%d 低于所有目标分辨率，按源分辨率输出", width, height)
+	}
+	return steps
+}
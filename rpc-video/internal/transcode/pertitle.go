@@ -0,0 +1,203 @@
+package transcode
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"video-platform-microservice/rpc-video/internal/storage"
+)
+
+// 按内容复杂度分析（per-title）的参数
+const (
+	probeSegmentLength = 4 * time.Second  // 每个采样片段的时长
+	probeSegmentSpan   = 10 * time.Second // 每 10 秒视频取一个片段
+	maxProbeSegments   = 5                // 最多采样片段数
+
+	ladderMinRatio  = 0.3 // 码率下限：配置码率的 30%，避免极简单的画面码率过低
+	ladderMaxRatio  = 1.5 // 码率上限：配置码率的 150%，复杂画面最多提高一半
+	ladderPeakRatio = 1.5 // VBR 峰值码率相对目标码率的倍数
+)
+
+// probeCRF 各编码器的参考质量参数（大致对应同一主观质量），采样编码在该质量下的码率即内容需要的码率
+var probeCRF = map[string]int{
+	CodecH264: 23,
+	CodecH265: 28,
+	CodecVP9:  33,
+	CodecAV1:  35,
+}
+
+// LadderRung 按内容复杂度计算出的一个分辨率的码率
+type LadderRung struct {
+	Name            string `json:"name"`
+	Height          int32  `json:"height"`
+	ProbeBitrate    int64  `json:"probe_bitrate"`     // 采样编码在参考质量下的平均码率（bit/s）
+	DefaultBitrate  string `json:"default_bitrate"`   // 配置中的固定码率
+	VideoBitrate    string `json:"video_bitrate"`     // 实际使用的目标码率
+	MaxBitrate      string `json:"max_bitrate"`       // 实际使用的峰值码率，cbr 为空
+	SkippedAnalysis string `json:"skipped,omitempty"` // 未分析的原因，此时使用配置码率
+}
+
+// ladderRungFor 按名称查找分析结果
+func ladderRungFor(ladder []LadderRung, name string) (LadderRung, bool) {
+	for _, r := range ladder {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return LadderRung{}, false
+}
+
+// apply 用分析得到的码率替换配置中的固定码率
+func (r LadderRung) apply(p Profile) Profile {
+	if r.SkippedAnalysis != "" || r.VideoBitrate == "" {
+		return p
+	}
+	p.VideoBitrate = r.VideoBitrate
+	if p.RateControl != RateCBR {
+		p.MaxBitrate = r.MaxBitrate
+	}
+	return p
+}
+
+// probeSegments 在视频中均匀选取采样片段的起始时间
+func probeSegments(duration time.Duration) []time.Duration {
+	if duration <= probeSegmentLength {
+		return []time.Duration{0}
+	}
+	n := int(duration / probeSegmentSpan)
+	if n < 1 {
+		n = 1
+	}
+	if n > maxProbeSegments {
+		n = maxProbeSegments
+	}
+	starts := make([]time.Duration, n)
+	for i := range starts {
+		// 片段中心均匀分布在 (i+0.5)/n 处
+		start := time.Duration((float64(i)+0.5)/float64(n)*float64(duration)) - probeSegmentLength/2
+		if start < 0 {
+			start = 0
+		}
+		if start+probeSegmentLength > duration {
+			start = duration - probeSegmentLength
+		}
+		starts[i] = start
+	}
+	return starts
+}
+
+// analyzeLadder 对每个需要转码的分辨率在采样片段上做快速 CRF 编码，按得到的码率计算码率阶梯。
+// 只调整 cbr/vbr/two_pass 配置的码率；crf 配置本身就按质量编码
+func analyzeLadder(ctx context.Context, transcoder Transcoder, sourcePath string, media *MediaInfo, steps []ladderStep) ([]LadderRung, error) {
+	if media == nil || media.Duration <= 0 {
+		return nil, fmt.Errorf("源视频时长未知，无法分析")
+	}
+	segments := probeSegments(media.Duration)
+
+	ladder := make([]LadderRung, 0, len(steps))
+	for _, step := range steps {
+		p := step.profile
+		if step.skip != "" {
+			continue
+		}
+		rung := LadderRung{Name: p.Name, Height: p.Height, DefaultBitrate: p.VideoBitrate}
+		if p.RateControl == RateCRF {
+			rung.SkippedAnalysis = "crf 配置按质量编码"
+			ladder = append(ladder, rung)
+			continue
+		}
+
+		measured, err := transcoder.ProbeBitrate(ctx, Job{SourcePath: sourcePath, Media: media, Profile: p}, segments)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			log.Printf("⚠️ 码率分析失败 (%s)，使用配置码率: %v", p.Name, err)
+			rung.SkippedAnalysis = "采样编码失败"
+			ladder = append(ladder, rung)
+			continue
+		}
+
+		base, _ := parseBitrate(p.VideoBitrate)
+		target := math.Min(math.Max(float64(measured), float64(base)*ladderMinRatio), float64(base)*ladderMaxRatio)
+		rung.ProbeBitrate = measured
+		rung.VideoBitrate = formatKbps(target)
+		if p.RateControl != RateCBR {
+			rung.MaxBitrate = formatKbps(target * ladderPeakRatio)
+		}
+		ladder = append(ladder, rung)
+		log.Printf("码率分析 %s: 采样 %d kbps，目标 %s（配置 %s）", p.Name, measured/1000, rung.VideoBitrate, p.VideoBitrate)
+	}
+	return ladder, nil
+}
+
+// probeBitrate 以快速预设和参考质量编码各采样片段，返回平均码率（bit/s）
+func probeBitrate(ctx context.Context, sourcePath string, media *MediaInfo, p Profile, segments []time.Duration) (int64, error) {
+	var totalBits float64
+	var totalSeconds float64
+	for _, start := range segments {
+		length := probeSegmentLength
+		if start+length > media.Duration {
+			length = media.Duration - start
+		}
+		outputPath, err := storage.NewLocalTempPath("probe-*.mkv")
+		if err != nil {
+			return 0, err
+		}
+		args := []string{
+			"-ss", strconv.FormatFloat(start.Seconds(), 'f', 3, 64),
+			"-t", strconv.FormatFloat(length.Seconds(), 'f', 3, 64),
+			"-i", sourcePath,
+			"-vf", p.videoFilter(media),
+		}
+		args = append(args, p.probeVideoArgs()...)
+		args = append(args, "-an", "-progress", "pipe:1", "-nostats", "-y", outputPath)
+		err = runFFmpeg(ctx, args, func(Progress) {})
+		info, statErr := os.Stat(outputPath)
+		os.Remove(outputPath)
+		if err != nil {
+			return 0, err
+		}
+		if statErr != nil {
+			return 0, statErr
+		}
+		totalBits += float64(info.Size() * 8)
+		totalSeconds += length.Seconds()
+	}
+	if totalSeconds <= 0 || totalBits <= 0 {
+		return 0, fmt.Errorf("采样编码没有输出")
+	}
+	return int64(totalBits / totalSeconds), nil
+}
+
+// probeVideoArgs 采样编码参数：与正式编码相同的编码器，最快的预设，固定参考质量。
+// 快速预设在同一质量下码率偏高，得到的码率偏保守
+func (p Profile) probeVideoArgs() []string {
+	crf := strconv.Itoa(probeCRF[p.Codec])
+	args := []string{"-c:v", p.encoder(), "-pix_fmt", "yuv420p", "-crf", crf}
+	switch p.Codec {
+	case CodecH264:
+		args = append(args, "-preset", "veryfast")
+	case CodecH265:
+		args = append(args, "-preset", "ultrafast", "-x265-params", "log-level=error")
+	case CodecVP9:
+		args = append(args, "-b:v", "0", "-deadline", "realtime", "-cpu-used", "8", "-row-mt", "1")
+	case CodecAV1:
+		args = append(args, "-preset", "12")
+	}
+	return args
+}
+
+// formatKbps 格式化为 ffmpeg 码率参数，按 50k 取整
+func formatKbps(bps float64) string {
+	kbps := int64(math.Round(bps/1000/50)) * 50
+	if kbps < 50 {
+		kbps = 50
+	}
+	return strconv.FormatInt(kbps, 10) + "k"
+}
@@ -0,0 +1,367 @@
+package transcode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"video-platform-microservice/rpc-video/internal/db"
+	"video-platform-microservice/rpc-video/internal/mp4"
+)
+
+// ErrProbeUnavailable ffprobe 未安装，无法探测媒体信息
+var ErrProbeUnavailable = errors.New("ffprobe 未安装")
+
+// HDR 格式
+const (
+	HDRFormatHDR10       = "hdr10"
+	HDRFormatHLG         = "hlg"
+	HDRFormatDolbyVision = "dolby_vision"
+)
+
+// MediaInfo 媒体探测结果
+type MediaInfo struct {
+	Duration        time.Duration
+	Container       string // mp4, mov, mkv, webm ...
+	VideoCodec      string // 第一个视频流的编码，没有视频流时为空
+	AudioCodec      string // 第一个音频流的编码，没有音频流时为空
+	Bitrate         int64  // 总码率（bit/s）
+	Width           int32  // 编码尺寸，未考虑旋转
+	Height          int32
+	FrameRate       float64
+	Rotation        int32 // 播放时需顺时针旋转的角度：0, 90, 180, 270
+	AudioChannels   int32
+	AudioSampleRate int32
+	HDR             bool
+	HDRFormat       string
+	StreamCount     int32
+	Faststart       bool // MP4/MOV 的 moov 位于 mdat 之前，可以边下载边播放；其他封装格式为 false
+}
+
+// HasVideo 是否包含视频流
+func (m *MediaInfo) HasVideo() bool {
+	return m.VideoCodec != ""
+}
+
+// HasAudio 是否包含音频流
+func (m *MediaInfo) HasAudio() bool {
+	return m.AudioCodec != ""
+}
+
+// DisplaySize 旋转后的显示尺寸
+func (m *MediaInfo) DisplaySize() (int32, int32) {
+	if m.Rotation == 90 || m.Rotation == 270 {
+		return m.Height, m.Width
+	}
+	return m.Width, m.Height
+}
+
+// ToDB 转换为 video_files 中的媒体信息列
+func (m *MediaInfo) ToDB() db.MediaMetadata {
+	return db.MediaMetadata{
+		DurationMs:      m.Duration.Milliseconds(),
+		Container:       m.Container,
+		VideoCodec:      m.VideoCodec,
+		AudioCodec:      m.AudioCodec,
+		Bitrate:         m.Bitrate,
+		FrameRate:       m.FrameRate,
+		Rotation:        m.Rotation,
+		AudioChannels:   m.AudioChannels,
+		AudioSampleRate: m.AudioSampleRate,
+		HDR:             m.HDR,
+		HDRFormat:       m.HDRFormat,
+		StreamCount:     m.StreamCount,
+		Faststart:       m.Faststart,
+	}
+}
+
+// MediaInfoFromDB 读取文件记录中的媒体信息；文件尚未探测过时返回 nil
+func MediaInfoFromDB(file *db.File) *MediaInfo {
+	if file.Media.StreamCount == 0 {
+		return nil
+	}
+	return &MediaInfo{
+		Duration:        time.Duration(file.Media.DurationMs) * time.Millisecond,
+		Container:       file.Media.Container,
+		VideoCodec:      file.Media.VideoCodec,
+		AudioCodec:      file.Media.AudioCodec,
+		Bitrate:         file.Media.Bitrate,
+		Width:           file.Width,
+		Height:          file.Height,
+		FrameRate:       file.Media.FrameRate,
+		Rotation:        file.Media.Rotation,
+		AudioChannels:   file.Media.AudioChannels,
+		AudioSampleRate: file.Media.AudioSampleRate,
+		HDR:             file.Media.HDR,
+		HDRFormat:       file.Media.HDRFormat,
+		StreamCount:     file.Media.StreamCount,
+		Faststart:       file.Media.Faststart,
+	}
+}
+
+// probeOutput ffprobe -show_format -show_streams 的 JSON 输出
+type probeOutput struct {
+	Format struct {
+		FormatName string            `json:"format_name"`
+		Duration   string            `json:"duration"`
+		BitRate    string            `json:"bit_rate"`
+		NbStreams  int32             `json:"nb_streams"`
+		Tags       map[string]string `json:"tags"`
+	} `json:"format"`
+	Streams []struct {
+		CodecType     string            `json:"codec_type"`
+		CodecName     string            `json:"codec_name"`
+		Width         int32             `json:"width"`
+		Height        int32             `json:"height"`
+		AvgFrameRate  string            `json:"avg_frame_rate"`
+		RFrameRate    string            `json:"r_frame_rate"`
+		Channels      int32             `json:"channels"`
+		SampleRate    string            `json:"sample_rate"`
+		ColorTransfer string            `json:"color_transfer"`
+		Tags          map[string]string `json:"tags"`
+		SideDataList  []probeSideData   `json:"side_data_list"`
+		Disposition   struct {
+			AttachedPic int `json:"attached_pic"`
+		} `json:"disposition"`
+	} `json:"streams"`
+}
+
+type probeSideData struct {
+	SideDataType string  `json:"side_data_type"`
+	Rotation     float64 `json:"rotation"`
+}
+
+// ProbeMedia 使用 ffprobe 探测媒体信息；ffprobe 未安装时 MP4/MOV 文件改用内置解析器
+func ProbeMedia(filePath string) (*MediaInfo, error) {
+	if _, err := exec.LookPath("ffprobe"); err != nil {
+		return probeMP4(filePath)
+	}
+	output, err := exec.Command("ffprobe",
+		"-v", "error",
+		"-show_format",
+		"-show_streams",
+		"-of", "json",
+		filePath,
+	).Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe 执行失败: %v", err)
+	}
+	var probe probeOutput
+	if err := json.Unmarshal(output, &probe); err != nil {
+		return nil, fmt.Errorf("无法解析 ffprobe 输出: %v", err)
+	}
+
+	info := &MediaInfo{StreamCount: probe.Format.NbStreams}
+	if seconds, err := strconv.ParseFloat(probe.Format.Duration, 64); err == nil {
+		info.Duration = time.Duration(seconds * float64(time.Second))
+	}
+	info.Bitrate, _ = strconv.ParseInt(probe.Format.BitRate, 10, 64)
+	if info.StreamCount == 0 {
+		info.StreamCount = int32(len(probe.Streams))
+	}
+
+	for _, s := range probe.Streams {
+		switch s.CodecType {
+		case "video":
+			// 内嵌的封面图（attached_pic）也是视频流，以第一个真正的视频流为准
+			if info.HasVideo() || s.Disposition.AttachedPic == 1 {
+				continue
+			}
+			info.VideoCodec = s.CodecName
+			info.Width, info.Height = s.Width, s.Height
+			info.FrameRate = parseFrameRate(s.AvgFrameRate)
+			if info.FrameRate == 0 {
+				info.FrameRate = parseFrameRate(s.RFrameRate)
+			}
+			info.Rotation = streamRotation(s.Tags["rotate"], s.SideDataList)
+			switch s.ColorTransfer {
+			case "smpte2084":
+				info.HDR, info.HDRFormat = true, HDRFormatHDR10
+			case "arib-std-b67":
+				info.HDR, info.HDRFormat = true, HDRFormatHLG
+			}
+			for _, sd := range s.SideDataList {
+				if sd.SideDataType == "DOVI configuration record" {
+					info.HDR, info.HDRFormat = true, HDRFormatDolbyVision
+				}
+			}
+		case "audio":
+			if info.HasAudio() {
+				continue
+			}
+			info.AudioCodec = s.CodecName
+			info.AudioChannels = s.Channels
+			if rate, err := strconv.Atoi(s.SampleRate); err == nil {
+				info.AudioSampleRate = int32(rate)
+			}
+		}
+	}
+	info.Container = containerName(probe.Format.FormatName, probe.Format.Tags["major_brand"], info)
+	// ffprobe 不输出 moov 的位置，MP4/MOV 用内置解析器读取
+	if info.Container == "mp4" || info.Container == "mov" {
+		if parsed, err := mp4.ParseFile(filePath); err == nil {
+			info.Faststart = parsed.Faststart
+		}
+	}
+
+	if info.StreamCount == 0 {
+		return nil, fmt.Errorf("没有可识别的音视频流")
+	}
+	return info, nil
+}
+
+// parseFrameRate 解析 "30000/1001" 形式的帧率
+func parseFrameRate(s string) float64 {
+	var num, den float64
+	if n, _ := fmt.Sscanf(s, "%g/%g", &num, &den); n != 2 || den == 0 {
+		return 0
+	}
+	return math.Round(num/den*1000) / 1000
+}
+
+// streamRotation 播放时需顺时针旋转的角度。
+// 旧版 ffprobe 输出 rotate 标签（顺时针）；新版输出显示矩阵的 rotation（逆时针）
+func streamRotation(rotateTag string, sideData []probeSideData) int32 {
+	degrees := 0
+	if rotateTag != "" {
+		degrees, _ = strconv.Atoi(rotateTag)
+	} else {
+		for _, sd := range sideData {
+			if sd.SideDataType == "Display Matrix" {
+				degrees = -int(math.Round(sd.Rotation))
+			}
+		}
+	}
+	// 只支持 90 度的倍数
+	degrees = ((degrees % 360) + 360) % 360 / 90 * 90
+	return int32(degrees)
+}
+
+// containerName 把 ffprobe 的 format_name（如 "mov,mp4,m4a,3gp,3g2,mj2"）归一为常见的封装名称
+func containerName(formatName, majorBrand string, info *MediaInfo) string {
+	switch {
+	case strings.HasPrefix(formatName, "mov,mp4"):
+		if strings.TrimSpace(majorBrand) == "qt" {
+			return "mov"
+		}
+		return "mp4"
+	case formatName == "matroska,webm":
+		switch info.VideoCodec {
+		case "vp8", "vp9", "av1", "":
+			if info.AudioCodec == "" || info.AudioCodec == "opus" || info.AudioCodec == "vorbis" {
+				return "webm"
+			}
+		}
+		return "mkv"
+	}
+	name, _, _ := strings.Cut(formatName, ",")
+	return name
+}
+
+// sourceContentTypes 源文件容器（及扩展名）对应的 Content-Type
+var sourceContentTypes = map[string]string{
+	"mp4":  "video/mp4",
+	"m4v":  "video/mp4",
+	"mov":  "video/quicktime",
+	"mkv":  "video/x-matroska",
+	"webm": "video/webm",
+	"avi":  "video/x-msvideo",
+	"flv":  "video/x-flv",
+	"ts":   "video/mp2t",
+	"3gp":  "video/3gpp",
+	"wmv":  "video/x-ms-wmv",
+}
+
+// SourceContentType 源文件的 Content-Type：优先使用探测到的容器，未探测时按扩展名判断
+func SourceContentType(filename, container string) string {
+	if contentType, ok := sourceContentTypes[container]; ok {
+		return contentType
+	}
+	if contentType, ok := sourceContentTypes[strings.TrimPrefix(strings.ToLower(path.Ext(filename)), ".")]; ok {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// fourCCCodecs 常见样本描述 FourCC 对应的 ffprobe 编码名称
+var fourCCCodecs = map[string]string{
+	"avc1": "h264", "avc3": "h264",
+	"hvc1": "hevc", "hev1": "hevc",
+	"dvh1": "hevc", "dvhe": "hevc",
+	"av01": "av1",
+	"vp08": "vp8", "vp09": "vp9",
+	"mp4v": "mpeg4",
+	"apch": "prores", "apcn": "prores", "apcs": "prores", "apco": "prores", "ap4h": "prores",
+	"mp4a": "aac",
+	"Opus": "opus",
+	"fLaC": "flac",
+	"ac-3": "ac3", "ec-3": "eac3",
+	".mp3": "mp3",
+	"alac": "alac",
+	"lpcm": "pcm", "sowt": "pcm_s16le", "twos": "pcm_s16be",
+}
+
+// probeMP4 用内置的 ISO-BMFF 解析器探测 MP4/MOV 文件，得不到 ffprobe 的码率细节时按 mdat 大小估算
+func probeMP4(filePath string) (*MediaInfo, error) {
+	parsed, err := mp4.ParseFile(filePath)
+	if errors.Is(err, mp4.ErrNotMP4) {
+		return nil, fmt.Errorf("%w，且不是 MP4/MOV 文件", ErrProbeUnavailable)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析 MP4 失败: %w", err)
+	}
+
+	info := &MediaInfo{
+		Duration:    parsed.Duration,
+		Container:   "mp4",
+		StreamCount: int32(len(parsed.Tracks)),
+		Faststart:   parsed.Faststart,
+	}
+	if strings.TrimSpace(parsed.MajorBrand) == "qt" {
+		info.Container = "mov"
+	}
+	if v := parsed.Video(); v != nil {
+		info.VideoCodec = fourCCCodec(v.FourCC)
+		info.Width, info.Height = int32(v.Width), int32(v.Height)
+		info.FrameRate = v.FrameRate
+		info.Rotation = int32(v.Rotation)
+		switch v.Transfer {
+		case 16: // SMPTE ST 2084 (PQ)
+			info.HDR, info.HDRFormat = true, HDRFormatHDR10
+		case 18: // ARIB STD-B67 (HLG)
+			info.HDR, info.HDRFormat = true, HDRFormatHLG
+		}
+		if v.FourCC == "dvh1" || v.FourCC == "dvhe" {
+			info.HDR, info.HDRFormat = true, HDRFormatDolbyVision
+		}
+		if info.Duration == 0 {
+			info.Duration = v.Duration
+		}
+	}
+	if a := parsed.Audio(); a != nil {
+		info.AudioCodec = fourCCCodec(a.FourCC)
+		info.AudioChannels = int32(a.Channels)
+		info.AudioSampleRate = int32(a.SampleRate)
+	}
+	if info.Duration > 0 {
+		info.Bitrate = int64(float64(parsed.MdatSize*8) / info.Duration.Seconds())
+	}
+	if info.StreamCount == 0 {
+		return nil, fmt.Errorf("没有可识别的音视频流")
+	}
+	return info, nil
+}
+
+// fourCCCodec 未知的 FourCC 原样返回
+func fourCCCodec(fourCC string) string {
+	if codec, ok := fourCCCodecs[fourCC]; ok {
+		return codec
+	}
+	return strings.TrimSpace(fourCC)
+}
@@ -0,0 +1,58 @@
+package transcode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// mp4Box 拼接一个 box
+func mp4Box(typ string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	return append(append(binary.BigEndian.AppendUint32(nil, uint32(8+len(body))), typ...), body...)
+}
+
+// testMP4 只有一个 mp4a 音频轨道的最小 MP4，moovFirst 决定 moov 与 mdat 的顺序
+func testMP4(moovFirst bool) []byte {
+	u32 := func(v uint32) []byte { return binary.BigEndian.AppendUint32(nil, v) }
+	mdhd := mp4Box("mdhd", make([]byte, 12), u32(48000), u32(480000), make([]byte, 4))
+	hdlr := mp4Box("hdlr", make([]byte, 8), []byte("soun"), make([]byte, 13))
+	// AudioSampleEntry：channelcount 位于第 16 字节，16.16 的采样率位于第 24 字节
+	entry := make([]byte, 28)
+	binary.BigEndian.PutUint16(entry[16:], 2)
+	binary.BigEndian.PutUint32(entry[24:], 48000<<16)
+	stsd := mp4Box("stsd", make([]byte, 4), u32(1), mp4Box("mp4a", entry))
+	trak := mp4Box("trak", mp4Box("mdia", mdhd, hdlr, mp4Box("minf", mp4Box("stbl", stsd))))
+	moov := mp4Box("moov", mp4Box("mvhd", make([]byte, 12), u32(1000), u32(10000), make([]byte, 80)), trak)
+	mdat := mp4Box("mdat", make([]byte, 1024))
+
+	ftyp := mp4Box("ftyp", []byte("M4A "), u32(0))
+	if moovFirst {
+		return bytes.Join([][]byte{ftyp, moov, mdat}, nil)
+	}
+	return bytes.Join([][]byte{ftyp, mdat, moov}, nil)
+}
+
+func TestProbeMP4Faststart(t *testing.T) {
+	for _, moovFirst := range []bool{true, false} {
+		path := filepath.Join(t.TempDir(), "audio.mp4")
+		if err := os.WriteFile(path, testMP4(moovFirst), 0644); err != nil {
+			t.Fatal(err)
+		}
+		info, err := probeMP4(path)
+		if err != nil {
+			t.Fatalf("probeMP4: %v", err)
+		}
+		if info.Faststart != moovFirst {
+			t.Errorf("moov first = %v: Faststart = %v", moovFirst, info.Faststart)
+		}
+		if info.AudioCodec != "aac" || info.AudioChannels != 2 || info.AudioSampleRate != 48000 {
+			t.Errorf("audio = %s %d ch %d Hz", info.AudioCodec, info.AudioChannels, info.AudioSampleRate)
+		}
+		if got := info.ToDB(); got.Faststart != moovFirst {
+			t.Errorf("ToDB().Faststart = %v, want %v", got.Faststart, moovFirst)
+		}
+	}
+}
@@ -0,0 +1,519 @@
+package transcode
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"video-platform-microservice/rpc-video/internal/db"
+)
+
+// 视频编码
+const (
+	CodecH264 = "h264"
+	CodecH265 = "h265"
+	CodecVP9  = "vp9"
+	CodecAV1  = "av1"
+)
+
+// 码率控制
+const (
+	RateCBR     = "cbr"
+	RateVBR     = "vbr"
+	RateCRF     = "crf"
+	RateTwoPass = "two_pass"
+)
+
+// 封装格式
+const (
+	ContainerMP4  = "mp4"
+	ContainerWebM = "webm"
+	ContainerMKV  = "mkv"
+)
+
+// 音频编码
+const (
+	AudioAAC  = "aac"
+	AudioOpus = "opus"
+	AudioNone = "none"
+)
+
+// ErrUnknownProfile 编码配置不存在
+var ErrUnknownProfile = errors.New("不支持的编码配置")
+
+// keyframeSeconds 关键帧间隔（秒）。HLS 只能在关键帧处切片，偶数的 HLS_SEGMENT_SECONDS 可以精确对齐
+const keyframeSeconds = 2
+
+// hdrToSDRFilter 把 HDR（PQ/HLG）色调映射到 SDR BT.709；所有配置都输出 8 位 yuv420p，不做映射画面会发灰
+const hdrToSDRFilter = "zscale=t=linear:npl=100,format=gbrpf32le,zscale=p=bt709," +
+	"tonemap=tonemap=hable:desat=0,zscale=t=bt709:m=bt709:r=tv,format=yuv420p"
+
+// Profile 编码配置。任务创建时保存快照，之后修改配置不影响已创建的任务
+type Profile struct {
+	Name            string `json:"name"`
+	Codec           string `json:"codec"`
+	RateControl     string `json:"rate_control"`
+	Container       string `json:"container"`
+	Width           int32  `json:"width"` // 0 表示按高度等比缩放
+	Height          int32  `json:"height"`
+	FitMode         string `json:"fit_mode,omitempty"` // fit, pad, crop；宽度为 0 时只能是 fit
+	VideoBitrate    string `json:"video_bitrate,omitempty"`
+	MaxBitrate      string `json:"max_bitrate,omitempty"`
+	CRF             int32  `json:"crf,omitempty"`
+	Preset          string `json:"preset,omitempty"`
+	AudioCodec      string `json:"audio_codec"`
+	AudioBitrate    string `json:"audio_bitrate,omitempty"`
+	AudioChannels   int32  `json:"audio_channels,omitempty"`
+	AudioSampleRate int32  `json:"audio_sample_rate,omitempty"`
+	BuiltIn         bool   `json:"builtin"`
+}
+
+// builtinProfiles 内置配置，与原来的分辨率名称保持一致
+var builtinProfiles = []Profile{
+	{Name: "1080p", Codec: CodecH264, RateControl: RateVBR, Container: ContainerMP4, Width: 1920, Height: 1080, VideoBitrate: "5000k", AudioCodec: AudioAAC, BuiltIn: true},
+	{Name: "720p", Codec: CodecH264, RateControl: RateVBR, Container: ContainerMP4, Width: 1280, Height: 720, VideoBitrate: "2500k", AudioCodec: AudioAAC, BuiltIn: true},
+	{Name: "480p", Codec: CodecH264, RateControl: RateVBR, Container: ContainerMP4, Width: 854, Height: 480, VideoBitrate: "1000k", AudioCodec: AudioAAC, BuiltIn: true},
+	{Name: "360p", Codec: CodecH264, RateControl: RateVBR, Container: ContainerMP4, Width: 640, Height: 360, VideoBitrate: "500k", AudioCodec: AudioAAC, BuiltIn: true},
+}
+
+var (
+	profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`) // 名称会出现在输出文件名中
+	bitratePattern     = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)([kKmM]?)$`)
+
+	x26xPresets = map[string]bool{
+		"ultrafast": true, "superfast": true, "veryfast": true, "faster": true, "fast": true,
+		"medium": true, "slow": true, "slower": true, "veryslow": true, "placebo": true,
+	}
+	vp9Deadlines = map[string]bool{"good": true, "best": true, "realtime": true}
+	opusRates    = map[int32]bool{8000: true, 12000: true, 16000: true, 24000: true, 48000: true}
+
+	// 封装格式支持的编码
+	containerVideo = map[string]map[string]bool{
+		ContainerMP4:  {CodecH264: true, CodecH265: true, CodecVP9: true, CodecAV1: true},
+		ContainerWebM: {CodecVP9: true, CodecAV1: true},
+		ContainerMKV:  {CodecH264: true, CodecH265: true, CodecVP9: true, CodecAV1: true},
+	}
+	containerAudio = map[string]map[string]bool{
+		ContainerMP4:  {AudioAAC: true, AudioOpus: true, AudioNone: true},
+		ContainerWebM: {AudioOpus: true, AudioNone: true},
+		ContainerMKV:  {AudioAAC: true, AudioOpus: true, AudioNone: true},
+	}
+)
+
+// BuiltinProfiles 返回内置配置
+func BuiltinProfiles() []Profile {
+	return append([]Profile(nil), builtinProfiles...)
+}
+
+// EnsureBuiltinProfiles 将内置配置写入 transcode_profiles（已存在的同名配置保持不变）
+func EnsureBuiltinProfiles() error {
+	profiles := make([]db.TranscodeProfile, 0, len(builtinProfiles))
+	for _, p := range builtinProfiles {
+		profiles = append(profiles, *p.ToDB())
+	}
+	return db.EnsureTranscodeProfiles(profiles)
+}
+
+// ResolveProfiles 按名称查找编码配置：优先使用数据库中的配置，其次是内置配置
+func ResolveProfiles(names []string) ([]Profile, error) {
+	profiles := make([]Profile, 0, len(names))
+	for _, name := range names {
+		record, err := db.GetTranscodeProfile(name)
+		if err != nil {
+			return nil, err
+		}
+		if record != nil {
+			profiles = append(profiles, ProfileFromDB(record))
+			continue
+		}
+		builtin, ok := builtinProfile(name)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownProfile, name)
+		}
+		profiles = append(profiles, builtin)
+	}
+	return profiles, nil
+}
+
+func builtinProfile(name string) (Profile, bool) {
+	for _, p := range builtinProfiles {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Profile{}, false
+}
+
+// ProfileFromDB 数据库记录转换为编码配置
+func ProfileFromDB(r *db.TranscodeProfile) Profile {
+	return Profile{
+		Name:            r.Name,
+		Codec:           r.Codec,
+		RateControl:     r.RateControl,
+		Container:       r.Container,
+		Width:           r.Width,
+		Height:          r.Height,
+		FitMode:         r.FitMode,
+		VideoBitrate:    r.VideoBitrate,
+		MaxBitrate:      r.MaxBitrate,
+		CRF:             r.CRF,
+		Preset:          r.Preset,
+		AudioCodec:      r.AudioCodec,
+		AudioBitrate:    r.AudioBitrate,
+		AudioChannels:   r.AudioChannels,
+		AudioSampleRate: r.AudioSampleRate,
+		BuiltIn:         r.BuiltIn,
+	}
+}
+
+// ToDB 编码配置转换为数据库记录
+func (p Profile) ToDB() *db.TranscodeProfile {
+	return &db.TranscodeProfile{
+		Name:            p.Name,
+		Codec:           p.Codec,
+		RateControl:     p.RateControl,
+		Container:       p.Container,
+		Width:           p.Width,
+		Height:          p.Height,
+		FitMode:         p.FitMode,
+		VideoBitrate:    p.VideoBitrate,
+		MaxBitrate:      p.MaxBitrate,
+		CRF:             p.CRF,
+		Preset:          p.Preset,
+		AudioCodec:      p.AudioCodec,
+		AudioBitrate:    p.AudioBitrate,
+		AudioChannels:   p.AudioChannels,
+		AudioSampleRate: p.AudioSampleRate,
+		BuiltIn:         p.BuiltIn,
+	}
+}
+
+// Normalize 统一大小写并填充默认值
+func (p *Profile) Normalize() {
+	p.Codec = strings.ToLower(strings.TrimSpace(p.Codec))
+	p.RateControl = strings.ToLower(strings.TrimSpace(p.RateControl))
+	p.Container = strings.ToLower(strings.TrimSpace(p.Container))
+	p.AudioCodec = strings.ToLower(strings.TrimSpace(p.AudioCodec))
+	p.Preset = strings.TrimSpace(p.Preset)
+	p.FitMode = strings.ToLower(strings.TrimSpace(p.FitMode))
+	if p.FitMode == "" {
+		p.FitMode = FitScale
+	}
+	switch p.Codec {
+	case "h.264", "avc", "x264":
+		p.Codec = CodecH264
+	case "h.265", "hevc", "x265":
+		p.Codec = CodecH265
+	}
+	if p.RateControl == "two-pass" || p.RateControl == "2pass" {
+		p.RateControl = RateTwoPass
+	}
+	if p.Container == "" {
+		p.Container = ContainerMP4
+	}
+	if p.AudioCodec == "" {
+		p.AudioCodec = AudioAAC
+		if p.Container == ContainerWebM {
+			p.AudioCodec = AudioOpus
+		}
+	}
+}
+
+// Validate 校验配置是否可以生成有效的 ffmpeg 参数
+func (p *Profile) Validate() error {
+	if !profileNamePattern.MatchString(p.Name) {
+		return fmt.Errorf("名称只能包含字母、数字、下划线和连字符，且不超过 64 个字符")
+	}
+
+	switch p.Codec {
+	case CodecH264, CodecH265, CodecVP9, CodecAV1:
+	default:
+		return fmt.Errorf("不支持的视频编码: %s", p.Codec)
+	}
+	videoCodecs, ok := containerVideo[p.Container]
+	if !ok {
+		return fmt.Errorf("不支持的封装格式: %s", p.Container)
+	}
+	if !videoCodecs[p.Codec] {
+		return fmt.Errorf("%s 不能封装 %s 视频", p.Container, p.Codec)
+	}
+	if !containerAudio[p.Container][p.AudioCodec] {
+		return fmt.Errorf("%s 不能封装 %s 音频", p.Container, p.AudioCodec)
+	}
+
+	if p.Height <= 0 || p.Height > 8192 || p.Height%2 != 0 {
+		return fmt.Errorf("高度必须是 2-8192 之间的偶数")
+	}
+	if p.Width < 0 || p.Width > 8192 || p.Width%2 != 0 {
+		return fmt.Errorf("宽度必须为 0 或 2-8192 之间的偶数")
+	}
+	switch p.FitMode {
+	case FitScale:
+	case FitPad, FitCrop:
+		if p.Width == 0 {
+			return fmt.Errorf("%s 模式需要指定宽度", p.FitMode)
+		}
+	default:
+		return fmt.Errorf("不支持的缩放模式: %s", p.FitMode)
+	}
+
+	switch p.RateControl {
+	case RateCBR, RateVBR, RateTwoPass:
+		if _, err := parseBitrate(p.VideoBitrate); err != nil {
+			return fmt.Errorf("%s 模式需要有效的 video_bitrate: %v", p.RateControl, err)
+		}
+	case RateCRF:
+		maxCRF := int32(63)
+		if p.Codec == CodecH264 || p.Codec == CodecH265 {
+			maxCRF = 51
+		}
+		if p.CRF < 0 || p.CRF > maxCRF {
+			return fmt.Errorf("%s 的 crf 取值范围为 0-%d", p.Codec, maxCRF)
+		}
+	default:
+		return fmt.Errorf("不支持的码率控制: %s", p.RateControl)
+	}
+	if p.RateControl == RateTwoPass && p.Codec == CodecAV1 {
+		return fmt.Errorf("av1 不支持 two_pass，请使用 crf 或 vbr")
+	}
+	if p.MaxBitrate != "" {
+		if p.RateControl == RateCBR {
+			return fmt.Errorf("cbr 模式不需要 max_bitrate")
+		}
+		if _, err := parseBitrate(p.MaxBitrate); err != nil {
+			return fmt.Errorf("max_bitrate 无效: %v", err)
+		}
+	}
+
+	if p.Preset != "" {
+		switch p.Codec {
+		case CodecH264, CodecH265:
+			if !x26xPresets[p.Preset] {
+				return fmt.Errorf("%s 不支持预设 %s", p.Codec, p.Preset)
+			}
+		case CodecVP9:
+			if !vp9Deadlines[p.Preset] {
+				return fmt.Errorf("vp9 的预设只能是 good、best 或 realtime")
+			}
+		case CodecAV1:
+			if n, err := strconv.Atoi(p.Preset); err != nil || n < 0 || n > 13 {
+				return fmt.Errorf("av1 的预设只能是 0-13")
+			}
+		}
+	}
+
+	if p.AudioCodec != AudioNone {
+		if p.AudioBitrate != "" {
+			if _, err := parseBitrate(p.AudioBitrate); err != nil {
+				return fmt.Errorf("audio_bitrate 无效: %v", err)
+			}
+		}
+		if p.AudioChannels < 0 || p.AudioChannels > 8 {
+			return fmt.Errorf("声道数必须为 0-8")
+		}
+		if p.AudioCodec == AudioOpus && p.AudioSampleRate != 0 && !opusRates[p.AudioSampleRate] {
+			return fmt.Errorf("opus 不支持采样率 %d", p.AudioSampleRate)
+		}
+		if p.AudioSampleRate < 0 || p.AudioSampleRate > 192000 {
+			return fmt.Errorf("采样率无效: %d", p.AudioSampleRate)
+		}
+	}
+	return nil
+}
+
+// Ext 输出文件扩展名
+func (p Profile) Ext() string {
+	return "." + p.Container
+}
+
+// passes 编码遍数
+func (p Profile) passes() int {
+	if p.RateControl == RateTwoPass {
+		return 2
+	}
+	return 1
+}
+
+// encoder ffmpeg 编码器名称
+func (p Profile) encoder() string {
+	switch p.Codec {
+	case CodecH265:
+		return "libx265"
+	case CodecVP9:
+		return "libvpx-vp9"
+	case CodecAV1:
+		return "libsvtav1"
+	default:
+		return "libx264"
+	}
+}
+
+// videoFilter 视频滤镜链：HDR 源先做色调映射，再缩放
+func (p Profile) videoFilter(media *MediaInfo) string {
+	if media != nil && media.HDR {
+		return hdrToSDRFilter + "," + p.scaleFilter(media)
+	}
+	return p.scaleFilter(media)
+}
+
+// keyframeArgs 按源帧率固定关键帧间隔，保证各分辨率的切片边界一致
+func keyframeArgs(media *MediaInfo) []string {
+	args := []string{"-force_key_frames", fmt.Sprintf("expr:gte(t,n_forced*%d)", keyframeSeconds)}
+	if media != nil && media.FrameRate > 0 {
+		gop := strconv.Itoa(int(math.Round(media.FrameRate * keyframeSeconds)))
+		args = append(args, "-g", gop, "-keyint_min", gop)
+	}
+	return args
+}
+
+// videoArgs 视频编码参数；pass 为 0 表示单遍编码，passLog 为两遍编码的统计文件前缀
+func (p Profile) videoArgs(pass int, passLog string) []string {
+	args := []string{"-c:v", p.encoder(), "-pix_fmt", "yuv420p"}
+
+	switch p.Codec {
+	case CodecH264, CodecH265:
+		if p.Preset != "" {
+			args = append(args, "-preset", p.Preset)
+		}
+	case CodecVP9:
+		if p.Preset != "" {
+			args = append(args, "-deadline", p.Preset)
+		}
+		args = append(args, "-row-mt", "1")
+	case CodecAV1:
+		if p.Preset != "" {
+			args = append(args, "-preset", p.Preset)
+		}
+	}
+
+	switch p.RateControl {
+	case RateCBR:
+		bps, _ := parseBitrate(p.VideoBitrate)
+		args = append(args,
+			"-b:v", p.VideoBitrate,
+			"-minrate", p.VideoBitrate,
+			"-maxrate", p.VideoBitrate,
+			"-bufsize", strconv.FormatInt(bps*2, 10),
+		)
+	case RateCRF:
+		args = append(args, "-crf", strconv.Itoa(int(p.CRF)))
+		if p.Codec == CodecVP9 {
+			// libvpx-vp9 的 crf 需要配合 -b:v：0 为恒定质量，非 0 为受限质量
+			maxRate := "0"
+			if p.MaxBitrate != "" {
+				maxRate = p.MaxBitrate
+			}
+			args = append(args, "-b:v", maxRate)
+		} else {
+			args = append(args, p.maxRateArgs()...)
+		}
+	default: // vbr, two_pass
+		args = append(args, "-b:v", p.VideoBitrate)
+		args = append(args, p.maxRateArgs()...)
+	}
+
+	if pass > 0 {
+		if p.Codec == CodecH265 {
+			args = append(args, "-x265-params", fmt.Sprintf("pass=%d:stats=%s.x265", pass, passLog))
+		} else {
+			args = append(args, "-pass", strconv.Itoa(pass), "-passlogfile", passLog)
+		}
+	}
+
+	if p.Codec == CodecH265 && p.Container == ContainerMP4 {
+		args = append(args, "-tag:v", "hvc1") // Apple 设备只识别 hvc1
+	}
+	return args
+}
+
+func (p Profile) maxRateArgs() []string {
+	if p.MaxBitrate == "" {
+		return nil
+	}
+	bps, _ := parseBitrate(p.MaxBitrate)
+	return []string{"-maxrate", p.MaxBitrate, "-bufsize", strconv.FormatInt(bps*2, 10)}
+}
+
+// audioArgs 音频编码参数；源没有音频流时不输出音频，声道数不超过源
+func (p Profile) audioArgs(media *MediaInfo) []string {
+	if p.AudioCodec == AudioNone || media != nil && !media.HasAudio() {
+		return []string{"-an"}
+	}
+	encoder := "aac"
+	if p.AudioCodec == AudioOpus {
+		encoder = "libopus"
+	}
+	args := []string{"-c:a", encoder}
+	if p.AudioBitrate != "" {
+		args = append(args, "-b:a", p.AudioBitrate)
+	}
+	if channels := p.AudioChannels; channels > 0 {
+		if media != nil && media.AudioChannels > 0 && media.AudioChannels < channels {
+			channels = media.AudioChannels
+		}
+		args = append(args, "-ac", strconv.Itoa(int(channels)))
+	}
+	if p.AudioSampleRate > 0 {
+		args = append(args, "-ar", strconv.Itoa(int(p.AudioSampleRate)))
+	}
+	return args
+}
+
+// containerArgs 封装参数
+func (p Profile) containerArgs() []string {
+	if p.Container == ContainerMP4 {
+		return []string{"-movflags", "+faststart"}
+	}
+	return nil
+}
+
+// ffmpegArgs 构建第 pass 遍（从 1 开始）的 ffmpeg 参数；两遍编码的第一遍只分析，不输出文件。
+// media 为源视频的媒体信息，未知时为 nil
+func (p Profile) ffmpegArgs(sourcePath, outputPath string, pass int, passLog string, media *MediaInfo) []string {
+	if p.passes() == 1 {
+		pass = 0
+	}
+	args := []string{"-i", sourcePath, "-vf", p.videoFilter(media)}
+	args = append(args, p.videoArgs(pass, passLog)...)
+	args = append(args, keyframeArgs(media)...)
+	if pass == 1 {
+		args = append(args, "-an", "-f", "null")
+		outputPath = os.DevNull
+	} else {
+		args = append(args, p.audioArgs(media)...)
+		args = append(args, p.containerArgs()...)
+	}
+	args = append(args,
+		"-progress", "pipe:1", // 机器可读的进度输出到 stdout
+		"-nostats",
+		"-y", // 覆盖输出文件
+		outputPath,
+	)
+	return args
+}
+
+// parseBitrate 解析 "2500k"、"5M"、"800000" 形式的码率，返回 bit/s
+func parseBitrate(s string) (int64, error) {
+	m := bitratePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, fmt.Errorf("无法解析码率: %q", s)
+	}
+	value, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, err
+	}
+	switch strings.ToLower(m[2]) {
+	case "k":
+		value *= 1000
+	case "m":
+		value *= 1000 * 1000
+	}
+	if value <= 0 {
+		return 0, fmt.Errorf("码率必须大于 0")
+	}
+	return int64(value), nil
+}
@@ -0,0 +1,295 @@
+package transcode
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"video-platform-microservice/rpc-video/internal/db"
+)
+
+// 分辨率（单个输出）状态
+const (
+	RenditionPending    = "pending"
+	RenditionProcessing = "processing"
+	RenditionRetrying   = "retrying"
+	RenditionCompleted  = "completed"
+	RenditionFailed     = "failed"
+)
+
+// progressInterval 转码进度写入数据库的最小间隔
+const progressInterval = time.Second
+
+// Progress ffmpeg -progress 输出的一次快照
+type Progress struct {
+	OutTime time.Duration // 已编码的媒体时长
+	Speed   float64       // 编码速度（相对实时的倍数），未知时为 0
+	Done    bool          // progress=end
+	Pass    int           // 两遍编码时的当前遍数（从 1 开始），单遍编码为 0
+	Passes  int
+}
+
+// parseProgress 逐行读取 ffmpeg -progress 的 key=value 输出，每个块结束（progress=continue/end）时回调
+func parseProgress(r io.Reader, onProgress func(Progress)) error {
+	var p Progress
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "out_time_us", "out_time_ms": // 旧版本 ffmpeg 的 out_time_ms 实际单位也是微秒
+			if us, err := strconv.ParseInt(value, 10, 64); err == nil && us >= 0 {
+				p.OutTime = time.Duration(us) * time.Microsecond
+			}
+		case "speed":
+			if speed, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(value), "x"), 64); err == nil {
+				p.Speed = speed
+			}
+		case "progress":
+			p.Done = value == "end"
+			onProgress(p)
+		}
+	}
+	return scanner.Err()
+}
+
+// tailBuffer 只保留最后 max 字节的输出（用于记录 ffmpeg 的 stderr）
+type tailBuffer struct {
+	max int
+	buf []byte
+}
+
+func newTailBuffer(max int) *tailBuffer {
+	return &tailBuffer{max: max}
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > t.max {
+		t.buf = t.buf[len(t.buf)-t.max:]
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	return string(t.buf)
+}
+
+// RenditionStatus 单个分辨率的转码状态
+type RenditionStatus struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	Progress   int32   `json:"progress"`
+	Speed      float64 `json:"speed"`
+	ETASeconds int64   `json:"eta_seconds"`
+	Error      string  `json:"error,omitempty"`
+	Reason     string  `json:"reason,omitempty"` // 跳过原因，或按源分辨率输出等调整说明
+	Attempts   int32   `json:"attempts"`
+	Output             // 输出地址与存储位置
+
+	Quality      *QualityScores `json:"quality,omitempty"`
+	QualityError string         `json:"quality_error,omitempty"`
+}
+
+// progressTracker 汇总各分辨率的进度，计算整体进度与 ETA 并节流写入数据库
+type progressTracker struct {
+	mu         sync.Mutex
+	taskID     string
+	workerID   string
+	duration   time.Duration // 源视频时长，未知时为 0
+	renditions []RenditionStatus
+	fraction   []float64 // 各分辨率已完成的比例 0-1
+	speed      float64
+	lastStderr string
+	lastFlush  time.Time
+
+	// save 写入整体进度与其他列，默认通过 db.UpdateTranscodeTaskProgress 写入（任务已被取消或回收时不再写入）
+	save func(progress int32, updates map[string]interface{})
+}
+
+func newProgressTracker(taskID, workerID string, names []string, duration time.Duration) *progressTracker {
+	t := &progressTracker{
+		taskID:     taskID,
+		workerID:   workerID,
+		duration:   duration,
+		renditions: make([]RenditionStatus, len(names)),
+		fraction:   make([]float64, len(names)),
+	}
+	t.save = func(progress int32, updates map[string]interface{}) {
+		db.UpdateTranscodeTaskProgress(taskID, workerID, progress, updates)
+	}
+	for i, name := range names {
+		t.renditions[i] = RenditionStatus{Name: name, Status: RenditionPending, ETASeconds: -1}
+	}
+	return t
+}
+
+// start 开始第 attempt 次转码第 i 个分辨率
+func (t *progressTracker) start(i int, attempt int32) {
+	t.mu.Lock()
+	t.renditions[i].Status = RenditionProcessing
+	t.renditions[i].Attempts = attempt
+	t.fraction[i] = 0
+	t.mu.Unlock()
+	t.flush(true)
+}
+
+// retrying 第 i 个分辨率失败，等待重试
+func (t *progressTracker) retrying(i int, err error, stderr string) {
+	t.mu.Lock()
+	r := &t.renditions[i]
+	r.Status = RenditionRetrying
+	r.Error = err.Error()
+	r.Progress = 0
+	r.Speed = 0
+	r.ETASeconds = -1
+	t.fraction[i] = 0
+	if stderr != "" {
+		t.lastStderr = stderr
+	}
+	t.mu.Unlock()
+	t.flush(true)
+}
+
+// skip 第 i 个分辨率不需要转码
+func (t *progressTracker) skip(i int, reason string) {
+	t.mu.Lock()
+	r := &t.renditions[i]
+	r.Status = RenditionSkipped
+	r.Reason = reason
+	r.ETASeconds = 0
+	t.fraction[i] = 1
+	t.mu.Unlock()
+}
+
+// annotate 记录对第 i 个分辨率的调整说明
+func (t *progressTracker) annotate(i int, reason string) {
+	t.mu.Lock()
+	t.renditions[i].Reason = reason
+	t.mu.Unlock()
+}
+
+// quality 记录第 i 个分辨率的质量指标
+func (t *progressTracker) quality(i int, scores *QualityScores, err error) {
+	t.mu.Lock()
+	r := &t.renditions[i]
+	r.Quality = scores
+	r.QualityError = ""
+	if err != nil {
+		r.QualityError = err.Error()
+	}
+	t.mu.Unlock()
+	t.flush(true)
+}
+
+// reuse 第 i 个分辨率在之前的执行中已经完成
+func (t *progressTracker) reuse(i int, previous RenditionStatus) {
+	t.mu.Lock()
+	t.renditions[i] = previous
+	t.fraction[i] = 1
+	t.mu.Unlock()
+}
+
+// update 根据 ffmpeg 进度更新第 i 个分辨率
+func (t *progressTracker) update(i int, p Progress) {
+	t.mu.Lock()
+	if t.duration > 0 {
+		fraction := math.Min(float64(p.OutTime)/float64(t.duration), 1)
+		if p.Passes > 1 && p.Pass > 0 {
+			fraction = (float64(p.Pass-1) + fraction) / float64(p.Passes)
+		}
+		t.fraction[i] = fraction
+	}
+	if p.Speed > 0 {
+		t.speed = p.Speed
+	}
+	r := &t.renditions[i]
+	r.Progress = int32(t.fraction[i] * 100)
+	r.Speed = p.Speed
+	r.ETASeconds = t.eta(1 - t.fraction[i])
+	t.mu.Unlock()
+	t.flush(false)
+}
+
+// finish 第 i 个分辨率结束
+func (t *progressTracker) finish(i int, out Output, err error, stderr string) {
+	t.mu.Lock()
+	r := &t.renditions[i]
+	r.Speed = 0
+	r.ETASeconds = 0
+	if err != nil {
+		r.Status = RenditionFailed
+		r.Error = err.Error()
+		if stderr != "" {
+			t.lastStderr = stderr
+		}
+	} else {
+		r.Status = RenditionCompleted
+		r.Progress = 100
+		r.Output = out
+		r.Error = ""
+	}
+	// 失败的分辨率不会再有进度，按已完成计入整体进度
+	t.fraction[i] = 1
+	t.mu.Unlock()
+	t.flush(true)
+}
+
+// eta 以当前速度编码 remaining 个源视频时长所需的秒数，未知时返回 -1
+// 调用方需持有锁
+func (t *progressTracker) eta(remaining float64) int64 {
+	if t.duration <= 0 || t.speed <= 0 {
+		return -1
+	}
+	return int64(math.Ceil(remaining * t.duration.Seconds() / t.speed))
+}
+
+// overall 整体进度与剩余时间，调用方需持有锁
+func (t *progressTracker) overall() (int32, int64) {
+	if len(t.fraction) == 0 {
+		return 0, -1
+	}
+	var done float64
+	for _, f := range t.fraction {
+		done += f
+	}
+	return int32(done * 100 / float64(len(t.fraction))), t.eta(float64(len(t.fraction)) - done)
+}
+
+// flush 写入数据库；非强制写入时按 progressInterval 节流
+func (t *progressTracker) flush(force bool) {
+	t.mu.Lock()
+	if !force && time.Since(t.lastFlush) < progressInterval {
+		t.mu.Unlock()
+		return
+	}
+	t.lastFlush = time.Now()
+	progress, eta := t.overall()
+	renditionsJSON, _ := json.Marshal(t.renditions)
+	updates := map[string]interface{}{
+		"renditions":  string(renditionsJSON),
+		"speed":       t.speed,
+		"eta_seconds": eta,
+	}
+	if t.lastStderr != "" {
+		updates["last_stderr"] = t.lastStderr
+	}
+	t.mu.Unlock()
+
+	t.save(progress, updates)
+}
+
+// snapshot 返回各分辨率状态的 JSON 与最后一次失败的 stderr
+func (t *progressTracker) snapshot() (string, string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	renditionsJSON, _ := json.Marshal(t.renditions)
+	return string(renditionsJSON), t.lastStderr
+}
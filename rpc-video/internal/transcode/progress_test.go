@@ -0,0 +1,225 @@
+package transcode
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ffmpeg 6 的 -progress pipe:1 输出：第一个块还没有时间戳，之后每个块以 progress=continue 结束，最后一个以 progress=end 结束
+const capturedProgress = `frame=0
+fps=0.00
+stream_0_0_q=0.0
+bitrate=N/A
+total_size=44
+out_time_us=N/A
+out_time_ms=N/A
+out_time=N/A
+dup_frames=0
+drop_frames=0
+speed=N/A
+progress=continue
+frame=121
+fps=60.25
+stream_0_0_q=28.0
+bitrate=1510.3kbits/s
+total_size=786480
+out_time_us=4166000
+out_time_ms=4166000
+out_time=00:00:04.166000
+dup_frames=0
+drop_frames=0
+speed=2.07x
+progress=continue
+frame=300
+fps=61.10
+stream_0_0_q=-1.0
+bitrate=1498.2kbits/s
+total_size=1872610
+out_time_us=10000000
+out_time_ms=10000000
+out_time=00:00:10.000000
+dup_frames=0
+drop_frames=0
+speed=2.04x
+progress=end
+`
+
+func TestParseProgress(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []Progress
+	}{
+		{
+			name:   "ffmpeg 6",
+			output: capturedProgress,
+			want: []Progress{
+				{},
+				{OutTime: 4166 * time.Millisecond, Speed: 2.07},
+				{OutTime: 10 * time.Second, Speed: 2.04, Done: true},
+			},
+		},
+		{
+			// 旧版本只输出 out_time_ms（单位同样是微秒），speed 带前导空格
+			name:   "legacy out_time_ms",
+			output: "out_time_ms=2500000\nspeed= 0.5x\nprogress=continue\n",
+			want:   []Progress{{OutTime: 2500 * time.Millisecond, Speed: 0.5}},
+		},
+		{
+			// N/A 不覆盖已有的值
+			name:   "N/A keeps previous values",
+			output: "out_time_us=1000000\nspeed=1x\nprogress=continue\nout_time_us=N/A\nspeed=N/A\nprogress=continue\n",
+			want:   []Progress{{OutTime: time.Second, Speed: 1}, {OutTime: time.Second, Speed: 1}},
+		},
+		{
+			name:   "negative out_time ignored",
+			output: "out_time_us=-9223372036854775807\nprogress=continue\n",
+			want:   []Progress{{}},
+		},
+		{
+			name:   "CRLF and noise",
+			output: "garbage line\r\nout_time_us=3000000\r\nspeed=1.5x\r\nprogress=end\r\n",
+			want:   []Progress{{OutTime: 3 * time.Second, Speed: 1.5, Done: true}},
+		},
+		{
+			name:   "truncated block",
+			output: "out_time_us=3000000\nspeed=1.5x\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []Progress
+			if err := parseProgress(strings.NewReader(tt.output), func(p Progress) { got = append(got, p) }); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("progress = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// savedProgress 一次写入的整体进度
+type savedProgress struct {
+	progress   int32
+	eta        int64
+	speed      float64
+	renditions []RenditionStatus
+}
+
+// newTestTracker 不写数据库，记录每次写入
+func newTestTracker(names []string, duration time.Duration) (*progressTracker, *[]savedProgress) {
+	var saved []savedProgress
+	tracker := newProgressTracker("task", "worker", names, duration)
+	tracker.save = func(progress int32, updates map[string]interface{}) {
+		var renditions []RenditionStatus
+		json.Unmarshal([]byte(updates["renditions"].(string)), &renditions)
+		saved = append(saved, savedProgress{
+			progress:   progress,
+			eta:        updates["eta_seconds"].(int64),
+			speed:      updates["speed"].(float64),
+			renditions: renditions,
+		})
+	}
+	return tracker, &saved
+}
+
+func TestProgressTracker(t *testing.T) {
+	tracker, saved := newTestTracker([]string{"480p", "720p"}, 10*time.Second)
+	last := func() savedProgress { return (*saved)[len(*saved)-1] }
+
+	tracker.start(0, 1)
+	if s := last(); s.progress != 0 || s.eta != -1 || s.renditions[0].Status != RenditionProcessing || s.renditions[1].Status != RenditionPending {
+		t.Fatalf("after start: %+v", s)
+	}
+
+	// 480p 编码到一半，速度 2x：该分辨率还需 5s/2，整体还剩 1.5 个源时长。
+	// start 刚写入过，清空 lastFlush 让这次更新不被节流
+	tracker.lastFlush = time.Time{}
+	tracker.update(0, Progress{OutTime: 5 * time.Second, Speed: 2})
+	s := last()
+	if s.progress != 25 || s.eta != 8 || s.speed != 2 {
+		t.Errorf("overall = %d%% eta %ds speed %v, want 25%% eta 8s speed 2", s.progress, s.eta, s.speed)
+	}
+	if r := s.renditions[0]; r.Progress != 50 || r.ETASeconds != 3 || r.Speed != 2 {
+		t.Errorf("480p = %+v, want 50%% eta 3s", r)
+	}
+
+	// 节流：progressInterval 内的进度只保留在内存中，下一次强制写入时带上
+	n := len(*saved)
+	tracker.update(0, Progress{OutTime: 8 * time.Second, Speed: 2})
+	if len(*saved) != n {
+		t.Errorf("throttled update was written")
+	}
+
+	tracker.finish(0, Output{URL: "/files/480p.mp4"}, nil, "")
+	tracker.start(1, 1)
+	tracker.retrying(1, errors.New("exit status 1"), "stderr tail")
+	s = last()
+	if r := s.renditions[1]; r.Status != RenditionRetrying || r.Error != "exit status 1" || r.Progress != 0 || r.ETASeconds != -1 {
+		t.Errorf("retrying 720p = %+v", r)
+	}
+	if s.progress != 50 {
+		t.Errorf("overall after 480p = %d%%, want 50%%", s.progress)
+	}
+
+	// 两遍编码：第二遍进行到一半时该分辨率完成 75%
+	tracker.start(1, 2)
+	tracker.lastFlush = time.Time{}
+	tracker.update(1, Progress{OutTime: 5 * time.Second, Speed: 4, Pass: 2, Passes: 2})
+	s = last()
+	if r := s.renditions[1]; r.Progress != 75 || r.Attempts != 2 {
+		t.Errorf("two-pass 720p = %+v, want 75%% on attempt 2", r)
+	}
+	if s.progress != 87 || s.eta != 1 {
+		t.Errorf("overall = %d%% eta %ds, want 87%% eta 1s", s.progress, s.eta)
+	}
+
+	// 失败的分辨率按已完成计入整体进度
+	tracker.finish(1, Output{}, errors.New("exit status 1"), "final stderr")
+	s = last()
+	if s.progress != 100 || s.eta != 0 {
+		t.Errorf("overall after failure = %d%% eta %ds, want 100%% eta 0", s.progress, s.eta)
+	}
+	if r := s.renditions[0]; r.Status != RenditionCompleted || r.Progress != 100 || r.URL != "/files/480p.mp4" {
+		t.Errorf("480p = %+v", r)
+	}
+	if r := s.renditions[1]; r.Status != RenditionFailed || r.Error != "exit status 1" {
+		t.Errorf("720p = %+v", r)
+	}
+	if _, stderr := tracker.snapshot(); stderr != "final stderr" {
+		t.Errorf("last stderr = %q", stderr)
+	}
+}
+
+func TestProgressTrackerUnknownDuration(t *testing.T) {
+	tracker, saved := newTestTracker([]string{"720p"}, 0)
+	tracker.start(0, 1)
+	tracker.lastFlush = time.Time{}
+	tracker.update(0, Progress{OutTime: 5 * time.Second, Speed: 2})
+	s := (*saved)[len(*saved)-1]
+	if s.progress != 0 || s.eta != -1 || s.renditions[0].ETASeconds != -1 || s.speed != 2 {
+		t.Errorf("unknown duration: %+v", s)
+	}
+}
+
+func TestProgressTrackerSkipAndReuse(t *testing.T) {
+	tracker, saved := newTestTracker([]string{"1080p", "720p", "480p"}, 10*time.Second)
+	tracker.skip(0, "源分辨率低于 1080p")
+	tracker.reuse(1, RenditionStatus{Name: "720p", Status: RenditionCompleted, Progress: 100, Attempts: 1})
+	tracker.start(2, 1)
+	s := (*saved)[len(*saved)-1]
+	if s.progress != 66 {
+		t.Errorf("overall = %d%%, want 66%% with one skipped and one reused", s.progress)
+	}
+	if r := s.renditions[0]; r.Status != RenditionSkipped || r.Reason == "" || r.ETASeconds != 0 {
+		t.Errorf("skipped = %+v", r)
+	}
+	if r := s.renditions[1]; r.Status != RenditionCompleted || r.Attempts != 1 {
+		t.Errorf("reused = %+v", r)
+	}
+}
@@ -0,0 +1,116 @@
+package transcode
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"video-platform-microservice/rpc-video/internal/storage"
+)
+
+// maxPSNR 完全相同的画面 PSNR 为 inf，按 100 dB 记录（JSON 不能表示 inf）
+const maxPSNR = 100
+
+var (
+	psnrPattern = regexp.MustCompile(`PSNR .*average:(inf|[0-9.]+)`)
+	ssimPattern = regexp.MustCompile(`SSIM .*All:([0-9.]+)`)
+	vmafPattern = regexp.MustCompile(`VMAF score[:=]\s*([0-9.]+)`)
+
+	vmafOnce      sync.Once
+	vmafAvailable bool
+)
+
+// QualityScores 单个分辨率的客观质量指标，未计算的指标为 nil
+type QualityScores struct {
+	PSNR *float64 `json:"psnr,omitempty"` // 亮度与色度的平均 PSNR（dB）
+	SSIM *float64 `json:"ssim,omitempty"` // 0-1
+	VMAF *float64 `json:"vmaf,omitempty"` // 0-100，ffmpeg 编译了 libvmaf 时才有
+}
+
+// hasVMAF ffmpeg 是否编译了 libvmaf 滤镜
+func hasVMAF() bool {
+	vmafOnce.Do(func() {
+		output, err := exec.Command("ffmpeg", "-hide_banner", "-filters").Output()
+		vmafAvailable = err == nil && strings.Contains(string(output), " libvmaf ")
+	})
+	return vmafAvailable
+}
+
+// measureQuality 计算转码输出相对源视频的 PSNR、SSIM（及 VMAF）。
+// 源画面经过与编码时相同的色调映射、缩放、补边或裁剪后再比较，衡量的是编码损失
+func measureQuality(ctx context.Context, sourcePath string, media *MediaInfo, profile Profile, out Output) (*QualityScores, error) {
+	key, err := storage.Resolve(out.StorageKey, out.Backend)
+	if err != nil {
+		return nil, err
+	}
+	outputPath, cleanup, err := storage.FetchLocal(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("读取转码输出失败: %w", err)
+	}
+	defer cleanup()
+
+	vmaf := hasVMAF()
+	refs := 2
+	if vmaf {
+		refs = 3
+	}
+	// 每个指标滤镜消耗一路参考画面，输出的失真画面继续传给下一个滤镜
+	graph := fmt.Sprintf("[1:v]%s,format=yuv420p,setpts=PTS-STARTPTS,split=%d", profile.videoFilter(media), refs)
+	for i := 0; i < refs; i++ {
+		graph += fmt.Sprintf("[ref%d]", i)
+	}
+	graph += ";[0:v]format=yuv420p,setpts=PTS-STARTPTS[dist0];" +
+		"[dist0][ref0]psnr[dist1];" +
+		"[dist1][ref1]ssim"
+	if vmaf {
+		graph += "[dist2];[dist2][ref2]libvmaf"
+	}
+
+	stderr, err := runFFmpegOutput(ctx, []string{
+		"-i", outputPath,
+		"-i", sourcePath,
+		"-lavfi", graph,
+		"-an",
+		"-progress", "pipe:1",
+		"-nostats",
+		"-f", "null",
+		"-",
+	}, func(Progress) {})
+	if err != nil {
+		return nil, err
+	}
+	return parseQuality(stderr)
+}
+
+// parseQuality 从 ffmpeg 的 stderr 中读取各滤镜输出的汇总结果
+func parseQuality(stderr string) (*QualityScores, error) {
+	scores := &QualityScores{}
+	if m := psnrPattern.FindStringSubmatch(stderr); m != nil {
+		psnr := float64(maxPSNR)
+		if m[1] != "inf" {
+			if v, err := strconv.ParseFloat(m[1], 64); err == nil {
+				psnr = math.Min(v, maxPSNR)
+			}
+		}
+		scores.PSNR = &psnr
+	}
+	if m := ssimPattern.FindStringSubmatch(stderr); m != nil {
+		if v, err := strconv.ParseFloat(m[1], 64); err == nil {
+			scores.SSIM = &v
+		}
+	}
+	if m := vmafPattern.FindStringSubmatch(stderr); m != nil {
+		if v, err := strconv.ParseFloat(m[1], 64); err == nil {
+			scores.VMAF = &v
+		}
+	}
+	if scores.PSNR == nil && scores.SSIM == nil {
+		return nil, fmt.Errorf("ffmpeg 输出中没有质量指标")
+	}
+	return scores, nil
+}
@@ -0,0 +1,442 @@
+package transcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"video-platform-microservice/rpc-video/internal/db"
+	"video-platform-microservice/rpc-video/internal/storage"
+)
+
+// 缩略图生成状态（video_blobs.thumbnail_status）
+const (
+	ThumbnailPending    = "pending"
+	ThumbnailProcessing = "processing"
+	ThumbnailCompleted  = "completed"
+	ThumbnailFailed     = "failed"
+)
+
+const (
+	thumbsPrefix      = "thumbs/"         // 缩略图在存储中的前缀：thumbs/<file_hash>/...
+	posterName        = "poster.jpg"      // 自动截取的封面
+	thumbnailsVTTName = "thumbnails.vtt"  // WebVTT 缩略图轨道
+	spritePattern     = "sprite_%03d.jpg" // 雪碧图文件名
+
+	posterMaxWidth    = 1280 // 封面最大宽度
+	thumbWidth        = 160  // 单个缩略图宽度
+	spriteColumns     = 10   // 每张雪碧图 10x10 个缩略图
+	spriteRows        = 10
+	thumbnailInterval = 10 * time.Second // 缩略图间隔
+	minThumbnails     = 10               // 短视频至少生成的缩略图数量
+	maxThumbnails     = 400              // 长视频增大间隔，最多 4 张雪碧图
+
+	MaxPosterSize = 5 * 1024 * 1024 // 上传封面的大小上限
+)
+
+// ErrInvalidPoster 封面参数无效（时间点超出范围、图片格式或大小不符合要求）
+var ErrInvalidPoster = errors.New("封面无效")
+
+// thumbnailSlots 限制同时生成缩略图的数量
+var thumbnailSlots = make(chan struct{}, 2)
+
+// posterExts 允许上传的封面格式
+var posterExts = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// ThumbnailKey 缩略图在存储中的 key
+func ThumbnailKey(fileHash, name string) string {
+	return thumbsPrefix + fileHash + "/" + name
+}
+
+// ThumbnailURL 缩略图的访问地址（由网关的 /api/video/thumbs 提供）
+func ThumbnailURL(fileHash, name string) string {
+	return "/api/video/thumbs/" + fileHash + "/" + name
+}
+
+// SpriteURLs 雪碧图地址列表
+func SpriteURLs(fileHash string, count int32) []string {
+	urls := make([]string, 0, count)
+	for i := 0; i < int(count); i++ {
+		urls = append(urls, ThumbnailURL(fileHash, fmt.Sprintf(spritePattern, i)))
+	}
+	return urls
+}
+
+// DefaultPosterURL 自动截取的封面地址
+func DefaultPosterURL(fileHash string) string {
+	return ThumbnailURL(fileHash, posterName)
+}
+
+// ThumbnailsVTTURL WebVTT 缩略图轨道地址
+func ThumbnailsVTTURL(fileHash string) string {
+	return ThumbnailURL(fileHash, thumbnailsVTTName)
+}
+
+// DeleteThumbnails 删除物理文件的所有缩略图（包括各用户的自定义封面）
+func DeleteThumbnails(ctx context.Context, fileHash string) error {
+	return storage.DeletePrefix(ctx, ThumbnailKey(fileHash, ""))
+}
+
+// GenerateThumbnailsAsync 在后台为物理文件生成封面、缩略图雪碧图和 WebVTT 轨道
+func GenerateThumbnailsAsync(fileHash string) {
+	go func() {
+		if err := GenerateThumbnails(context.Background(), fileHash); err != nil {
+			log.Printf("❌ 生成缩略图失败: %s, error: %v", fileHash, err)
+		}
+	}()
+}
+
+// ResumeThumbnails 启动时补齐未生成缩略图的物理文件（包括上一次运行中断的和功能上线前上传的）
+func ResumeThumbnails() {
+	if err := db.ResetStaleBlobThumbnails(); err != nil {
+		log.Printf("⚠️ 重置缩略图状态失败: %v", err)
+	}
+	seen := make(map[string]bool)
+	for {
+		blobs, err := db.GetPendingThumbnailBlobs(50)
+		if err != nil {
+			log.Printf("⚠️ 查询待生成缩略图失败: %v", err)
+			return
+		}
+		if len(blobs) == 0 {
+			return
+		}
+		for _, blob := range blobs {
+			// 领取失败的文件仍是 pending，再次遇到说明没有进展，留给下次启动
+			if seen[blob.FileHash] {
+				return
+			}
+			seen[blob.FileHash] = true
+			if err := GenerateThumbnails(context.Background(), blob.FileHash); err != nil {
+				log.Printf("❌ 生成缩略图失败: %s, error: %v", blob.FileHash, err)
+			}
+		}
+	}
+}
+
+// GenerateThumbnails 生成封面、缩略图雪碧图和 WebVTT 轨道；已生成或正在生成时直接返回
+func GenerateThumbnails(ctx context.Context, fileHash string) error {
+	ok, err := db.ClaimBlobThumbnails(fileHash)
+	if err != nil || !ok {
+		return err
+	}
+
+	thumbnailSlots <- struct{}{}
+	defer func() { <-thumbnailSlots }()
+
+	spriteCount, err := generateThumbnails(ctx, fileHash)
+	if err != nil {
+		db.UpdateBlob(fileHash, map[string]interface{}{"thumbnail_status": ThumbnailFailed})
+		return err
+	}
+	log.Printf("✅ 缩略图已生成: %s (%d 张雪碧图)", fileHash, spriteCount)
+	return db.UpdateBlob(fileHash, map[string]interface{}{
+		"thumbnail_status": ThumbnailCompleted,
+		"sprite_count":     spriteCount,
+	})
+}
+
+func generateThumbnails(ctx context.Context, fileHash string) (int32, error) {
+	blob, err := db.GetBlob(fileHash)
+	if err != nil {
+		return 0, err
+	}
+	if blob == nil {
+		return 0, fmt.Errorf("源文件不存在: %s", fileHash)
+	}
+	sourceKey, err := storage.Resolve(blob.StorageKey, blob.StorageBackend)
+	if err != nil {
+		return 0, fmt.Errorf("源文件不可读: %s: %w", fileHash, err)
+	}
+	sourcePath, cleanup, err := storage.FetchLocal(ctx, sourceKey)
+	if err != nil {
+		return 0, fmt.Errorf("源文件不存在: %s: %w", sourceKey, err)
+	}
+	defer cleanup()
+
+	media, err := defaultProber.Probe(sourcePath)
+	if err != nil {
+		return 0, err
+	}
+	// ffmpeg 默认按旋转信息自动旋转画面，缩略图按显示尺寸计算
+	width, height := media.DisplaySize()
+	duration := media.Duration
+	if !media.HasVideo() || width <= 0 || height <= 0 || duration <= 0 {
+		return 0, fmt.Errorf("无法获取视频尺寸或时长")
+	}
+
+	workDir, err := storage.NewLocalTempDir("thumbs-*")
+	if err != nil {
+		return 0, fmt.Errorf("创建临时目录失败: %v", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	// 1. 封面：默认取 10% 处的画面，避开片头黑屏
+	posterAt := time.Duration(float64(duration) * 0.1)
+	if err := defaultTranscoder.ExtractFrame(ctx, sourcePath, posterAt, filepath.Join(workDir, posterName)); err != nil {
+		return 0, fmt.Errorf("截取封面失败: %w", err)
+	}
+
+	// 2. 雪碧图：按间隔抽帧，缩放后拼接为 10x10 的网格
+	interval := spriteInterval(duration)
+	thumbHeight := int(math.Round(float64(thumbWidth)*float64(height)/float64(width)/2)) * 2
+	if thumbHeight < 2 {
+		thumbHeight = 2
+	}
+	sprites, err := defaultTranscoder.RenderSprites(ctx, sourcePath, interval, thumbWidth, thumbHeight, workDir)
+	if err != nil {
+		return 0, fmt.Errorf("生成雪碧图失败: %w", err)
+	}
+	if sprites == 0 {
+		return 0, fmt.Errorf("没有生成雪碧图")
+	}
+
+	// 3. WebVTT 缩略图轨道
+	vtt := thumbnailsVTT(duration, interval, sprites, thumbWidth, thumbHeight)
+	if err := os.WriteFile(filepath.Join(workDir, thumbnailsVTTName), []byte(vtt), 0644); err != nil {
+		return 0, err
+	}
+
+	// 雪碧图先于 VTT 上传，VTT 引用的图片总是存在
+	for _, name := range append(spriteNames(sprites), posterName, thumbnailsVTTName) {
+		if err := storage.PutLocalFile(ctx, ThumbnailKey(fileHash, name), filepath.Join(workDir, name)); err != nil {
+			return 0, fmt.Errorf("上传 %s 失败: %v", name, err)
+		}
+	}
+	return int32(sprites), nil
+}
+
+// spriteInterval 缩略图间隔：默认 10 秒；短视频至少 10 张，长视频最多 400 张
+func spriteInterval(duration time.Duration) time.Duration {
+	interval := thumbnailInterval
+	if duration < interval*minThumbnails {
+		interval = duration / minThumbnails
+	}
+	if duration > interval*maxThumbnails {
+		interval = duration / maxThumbnails
+	}
+	if interval < time.Second {
+		interval = time.Second
+	}
+	return interval.Round(time.Millisecond)
+}
+
+// renderSprites 按间隔抽帧，缩放后拼接为 spriteColumns x spriteRows 的网格
+func renderSprites(ctx context.Context, sourcePath string, interval time.Duration, width, height int, dir string) (int, error) {
+	args := []string{
+		"-i", sourcePath,
+		"-vf", fmt.Sprintf("fps=1/%.3f,scale=%d:%d,tile=%dx%d", interval.Seconds(), width, height, spriteColumns, spriteRows),
+		"-q:v", "5",
+		"-progress", "pipe:1",
+		"-nostats",
+		"-y",
+		filepath.Join(dir, spritePattern),
+	}
+	if err := runFFmpeg(ctx, args, func(Progress) {}); err != nil {
+		return 0, err
+	}
+	sprites, _ := filepath.Glob(filepath.Join(dir, "sprite_*.jpg"))
+	return len(sprites), nil
+}
+
+// SpriteName 第 i 张雪碧图的文件名
+func SpriteName(i int) string {
+	return fmt.Sprintf(spritePattern, i)
+}
+
+func spriteNames(count int) []string {
+	names := make([]string, count)
+	for i := range names {
+		names[i] = SpriteName(i)
+	}
+	return names
+}
+
+// thumbnailsVTT 生成 WebVTT 缩略图轨道，每个 cue 指向雪碧图中的一个区域（媒体片段 #xywh）
+func thumbnailsVTT(duration, interval time.Duration, spriteCount, width, height int) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	perSprite := spriteColumns * spriteRows
+	for i := 0; i < spriteCount*perSprite; i++ {
+		start := time.Duration(i) * interval
+		if start >= duration {
+			break
+		}
+		end := start + interval
+		if end > duration {
+			end = duration
+		}
+		cell := i % perSprite
+		fmt.Fprintf(&b, "%s --> %s\n%s#xywh=%d,%d,%d,%d\n\n",
+			vttTimestamp(start), vttTimestamp(end),
+			fmt.Sprintf(spritePattern, i/perSprite),
+			(cell%spriteColumns)*width, (cell/spriteColumns)*height, width, height,
+		)
+	}
+	return b.String()
+}
+
+// vttTimestamp 格式化为 hh:mm:ss.ttt
+func vttTimestamp(d time.Duration) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// extractFrame 截取 at 处的一帧为 JPEG，宽度不超过 posterMaxWidth
+func extractFrame(ctx context.Context, sourcePath string, at time.Duration, outputPath string) error {
+	args := []string{
+		"-ss", strconv.FormatFloat(at.Seconds(), 'f', 3, 64), // 放在 -i 之前按关键帧快速定位
+		"-i", sourcePath,
+		"-frames:v", "1",
+		"-vf", fmt.Sprintf("scale='min(%d,iw)':-2", posterMaxWidth),
+		"-q:v", "2",
+		"-progress", "pipe:1",
+		"-nostats",
+		"-y",
+		outputPath,
+	}
+	if err := runFFmpeg(ctx, args, func(Progress) {}); err != nil {
+		return err
+	}
+	if info, err := os.Stat(outputPath); err != nil || info.Size() == 0 {
+		return fmt.Errorf("时间点 %v 超出视频范围", at)
+	}
+	return nil
+}
+
+// userPosterName 用户自定义封面的文件名
+func userPosterName(userID, ext string) string {
+	return "poster_" + userID + ext
+}
+
+// SetPosterAt 截取 at 处的画面作为用户的自定义封面，返回封面地址
+func SetPosterAt(ctx context.Context, fileHash, userID string, at time.Duration) (string, error) {
+	blob, err := db.GetBlob(fileHash)
+	if err != nil {
+		return "", err
+	}
+	if blob == nil {
+		return "", fmt.Errorf("源文件不存在: %s", fileHash)
+	}
+	sourceKey, err := storage.Resolve(blob.StorageKey, blob.StorageBackend)
+	if err != nil {
+		return "", fmt.Errorf("源文件不可读: %s: %w", fileHash, err)
+	}
+	sourcePath, cleanup, err := storage.FetchLocal(ctx, sourceKey)
+	if err != nil {
+		return "", fmt.Errorf("源文件不存在: %s: %w", sourceKey, err)
+	}
+	defer cleanup()
+
+	if media, err := defaultProber.Probe(sourcePath); err == nil && at >= media.Duration {
+		return "", fmt.Errorf("%w: 时间点超出视频时长 %.3f 秒", ErrInvalidPoster, media.Duration.Seconds())
+	}
+
+	outputPath, err := storage.NewLocalTempPath("poster-*.jpg")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(outputPath)
+	if err := defaultTranscoder.ExtractFrame(ctx, sourcePath, at, outputPath); err != nil {
+		return "", fmt.Errorf("截取封面失败: %w", err)
+	}
+	return saveUserPoster(ctx, fileHash, userID, ".jpg", outputPath)
+}
+
+// SetPosterImage 保存用户上传的封面图片，返回封面地址
+func SetPosterImage(ctx context.Context, fileHash, userID string, image []byte) (string, error) {
+	if len(image) > MaxPosterSize {
+		return "", fmt.Errorf("%w: 图片不能超过 %d MB", ErrInvalidPoster, MaxPosterSize/1024/1024)
+	}
+	ext, ok := posterExts[http.DetectContentType(image)]
+	if !ok {
+		return "", fmt.Errorf("%w: 只支持 JPEG、PNG、WebP 图片", ErrInvalidPoster)
+	}
+
+	outputPath, err := storage.NewLocalTempPath("poster-*" + ext)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(outputPath)
+	if err := os.WriteFile(outputPath, image, 0644); err != nil {
+		return "", err
+	}
+	return saveUserPoster(ctx, fileHash, userID, ext, outputPath)
+}
+
+// ResetPoster 删除用户的自定义封面，恢复为自动截取的封面
+func ResetPoster(ctx context.Context, fileHash, userID string) error {
+	DeleteUserPoster(fileHash, userID)
+	return db.UpdateFilePoster(fileHash, userID, "")
+}
+
+// DeleteUserPoster 删除用户的自定义封面文件
+func DeleteUserPoster(fileHash, userID string) {
+	deleteUserPosters(fileHash, userID, "")
+}
+
+// saveUserPoster 上传自定义封面并记录到 video_files，地址带版本号避免客户端缓存旧封面
+func saveUserPoster(ctx context.Context, fileHash, userID, ext, localPath string) (string, error) {
+	name := userPosterName(userID, ext)
+	if err := storage.PutLocalFile(ctx, ThumbnailKey(fileHash, name), localPath); err != nil {
+		return "", fmt.Errorf("保存封面失败: %v", err)
+	}
+	deleteUserPosters(fileHash, userID, ext)
+
+	url := fmt.Sprintf("%s?v=%d", ThumbnailURL(fileHash, name), time.Now().Unix())
+	if err := db.UpdateFilePoster(fileHash, userID, url); err != nil {
+		return "", err
+	}
+	return url, nil
+}
+
+// deleteUserPosters 删除用户其他格式的自定义封面，keepExt 为保留的格式
+func deleteUserPosters(fileHash, userID, keepExt string) {
+	for _, ext := range posterExts {
+		if ext != keepExt {
+			storage.DeleteObject(ThumbnailKey(fileHash, userPosterName(userID, ext)))
+		}
+	}
+}
+
+// thumbnailContentTypes 缩略图文件的 Content-Type
+var thumbnailContentTypes = map[string]string{
+	".jpg":  "image/jpeg",
+	".png":  "image/png",
+	".webp": "image/webp",
+	".vtt":  "text/vtt; charset=utf-8",
+}
+
+// ThumbnailContentType 根据扩展名返回缩略图文件的 Content-Type
+func ThumbnailContentType(name string) string {
+	return thumbnailContentTypes[path.Ext(name)]
+}
+
+// ThumbnailAccessible 校验缩略图路径：公共文件（封面、雪碧图、VTT）或该用户自己的自定义封面
+func ThumbnailAccessible(name, userID string) bool {
+	if strings.Contains(name, "/") || ThumbnailContentType(name) == "" {
+		return false
+	}
+	if name == posterName || name == thumbnailsVTTName {
+		return true
+	}
+	var index int
+	if n, err := fmt.Sscanf(name, spritePattern, &index); err == nil && n == 1 && name == fmt.Sprintf(spritePattern, index) {
+		return true
+	}
+	ext := path.Ext(name)
+	return ext != ".vtt" && name == userPosterName(userID, ext)
+}
@@ -1,35 +1,25 @@
 package transcode
 
 import (
+"context"
 "encoding/json"
+"errors"
 "fmt"
+"io"
 "log"
 "os"
 "os/exec"
 "path/filepath"
+"strconv"
 "strings"
 "sync"
+"time"
 
 "github.com/google/uuid"
 "video-platform-microservice/rpc-video/internal/db"
 "video-platform-microservice/rpc-video/internal/storage"
 )
 
-// ResolutionConfig 分辨率配置
-type ResolutionConfig struct {
-Name   string
-Width  int
-Height int
-Bitrate string
-}
-
-var resolutions = map[string]ResolutionConfig{
-"1080p": {Name: "1080p", Width: 1920, Height: 1080, Bitrate: "5000k"},
-"720p":  {Name: "720p", Width: 1280, Height: 720, Bitrate: "2500k"},
-"480p":  {Name: "480p", Width: 854, Height: 480, Bitrate: "1000k"},
-"360p":  {Name: "360p", Width: 640, Height: 360, Bitrate: "500k"},
-}
-
 // TaskStatus 任务状态结构
 type TaskStatus struct {
 TaskID        string   `json:"task_id"`
@@ -37,83 +27,235 @@ Status        string   `json:"status"`
 Progress      int32    `json:"progress"`
 CompletedURLs []string `json:"completed_urls"`
 Error         string   `json:"error,omitempty"`
+Renditions    []RenditionStatus `json:"renditions"`
+Speed         float64  `json:"speed"`
+ETASeconds    int64    `json:"eta_seconds"`
+LastStderr    string   `json:"last_stderr,omitempty"`
+PerTitle      bool     `json:"per_title"`
+QualityMetrics bool    `json:"quality_metrics"`
+Ladder        []LadderRung `json:"ladder,omitempty"`
+}
+
+// 队列参数
+const (
+leaseTTL            = 60 * time.Second // 任务租约时长
+heartbeatInterval   = leaseTTL / 3     // 心跳续期间隔
+cancelCheckInterval = 2 * time.Second  // 检查任务是否被取消的间隔
+pollInterval        = time.Second      // 队列为空时的轮询间隔
+reapInterval        = 30 * time.Second // 检查租约过期任务的间隔
+maxAttempts         = 3                // 工作者中断后最多重新领取的次数
+
+renditionMaxAttempts = 3               // 单个分辨率最多尝试次数，超过后任务进入死信状态
+retryBaseDelay       = 5 * time.Second // 重试退避的初始间隔，每次翻倍
+retryMaxDelay        = 2 * time.Minute // 重试退避的最大间隔
+)
+
+// 任务状态
+const (
+StatusPending    = "pending"
+StatusProcessing = "processing"
+StatusCompleted  = "completed"
+StatusFailed     = "failed"      // 不可重试的错误（源文件不存在等）
+StatusCancelled  = "cancelled"
+StatusDeadLetter = "dead_letter" // 重试耗尽，保留最后一次 ffmpeg 输出，可通过 RetryTranscode 重新执行
+)
+
+// 运行模式
+const (
+ModeEmbedded   = "embedded"   // 在 rpc-video 进程内运行工作协程
+ModeStandalone = "standalone" // 由独立的 transcode-worker 进程执行，rpc-video 只负责创建任务
+)
+
+// Config 转码工作者配置
+type Config struct {
+Mode              string
+Workers           int
+HLSSegmentType    string // fmp4 / mpegts
+HLSSegmentSeconds int    // 目标分片时长
+DASH              bool   // 是否基于同一组 fMP4 分片生成 DASH 清单
+RetryDelay        time.Duration // 重试退避的初始间隔，为 0 时使用 retryBaseDelay
+
+Transcoder Transcoder // 为 nil 时使用 ffmpeg
+Prober     Prober     // 为 nil 时使用 ffprobe
+}
+
+// LoadConfig 从环境变量读取配置：TRANSCODE_MODE（embedded/standalone）、TRANSCODE_WORKERS、
+// HLS_SEGMENT_TYPE（fmp4/mpegts）、HLS_SEGMENT_SECONDS、DASH_ENABLED
+func LoadConfig() (Config, error) {
+cfg := Config{Mode: ModeEmbedded, Workers: 2, HLSSegmentType: HLSSegmentFMP4, HLSSegmentSeconds: 6}
+if mode := os.Getenv("TRANSCODE_MODE"); mode != "" {
+cfg.Mode = strings.ToLower(mode)
+}
+if cfg.Mode != ModeEmbedded && cfg.Mode != ModeStandalone {
+return cfg, fmt.Errorf("不支持的转码模式: %s", cfg.Mode)
+}
+if workers := os.Getenv("TRANSCODE_WORKERS"); workers != "" {
+n, err := strconv.Atoi(workers)
+if err != nil || n <= 0 {
+return cfg, fmt.Errorf("无效的 TRANSCODE_WORKERS: %s", workers)
+}
+cfg.Workers = n
+}
+if segmentType := os.Getenv("HLS_SEGMENT_TYPE"); segmentType != "" {
+cfg.HLSSegmentType = strings.ToLower(segmentType)
+}
+if cfg.HLSSegmentType != HLSSegmentFMP4 && cfg.HLSSegmentType != HLSSegmentMPEGTS {
+return cfg, fmt.Errorf("不支持的 HLS 分片类型: %s", cfg.HLSSegmentType)
+}
+if seconds := os.Getenv("HLS_SEGMENT_SECONDS"); seconds != "" {
+n, err := strconv.Atoi(seconds)
+if err != nil || n <= 0 {
+return cfg, fmt.Errorf("无效的 HLS_SEGMENT_SECONDS: %s", seconds)
+}
+cfg.HLSSegmentSeconds = n
+}
+if dash := os.Getenv("DASH_ENABLED"); dash != "" {
+enabled, err := strconv.ParseBool(dash)
+if err != nil {
+return cfg, fmt.Errorf("无效的 DASH_ENABLED: %s", dash)
+}
+cfg.DASH = enabled
+}
+if cfg.DASH && cfg.HLSSegmentType != HLSSegmentFMP4 {
+return cfg, fmt.Errorf("DASH 需要 fMP4 分片（HLS_SEGMENT_TYPE=fmp4）")
+}
+return cfg, nil
 }
 
 // Manager 转码管理器
+// 任务持久化在 transcode_tasks 表中，工作者从表中领取 pending 任务并持有租约，
+// 多个实例可以共享同一个队列；工作者崩溃后租约过期，任务自动回到队列
 type Manager struct {
-mu     sync.RWMutex
-tasks  map[string]*TaskStatus
-queue  chan string // 任务队列
-workers int
+workerID string
+workers  int
+cfg      Config
+transcoder Transcoder
+prober     Prober
+wake     chan struct{} // 本实例创建任务后唤醒空闲的工作协程
+stop     chan struct{} // Shutdown 时关闭，工作协程不再领取新任务
+
+ctx    context.Context // Shutdown 超时后取消，中断执行中的任务
+cancel context.CancelFunc
+wg     sync.WaitGroup
 }
 
 var manager *Manager
 
 // InitTranscodeManager 初始化转码管理器
-func InitTranscodeManager(workers int) {
+func InitTranscodeManager(cfg Config) {
+workers := cfg.Workers
 if workers <= 0 {
 workers = 2 // 默认2个工作协程
 }
+if cfg.HLSSegmentType == "" {
+cfg.HLSSegmentType = HLSSegmentFMP4
+}
+if cfg.HLSSegmentType != HLSSegmentFMP4 {
+cfg.DASH = false // DASH 只能复用 fMP4 分片
+}
+if cfg.HLSSegmentSeconds <= 0 {
+cfg.HLSSegmentSeconds = 6
+}
+
+if cfg.Prober == nil {
+cfg.Prober = FFprobe{}
+}
+if cfg.Transcoder == nil {
+cfg.Transcoder = NewFFmpeg(cfg)
+}
+SetEngine(cfg.Transcoder, cfg.Prober)
 
+hostname, _ := os.Hostname()
+ctx, cancel := context.WithCancel(context.Background())
 manager = &Manager{
-tasks:   make(map[string]*TaskStatus),
-queue:   make(chan string, 100),
-workers: workers,
+workerID: fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.New().String()[:8]),
+workers:  workers,
+cfg:      cfg,
+transcoder: cfg.Transcoder,
+prober:     cfg.Prober,
+wake:     make(chan struct{}, workers),
+stop:     make(chan struct{}),
+ctx:      ctx,
+cancel:   cancel,
 }
 
+// 回收崩溃工作者遗留的任务
+manager.reap()
+go manager.reaper()
+
 // 启动工作协程
 for i := 0; i < workers; i++ {
+manager.wg.Add(1)
 go manager.worker()
 }
 
-log.Printf("✅ 转码管理器已启动 (workers: %d)", workers)
+log.Printf("✅ 转码管理器已启动 (workers: %d, id: %s)", workers, manager.workerID)
 }
 
-// CreateTask 创建转码任务
-func CreateTask(fileHash, userID string, resolutionList []string) (string, error) {
-taskID := uuid.New().String()
+// Shutdown 停止领取新任务并等待执行中的任务完成；
+// timeout 到期后中断剩余任务，并立即把它们放回队列由其他工作者接手。重复调用时直接返回
+func Shutdown(timeout time.Duration) {
+if manager == nil {
+return
+}
 
-// 验证分辨率
-for _, res := range resolutionList {
-if _, ok := resolutions[res]; !ok {
-return "", fmt.Errorf("不支持的分辨率: %s", res)
+stopped := make(chan struct{})
+go func() {
+manager.wg.Wait()
+close(stopped)
+}()
+
+// 先让空闲的工作协程退出，执行中的任务不受影响
+close(manager.stop)
+select {
+case <-stopped:
+case <-time.After(timeout):
+log.Printf("⚠️ 等待转码任务超时，中断剩余任务")
+manager.cancel()
+<-stopped
+}
+manager.cancel()
+log.Printf("转码管理器已停止: %s", manager.workerID)
+manager = nil
 }
+
+// TaskOptions 转码任务的可选分析
+type TaskOptions struct {
+PerTitle       bool // 先按内容复杂度分析，用计算出的码率阶梯替换配置中的固定码率
+QualityMetrics bool // 每个分辨率完成后计算 PSNR/SSIM（及 VMAF）
 }
 
-// 保存到数据库
-resolutionsJSON, _ := json.Marshal(resolutionList)
-if err := db.CreateTranscodeTask(taskID, fileHash, userID, string(resolutionsJSON), ""); err != nil {
+// CreateTask 创建转码任务，profileNames 为编码配置名称（内置的分辨率名称同样有效）
+func CreateTask(fileHash, userID string, profileNames []string, opts TaskOptions) (string, error) {
+taskID := uuid.New().String()
+
+// 解析编码配置并保存快照，执行时不受之后修改配置的影响
+profiles, err := ResolveProfiles(profileNames)
+if err != nil {
 return "", err
 }
 
-// 添加到内存状态
-manager.mu.Lock()
-manager.tasks[taskID] = &TaskStatus{
-TaskID:        taskID,
-Status:        "pending",
-Progress:      0,
-CompletedURLs: []string{},
+// 保存到数据库（即加入队列）
+namesJSON, _ := json.Marshal(profileNames)
+profilesJSON, _ := json.Marshal(profiles)
+if err := db.CreateTranscodeTask(taskID, fileHash, userID, string(namesJSON), string(profilesJSON), opts.PerTitle, opts.QualityMetrics, ""); err != nil {
+return "", err
 }
-manager.mu.Unlock()
 
-// 加入队列
-manager.queue <- taskID
+// 唤醒本实例空闲的工作协程，不阻塞调用方（standalone 模式下由独立工作者轮询领取）
+if manager != nil {
+select {
+case manager.wake <- struct{}{}:
+default:
+}
+}
 
-log.Printf("转码任务已创建: %s (file: %s, resolutions: %v)", taskID, fileHash, resolutionList)
+log.Printf("转码任务已创建: %s (file: %s, profiles: %v)", taskID, fileHash, profileNames)
 return taskID, nil
 }
 
 // GetTaskStatus 获取任务状态
 func GetTaskStatus(taskID string) (*TaskStatus, error) {
-manager.mu.RLock()
-status, ok := manager.tasks[taskID]
-manager.mu.RUnlock()
-
-if ok {
-return status, nil
-}
-
-// 从数据库加载
 task, err := db.GetTranscodeTask(taskID)
 if err != nil {
 return nil, err
@@ -122,44 +264,169 @@ if task == nil {
 return nil, fmt.Errorf("任务不存在: %s", taskID)
 }
 
-var urls []string
+urls := []string{}
 if task.ResultURLs != "" {
 json.Unmarshal([]byte(task.ResultURLs), &urls)
 }
 
-status = &TaskStatus{
+renditions := []RenditionStatus{}
+if task.Renditions != "" {
+json.Unmarshal([]byte(task.Renditions), &renditions)
+}
+
+var ladder []LadderRung
+if task.Ladder != "" {
+json.Unmarshal([]byte(task.Ladder), &ladder)
+}
+
+return &TaskStatus{
 TaskID:        task.TaskID,
 Status:        task.Status,
 Progress:      task.Progress,
 CompletedURLs: urls,
+Error:         task.Error,
+Renditions:    renditions,
+Speed:         task.Speed,
+ETASeconds:    task.ETASeconds,
+LastStderr:    task.LastStderr,
+PerTitle:      task.PerTitle,
+QualityMetrics: task.QualityMetrics,
+Ladder:        ladder,
+}, nil
+}
+
+// worker 工作协程：领取任务 -> 执行 -> 继续领取
+func (m *Manager) worker() {
+defer m.wg.Done()
+for {
+select {
+case <-m.stop:
+return
+default:
 }
 
-return status, nil
+taskID, err := m.claim()
+if err != nil {
+log.Printf("❌ 领取转码任务失败: %v", err)
+}
+if taskID == "" {
+select {
+case <-m.stop:
+return
+case <-m.wake:
+case <-time.After(pollInterval):
+}
+continue
+}
+m.runTask(taskID)
+}
 }
 
-// worker 工作协程
-func (m *Manager) worker() {
-for taskID := range m.queue {
-log.Printf("开始处理转码任务: %s", taskID)
+// claim 从队列中领取一个 pending 任务，没有可领取的任务时返回空字符串
+func (m *Manager) claim() (string, error) {
+tasks, err := db.GetPendingTranscodeTasks(10)
+if err != nil {
+return "", err
+}
+for _, task := range tasks {
+ok, err := db.ClaimTranscodeTask(task.TaskID, m.workerID, leaseTTL)
+if err != nil {
+return "", err
+}
+if ok {
+return task.TaskID, nil
+}
+// 已被其他工作者领取，尝试下一个
+}
+return "", nil
+}
 
-// 更新状态为 processing
-m.updateStatus(taskID, "processing", 0, nil)
+// runTask 持有租约执行任务，租约丢失时取消转码
+func (m *Manager) runTask(taskID string) {
+log.Printf("开始处理转码任务: %s", taskID)
 
-// 执行转码
-if err := m.processTask(taskID); err != nil {
-log.Printf("❌ 转码失败: %s, error: %v", taskID, err)
-m.updateStatus(taskID, "failed", 0, nil)
+ctx, cancel := context.WithCancel(m.ctx)
+defer cancel()
+go m.heartbeat(ctx, cancel, taskID)
 
-// 更新数据库
-db.UpdateTranscodeTaskProgress(taskID, "failed", 0, "")
+if err := m.processTask(ctx, taskID); err != nil {
+if m.ctx.Err() != nil {
+log.Printf("⚠️ 工作者停止，转码任务放回队列: %s", taskID)
+db.ReleaseTranscodeTask(taskID, m.workerID)
+return
+}
+if ctx.Err() != nil {
+if status, _ := db.GetTranscodeTaskStatus(taskID); status == StatusCancelled {
+log.Printf("转码任务已取消: %s", taskID)
 } else {
+log.Printf("⚠️ 转码任务租约丢失，放弃执行: %s", taskID)
+}
+return
+}
+log.Printf("❌ 转码失败: %s, error: %v", taskID, err)
+db.FinishTranscodeTask(taskID, m.workerID, map[string]interface{}{
+"status": StatusFailed,
+"error":  err.Error(),
+})
+return
+}
 log.Printf("✅ 转码完成: %s", taskID)
 }
+
+// heartbeat 定期续期租约，并检查任务是否被取消；任务被取消或租约被回收时终止执行（ffmpeg 随 ctx 被杀死）
+func (m *Manager) heartbeat(ctx context.Context, cancel context.CancelFunc, taskID string) {
+ticker := time.NewTicker(cancelCheckInterval)
+defer ticker.Stop()
+lastRenew := time.Now()
+for {
+select {
+case <-ctx.Done():
+return
+case <-ticker.C:
+if time.Since(lastRenew) < heartbeatInterval {
+if status, err := db.GetTranscodeTaskStatus(taskID); err == nil && status == StatusCancelled {
+cancel()
+return
+}
+continue
+}
+lastRenew = time.Now()
+ok, err := db.HeartbeatTranscodeTask(taskID, m.workerID, leaseTTL)
+if err != nil {
+// 数据库暂时不可用时继续执行，租约过期前还有机会续期
+log.Printf("⚠️ 转码任务心跳失败: %s, error: %v", taskID, err)
+continue
+}
+if !ok {
+cancel()
+return
+}
+}
+}
+}
+
+// reaper 定期把租约过期的任务放回队列
+func (m *Manager) reaper() {
+ticker := time.NewTicker(reapInterval)
+defer ticker.Stop()
+for range ticker.C {
+m.reap()
+}
+}
+
+func (m *Manager) reap() {
+requeued, deadLettered, err := db.RequeueExpiredTranscodeTasks(maxAttempts)
+if err != nil {
+log.Printf("❌ 回收过期转码任务失败: %v", err)
+return
+}
+if requeued > 0 || deadLettered > 0 {
+log.Printf("回收过期转码任务: %d 个重新入队, %d 个进入死信", requeued, deadLettered)
 }
 }
 
 // processTask 处理单个转码任务
-func (m *Manager) processTask(taskID string) error {
+func (m *Manager) processTask(ctx context.Context, taskID string) error {
 // 从数据库获取任务信息
 task, err := db.GetTranscodeTask(taskID)
 if err != nil {
@@ -175,144 +442,533 @@ if file == nil {
 return fmt.Errorf("文件不存在: %s", task.FileHash)
 }
 
-// 解析分辨率列表
-var resolutionList []string
-if err := json.Unmarshal([]byte(task.Resolutions), &resolutionList); err != nil {
+// 编码配置：使用创建任务时的快照，早期任务只记录了名称，按名称重新解析
+var profiles []Profile
+if task.Profiles != "" {
+if err := json.Unmarshal([]byte(task.Profiles), &profiles); err != nil {
+return err
+}
+} else {
+var names []string
+if err := json.Unmarshal([]byte(task.Resolutions), &names); err != nil {
+return err
+}
+if profiles, err = ResolveProfiles(names); err != nil {
 return err
 }
+}
+profileNames := make([]string, len(profiles))
+for i, p := range profiles {
+profileNames[i] = p.Name
+}
+
+// 获取源文件路径（对象存储会先下载到本地临时目录）
+sourceKey, err := storage.Resolve(file.StorageKey, file.StorageBackend)
+if err != nil {
+return fmt.Errorf("源文件不可读: %s: %w", task.FileHash, err)
+}
+sourcePath, cleanup, err := storage.FetchLocal(ctx, sourceKey)
+if err != nil {
+return fmt.Errorf("源文件不存在: %s: %w", sourceKey, err)
+}
+defer cleanup()
 
-// 获取源文件路径
-sourcePath := storage.GetFilePath(task.FileHash, file.Filename)
-if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
-return fmt.Errorf("源文件不存在: %s", sourcePath)
+// 媒体信息：合并时已探测并保存，早期上传的文件在这里补探测并回写。
+// 时长用于把 ffmpeg 的 out_time 换算为百分比，音频流、帧率与 HDR 决定编码参数；
+// 探测失败时按默认参数编码，只在每个分辨率完成时更新进度
+media := MediaInfoFromDB(file)
+if media == nil {
+if media, err = m.prober.Probe(sourcePath); err != nil {
+log.Printf("⚠️ 探测媒体信息失败: %v", err)
+} else if err := db.UpdateFileMedia(task.FileHash, media.Width, media.Height, media.ToDB()); err != nil {
+log.Printf("⚠️ 保存媒体信息失败: %v", err)
+}
+}
+var duration time.Duration
+if media != nil {
+duration = media.Duration
+}
+
+// 之前的执行（RetryTranscode 或中断后重新领取）已完成的分辨率直接复用
+previous := map[string]RenditionStatus{}
+if task.Renditions != "" {
+var renditions []RenditionStatus
+json.Unmarshal([]byte(task.Renditions), &renditions)
+for _, r := range renditions {
+if r.Status == RenditionCompleted && r.StorageKey != "" {
+previous[r.Name] = r
+}
+}
 }
 
-// 逐个转码
+// 逐个转码；高于源视频的分辨率跳过，不做放大
 completedURLs := []string{}
-total := len(resolutionList)
+outputs := []Rendition{}
+failed := []string{}
+total := len(profiles)
+tracker := newProgressTracker(taskID, m.workerID, profileNames, duration)
 
-for i, resName := range resolutionList {
+steps := planLadder(profiles, media)
+ladder, err := m.perTitleLadder(ctx, task, sourcePath, media, steps)
+if err != nil {
+m.discardIfCancelled(taskID, outputs)
+return err
+}
+
+for i, step := range steps {
+profile := step.profile
+if rung, ok := ladderRungFor(ladder, profile.Name); ok {
+profile = rung.apply(profile)
+}
+resName := profile.Name
+if err := ctx.Err(); err != nil {
+m.discardIfCancelled(taskID, outputs)
+return err
+}
+
+if step.skip != "" {
+log.Printf("转码 %s (%d/%d): %s 跳过: %s", taskID, i+1, total, resName, step.skip)
+tracker.skip(i, step.skip)
+continue
+}
+if step.note != "" {
+tracker.annotate(i, step.note)
+}
+
+if r, ok := previous[resName]; ok {
+log.Printf("转码 %s (%d/%d): %s 已完成，跳过", taskID, i+1, total, resName)
+tracker.reuse(i, r)
+completedURLs = append(completedURLs, r.URL)
+outputs = append(outputs, Rendition{Profile: profile, Output: r.Output})
+continue
+}
 log.Printf("转码 %s (%d/%d): %s", taskID, i+1, total, resName)
 
-outputURL, err := transcodeVideo(sourcePath, task.FileHash, resName)
+output, err := m.transcodeWithRetry(ctx, tracker, i, sourcePath, media, task, profile)
 if err != nil {
-log.Printf("❌ 转码失败 (%s): %v", resName, err)
+if ctx.Err() != nil {
+m.discardIfCancelled(taskID, outputs)
+return ctx.Err()
+}
+failed = append(failed, resName)
 continue
 }
 
-completedURLs = append(completedURLs, outputURL)
+if task.QualityMetrics {
+scores, err := m.transcoder.MeasureQuality(ctx, Job{SourcePath: sourcePath, Media: media, FileHash: task.FileHash, TaskID: taskID, Profile: profile}, output)
+if err != nil {
+if ctx.Err() != nil {
+m.discardIfCancelled(taskID, append(outputs, Rendition{Profile: profile, Output: output}))
+return ctx.Err()
+}
+// 质量指标只用于分析，计算失败不影响转码结果
+log.Printf("⚠️ 质量指标计算失败 (%s): %v", resName, err)
+}
+tracker.quality(i, scores, err)
+}
 
-// 更新进度
-progress := int32((i + 1) * 100 / total)
-m.updateStatus(taskID, "processing", progress, completedURLs)
+completedURLs = append(completedURLs, output.URL)
+outputs = append(outputs, Rendition{Profile: profile, Output: output})
 
-// 更新数据库
+// 更新已完成的URL
 urlsJSON, _ := json.Marshal(completedURLs)
-db.UpdateTranscodeTaskProgress(taskID, "processing", progress, string(urlsJSON))
+db.UpdateRunningTranscodeTask(taskID, m.workerID, map[string]interface{}{"result_urls": string(urlsJSON)})
 }
 
-// 标记完成
-status := "completed"
-if len(completedURLs) == 0 {
-status = "failed"
+// 打包为 HLS（及 DASH），生成自适应码率的播放清单；写入新的播放目录，文件记录切换后才删除旧目录
+var streams StreamURLs
+var packageErr error
+version := NewStreamVersion(taskID)
+if len(outputs) > 0 {
+log.Printf("打包 HLS %s: %d 个分辨率", taskID, len(outputs))
+streams, packageErr = m.transcoder.Package(ctx, task.FileHash, version, outputs)
+if packageErr != nil {
+deleteStreamVersion(task.FileHash, version)
+if ctx.Err() != nil {
+m.discardIfCancelled(taskID, outputs)
+return ctx.Err()
+}
+log.Printf("❌ HLS 打包失败: %s, error: %v", taskID, packageErr)
+}
 }
 
-m.updateStatus(taskID, status, 100, completedURLs)
+// 标记完成；有分辨率重试耗尽或打包失败时进入死信状态，保留已完成的分辨率
+status := StatusCompleted
+if len(failed) > 0 || packageErr != nil {
+status = StatusDeadLetter
+}
 
-// 更新数据库
+// 更新数据库（仅当仍持有租约时）
 urlsJSON, _ := json.Marshal(completedURLs)
-db.UpdateTranscodeTaskProgress(taskID, status, 100, string(urlsJSON))
+renditionsJSON, lastStderr := tracker.snapshot()
+updates := map[string]interface{}{
+"status":      status,
+"progress":    100,
+"result_urls": string(urlsJSON),
+"renditions":  renditionsJSON,
+"speed":       0,
+"eta_seconds": 0,
+}
+if len(failed) > 0 {
+updates["error"] = fmt.Sprintf("%v 重试 %d 次后仍转码失败", failed, renditionMaxAttempts)
+updates["last_stderr"] = lastStderr
+} else if packageErr != nil {
+updates["error"] = fmt.Sprintf("打包失败: %v", packageErr)
+var ffmpegErr *FFmpegError
+if errors.As(packageErr, &ffmpegErr) {
+updates["last_stderr"] = ffmpegErr.Stderr
+}
+}
+ok, err := db.FinishTranscodeTask(taskID, m.workerID, updates)
+if err != nil {
+return err
+}
+if !ok {
+// 新的播放目录还没有被文件记录引用，重新领取的工作者会重新打包
+deleteStreamVersion(task.FileHash, version)
+m.discardIfCancelled(taskID, outputs)
+return fmt.Errorf("租约已丢失: %s", taskID)
+}
 
 // 更新文件的转码状态
-db.UpdateFileTranscodeStatus(task.FileHash, task.UserID, status, string(urlsJSON))
+fileStatus := status
+if status == StatusDeadLetter {
+fileStatus = StatusFailed
+}
+db.UpdateFileTranscodeStatus(task.FileHash, task.UserID, fileStatus, string(urlsJSON))
+if streams.HLS != "" {
+previous, err := db.SwitchFileStreamURLs(task.FileHash, task.UserID, streams.HLS, streams.DASH)
+if err != nil {
+log.Printf("⚠️ 更新播放地址失败: %s, error: %v", taskID, err)
+deleteStreamVersion(task.FileHash, version)
+} else if old := StreamVersion(task.FileHash, previous); old != version {
+deleteStreamVersion(task.FileHash, old)
+}
+}
 
 return nil
 }
 
-// transcodeVideo 执行单个视频转码
-func transcodeVideo(sourcePath, fileHash, resolutionName string) (string, error) {
-config, ok := resolutions[resolutionName]
-if !ok {
-return "", fmt.Errorf("不支持的分辨率: %s", resolutionName)
+// perTitleLadder 返回任务的码率阶梯：未开启时为空；之前的执行已分析过时直接复用（重试时码率保持一致）。
+// 分析失败时记录日志并使用配置中的固定码率，只有取消才返回错误
+func (m *Manager) perTitleLadder(ctx context.Context, task *db.TranscodeTask, sourcePath string, media *MediaInfo, steps []ladderStep) ([]LadderRung, error) {
+if !task.PerTitle {
+return nil, nil
+}
+var ladder []LadderRung
+if task.Ladder != "" && json.Unmarshal([]byte(task.Ladder), &ladder) == nil {
+return ladder, nil
 }
 
-// 输出文件路径
-ext := filepath.Ext(sourcePath)
-outputFilename := fmt.Sprintf("%s_%s%s", fileHash, resolutionName, ext)
-outputPath := filepath.Join(storage.StoragePath, "files", outputFilename)
+log.Printf("码率分析 %s: 采样编码中", task.TaskID)
+ladder, err := analyzeLadder(ctx, m.transcoder, sourcePath, media, steps)
+if err != nil {
+if ctx.Err() != nil {
+return nil, ctx.Err()
+}
+log.Printf("⚠️ 码率分析失败 %s，使用配置码率: %v", task.TaskID, err)
+return nil, nil
+}
+ladderJSON, _ := json.Marshal(ladder)
+db.UpdateRunningTranscodeTask(task.TaskID, m.workerID, map[string]interface{}{"ladder": string(ladderJSON)})
+return ladder, nil
+}
 
-// 检查ffmpeg是否存在
-if _, err := exec.LookPath("ffmpeg"); err != nil {
-return "", fmt.Errorf("ffmpeg 未安装或不在 PATH 中")
+// transcodeWithRetry 转码单个分辨率，失败时按指数退避重试
+// 返回错误时 ctx 未取消即表示重试已耗尽
+func (m *Manager) transcodeWithRetry(ctx context.Context, tracker *progressTracker, i int, sourcePath string, media *MediaInfo, task *db.TranscodeTask, profile Profile) (Output, error) {
+resName := profile.Name
+for attempt := int32(1); ; attempt++ {
+tracker.start(i, attempt)
+job := Job{SourcePath: sourcePath, Media: media, FileHash: task.FileHash, TaskID: task.TaskID, Profile: profile}
+output, err := m.transcoder.Transcode(ctx, job, func(p Progress) {
+tracker.update(i, p)
+})
+if err == nil {
+tracker.finish(i, output, nil, "")
+return output, nil
+}
+if ctx.Err() != nil {
+return Output{}, ctx.Err()
+}
+
+stderr := ""
+var ffmpegErr *FFmpegError
+if errors.As(err, &ffmpegErr) {
+stderr = ffmpegErr.Stderr
+}
+if attempt >= renditionMaxAttempts {
+log.Printf("❌ 转码失败 (%s)，重试已耗尽: %v", resName, err)
+tracker.finish(i, Output{}, err, stderr)
+return Output{}, err
+}
+
+delay := retryDelay(m.cfg.RetryDelay, attempt)
+log.Printf("⚠️ 转码失败 (%s)，%v 后第 %d 次重试: %v", resName, delay, attempt+1, err)
+tracker.retrying(i, err, stderr)
+select {
+case <-ctx.Done():
+return Output{}, ctx.Err()
+case <-time.After(delay):
+}
+}
+}
+
+// retryDelay 第 attempt 次失败后的退避时间：base 为 0 时 5s, 10s, 20s ... 最多 2 分钟
+func retryDelay(base time.Duration, attempt int32) time.Duration {
+if base <= 0 {
+base = retryBaseDelay
+}
+delay := base << (attempt - 1)
+if delay <= 0 || delay > retryMaxDelay {
+return retryMaxDelay
+}
+return delay
+}
+
+// discardIfCancelled 任务被取消时删除本次执行已生成的输出
+func (m *Manager) discardIfCancelled(taskID string, outputs []Rendition) {
+if status, _ := db.GetTranscodeTaskStatus(taskID); status == StatusCancelled {
+for _, out := range outputs {
+deleteOutput(out.Output, taskID)
+}
+}
+}
+
+// deleteStreamVersion 删除不再被引用的播放目录；任务可能已被取消，不使用任务的 ctx
+func deleteStreamVersion(fileHash, version string) {
+if err := DeleteStreamVersion(context.Background(), fileHash, version); err != nil {
+log.Printf("⚠️ 删除播放文件失败: %s/%s, error: %v", fileHash, version, err)
+}
+}
+
+// deleteOutput 删除转码输出文件；早期按文件共享的输出仍被 owners 以外的任务引用时保留
+func deleteOutput(out Output, owners ...string) {
+refs, err := db.CountTranscodeOutputReferences(out.StorageKey, owners)
+if err == nil && refs > 0 {
+log.Printf("转码输出仍被 %d 个任务引用，保留: %s", refs, out.URL)
+return
+}
+var key string
+if err == nil {
+key, err = storage.Resolve(out.StorageKey, out.Backend)
+}
+if err == nil {
+err = storage.DeleteObject(key)
+}
+if err != nil {
+log.Printf("⚠️ 删除转码输出失败: %s, error: %v", out.URL, err)
+}
+}
+
+// BackfillRenditionLocations 为记录存储位置之前完成的分辨率补全 storage_key、存储后端与大小。
+// 旧的输出都写在当前存储后端中，key 即去掉前导 / 的地址
+func BackfillRenditionLocations() error {
+tasks, err := db.GetTranscodeTasksWithoutLocations()
+if err != nil {
+return err
+}
+backend := storage.BackendName()
+for _, task := range tasks {
+var renditions []RenditionStatus
+if err := json.Unmarshal([]byte(task.Renditions), &renditions); err != nil {
+log.Printf("⚠️ 无法解析转码任务的分辨率: %s, error: %v", task.TaskID, err)
+continue
+}
+for i := range renditions {
+r := &renditions[i]
+if r.URL == "" || r.StorageKey != "" {
+continue
+}
+r.StorageKey = strings.TrimPrefix(r.URL, "/")
+r.Backend = backend
+if size, err := storage.GetFileSize(r.StorageKey); err == nil {
+r.Size = size
+}
+}
+renditionsJSON, _ := json.Marshal(renditions)
+if err := db.UpdateTranscodeTask(task.TaskID, map[string]interface{}{"renditions": string(renditionsJSON)}); err != nil {
+return err
+}
+}
+if len(tasks) > 0 {
+log.Printf("✅ 已补全 %d 个转码任务的存储位置", len(tasks))
+}
+return nil
 }
 
-// 构建ffmpeg命令
-args := []string{
-"-i", sourcePath,
-"-vf", fmt.Sprintf("scale=%d:%d", config.Width, config.Height),
-"-b:v", config.Bitrate,
-"-c:v", "libx264",
-"-c:a", "aac",
-"-y", // 覆盖输出文件
-outputPath,
+// CancelTask 取消转码任务：排队中的任务直接取消；执行中的任务由持有它的工作者在数秒内终止 ffmpeg
+// 已生成的输出会被删除。返回取消前的状态
+func CancelTask(taskID string) (string, error) {
+previous, err := db.CancelTranscodeTask(taskID)
+if err != nil {
+return "", err
+}
+if previous != StatusPending && previous != StatusProcessing {
+return previous, fmt.Errorf("任务状态为 %s，无法取消", previous)
 }
 
-// 执行转码
-cmd := exec.Command("ffmpeg", args...)
-output, err := cmd.CombinedOutput()
+// 删除已记录的输出；执行中的分辨率由工作者停止后自行清理
+task, err := db.GetTranscodeTask(taskID)
+if err != nil || task == nil {
+return previous, err
+}
+var renditions []RenditionStatus
+if task.Renditions != "" {
+json.Unmarshal([]byte(task.Renditions), &renditions)
+}
+for _, r := range renditions {
+if r.StorageKey != "" {
+deleteOutput(r.Output, taskID)
+}
+}
+db.UpdateTranscodeTask(taskID, map[string]interface{}{"result_urls": "[]", "renditions": ""})
+db.UpdateFileTranscodeStatus(task.FileHash, task.UserID, StatusCancelled, "[]")
+
+log.Printf("转码任务已取消: %s (原状态: %s)", taskID, previous)
+return previous, nil
+}
+
+// DeleteUserOutputs 用户删除视频时删除其转码任务记录的全部输出：执行中的任务先取消，
+// 早期按文件共享的输出仍被其他用户的任务引用时保留
+func DeleteUserOutputs(fileHash, userID string) error {
+tasks, err := db.GetUserTranscodeTasks(fileHash, userID)
 if err != nil {
-return "", fmt.Errorf("ffmpeg 执行失败: %v, output: %s", err, string(output))
+return err
+}
+taskIDs := make([]string, len(tasks))
+for i, task := range tasks {
+taskIDs[i] = task.TaskID
+if task.Status == StatusPending || task.Status == StatusProcessing {
+CancelTask(task.TaskID)
+}
+}
+for _, task := range tasks {
+// 取消时已删除输出并清空记录，重新读取
+current, err := db.GetTranscodeTask(task.TaskID)
+if err != nil || current == nil || current.Renditions == "" {
+continue
+}
+var renditions []RenditionStatus
+json.Unmarshal([]byte(current.Renditions), &renditions)
+for _, r := range renditions {
+if r.StorageKey != "" {
+deleteOutput(r.Output, taskIDs...)
+}
+}
+db.UpdateTranscodeTask(task.TaskID, map[string]interface{}{"result_urls": "[]", "renditions": ""})
+}
+return nil
 }
 
-// 生成URL
-url := fmt.Sprintf("/files/%s", outputFilename)
-return url, nil
+// RetryTask 重新执行失败、进入死信或已取消的任务，已完成的分辨率不会重复转码
+func RetryTask(taskID string) error {
+ok, err := db.RetryTranscodeTask(taskID)
+if err != nil {
+return err
+}
+if !ok {
+return fmt.Errorf("只有失败、死信或已取消的任务可以重试")
 }
 
-// updateStatus 更新任务状态（内存）
-func (m *Manager) updateStatus(taskID, status string, progress int32, urls []string) {
-m.mu.Lock()
-defer m.mu.Unlock()
+if manager != nil {
+select {
+case manager.wake <- struct{}{}:
+default:
+}
+}
 
-if task, ok := m.tasks[taskID]; ok {
-task.Status = status
-task.Progress = progress
-if urls != nil {
-task.CompletedURLs = urls
+log.Printf("转码任务已重新入队: %s", taskID)
+return nil
 }
+
+// FFmpegError ffmpeg 执行失败，Stderr 为输出的末尾部分
+type FFmpegError struct {
+Err    error
+Stderr string
 }
+
+func (e *FFmpegError) Error() string {
+return fmt.Sprintf("ffmpeg 执行失败: %v", e.Err)
 }
 
-// ExtractResolution 提取视频分辨率
-func ExtractResolution(filePath string) (int32, int32, error) {
-// 使用ffprobe获取视频信息
-if _, err := exec.LookPath("ffprobe"); err != nil {
-log.Println("⚠️ ffprobe 未安装，无法提取分辨率")
-return 0, 0, nil
+// transcodeVideo 按编码配置执行单个视频转码并写入 key，media 为源视频的媒体信息（未知时为 nil），
+// onProgress 接收 ffmpeg -progress 的进度快照
+func transcodeVideo(ctx context.Context, sourcePath string, media *MediaInfo, key string, profile Profile, onProgress func(Progress)) (Output, error) {
+// 检查ffmpeg是否存在
+if _, err := exec.LookPath("ffmpeg"); err != nil {
+return Output{}, fmt.Errorf("ffmpeg 未安装或不在 PATH 中")
 }
 
-cmd := exec.Command("ffprobe",
-"-v", "error",
-"-select_streams", "v:0",
-"-show_entries", "stream=width,height",
-"-of", "csv=p=0",
-filePath,
-)
+// 先输出到本地临时文件，完成后再写入存储后端
+ext := profile.Ext()
+outputPath, err := storage.NewLocalTempPath("transcode-*" + ext)
+if err != nil {
+return Output{}, fmt.Errorf("创建临时文件失败: %v", err)
+}
 
-output, err := cmd.Output()
+// 两遍编码的统计文件与输出文件放在一起，结束后删除
+passLog := outputPath + ".pass"
+defer func() {
+logs, _ := filepath.Glob(passLog + "*")
+for _, f := range logs {
+os.Remove(f)
+}
+}()
+
+passes := profile.passes()
+for pass := 1; pass <= passes; pass++ {
+args := profile.ffmpegArgs(sourcePath, outputPath, pass, passLog, media)
+err := runFFmpeg(ctx, args, func(p Progress) {
+if passes > 1 {
+p.Pass, p.Passes = pass, passes
+}
+onProgress(p)
+})
 if err != nil {
-return 0, 0, fmt.Errorf("ffprobe 执行失败: %v", err)
+os.Remove(outputPath)
+return Output{}, err
+}
 }
 
-// 解析输出 (格式: width,height)
-parts := strings.Split(strings.TrimSpace(string(output)), ",")
-if len(parts) != 2 {
-return 0, 0, fmt.Errorf("无法解析分辨率: %s", output)
+info, err := os.Stat(outputPath)
+if err != nil {
+return Output{}, fmt.Errorf("读取转码结果失败: %v", err)
+}
+if err := storage.PutLocalFile(ctx, key, outputPath); err != nil {
+return Output{}, fmt.Errorf("保存转码结果失败: %v", err)
 }
 
-var width, height int32
-fmt.Sscanf(parts[0], "%d", &width)
-fmt.Sscanf(parts[1], "%d", &height)
+return Output{
+URL:        "/" + key,
+StorageKey: key,
+Backend:    storage.BackendName(),
+Size:       info.Size(),
+}, nil
+}
 
-return width, height, nil
+// runFFmpeg 执行 ffmpeg：stdout 解析进度，stderr 只保留末尾用于错误信息
+func runFFmpeg(ctx context.Context, args []string, onProgress func(Progress)) error {
+_, err := runFFmpegOutput(ctx, args, onProgress)
+return err
+}
+
+// runFFmpegOutput 同 runFFmpeg，成功时返回 stderr 的末尾部分（psnr/ssim 等滤镜的统计结果输出在这里）
+func runFFmpegOutput(ctx context.Context, args []string, onProgress func(Progress)) (string, error) {
+cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+stderr := newTailBuffer(8 * 1024)
+cmd.Stderr = stderr
+stdout, err := cmd.StdoutPipe()
+if err != nil {
+return "", err
+}
+if err := cmd.Start(); err != nil {
+return "", fmt.Errorf("ffmpeg 启动失败: %v", err)
+}
+parseProgress(stdout, onProgress)
+io.Copy(io.Discard, stdout) // 解析中断时继续读完，避免 ffmpeg 阻塞在写 stdout
+if err := cmd.Wait(); err != nil {
+return "", &FFmpegError{Err: err, Stderr: stderr.String()}
+}
+return stderr.String(), nil
 }
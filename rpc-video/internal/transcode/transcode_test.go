@@ -0,0 +1,28 @@
+package transcode
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		base    time.Duration
+		attempt int32
+		want    time.Duration
+	}{
+		{0, 1, 5 * time.Second},
+		{0, 2, 10 * time.Second},
+		{0, 3, 20 * time.Second},
+		{0, 5, 80 * time.Second},
+		{0, 6, retryMaxDelay},
+		{0, 64, retryMaxDelay},
+		{10 * time.Millisecond, 1, 10 * time.Millisecond},
+		{10 * time.Millisecond, 3, 40 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(tt.base, tt.attempt); got != tt.want {
+			t.Errorf("retryDelay(%v, %d) = %v, want %v", tt.base, tt.attempt, got, tt.want)
+		}
+	}
+}
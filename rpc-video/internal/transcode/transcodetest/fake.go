@@ -0,0 +1,215 @@
+// Package transcodetest 提供不依赖 ffmpeg/ffprobe 的转码器与探测器：
+// 按脚本上报进度、返回失败，成功时写入合成的输出文件，用于测试转码工作者的调度、进度与状态流转
+package transcodetest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"video-platform-microservice/rpc-video/internal/storage"
+	"video-platform-microservice/rpc-video/internal/transcode"
+)
+
+// Attempt 一次编码尝试的脚本：依次上报 Progress（每次间隔 Interval），然后返回 Err；
+// Err 为 nil 时写入合成的输出文件并返回其地址
+type Attempt struct {
+	Progress []transcode.Progress
+	Interval time.Duration
+	Err      error
+}
+
+// Fail 模拟 ffmpeg 以非零状态退出，stderr 会作为任务的 last_stderr 保存
+func Fail(stderr string) error {
+	return &transcode.FFmpegError{Err: errors.New("exit status 1"), Stderr: stderr}
+}
+
+// Transcoder 按脚本执行的转码器。Script 以编码配置名称为键，按顺序给出每次尝试的结果；
+// 脚本用完（或没有脚本）的尝试直接成功
+type Transcoder struct {
+	Script     map[string][]Attempt
+	PackageErr error                    // Package 返回的错误，为 nil 时写入合成的主播放列表
+	Bitrates   map[string]int64         // ProbeBitrate 按编码配置名称返回的采样码率，没有时返回错误
+	Quality    *transcode.QualityScores // MeasureQuality 返回的指标，为 nil 时返回错误
+	Sprites    int                      // RenderSprites 生成的雪碧图张数，为 0 时生成 1 张
+
+	mu       sync.Mutex
+	attempts map[string]int
+	packaged [][]transcode.Rendition
+}
+
+// Attempts 返回编码配置已执行的尝试次数
+func (t *Transcoder) Attempts(profile string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.attempts[profile]
+}
+
+// Packaged 返回每次 Package 调用收到的分辨率
+func (t *Transcoder) Packaged() [][]transcode.Rendition {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([][]transcode.Rendition(nil), t.packaged...)
+}
+
+// next 取出本次尝试的脚本
+func (t *Transcoder) next(profile string) Attempt {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.attempts == nil {
+		t.attempts = map[string]int{}
+	}
+	n := t.attempts[profile]
+	t.attempts[profile] = n + 1
+	if script := t.Script[profile]; n < len(script) {
+		return script[n]
+	}
+	return Attempt{}
+}
+
+// Transcode 按脚本上报进度并返回结果
+func (t *Transcoder) Transcode(ctx context.Context, job transcode.Job, onProgress func(transcode.Progress)) (transcode.Output, error) {
+	attempt := t.next(job.Profile.Name)
+	for _, p := range attempt.Progress {
+		if attempt.Interval > 0 {
+			select {
+			case <-ctx.Done():
+				return transcode.Output{}, ctx.Err()
+			case <-time.After(attempt.Interval):
+			}
+		}
+		onProgress(p)
+	}
+	if err := ctx.Err(); err != nil {
+		return transcode.Output{}, err
+	}
+	if attempt.Err != nil {
+		return transcode.Output{}, attempt.Err
+	}
+
+	content := fmt.Sprintf("fake %s %s %dx%d\n", job.Profile.Codec, job.Profile.VideoBitrate, job.Profile.Width, job.Profile.Height)
+	key := job.OutputKey()
+	if err := put(ctx, key, content); err != nil {
+		return transcode.Output{}, err
+	}
+	return transcode.Output{
+		URL:        "/" + key,
+		StorageKey: key,
+		Backend:    storage.BackendName(),
+		Size:       int64(len(content)),
+	}, nil
+}
+
+// Package 记录收到的分辨率并写入合成的主播放列表
+func (t *Transcoder) Package(ctx context.Context, fileHash, version string, renditions []transcode.Rendition) (transcode.StreamURLs, error) {
+	t.mu.Lock()
+	t.packaged = append(t.packaged, append([]transcode.Rendition(nil), renditions...))
+	t.mu.Unlock()
+	if t.PackageErr != nil {
+		return transcode.StreamURLs{}, t.PackageErr
+	}
+
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	for _, r := range renditions {
+		fmt.Fprintf(&b, "#EXT-X-STREAM-INF:BANDWIDTH=1,RESOLUTION=%dx%d\n%s\n", r.Profile.Width, r.Profile.Height, r.URL)
+	}
+	name := version + "/master.m3u8"
+	if err := put(ctx, transcode.StreamKey(fileHash, name), b.String()); err != nil {
+		return transcode.StreamURLs{}, err
+	}
+	return transcode.StreamURLs{HLS: transcode.PlaybackURL(fileHash, name)}, nil
+}
+
+// ProbeBitrate 返回 Bitrates 中的采样码率
+func (t *Transcoder) ProbeBitrate(ctx context.Context, job transcode.Job, segments []time.Duration) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	bitrate, ok := t.Bitrates[job.Profile.Name]
+	if !ok {
+		return 0, fmt.Errorf("没有 %s 的采样码率", job.Profile.Name)
+	}
+	return bitrate, nil
+}
+
+// MeasureQuality 返回 Quality 的副本
+func (t *Transcoder) MeasureQuality(ctx context.Context, job transcode.Job, out transcode.Output) (*transcode.QualityScores, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if t.Quality == nil {
+		return nil, errors.New("没有质量指标")
+	}
+	scores := *t.Quality
+	return &scores, nil
+}
+
+// ExtractFrame 写入合成的封面文件
+func (t *Transcoder) ExtractFrame(ctx context.Context, sourcePath string, at time.Duration, outputPath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath, []byte(fmt.Sprintf("fake frame %v\n", at)), 0644)
+}
+
+// RenderSprites 写入 Sprites 张合成的雪碧图
+func (t *Transcoder) RenderSprites(ctx context.Context, sourcePath string, interval time.Duration, width, height int, dir string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	count := t.Sprites
+	if count <= 0 {
+		count = 1
+	}
+	for i := 0; i < count; i++ {
+		content := fmt.Sprintf("fake sprite %d %dx%d\n", i, width, height)
+		if err := os.WriteFile(filepath.Join(dir, transcode.SpriteName(i)), []byte(content), 0644); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+// put 写入合成文件
+func put(ctx context.Context, key, content string) error {
+	return storage.GetBackend().Put(ctx, key, bytes.NewReader([]byte(content)), int64(len(content)))
+}
+
+// Prober 返回固定结果的探测器
+type Prober struct {
+	Media   *transcode.MediaInfo
+	Streams []transcode.StreamInfo
+	Err     error
+}
+
+// Probe 返回 Media 的副本或 Err
+func (p Prober) Probe(string) (*transcode.MediaInfo, error) {
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	if p.Media == nil {
+		return nil, transcode.ErrProbeUnavailable
+	}
+	media := *p.Media
+	return &media, nil
+}
+
+// ProbeStreams 返回 Streams 或 Err
+func (p Prober) ProbeStreams(string) ([]transcode.StreamInfo, error) {
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	return append([]transcode.StreamInfo(nil), p.Streams...), nil
+}
+
+var (
+	_ transcode.Transcoder = (*Transcoder)(nil)
+	_ transcode.Prober     = Prober{}
+)
@@ -0,0 +1,286 @@
+package transcode_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	commonDb "github.com/see1youagain/video-platform-microservice/common/db"
+	"gorm.io/gorm/logger"
+	"video-platform-microservice/rpc-video/internal/db"
+	"video-platform-microservice/rpc-video/internal/storage"
+	"video-platform-microservice/rpc-video/internal/transcode"
+	"video-platform-microservice/rpc-video/internal/transcode/transcodetest"
+)
+
+const (
+	testFileHash = "0123456789abcdef0123456789abcdef"
+	testUserID   = "user-1"
+)
+
+var (
+	dbOnce sync.Once
+	dbErr  error
+)
+
+// startWorker 连接测试库、清空队列并以 fake 转码器启动工作者。
+// 队列持久化在 MySQL 中，没有设置 TEST_DB_NAME 时跳过；测试会清空该库中的文件、blob 与转码任务
+func startWorker(t *testing.T, fake *transcodetest.Transcoder) {
+	t.Helper()
+	name := os.Getenv("TEST_DB_NAME")
+	if name == "" {
+		t.Skip("set TEST_DB_NAME (and DB_HOST, DB_PORT, DB_USER, DB_PASSWORD) to run worker tests against MySQL")
+	}
+	dbOnce.Do(func() {
+		dbErr = commonDb.InitDBWithConfig(commonDb.Config{
+			Host:     getenv("DB_HOST", "127.0.0.1"),
+			Port:     getenv("DB_PORT", "3306"),
+			User:     getenv("DB_USER", "root"),
+			Password: os.Getenv("DB_PASSWORD"),
+			DBName:   name,
+			LogLevel: logger.Silent,
+		})
+		if dbErr == nil {
+			dbErr = db.Init()
+		}
+	})
+	if dbErr != nil {
+		t.Fatalf("init database: %v", dbErr)
+	}
+	for _, model := range []interface{}{&db.TranscodeTask{}, &db.File{}, &db.Blob{}} {
+		if err := db.GetDB().Where("1 = 1").Delete(model).Error; err != nil {
+			t.Fatalf("clear %T: %v", model, err)
+		}
+	}
+
+	root := t.TempDir()
+	storage.StoragePath = root
+	if err := os.MkdirAll(filepath.Join(root, "tmp"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	storage.SetBackend(storage.NewLocalBackend(root))
+
+	source := []byte("source video")
+	sourceKey := storage.GetBlobKey(testFileHash, "video.mp4")
+	if err := storage.GetBackend().Put(context.Background(), sourceKey, bytes.NewReader(source), int64(len(source))); err != nil {
+		t.Fatal(err)
+	}
+	err := db.GetDB().Create(&db.File{
+		FileHash:       testFileHash,
+		UserID:         testUserID,
+		Filename:       "video.mp4",
+		FileSize:       int64(len(source)),
+		URL:            "/" + sourceKey,
+		StorageKey:     sourceKey,
+		StorageBackend: storage.BackendName(),
+		Status:         "finished",
+	}).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transcode.InitTranscodeManager(transcode.Config{
+		Workers:    1,
+		RetryDelay: 10 * time.Millisecond,
+		Transcoder: fake,
+		Prober: transcodetest.Prober{Media: &transcode.MediaInfo{
+			Duration:   10 * time.Second,
+			Width:      1920,
+			Height:     1080,
+			VideoCodec: "h264",
+		}},
+	})
+	t.Cleanup(func() { transcode.Shutdown(5 * time.Second) })
+}
+
+func getenv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// waitFor 轮询任务状态直到 done 返回 true
+func waitFor(t *testing.T, taskID string, what string, done func(*transcode.TaskStatus) bool) *transcode.TaskStatus {
+	t.Helper()
+	deadline := time.Now().Add(20 * time.Second)
+	for {
+		status, err := transcode.GetTaskStatus(taskID)
+		if err == nil && done(status) {
+			return status
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s: status = %+v, err = %v", what, status, err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func hasStatus(want string) func(*transcode.TaskStatus) bool {
+	return func(s *transcode.TaskStatus) bool { return s.Status == want }
+}
+
+func createTask(t *testing.T, profiles ...string) string {
+	t.Helper()
+	taskID, err := transcode.CreateTask(testFileHash, testUserID, profiles, transcode.TaskOptions{})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	return taskID
+}
+
+// outputKeys 列出存储中的编码输出
+func outputKeys(t *testing.T) []string {
+	t.Helper()
+	objects, err := storage.GetBackend().List(context.Background(), "files/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]string, len(objects))
+	for i, obj := range objects {
+		keys[i] = obj.Key
+	}
+	return keys
+}
+
+func TestWorkerReportsProgress(t *testing.T) {
+	var steps []transcode.Progress
+	for i := 1; i <= 8; i++ {
+		steps = append(steps, transcode.Progress{OutTime: time.Duration(i) * 1250 * time.Millisecond, Speed: 2, Done: i == 8})
+	}
+	fake := &transcodetest.Transcoder{Script: map[string][]transcodetest.Attempt{
+		"720p": {{Progress: steps, Interval: 300 * time.Millisecond}},
+	}}
+	startWorker(t, fake)
+	taskID := createTask(t, "720p")
+
+	running := waitFor(t, taskID, "partial progress", func(s *transcode.TaskStatus) bool {
+		return s.Status == transcode.StatusProcessing && s.Progress > 0 && s.Progress < 100
+	})
+	if r := running.Renditions[0]; r.Status != transcode.RenditionProcessing || r.Speed != 2 || r.ETASeconds <= 0 {
+		t.Errorf("running rendition = %+v, want processing with speed 2 and an ETA", r)
+	}
+
+	done := waitFor(t, taskID, "completion", hasStatus(transcode.StatusCompleted))
+	if done.Progress != 100 || len(done.CompletedURLs) != 1 {
+		t.Errorf("completed task progress = %d, urls = %v", done.Progress, done.CompletedURLs)
+	}
+	r := done.Renditions[0]
+	if r.Status != transcode.RenditionCompleted || r.Attempts != 1 || r.StorageKey == "" || r.Size == 0 {
+		t.Errorf("completed rendition = %+v", r)
+	}
+	if !strings.Contains(r.StorageKey, taskID) {
+		t.Errorf("output key %q is not unique to task %s", r.StorageKey, taskID)
+	}
+	if len(fake.Packaged()) != 1 {
+		t.Errorf("Package called %d times, want 1", len(fake.Packaged()))
+	}
+
+	file, err := db.GetFileByHashAndUser(testFileHash, testUserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.TranscodeStatus != transcode.StatusCompleted || transcode.StreamVersion(testFileHash, file.PlaybackURL) == "" {
+		t.Errorf("file transcode status = %q, playback url = %q", file.TranscodeStatus, file.PlaybackURL)
+	}
+}
+
+func TestWorkerRetriesWithBackoff(t *testing.T) {
+	fake := &transcodetest.Transcoder{Script: map[string][]transcodetest.Attempt{
+		"720p": {{Err: transcodetest.Fail("first failure")}, {Err: transcodetest.Fail("second failure")}},
+	}}
+	startWorker(t, fake)
+	taskID := createTask(t, "720p")
+
+	done := waitFor(t, taskID, "completion", hasStatus(transcode.StatusCompleted))
+	if got := fake.Attempts("720p"); got != 3 {
+		t.Errorf("attempts = %d, want 3", got)
+	}
+	if r := done.Renditions[0]; r.Status != transcode.RenditionCompleted || r.Attempts != 3 || r.Error != "" {
+		t.Errorf("rendition = %+v, want completed on attempt 3", r)
+	}
+}
+
+func TestWorkerDeadLettersAndRetries(t *testing.T) {
+	fake := &transcodetest.Transcoder{Script: map[string][]transcodetest.Attempt{
+		"480p": {
+			{Err: transcodetest.Fail("failure 1")},
+			{Err: transcodetest.Fail("failure 2")},
+			{Err: transcodetest.Fail("failure 3")},
+		},
+	}}
+	startWorker(t, fake)
+	taskID := createTask(t, "480p", "720p")
+
+	dead := waitFor(t, taskID, "dead letter", hasStatus(transcode.StatusDeadLetter))
+	if !strings.Contains(dead.LastStderr, "failure 3") || dead.Error == "" {
+		t.Errorf("dead letter error = %q, last stderr = %q", dead.Error, dead.LastStderr)
+	}
+	if r := dead.Renditions[0]; r.Status != transcode.RenditionFailed || r.Attempts != 3 {
+		t.Errorf("failed rendition = %+v", r)
+	}
+	if r := dead.Renditions[1]; r.Status != transcode.RenditionCompleted {
+		t.Errorf("720p rendition = %+v, want completed", r)
+	}
+
+	if err := transcode.RetryTask(taskID); err != nil {
+		t.Fatalf("RetryTask: %v", err)
+	}
+	done := waitFor(t, taskID, "completion after retry", hasStatus(transcode.StatusCompleted))
+	if got := fake.Attempts("480p"); got != 4 {
+		t.Errorf("480p attempts = %d, want 4", got)
+	}
+	if got := fake.Attempts("720p"); got != 1 {
+		t.Errorf("720p attempts = %d, want 1 (completed rendition reused)", got)
+	}
+	if len(done.CompletedURLs) != 2 {
+		t.Errorf("completed urls = %v, want 2", done.CompletedURLs)
+	}
+}
+
+func TestWorkerCancel(t *testing.T) {
+	long := make([]transcode.Progress, 100)
+	for i := range long {
+		long[i] = transcode.Progress{OutTime: time.Duration(i) * 100 * time.Millisecond, Speed: 1}
+	}
+	fake := &transcodetest.Transcoder{Script: map[string][]transcodetest.Attempt{
+		"720p": {{Progress: long, Interval: 100 * time.Millisecond}},
+	}}
+	startWorker(t, fake)
+	taskID := createTask(t, "480p", "720p")
+
+	waitFor(t, taskID, "second rendition to start", func(s *transcode.TaskStatus) bool {
+		return len(s.Renditions) == 2 && s.Renditions[0].Status == transcode.RenditionCompleted &&
+			s.Renditions[1].Status == transcode.RenditionProcessing
+	})
+	previous, err := transcode.CancelTask(taskID)
+	if err != nil || previous != transcode.StatusProcessing {
+		t.Fatalf("CancelTask = %q, %v", previous, err)
+	}
+
+	// 工作者在 cancelCheckInterval 内发现取消并停止编码，Shutdown 不需要等到超时
+	started := time.Now()
+	transcode.Shutdown(15 * time.Second)
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("worker kept encoding for %v after cancel", elapsed)
+	}
+
+	status, err := transcode.GetTaskStatus(taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != transcode.StatusCancelled || len(status.CompletedURLs) != 0 {
+		t.Errorf("cancelled task = %+v", status)
+	}
+	if keys := outputKeys(t); len(keys) != 0 {
+		t.Errorf("outputs left after cancel: %v", keys)
+	}
+	if got := fake.Attempts("720p"); got != 1 {
+		t.Errorf("720p attempts = %d, want 1", got)
+	}
+}
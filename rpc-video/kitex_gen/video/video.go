@@ -5,16 +5,65 @@ package video
 import (
 	"context"
 	"fmt"
+	"github.com/cloudwego/kitex/pkg/streaming"
 )
 
+type PossessionChallenge struct {
+	Nonce  string `thrift:"nonce,1" frugal:"1,default,string" json:"nonce"`
+	Offset int64  `thrift:"offset,2" frugal:"2,default,i64" json:"offset"`
+	Length int64  `thrift:"length,3" frugal:"3,default,i64" json:"length"`
+}
+
+func NewPossessionChallenge() *PossessionChallenge {
+	return &PossessionChallenge{}
+}
+
+func (p *PossessionChallenge) InitDefault() {
+}
+
+func (p *PossessionChallenge) GetNonce() (v string) {
+	return p.Nonce
+}
+
+func (p *PossessionChallenge) GetOffset() (v int64) {
+	return p.Offset
+}
+
+func (p *PossessionChallenge) GetLength() (v int64) {
+	return p.Length
+}
+func (p *PossessionChallenge) SetNonce(val string) {
+	p.Nonce = val
+}
+func (p *PossessionChallenge) SetOffset(val int64) {
+	p.Offset = val
+}
+func (p *PossessionChallenge) SetLength(val int64) {
+	p.Length = val
+}
+
+func (p *PossessionChallenge) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("PossessionChallenge(%+v)", *p)
+}
+
+var fieldIDToName_PossessionChallenge = map[int16]string{
+	1: "nonce",
+	2: "offset",
+	3: "length",
+}
+
 type InitUploadReq struct {
-	FileHash  string `thrift:"file_hash,1" frugal:"1,default,string" json:"file_hash"`
-	Filename  string `thrift:"filename,2" frugal:"2,default,string" json:"filename"`
-	FileSize  int64  `thrift:"file_size,3" frugal:"3,default,i64" json:"file_size"`
-	UserId    string `thrift:"user_id,4" frugal:"4,default,string" json:"user_id"`
-	Width     int32  `thrift:"width,5" frugal:"5,default,i32" json:"width"`
-	Height    int32  `thrift:"height,6" frugal:"6,default,i32" json:"height"`
-	RequestId string `thrift:"request_id,7" frugal:"7,default,string" json:"request_id"`
+	FileHash        string  `thrift:"file_hash,1" frugal:"1,default,string" json:"file_hash"`
+	Filename        string  `thrift:"filename,2" frugal:"2,default,string" json:"filename"`
+	FileSize        int64   `thrift:"file_size,3" frugal:"3,default,i64" json:"file_size"`
+	UserId          string  `thrift:"user_id,4" frugal:"4,default,string" json:"user_id"`
+	Width           int32   `thrift:"width,5" frugal:"5,default,i32" json:"width"`
+	Height          int32   `thrift:"height,6" frugal:"6,default,i32" json:"height"`
+	RequestId       string  `thrift:"request_id,7" frugal:"7,default,string" json:"request_id"`
+	ChallengeAnswer *string `thrift:"challenge_answer,8,optional" frugal:"8,optional,string" json:"challenge_answer,omitempty"`
 }
 
 func NewInitUploadReq() *InitUploadReq {
@@ -51,6 +100,15 @@ func (p *InitUploadReq) GetHeight() (v int32) {
 func (p *InitUploadReq) GetRequestId() (v string) {
 	return p.RequestId
 }
+
+var InitUploadReq_ChallengeAnswer_DEFAULT string
+
+func (p *InitUploadReq) GetChallengeAnswer() (v string) {
+	if !p.IsSetChallengeAnswer() {
+		return InitUploadReq_ChallengeAnswer_DEFAULT
+	}
+	return *p.ChallengeAnswer
+}
 func (p *InitUploadReq) SetFileHash(val string) {
 	p.FileHash = val
 }
@@ -72,6 +130,13 @@ func (p *InitUploadReq) SetHeight(val int32) {
 func (p *InitUploadReq) SetRequestId(val string) {
 	p.RequestId = val
 }
+func (p *InitUploadReq) SetChallengeAnswer(val *string) {
+	p.ChallengeAnswer = val
+}
+
+func (p *InitUploadReq) IsSetChallengeAnswer() bool {
+	return p.ChallengeAnswer != nil
+}
 
 func (p *InitUploadReq) String() string {
 	if p == nil {
@@ -88,14 +153,16 @@ var fieldIDToName_InitUploadReq = map[int16]string{
 	5: "width",
 	6: "height",
 	7: "request_id",
+	8: "challenge_answer",
 }
 
 type InitUploadResp struct {
-	Code           int32    `thrift:"code,1" frugal:"1,default,i32" json:"code"`
-	Msg            string   `thrift:"msg,2" frugal:"2,default,string" json:"msg"`
-	Status         string   `thrift:"status,3" frugal:"3,default,string" json:"status"`
-	FinishedChunks []string `thrift:"finished_chunks,4" frugal:"4,default,list<string>" json:"finished_chunks"`
-	Url            string   `thrift:"url,5" frugal:"5,default,string" json:"url"`
+	Code           int32                `thrift:"code,1" frugal:"1,default,i32" json:"code"`
+	Msg            string               `thrift:"msg,2" frugal:"2,default,string" json:"msg"`
+	Status         string               `thrift:"status,3" frugal:"3,default,string" json:"status"`
+	FinishedChunks []string             `thrift:"finished_chunks,4" frugal:"4,default,list<string>" json:"finished_chunks"`
+	Url            string               `thrift:"url,5" frugal:"5,default,string" json:"url"`
+	Challenge      *PossessionChallenge `thrift:"challenge,6,optional" frugal:"6,optional,PossessionChallenge" json:"challenge,omitempty"`
 }
 
 func NewInitUploadResp() *InitUploadResp {
@@ -124,6 +191,15 @@ func (p *InitUploadResp) GetFinishedChunks() (v []string) {
 func (p *InitUploadResp) GetUrl() (v string) {
 	return p.Url
 }
+
+var InitUploadResp_Challenge_DEFAULT *PossessionChallenge
+
+func (p *InitUploadResp) GetChallenge() (v *PossessionChallenge) {
+	if !p.IsSetChallenge() {
+		return InitUploadResp_Challenge_DEFAULT
+	}
+	return p.Challenge
+}
 func (p *InitUploadResp) SetCode(val int32) {
 	p.Code = val
 }
@@ -139,6 +215,13 @@ func (p *InitUploadResp) SetFinishedChunks(val []string) {
 func (p *InitUploadResp) SetUrl(val string) {
 	p.Url = val
 }
+func (p *InitUploadResp) SetChallenge(val *PossessionChallenge) {
+	p.Challenge = val
+}
+
+func (p *InitUploadResp) IsSetChallenge() bool {
+	return p.Challenge != nil
+}
 
 func (p *InitUploadResp) String() string {
 	if p == nil {
@@ -153,13 +236,15 @@ var fieldIDToName_InitUploadResp = map[int16]string{
 	3: "status",
 	4: "finished_chunks",
 	5: "url",
+	6: "challenge",
 }
 
 type UploadChunkReq struct {
-	FileHash string `thrift:"file_hash,1" frugal:"1,default,string" json:"file_hash"`
-	Index    string `thrift:"index,2" frugal:"2,default,string" json:"index"`
-	Data     []byte `thrift:"data,3" frugal:"3,default,binary" json:"data"`
-	UserId   string `thrift:"user_id,4" frugal:"4,default,string" json:"user_id"`
+	FileHash  string  `thrift:"file_hash,1" frugal:"1,default,string" json:"file_hash"`
+	Index     string  `thrift:"index,2" frugal:"2,default,string" json:"index"`
+	Data      []byte  `thrift:"data,3" frugal:"3,default,binary" json:"data"`
+	UserId    string  `thrift:"user_id,4" frugal:"4,default,string" json:"user_id"`
+	ChunkHash *string `thrift:"chunk_hash,5,optional" frugal:"5,optional,string" json:"chunk_hash,omitempty"`
 }
 
 func NewUploadChunkReq() *UploadChunkReq {
@@ -184,6 +269,15 @@ func (p *UploadChunkReq) GetData() (v []byte) {
 func (p *UploadChunkReq) GetUserId() (v string) {
 	return p.UserId
 }
+
+var UploadChunkReq_ChunkHash_DEFAULT string
+
+func (p *UploadChunkReq) GetChunkHash() (v string) {
+	if !p.IsSetChunkHash() {
+		return UploadChunkReq_ChunkHash_DEFAULT
+	}
+	return *p.ChunkHash
+}
 func (p *UploadChunkReq) SetFileHash(val string) {
 	p.FileHash = val
 }
@@ -196,6 +290,13 @@ func (p *UploadChunkReq) SetData(val []byte) {
 func (p *UploadChunkReq) SetUserId(val string) {
 	p.UserId = val
 }
+func (p *UploadChunkReq) SetChunkHash(val *string) {
+	p.ChunkHash = val
+}
+
+func (p *UploadChunkReq) IsSetChunkHash() bool {
+	return p.ChunkHash != nil
+}
 
 func (p *UploadChunkReq) String() string {
 	if p == nil {
@@ -209,6 +310,7 @@ var fieldIDToName_UploadChunkReq = map[int16]string{
 	2: "index",
 	3: "data",
 	4: "user_id",
+	5: "chunk_hash",
 }
 
 type UploadChunkResp struct {
@@ -249,6 +351,98 @@ var fieldIDToName_UploadChunkResp = map[int16]string{
 	2: "msg",
 }
 
+type UploadChunkFrame struct {
+	FileHash  string  `thrift:"file_hash,1" frugal:"1,default,string" json:"file_hash"`
+	Index     string  `thrift:"index,2" frugal:"2,default,string" json:"index"`
+	Data      []byte  `thrift:"data,3" frugal:"3,default,binary" json:"data"`
+	UserId    string  `thrift:"user_id,4" frugal:"4,default,string" json:"user_id"`
+	ChunkHash *string `thrift:"chunk_hash,5,optional" frugal:"5,optional,string" json:"chunk_hash,omitempty"`
+	Size      *int64  `thrift:"size,6,optional" frugal:"6,optional,i64" json:"size,omitempty"`
+}
+
+func NewUploadChunkFrame() *UploadChunkFrame {
+	return &UploadChunkFrame{}
+}
+
+func (p *UploadChunkFrame) InitDefault() {
+}
+
+func (p *UploadChunkFrame) GetFileHash() (v string) {
+	return p.FileHash
+}
+
+func (p *UploadChunkFrame) GetIndex() (v string) {
+	return p.Index
+}
+
+func (p *UploadChunkFrame) GetData() (v []byte) {
+	return p.Data
+}
+
+func (p *UploadChunkFrame) GetUserId() (v string) {
+	return p.UserId
+}
+
+var UploadChunkFrame_ChunkHash_DEFAULT string
+
+func (p *UploadChunkFrame) GetChunkHash() (v string) {
+	if !p.IsSetChunkHash() {
+		return UploadChunkFrame_ChunkHash_DEFAULT
+	}
+	return *p.ChunkHash
+}
+
+var UploadChunkFrame_Size_DEFAULT int64
+
+func (p *UploadChunkFrame) GetSize() (v int64) {
+	if !p.IsSetSize() {
+		return UploadChunkFrame_Size_DEFAULT
+	}
+	return *p.Size
+}
+func (p *UploadChunkFrame) SetFileHash(val string) {
+	p.FileHash = val
+}
+func (p *UploadChunkFrame) SetIndex(val string) {
+	p.Index = val
+}
+func (p *UploadChunkFrame) SetData(val []byte) {
+	p.Data = val
+}
+func (p *UploadChunkFrame) SetUserId(val string) {
+	p.UserId = val
+}
+func (p *UploadChunkFrame) SetChunkHash(val *string) {
+	p.ChunkHash = val
+}
+func (p *UploadChunkFrame) SetSize(val *int64) {
+	p.Size = val
+}
+
+func (p *UploadChunkFrame) IsSetChunkHash() bool {
+	return p.ChunkHash != nil
+}
+
+func (p *UploadChunkFrame) IsSetSize() bool {
+	return p.Size != nil
+}
+
+func (p *UploadChunkFrame) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("UploadChunkFrame(%+v)", *p)
+}
+
+var fieldIDToName_UploadChunkFrame = map[int16]string{
+	1: "file_hash",
+	2: "index",
+	3: "data",
+	4: "user_id",
+	5: "chunk_hash",
+	6: "size",
+}
+
 type MergeFileReq struct {
 	FileHash    string `thrift:"file_hash,1" frugal:"1,default,string" json:"file_hash"`
 	Filename    string `thrift:"filename,2" frugal:"2,default,string" json:"filename"`
@@ -333,9 +527,12 @@ var fieldIDToName_MergeFileReq = map[int16]string{
 }
 
 type MergeFileResp struct {
-	Code int32  `thrift:"code,1" frugal:"1,default,i32" json:"code"`
-	Msg  string `thrift:"msg,2" frugal:"2,default,string" json:"msg"`
-	Url  string `thrift:"url,3" frugal:"3,default,string" json:"url"`
+	Code      int32   `thrift:"code,1" frugal:"1,default,i32" json:"code"`
+	Msg       string  `thrift:"msg,2" frugal:"2,default,string" json:"msg"`
+	Url       string  `thrift:"url,3" frugal:"3,default,string" json:"url"`
+	BadChunks []int32 `thrift:"bad_chunks,4" frugal:"4,default,list<i32>" json:"bad_chunks"`
+	JobId     string  `thrift:"job_id,5" frugal:"5,default,string" json:"job_id"`
+	Status    string  `thrift:"status,6" frugal:"6,default,string" json:"status"`
 }
 
 func NewMergeFileResp() *MergeFileResp {
@@ -356,6 +553,18 @@ func (p *MergeFileResp) GetMsg() (v string) {
 func (p *MergeFileResp) GetUrl() (v string) {
 	return p.Url
 }
+
+func (p *MergeFileResp) GetBadChunks() (v []int32) {
+	return p.BadChunks
+}
+
+func (p *MergeFileResp) GetJobId() (v string) {
+	return p.JobId
+}
+
+func (p *MergeFileResp) GetStatus() (v string) {
+	return p.Status
+}
 func (p *MergeFileResp) SetCode(val int32) {
 	p.Code = val
 }
@@ -365,6 +574,15 @@ func (p *MergeFileResp) SetMsg(val string) {
 func (p *MergeFileResp) SetUrl(val string) {
 	p.Url = val
 }
+func (p *MergeFileResp) SetBadChunks(val []int32) {
+	p.BadChunks = val
+}
+func (p *MergeFileResp) SetJobId(val string) {
+	p.JobId = val
+}
+func (p *MergeFileResp) SetStatus(val string) {
+	p.Status = val
+}
 
 func (p *MergeFileResp) String() string {
 	if p == nil {
@@ -377,6 +595,157 @@ var fieldIDToName_MergeFileResp = map[int16]string{
 	1: "code",
 	2: "msg",
 	3: "url",
+	4: "bad_chunks",
+	5: "job_id",
+	6: "status",
+}
+
+type GetMergeStatusReq struct {
+	JobId  string `thrift:"job_id,1" frugal:"1,default,string" json:"job_id"`
+	UserId string `thrift:"user_id,2" frugal:"2,default,string" json:"user_id"`
+}
+
+func NewGetMergeStatusReq() *GetMergeStatusReq {
+	return &GetMergeStatusReq{}
+}
+
+func (p *GetMergeStatusReq) InitDefault() {
+}
+
+func (p *GetMergeStatusReq) GetJobId() (v string) {
+	return p.JobId
+}
+
+func (p *GetMergeStatusReq) GetUserId() (v string) {
+	return p.UserId
+}
+func (p *GetMergeStatusReq) SetJobId(val string) {
+	p.JobId = val
+}
+func (p *GetMergeStatusReq) SetUserId(val string) {
+	p.UserId = val
+}
+
+func (p *GetMergeStatusReq) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("GetMergeStatusReq(%+v)", *p)
+}
+
+var fieldIDToName_GetMergeStatusReq = map[int16]string{
+	1: "job_id",
+	2: "user_id",
+}
+
+type GetMergeStatusResp struct {
+	Code        int32   `thrift:"code,1" frugal:"1,default,i32" json:"code"`
+	Msg         string  `thrift:"msg,2" frugal:"2,default,string" json:"msg"`
+	JobId       string  `thrift:"job_id,3" frugal:"3,default,string" json:"job_id"`
+	Status      string  `thrift:"status,4" frugal:"4,default,string" json:"status"`
+	Phase       string  `thrift:"phase,5" frugal:"5,default,string" json:"phase"`
+	BytesMerged int64   `thrift:"bytes_merged,6" frugal:"6,default,i64" json:"bytes_merged"`
+	TotalBytes  int64   `thrift:"total_bytes,7" frugal:"7,default,i64" json:"total_bytes"`
+	Url         string  `thrift:"url,8" frugal:"8,default,string" json:"url"`
+	BadChunks   []int32 `thrift:"bad_chunks,9" frugal:"9,default,list<i32>" json:"bad_chunks"`
+	Error       string  `thrift:"error,10" frugal:"10,default,string" json:"error"`
+}
+
+func NewGetMergeStatusResp() *GetMergeStatusResp {
+	return &GetMergeStatusResp{}
+}
+
+func (p *GetMergeStatusResp) InitDefault() {
+}
+
+func (p *GetMergeStatusResp) GetCode() (v int32) {
+	return p.Code
+}
+
+func (p *GetMergeStatusResp) GetMsg() (v string) {
+	return p.Msg
+}
+
+func (p *GetMergeStatusResp) GetJobId() (v string) {
+	return p.JobId
+}
+
+func (p *GetMergeStatusResp) GetStatus() (v string) {
+	return p.Status
+}
+
+func (p *GetMergeStatusResp) GetPhase() (v string) {
+	return p.Phase
+}
+
+func (p *GetMergeStatusResp) GetBytesMerged() (v int64) {
+	return p.BytesMerged
+}
+
+func (p *GetMergeStatusResp) GetTotalBytes() (v int64) {
+	return p.TotalBytes
+}
+
+func (p *GetMergeStatusResp) GetUrl() (v string) {
+	return p.Url
+}
+
+func (p *GetMergeStatusResp) GetBadChunks() (v []int32) {
+	return p.BadChunks
+}
+
+func (p *GetMergeStatusResp) GetError() (v string) {
+	return p.Error
+}
+func (p *GetMergeStatusResp) SetCode(val int32) {
+	p.Code = val
+}
+func (p *GetMergeStatusResp) SetMsg(val string) {
+	p.Msg = val
+}
+func (p *GetMergeStatusResp) SetJobId(val string) {
+	p.JobId = val
+}
+func (p *GetMergeStatusResp) SetStatus(val string) {
+	p.Status = val
+}
+func (p *GetMergeStatusResp) SetPhase(val string) {
+	p.Phase = val
+}
+func (p *GetMergeStatusResp) SetBytesMerged(val int64) {
+	p.BytesMerged = val
+}
+func (p *GetMergeStatusResp) SetTotalBytes(val int64) {
+	p.TotalBytes = val
+}
+func (p *GetMergeStatusResp) SetUrl(val string) {
+	p.Url = val
+}
+func (p *GetMergeStatusResp) SetBadChunks(val []int32) {
+	p.BadChunks = val
+}
+func (p *GetMergeStatusResp) SetError(val string) {
+	p.Error = val
+}
+
+func (p *GetMergeStatusResp) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("GetMergeStatusResp(%+v)", *p)
+}
+
+var fieldIDToName_GetMergeStatusResp = map[int16]string{
+	1:  "code",
+	2:  "msg",
+	3:  "job_id",
+	4:  "status",
+	5:  "phase",
+	6:  "bytes_merged",
+	7:  "total_bytes",
+	8:  "url",
+	9:  "bad_chunks",
+	10: "error",
 }
 
 type DownloadChunkReq struct {
@@ -491,122 +860,456 @@ var fieldIDToName_DownloadChunkResp = map[int16]string{
 	4: "total_size",
 }
 
-type GetVideoInfoReq struct {
-	FileHash string `thrift:"file_hash,1" frugal:"1,default,string" json:"file_hash"`
-	UserId   string `thrift:"user_id,2" frugal:"2,default,string" json:"user_id"`
+type DownloadStreamReq struct {
+	FileHash  string `thrift:"file_hash,1" frugal:"1,default,string" json:"file_hash"`
+	StartByte int64  `thrift:"start_byte,2" frugal:"2,default,i64" json:"start_byte"`
+	EndByte   int64  `thrift:"end_byte,3" frugal:"3,default,i64" json:"end_byte"`
+	HeadOnly  bool   `thrift:"head_only,4" frugal:"4,default,bool" json:"head_only"`
 }
 
-func NewGetVideoInfoReq() *GetVideoInfoReq {
-	return &GetVideoInfoReq{}
+func NewDownloadStreamReq() *DownloadStreamReq {
+	return &DownloadStreamReq{}
 }
 
-func (p *GetVideoInfoReq) InitDefault() {
+func (p *DownloadStreamReq) InitDefault() {
 }
 
-func (p *GetVideoInfoReq) GetFileHash() (v string) {
+func (p *DownloadStreamReq) GetFileHash() (v string) {
 	return p.FileHash
 }
 
-func (p *GetVideoInfoReq) GetUserId() (v string) {
-	return p.UserId
+func (p *DownloadStreamReq) GetStartByte() (v int64) {
+	return p.StartByte
 }
-func (p *GetVideoInfoReq) SetFileHash(val string) {
+
+func (p *DownloadStreamReq) GetEndByte() (v int64) {
+	return p.EndByte
+}
+
+func (p *DownloadStreamReq) GetHeadOnly() (v bool) {
+	return p.HeadOnly
+}
+func (p *DownloadStreamReq) SetFileHash(val string) {
 	p.FileHash = val
 }
-func (p *GetVideoInfoReq) SetUserId(val string) {
-	p.UserId = val
+func (p *DownloadStreamReq) SetStartByte(val int64) {
+	p.StartByte = val
+}
+func (p *DownloadStreamReq) SetEndByte(val int64) {
+	p.EndByte = val
+}
+func (p *DownloadStreamReq) SetHeadOnly(val bool) {
+	p.HeadOnly = val
 }
 
-func (p *GetVideoInfoReq) String() string {
+func (p *DownloadStreamReq) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("GetVideoInfoReq(%+v)", *p)
+	return fmt.Sprintf("DownloadStreamReq(%+v)", *p)
 }
 
-var fieldIDToName_GetVideoInfoReq = map[int16]string{
+var fieldIDToName_DownloadStreamReq = map[int16]string{
 	1: "file_hash",
-	2: "user_id",
+	2: "start_byte",
+	3: "end_byte",
+	4: "head_only",
 }
 
-type GetVideoInfoResp struct {
-	Code            int32    `thrift:"code,1" frugal:"1,default,i32" json:"code"`
-	Msg             string   `thrift:"msg,2" frugal:"2,default,string" json:"msg"`
-	FileHash        string   `thrift:"file_hash,3" frugal:"3,default,string" json:"file_hash"`
-	Filename        string   `thrift:"filename,4" frugal:"4,default,string" json:"filename"`
-	FileSize        int64    `thrift:"file_size,5" frugal:"5,default,i64" json:"file_size"`
-	Width           int32    `thrift:"width,6" frugal:"6,default,i32" json:"width"`
-	Height          int32    `thrift:"height,7" frugal:"7,default,i32" json:"height"`
-	Url             string   `thrift:"url,8" frugal:"8,default,string" json:"url"`
-	TranscodeUrls   []string `thrift:"transcode_urls,9" frugal:"9,default,list<string>" json:"transcode_urls"`
-	TranscodeStatus string   `thrift:"transcode_status,10" frugal:"10,default,string" json:"transcode_status"`
+type DownloadFrame struct {
+	Code         int32  `thrift:"code,1" frugal:"1,default,i32" json:"code"`
+	Msg          string `thrift:"msg,2" frugal:"2,default,string" json:"msg"`
+	Data         []byte `thrift:"data,3" frugal:"3,default,binary" json:"data"`
+	TotalSize    int64  `thrift:"total_size,4" frugal:"4,default,i64" json:"total_size"`
+	StartByte    int64  `thrift:"start_byte,5" frugal:"5,default,i64" json:"start_byte"`
+	EndByte      int64  `thrift:"end_byte,6" frugal:"6,default,i64" json:"end_byte"`
+	Filename     string `thrift:"filename,7" frugal:"7,default,string" json:"filename"`
+	LastModified int64  `thrift:"last_modified,8" frugal:"8,default,i64" json:"last_modified"`
+	ContentType  string `thrift:"content_type,9" frugal:"9,default,string" json:"content_type"`
 }
 
-func NewGetVideoInfoResp() *GetVideoInfoResp {
-	return &GetVideoInfoResp{}
+func NewDownloadFrame() *DownloadFrame {
+	return &DownloadFrame{}
 }
 
-func (p *GetVideoInfoResp) InitDefault() {
+func (p *DownloadFrame) InitDefault() {
 }
 
-func (p *GetVideoInfoResp) GetCode() (v int32) {
+func (p *DownloadFrame) GetCode() (v int32) {
 	return p.Code
 }
 
-func (p *GetVideoInfoResp) GetMsg() (v string) {
+func (p *DownloadFrame) GetMsg() (v string) {
 	return p.Msg
 }
 
-func (p *GetVideoInfoResp) GetFileHash() (v string) {
-	return p.FileHash
-}
-
-func (p *GetVideoInfoResp) GetFilename() (v string) {
-	return p.Filename
+func (p *DownloadFrame) GetData() (v []byte) {
+	return p.Data
 }
 
-func (p *GetVideoInfoResp) GetFileSize() (v int64) {
-	return p.FileSize
+func (p *DownloadFrame) GetTotalSize() (v int64) {
+	return p.TotalSize
 }
 
-func (p *GetVideoInfoResp) GetWidth() (v int32) {
-	return p.Width
+func (p *DownloadFrame) GetStartByte() (v int64) {
+	return p.StartByte
 }
 
-func (p *GetVideoInfoResp) GetHeight() (v int32) {
-	return p.Height
+func (p *DownloadFrame) GetEndByte() (v int64) {
+	return p.EndByte
 }
 
-func (p *GetVideoInfoResp) GetUrl() (v string) {
-	return p.Url
+func (p *DownloadFrame) GetFilename() (v string) {
+	return p.Filename
 }
 
-func (p *GetVideoInfoResp) GetTranscodeUrls() (v []string) {
-	return p.TranscodeUrls
+func (p *DownloadFrame) GetLastModified() (v int64) {
+	return p.LastModified
 }
 
-func (p *GetVideoInfoResp) GetTranscodeStatus() (v string) {
-	return p.TranscodeStatus
+func (p *DownloadFrame) GetContentType() (v string) {
+	return p.ContentType
 }
-func (p *GetVideoInfoResp) SetCode(val int32) {
+func (p *DownloadFrame) SetCode(val int32) {
 	p.Code = val
 }
-func (p *GetVideoInfoResp) SetMsg(val string) {
+func (p *DownloadFrame) SetMsg(val string) {
 	p.Msg = val
 }
-func (p *GetVideoInfoResp) SetFileHash(val string) {
-	p.FileHash = val
+func (p *DownloadFrame) SetData(val []byte) {
+	p.Data = val
 }
-func (p *GetVideoInfoResp) SetFilename(val string) {
-	p.Filename = val
+func (p *DownloadFrame) SetTotalSize(val int64) {
+	p.TotalSize = val
 }
-func (p *GetVideoInfoResp) SetFileSize(val int64) {
-	p.FileSize = val
+func (p *DownloadFrame) SetStartByte(val int64) {
+	p.StartByte = val
 }
-func (p *GetVideoInfoResp) SetWidth(val int32) {
-	p.Width = val
+func (p *DownloadFrame) SetEndByte(val int64) {
+	p.EndByte = val
 }
-func (p *GetVideoInfoResp) SetHeight(val int32) {
+func (p *DownloadFrame) SetFilename(val string) {
+	p.Filename = val
+}
+func (p *DownloadFrame) SetLastModified(val int64) {
+	p.LastModified = val
+}
+func (p *DownloadFrame) SetContentType(val string) {
+	p.ContentType = val
+}
+
+func (p *DownloadFrame) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("DownloadFrame(%+v)", *p)
+}
+
+var fieldIDToName_DownloadFrame = map[int16]string{
+	1: "code",
+	2: "msg",
+	3: "data",
+	4: "total_size",
+	5: "start_byte",
+	6: "end_byte",
+	7: "filename",
+	8: "last_modified",
+	9: "content_type",
+}
+
+type GetVideoInfoReq struct {
+	FileHash string `thrift:"file_hash,1" frugal:"1,default,string" json:"file_hash"`
+	UserId   string `thrift:"user_id,2" frugal:"2,default,string" json:"user_id"`
+}
+
+func NewGetVideoInfoReq() *GetVideoInfoReq {
+	return &GetVideoInfoReq{}
+}
+
+func (p *GetVideoInfoReq) InitDefault() {
+}
+
+func (p *GetVideoInfoReq) GetFileHash() (v string) {
+	return p.FileHash
+}
+
+func (p *GetVideoInfoReq) GetUserId() (v string) {
+	return p.UserId
+}
+func (p *GetVideoInfoReq) SetFileHash(val string) {
+	p.FileHash = val
+}
+func (p *GetVideoInfoReq) SetUserId(val string) {
+	p.UserId = val
+}
+
+func (p *GetVideoInfoReq) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("GetVideoInfoReq(%+v)", *p)
+}
+
+var fieldIDToName_GetVideoInfoReq = map[int16]string{
+	1: "file_hash",
+	2: "user_id",
+}
+
+type MediaInfo struct {
+	Duration        float64 `thrift:"duration,1" frugal:"1,default,double" json:"duration"`
+	Container       string  `thrift:"container,2" frugal:"2,default,string" json:"container"`
+	VideoCodec      string  `thrift:"video_codec,3" frugal:"3,default,string" json:"video_codec"`
+	AudioCodec      string  `thrift:"audio_codec,4" frugal:"4,default,string" json:"audio_codec"`
+	Bitrate         int64   `thrift:"bitrate,5" frugal:"5,default,i64" json:"bitrate"`
+	FrameRate       float64 `thrift:"frame_rate,6" frugal:"6,default,double" json:"frame_rate"`
+	Rotation        int32   `thrift:"rotation,7" frugal:"7,default,i32" json:"rotation"`
+	AudioChannels   int32   `thrift:"audio_channels,8" frugal:"8,default,i32" json:"audio_channels"`
+	AudioSampleRate int32   `thrift:"audio_sample_rate,9" frugal:"9,default,i32" json:"audio_sample_rate"`
+	Hdr             bool    `thrift:"hdr,10" frugal:"10,default,bool" json:"hdr"`
+	HdrFormat       string  `thrift:"hdr_format,11" frugal:"11,default,string" json:"hdr_format"`
+	StreamCount     int32   `thrift:"stream_count,12" frugal:"12,default,i32" json:"stream_count"`
+	Faststart       bool    `thrift:"faststart,13" frugal:"13,default,bool" json:"faststart"`
+}
+
+func NewMediaInfo() *MediaInfo {
+	return &MediaInfo{}
+}
+
+func (p *MediaInfo) InitDefault() {
+}
+
+func (p *MediaInfo) GetDuration() (v float64) {
+	return p.Duration
+}
+
+func (p *MediaInfo) GetContainer() (v string) {
+	return p.Container
+}
+
+func (p *MediaInfo) GetVideoCodec() (v string) {
+	return p.VideoCodec
+}
+
+func (p *MediaInfo) GetAudioCodec() (v string) {
+	return p.AudioCodec
+}
+
+func (p *MediaInfo) GetBitrate() (v int64) {
+	return p.Bitrate
+}
+
+func (p *MediaInfo) GetFrameRate() (v float64) {
+	return p.FrameRate
+}
+
+func (p *MediaInfo) GetRotation() (v int32) {
+	return p.Rotation
+}
+
+func (p *MediaInfo) GetAudioChannels() (v int32) {
+	return p.AudioChannels
+}
+
+func (p *MediaInfo) GetAudioSampleRate() (v int32) {
+	return p.AudioSampleRate
+}
+
+func (p *MediaInfo) GetHdr() (v bool) {
+	return p.Hdr
+}
+
+func (p *MediaInfo) GetHdrFormat() (v string) {
+	return p.HdrFormat
+}
+
+func (p *MediaInfo) GetStreamCount() (v int32) {
+	return p.StreamCount
+}
+
+func (p *MediaInfo) GetFaststart() (v bool) {
+	return p.Faststart
+}
+func (p *MediaInfo) SetDuration(val float64) {
+	p.Duration = val
+}
+func (p *MediaInfo) SetContainer(val string) {
+	p.Container = val
+}
+func (p *MediaInfo) SetVideoCodec(val string) {
+	p.VideoCodec = val
+}
+func (p *MediaInfo) SetAudioCodec(val string) {
+	p.AudioCodec = val
+}
+func (p *MediaInfo) SetBitrate(val int64) {
+	p.Bitrate = val
+}
+func (p *MediaInfo) SetFrameRate(val float64) {
+	p.FrameRate = val
+}
+func (p *MediaInfo) SetRotation(val int32) {
+	p.Rotation = val
+}
+func (p *MediaInfo) SetAudioChannels(val int32) {
+	p.AudioChannels = val
+}
+func (p *MediaInfo) SetAudioSampleRate(val int32) {
+	p.AudioSampleRate = val
+}
+func (p *MediaInfo) SetHdr(val bool) {
+	p.Hdr = val
+}
+func (p *MediaInfo) SetHdrFormat(val string) {
+	p.HdrFormat = val
+}
+func (p *MediaInfo) SetStreamCount(val int32) {
+	p.StreamCount = val
+}
+func (p *MediaInfo) SetFaststart(val bool) {
+	p.Faststart = val
+}
+
+func (p *MediaInfo) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("MediaInfo(%+v)", *p)
+}
+
+var fieldIDToName_MediaInfo = map[int16]string{
+	1:  "duration",
+	2:  "container",
+	3:  "video_codec",
+	4:  "audio_codec",
+	5:  "bitrate",
+	6:  "frame_rate",
+	7:  "rotation",
+	8:  "audio_channels",
+	9:  "audio_sample_rate",
+	10: "hdr",
+	11: "hdr_format",
+	12: "stream_count",
+	13: "faststart",
+}
+
+type GetVideoInfoResp struct {
+	Code             int32      `thrift:"code,1" frugal:"1,default,i32" json:"code"`
+	Msg              string     `thrift:"msg,2" frugal:"2,default,string" json:"msg"`
+	FileHash         string     `thrift:"file_hash,3" frugal:"3,default,string" json:"file_hash"`
+	Filename         string     `thrift:"filename,4" frugal:"4,default,string" json:"filename"`
+	FileSize         int64      `thrift:"file_size,5" frugal:"5,default,i64" json:"file_size"`
+	Width            int32      `thrift:"width,6" frugal:"6,default,i32" json:"width"`
+	Height           int32      `thrift:"height,7" frugal:"7,default,i32" json:"height"`
+	Url              string     `thrift:"url,8" frugal:"8,default,string" json:"url"`
+	TranscodeUrls    []string   `thrift:"transcode_urls,9" frugal:"9,default,list<string>" json:"transcode_urls"`
+	TranscodeStatus  string     `thrift:"transcode_status,10" frugal:"10,default,string" json:"transcode_status"`
+	PlaybackUrl      string     `thrift:"playback_url,11" frugal:"11,default,string" json:"playback_url"`
+	DashUrl          string     `thrift:"dash_url,12" frugal:"12,default,string" json:"dash_url"`
+	PosterUrl        string     `thrift:"poster_url,13" frugal:"13,default,string" json:"poster_url"`
+	ThumbnailsVttUrl string     `thrift:"thumbnails_vtt_url,14" frugal:"14,default,string" json:"thumbnails_vtt_url"`
+	SpriteUrls       []string   `thrift:"sprite_urls,15" frugal:"15,default,list<string>" json:"sprite_urls"`
+	ThumbnailStatus  string     `thrift:"thumbnail_status,16" frugal:"16,default,string" json:"thumbnail_status"`
+	Media            *MediaInfo `thrift:"media,17" frugal:"17,default,MediaInfo" json:"media"`
+}
+
+func NewGetVideoInfoResp() *GetVideoInfoResp {
+	return &GetVideoInfoResp{}
+}
+
+func (p *GetVideoInfoResp) InitDefault() {
+}
+
+func (p *GetVideoInfoResp) GetCode() (v int32) {
+	return p.Code
+}
+
+func (p *GetVideoInfoResp) GetMsg() (v string) {
+	return p.Msg
+}
+
+func (p *GetVideoInfoResp) GetFileHash() (v string) {
+	return p.FileHash
+}
+
+func (p *GetVideoInfoResp) GetFilename() (v string) {
+	return p.Filename
+}
+
+func (p *GetVideoInfoResp) GetFileSize() (v int64) {
+	return p.FileSize
+}
+
+func (p *GetVideoInfoResp) GetWidth() (v int32) {
+	return p.Width
+}
+
+func (p *GetVideoInfoResp) GetHeight() (v int32) {
+	return p.Height
+}
+
+func (p *GetVideoInfoResp) GetUrl() (v string) {
+	return p.Url
+}
+
+func (p *GetVideoInfoResp) GetTranscodeUrls() (v []string) {
+	return p.TranscodeUrls
+}
+
+func (p *GetVideoInfoResp) GetTranscodeStatus() (v string) {
+	return p.TranscodeStatus
+}
+
+func (p *GetVideoInfoResp) GetPlaybackUrl() (v string) {
+	return p.PlaybackUrl
+}
+
+func (p *GetVideoInfoResp) GetDashUrl() (v string) {
+	return p.DashUrl
+}
+
+func (p *GetVideoInfoResp) GetPosterUrl() (v string) {
+	return p.PosterUrl
+}
+
+func (p *GetVideoInfoResp) GetThumbnailsVttUrl() (v string) {
+	return p.ThumbnailsVttUrl
+}
+
+func (p *GetVideoInfoResp) GetSpriteUrls() (v []string) {
+	return p.SpriteUrls
+}
+
+func (p *GetVideoInfoResp) GetThumbnailStatus() (v string) {
+	return p.ThumbnailStatus
+}
+
+var GetVideoInfoResp_Media_DEFAULT *MediaInfo
+
+func (p *GetVideoInfoResp) GetMedia() (v *MediaInfo) {
+	if !p.IsSetMedia() {
+		return GetVideoInfoResp_Media_DEFAULT
+	}
+	return p.Media
+}
+func (p *GetVideoInfoResp) SetCode(val int32) {
+	p.Code = val
+}
+func (p *GetVideoInfoResp) SetMsg(val string) {
+	p.Msg = val
+}
+func (p *GetVideoInfoResp) SetFileHash(val string) {
+	p.FileHash = val
+}
+func (p *GetVideoInfoResp) SetFilename(val string) {
+	p.Filename = val
+}
+func (p *GetVideoInfoResp) SetFileSize(val int64) {
+	p.FileSize = val
+}
+func (p *GetVideoInfoResp) SetWidth(val int32) {
+	p.Width = val
+}
+func (p *GetVideoInfoResp) SetHeight(val int32) {
 	p.Height = val
 }
 func (p *GetVideoInfoResp) SetUrl(val string) {
@@ -618,6 +1321,31 @@ func (p *GetVideoInfoResp) SetTranscodeUrls(val []string) {
 func (p *GetVideoInfoResp) SetTranscodeStatus(val string) {
 	p.TranscodeStatus = val
 }
+func (p *GetVideoInfoResp) SetPlaybackUrl(val string) {
+	p.PlaybackUrl = val
+}
+func (p *GetVideoInfoResp) SetDashUrl(val string) {
+	p.DashUrl = val
+}
+func (p *GetVideoInfoResp) SetPosterUrl(val string) {
+	p.PosterUrl = val
+}
+func (p *GetVideoInfoResp) SetThumbnailsVttUrl(val string) {
+	p.ThumbnailsVttUrl = val
+}
+func (p *GetVideoInfoResp) SetSpriteUrls(val []string) {
+	p.SpriteUrls = val
+}
+func (p *GetVideoInfoResp) SetThumbnailStatus(val string) {
+	p.ThumbnailStatus = val
+}
+func (p *GetVideoInfoResp) SetMedia(val *MediaInfo) {
+	p.Media = val
+}
+
+func (p *GetVideoInfoResp) IsSetMedia() bool {
+	return p.Media != nil
+}
 
 func (p *GetVideoInfoResp) String() string {
 	if p == nil {
@@ -637,123 +1365,475 @@ var fieldIDToName_GetVideoInfoResp = map[int16]string{
 	8:  "url",
 	9:  "transcode_urls",
 	10: "transcode_status",
+	11: "playback_url",
+	12: "dash_url",
+	13: "poster_url",
+	14: "thumbnails_vtt_url",
+	15: "sprite_urls",
+	16: "thumbnail_status",
+	17: "media",
 }
 
-type TranscodeReq struct {
-	FileHash    string   `thrift:"file_hash,1" frugal:"1,default,string" json:"file_hash"`
-	UserId      string   `thrift:"user_id,2" frugal:"2,default,string" json:"user_id"`
-	Resolutions []string `thrift:"resolutions,3" frugal:"3,default,list<string>" json:"resolutions"`
-	RequestId   string   `thrift:"request_id,4" frugal:"4,default,string" json:"request_id"`
+type GetThumbnailFileReq struct {
+	FileHash string `thrift:"file_hash,1" frugal:"1,default,string" json:"file_hash"`
+	UserId   string `thrift:"user_id,2" frugal:"2,default,string" json:"user_id"`
+	Path     string `thrift:"path,3" frugal:"3,default,string" json:"path"`
 }
 
-func NewTranscodeReq() *TranscodeReq {
-	return &TranscodeReq{}
+func NewGetThumbnailFileReq() *GetThumbnailFileReq {
+	return &GetThumbnailFileReq{}
 }
 
-func (p *TranscodeReq) InitDefault() {
+func (p *GetThumbnailFileReq) InitDefault() {
 }
 
-func (p *TranscodeReq) GetFileHash() (v string) {
+func (p *GetThumbnailFileReq) GetFileHash() (v string) {
 	return p.FileHash
 }
 
-func (p *TranscodeReq) GetUserId() (v string) {
+func (p *GetThumbnailFileReq) GetUserId() (v string) {
 	return p.UserId
 }
 
-func (p *TranscodeReq) GetResolutions() (v []string) {
-	return p.Resolutions
-}
-
-func (p *TranscodeReq) GetRequestId() (v string) {
-	return p.RequestId
+func (p *GetThumbnailFileReq) GetPath() (v string) {
+	return p.Path
 }
-func (p *TranscodeReq) SetFileHash(val string) {
+func (p *GetThumbnailFileReq) SetFileHash(val string) {
 	p.FileHash = val
 }
-func (p *TranscodeReq) SetUserId(val string) {
+func (p *GetThumbnailFileReq) SetUserId(val string) {
 	p.UserId = val
 }
-func (p *TranscodeReq) SetResolutions(val []string) {
-	p.Resolutions = val
-}
-func (p *TranscodeReq) SetRequestId(val string) {
-	p.RequestId = val
+func (p *GetThumbnailFileReq) SetPath(val string) {
+	p.Path = val
 }
 
-func (p *TranscodeReq) String() string {
+func (p *GetThumbnailFileReq) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("TranscodeReq(%+v)", *p)
+	return fmt.Sprintf("GetThumbnailFileReq(%+v)", *p)
 }
 
-var fieldIDToName_TranscodeReq = map[int16]string{
+var fieldIDToName_GetThumbnailFileReq = map[int16]string{
 	1: "file_hash",
 	2: "user_id",
-	3: "resolutions",
-	4: "request_id",
+	3: "path",
 }
 
-type TranscodeResp struct {
-	Code   int32  `thrift:"code,1" frugal:"1,default,i32" json:"code"`
-	Msg    string `thrift:"msg,2" frugal:"2,default,string" json:"msg"`
-	TaskId string `thrift:"task_id,3" frugal:"3,default,string" json:"task_id"`
+type GetThumbnailFileResp struct {
+	Code        int32  `thrift:"code,1" frugal:"1,default,i32" json:"code"`
+	Msg         string `thrift:"msg,2" frugal:"2,default,string" json:"msg"`
+	Data        []byte `thrift:"data,3" frugal:"3,default,binary" json:"data"`
+	ContentType string `thrift:"content_type,4" frugal:"4,default,string" json:"content_type"`
 }
 
-func NewTranscodeResp() *TranscodeResp {
-	return &TranscodeResp{}
+func NewGetThumbnailFileResp() *GetThumbnailFileResp {
+	return &GetThumbnailFileResp{}
 }
 
-func (p *TranscodeResp) InitDefault() {
+func (p *GetThumbnailFileResp) InitDefault() {
 }
 
-func (p *TranscodeResp) GetCode() (v int32) {
+func (p *GetThumbnailFileResp) GetCode() (v int32) {
 	return p.Code
 }
 
-func (p *TranscodeResp) GetMsg() (v string) {
+func (p *GetThumbnailFileResp) GetMsg() (v string) {
 	return p.Msg
 }
 
-func (p *TranscodeResp) GetTaskId() (v string) {
-	return p.TaskId
+func (p *GetThumbnailFileResp) GetData() (v []byte) {
+	return p.Data
 }
-func (p *TranscodeResp) SetCode(val int32) {
+
+func (p *GetThumbnailFileResp) GetContentType() (v string) {
+	return p.ContentType
+}
+func (p *GetThumbnailFileResp) SetCode(val int32) {
 	p.Code = val
 }
-func (p *TranscodeResp) SetMsg(val string) {
+func (p *GetThumbnailFileResp) SetMsg(val string) {
 	p.Msg = val
 }
-func (p *TranscodeResp) SetTaskId(val string) {
-	p.TaskId = val
+func (p *GetThumbnailFileResp) SetData(val []byte) {
+	p.Data = val
+}
+func (p *GetThumbnailFileResp) SetContentType(val string) {
+	p.ContentType = val
 }
 
-func (p *TranscodeResp) String() string {
+func (p *GetThumbnailFileResp) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("TranscodeResp(%+v)", *p)
+	return fmt.Sprintf("GetThumbnailFileResp(%+v)", *p)
 }
 
-var fieldIDToName_TranscodeResp = map[int16]string{
+var fieldIDToName_GetThumbnailFileResp = map[int16]string{
 	1: "code",
 	2: "msg",
-	3: "task_id",
+	3: "data",
+	4: "content_type",
 }
 
-type GetTranscodeStatusReq struct {
-	TaskId string `thrift:"task_id,1" frugal:"1,default,string" json:"task_id"`
+type SetPosterReq struct {
+	FileHash  string   `thrift:"file_hash,1" frugal:"1,default,string" json:"file_hash"`
+	UserId    string   `thrift:"user_id,2" frugal:"2,default,string" json:"user_id"`
+	Timestamp *float64 `thrift:"timestamp,3,optional" frugal:"3,optional,double" json:"timestamp,omitempty"`
+	Image     []byte   `thrift:"image,4" frugal:"4,default,binary" json:"image"`
 }
 
-func NewGetTranscodeStatusReq() *GetTranscodeStatusReq {
-	return &GetTranscodeStatusReq{}
+func NewSetPosterReq() *SetPosterReq {
+	return &SetPosterReq{}
 }
 
-func (p *GetTranscodeStatusReq) InitDefault() {
+func (p *SetPosterReq) InitDefault() {
 }
 
-func (p *GetTranscodeStatusReq) GetTaskId() (v string) {
+func (p *SetPosterReq) GetFileHash() (v string) {
+	return p.FileHash
+}
+
+func (p *SetPosterReq) GetUserId() (v string) {
+	return p.UserId
+}
+
+var SetPosterReq_Timestamp_DEFAULT float64
+
+func (p *SetPosterReq) GetTimestamp() (v float64) {
+	if !p.IsSetTimestamp() {
+		return SetPosterReq_Timestamp_DEFAULT
+	}
+	return *p.Timestamp
+}
+
+func (p *SetPosterReq) GetImage() (v []byte) {
+	return p.Image
+}
+func (p *SetPosterReq) SetFileHash(val string) {
+	p.FileHash = val
+}
+func (p *SetPosterReq) SetUserId(val string) {
+	p.UserId = val
+}
+func (p *SetPosterReq) SetTimestamp(val *float64) {
+	p.Timestamp = val
+}
+func (p *SetPosterReq) SetImage(val []byte) {
+	p.Image = val
+}
+
+func (p *SetPosterReq) IsSetTimestamp() bool {
+	return p.Timestamp != nil
+}
+
+func (p *SetPosterReq) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("SetPosterReq(%+v)", *p)
+}
+
+var fieldIDToName_SetPosterReq = map[int16]string{
+	1: "file_hash",
+	2: "user_id",
+	3: "timestamp",
+	4: "image",
+}
+
+type SetPosterResp struct {
+	Code      int32  `thrift:"code,1" frugal:"1,default,i32" json:"code"`
+	Msg       string `thrift:"msg,2" frugal:"2,default,string" json:"msg"`
+	PosterUrl string `thrift:"poster_url,3" frugal:"3,default,string" json:"poster_url"`
+}
+
+func NewSetPosterResp() *SetPosterResp {
+	return &SetPosterResp{}
+}
+
+func (p *SetPosterResp) InitDefault() {
+}
+
+func (p *SetPosterResp) GetCode() (v int32) {
+	return p.Code
+}
+
+func (p *SetPosterResp) GetMsg() (v string) {
+	return p.Msg
+}
+
+func (p *SetPosterResp) GetPosterUrl() (v string) {
+	return p.PosterUrl
+}
+func (p *SetPosterResp) SetCode(val int32) {
+	p.Code = val
+}
+func (p *SetPosterResp) SetMsg(val string) {
+	p.Msg = val
+}
+func (p *SetPosterResp) SetPosterUrl(val string) {
+	p.PosterUrl = val
+}
+
+func (p *SetPosterResp) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("SetPosterResp(%+v)", *p)
+}
+
+var fieldIDToName_SetPosterResp = map[int16]string{
+	1: "code",
+	2: "msg",
+	3: "poster_url",
+}
+
+type GetPlaybackFileReq struct {
+	FileHash string `thrift:"file_hash,1" frugal:"1,default,string" json:"file_hash"`
+	UserId   string `thrift:"user_id,2" frugal:"2,default,string" json:"user_id"`
+	Path     string `thrift:"path,3" frugal:"3,default,string" json:"path"`
+}
+
+func NewGetPlaybackFileReq() *GetPlaybackFileReq {
+	return &GetPlaybackFileReq{}
+}
+
+func (p *GetPlaybackFileReq) InitDefault() {
+}
+
+func (p *GetPlaybackFileReq) GetFileHash() (v string) {
+	return p.FileHash
+}
+
+func (p *GetPlaybackFileReq) GetUserId() (v string) {
+	return p.UserId
+}
+
+func (p *GetPlaybackFileReq) GetPath() (v string) {
+	return p.Path
+}
+func (p *GetPlaybackFileReq) SetFileHash(val string) {
+	p.FileHash = val
+}
+func (p *GetPlaybackFileReq) SetUserId(val string) {
+	p.UserId = val
+}
+func (p *GetPlaybackFileReq) SetPath(val string) {
+	p.Path = val
+}
+
+func (p *GetPlaybackFileReq) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("GetPlaybackFileReq(%+v)", *p)
+}
+
+var fieldIDToName_GetPlaybackFileReq = map[int16]string{
+	1: "file_hash",
+	2: "user_id",
+	3: "path",
+}
+
+type GetPlaybackFileResp struct {
+	Code        int32  `thrift:"code,1" frugal:"1,default,i32" json:"code"`
+	Msg         string `thrift:"msg,2" frugal:"2,default,string" json:"msg"`
+	Data        []byte `thrift:"data,3" frugal:"3,default,binary" json:"data"`
+	ContentType string `thrift:"content_type,4" frugal:"4,default,string" json:"content_type"`
+}
+
+func NewGetPlaybackFileResp() *GetPlaybackFileResp {
+	return &GetPlaybackFileResp{}
+}
+
+func (p *GetPlaybackFileResp) InitDefault() {
+}
+
+func (p *GetPlaybackFileResp) GetCode() (v int32) {
+	return p.Code
+}
+
+func (p *GetPlaybackFileResp) GetMsg() (v string) {
+	return p.Msg
+}
+
+func (p *GetPlaybackFileResp) GetData() (v []byte) {
+	return p.Data
+}
+
+func (p *GetPlaybackFileResp) GetContentType() (v string) {
+	return p.ContentType
+}
+func (p *GetPlaybackFileResp) SetCode(val int32) {
+	p.Code = val
+}
+func (p *GetPlaybackFileResp) SetMsg(val string) {
+	p.Msg = val
+}
+func (p *GetPlaybackFileResp) SetData(val []byte) {
+	p.Data = val
+}
+func (p *GetPlaybackFileResp) SetContentType(val string) {
+	p.ContentType = val
+}
+
+func (p *GetPlaybackFileResp) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("GetPlaybackFileResp(%+v)", *p)
+}
+
+var fieldIDToName_GetPlaybackFileResp = map[int16]string{
+	1: "code",
+	2: "msg",
+	3: "data",
+	4: "content_type",
+}
+
+type TranscodeReq struct {
+	FileHash       string   `thrift:"file_hash,1" frugal:"1,default,string" json:"file_hash"`
+	UserId         string   `thrift:"user_id,2" frugal:"2,default,string" json:"user_id"`
+	Resolutions    []string `thrift:"resolutions,3" frugal:"3,default,list<string>" json:"resolutions"`
+	RequestId      string   `thrift:"request_id,4" frugal:"4,default,string" json:"request_id"`
+	Profiles       []string `thrift:"profiles,5" frugal:"5,default,list<string>" json:"profiles"`
+	PerTitle       bool     `thrift:"per_title,6" frugal:"6,default,bool" json:"per_title"`
+	QualityMetrics bool     `thrift:"quality_metrics,7" frugal:"7,default,bool" json:"quality_metrics"`
+}
+
+func NewTranscodeReq() *TranscodeReq {
+	return &TranscodeReq{}
+}
+
+func (p *TranscodeReq) InitDefault() {
+}
+
+func (p *TranscodeReq) GetFileHash() (v string) {
+	return p.FileHash
+}
+
+func (p *TranscodeReq) GetUserId() (v string) {
+	return p.UserId
+}
+
+func (p *TranscodeReq) GetResolutions() (v []string) {
+	return p.Resolutions
+}
+
+func (p *TranscodeReq) GetRequestId() (v string) {
+	return p.RequestId
+}
+
+func (p *TranscodeReq) GetProfiles() (v []string) {
+	return p.Profiles
+}
+
+func (p *TranscodeReq) GetPerTitle() (v bool) {
+	return p.PerTitle
+}
+
+func (p *TranscodeReq) GetQualityMetrics() (v bool) {
+	return p.QualityMetrics
+}
+func (p *TranscodeReq) SetFileHash(val string) {
+	p.FileHash = val
+}
+func (p *TranscodeReq) SetUserId(val string) {
+	p.UserId = val
+}
+func (p *TranscodeReq) SetResolutions(val []string) {
+	p.Resolutions = val
+}
+func (p *TranscodeReq) SetRequestId(val string) {
+	p.RequestId = val
+}
+func (p *TranscodeReq) SetProfiles(val []string) {
+	p.Profiles = val
+}
+func (p *TranscodeReq) SetPerTitle(val bool) {
+	p.PerTitle = val
+}
+func (p *TranscodeReq) SetQualityMetrics(val bool) {
+	p.QualityMetrics = val
+}
+
+func (p *TranscodeReq) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("TranscodeReq(%+v)", *p)
+}
+
+var fieldIDToName_TranscodeReq = map[int16]string{
+	1: "file_hash",
+	2: "user_id",
+	3: "resolutions",
+	4: "request_id",
+	5: "profiles",
+	6: "per_title",
+	7: "quality_metrics",
+}
+
+type TranscodeResp struct {
+	Code   int32  `thrift:"code,1" frugal:"1,default,i32" json:"code"`
+	Msg    string `thrift:"msg,2" frugal:"2,default,string" json:"msg"`
+	TaskId string `thrift:"task_id,3" frugal:"3,default,string" json:"task_id"`
+}
+
+func NewTranscodeResp() *TranscodeResp {
+	return &TranscodeResp{}
+}
+
+func (p *TranscodeResp) InitDefault() {
+}
+
+func (p *TranscodeResp) GetCode() (v int32) {
+	return p.Code
+}
+
+func (p *TranscodeResp) GetMsg() (v string) {
+	return p.Msg
+}
+
+func (p *TranscodeResp) GetTaskId() (v string) {
+	return p.TaskId
+}
+func (p *TranscodeResp) SetCode(val int32) {
+	p.Code = val
+}
+func (p *TranscodeResp) SetMsg(val string) {
+	p.Msg = val
+}
+func (p *TranscodeResp) SetTaskId(val string) {
+	p.TaskId = val
+}
+
+func (p *TranscodeResp) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("TranscodeResp(%+v)", *p)
+}
+
+var fieldIDToName_TranscodeResp = map[int16]string{
+	1: "code",
+	2: "msg",
+	3: "task_id",
+}
+
+type GetTranscodeStatusReq struct {
+	TaskId string `thrift:"task_id,1" frugal:"1,default,string" json:"task_id"`
+}
+
+func NewGetTranscodeStatusReq() *GetTranscodeStatusReq {
+	return &GetTranscodeStatusReq{}
+}
+
+func (p *GetTranscodeStatusReq) InitDefault() {
+}
+
+func (p *GetTranscodeStatusReq) GetTaskId() (v string) {
 	return p.TaskId
 }
 func (p *GetTranscodeStatusReq) SetTaskId(val string) {
@@ -771,615 +1851,3628 @@ var fieldIDToName_GetTranscodeStatusReq = map[int16]string{
 	1: "task_id",
 }
 
-type GetTranscodeStatusResp struct {
-	Code          int32    `thrift:"code,1" frugal:"1,default,i32" json:"code"`
-	Msg           string   `thrift:"msg,2" frugal:"2,default,string" json:"msg"`
-	Status        string   `thrift:"status,3" frugal:"3,default,string" json:"status"`
-	Progress      int32    `thrift:"progress,4" frugal:"4,default,i32" json:"progress"`
-	CompletedUrls []string `thrift:"completed_urls,5" frugal:"5,default,list<string>" json:"completed_urls"`
+type RenditionProgress struct {
+	Name         string   `thrift:"name,1" frugal:"1,default,string" json:"name"`
+	Status       string   `thrift:"status,2" frugal:"2,default,string" json:"status"`
+	Progress     int32    `thrift:"progress,3" frugal:"3,default,i32" json:"progress"`
+	Speed        float64  `thrift:"speed,4" frugal:"4,default,double" json:"speed"`
+	EtaSeconds   int64    `thrift:"eta_seconds,5" frugal:"5,default,i64" json:"eta_seconds"`
+	Error        string   `thrift:"error,6" frugal:"6,default,string" json:"error"`
+	Attempts     int32    `thrift:"attempts,7" frugal:"7,default,i32" json:"attempts"`
+	Reason       string   `thrift:"reason,8" frugal:"8,default,string" json:"reason"`
+	Psnr         *float64 `thrift:"psnr,9,optional" frugal:"9,optional,double" json:"psnr,omitempty"`
+	Ssim         *float64 `thrift:"ssim,10,optional" frugal:"10,optional,double" json:"ssim,omitempty"`
+	Vmaf         *float64 `thrift:"vmaf,11,optional" frugal:"11,optional,double" json:"vmaf,omitempty"`
+	QualityError string   `thrift:"quality_error,12" frugal:"12,default,string" json:"quality_error"`
 }
 
-func NewGetTranscodeStatusResp() *GetTranscodeStatusResp {
-	return &GetTranscodeStatusResp{}
+func NewRenditionProgress() *RenditionProgress {
+	return &RenditionProgress{}
+}
+
+func (p *RenditionProgress) InitDefault() {
+}
+
+func (p *RenditionProgress) GetName() (v string) {
+	return p.Name
+}
+
+func (p *RenditionProgress) GetStatus() (v string) {
+	return p.Status
+}
+
+func (p *RenditionProgress) GetProgress() (v int32) {
+	return p.Progress
+}
+
+func (p *RenditionProgress) GetSpeed() (v float64) {
+	return p.Speed
+}
+
+func (p *RenditionProgress) GetEtaSeconds() (v int64) {
+	return p.EtaSeconds
+}
+
+func (p *RenditionProgress) GetError() (v string) {
+	return p.Error
+}
+
+func (p *RenditionProgress) GetAttempts() (v int32) {
+	return p.Attempts
+}
+
+func (p *RenditionProgress) GetReason() (v string) {
+	return p.Reason
+}
+
+var RenditionProgress_Psnr_DEFAULT float64
+
+func (p *RenditionProgress) GetPsnr() (v float64) {
+	if !p.IsSetPsnr() {
+		return RenditionProgress_Psnr_DEFAULT
+	}
+	return *p.Psnr
+}
+
+var RenditionProgress_Ssim_DEFAULT float64
+
+func (p *RenditionProgress) GetSsim() (v float64) {
+	if !p.IsSetSsim() {
+		return RenditionProgress_Ssim_DEFAULT
+	}
+	return *p.Ssim
+}
+
+var RenditionProgress_Vmaf_DEFAULT float64
+
+func (p *RenditionProgress) GetVmaf() (v float64) {
+	if !p.IsSetVmaf() {
+		return RenditionProgress_Vmaf_DEFAULT
+	}
+	return *p.Vmaf
+}
+
+func (p *RenditionProgress) GetQualityError() (v string) {
+	return p.QualityError
+}
+func (p *RenditionProgress) SetName(val string) {
+	p.Name = val
+}
+func (p *RenditionProgress) SetStatus(val string) {
+	p.Status = val
+}
+func (p *RenditionProgress) SetProgress(val int32) {
+	p.Progress = val
+}
+func (p *RenditionProgress) SetSpeed(val float64) {
+	p.Speed = val
+}
+func (p *RenditionProgress) SetEtaSeconds(val int64) {
+	p.EtaSeconds = val
+}
+func (p *RenditionProgress) SetError(val string) {
+	p.Error = val
+}
+func (p *RenditionProgress) SetAttempts(val int32) {
+	p.Attempts = val
+}
+func (p *RenditionProgress) SetReason(val string) {
+	p.Reason = val
+}
+func (p *RenditionProgress) SetPsnr(val *float64) {
+	p.Psnr = val
+}
+func (p *RenditionProgress) SetSsim(val *float64) {
+	p.Ssim = val
+}
+func (p *RenditionProgress) SetVmaf(val *float64) {
+	p.Vmaf = val
+}
+func (p *RenditionProgress) SetQualityError(val string) {
+	p.QualityError = val
+}
+
+func (p *RenditionProgress) IsSetPsnr() bool {
+	return p.Psnr != nil
+}
+
+func (p *RenditionProgress) IsSetSsim() bool {
+	return p.Ssim != nil
+}
+
+func (p *RenditionProgress) IsSetVmaf() bool {
+	return p.Vmaf != nil
+}
+
+func (p *RenditionProgress) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("RenditionProgress(%+v)", *p)
+}
+
+var fieldIDToName_RenditionProgress = map[int16]string{
+	1:  "name",
+	2:  "status",
+	3:  "progress",
+	4:  "speed",
+	5:  "eta_seconds",
+	6:  "error",
+	7:  "attempts",
+	8:  "reason",
+	9:  "psnr",
+	10: "ssim",
+	11: "vmaf",
+	12: "quality_error",
+}
+
+type LadderRung struct {
+	Name           string `thrift:"name,1" frugal:"1,default,string" json:"name"`
+	Height         int32  `thrift:"height,2" frugal:"2,default,i32" json:"height"`
+	ProbeBitrate   int64  `thrift:"probe_bitrate,3" frugal:"3,default,i64" json:"probe_bitrate"`
+	DefaultBitrate string `thrift:"default_bitrate,4" frugal:"4,default,string" json:"default_bitrate"`
+	VideoBitrate   string `thrift:"video_bitrate,5" frugal:"5,default,string" json:"video_bitrate"`
+	MaxBitrate     string `thrift:"max_bitrate,6" frugal:"6,default,string" json:"max_bitrate"`
+	Skipped        string `thrift:"skipped,7" frugal:"7,default,string" json:"skipped"`
+}
+
+func NewLadderRung() *LadderRung {
+	return &LadderRung{}
+}
+
+func (p *LadderRung) InitDefault() {
+}
+
+func (p *LadderRung) GetName() (v string) {
+	return p.Name
+}
+
+func (p *LadderRung) GetHeight() (v int32) {
+	return p.Height
+}
+
+func (p *LadderRung) GetProbeBitrate() (v int64) {
+	return p.ProbeBitrate
+}
+
+func (p *LadderRung) GetDefaultBitrate() (v string) {
+	return p.DefaultBitrate
+}
+
+func (p *LadderRung) GetVideoBitrate() (v string) {
+	return p.VideoBitrate
+}
+
+func (p *LadderRung) GetMaxBitrate() (v string) {
+	return p.MaxBitrate
+}
+
+func (p *LadderRung) GetSkipped() (v string) {
+	return p.Skipped
+}
+func (p *LadderRung) SetName(val string) {
+	p.Name = val
+}
+func (p *LadderRung) SetHeight(val int32) {
+	p.Height = val
+}
+func (p *LadderRung) SetProbeBitrate(val int64) {
+	p.ProbeBitrate = val
+}
+func (p *LadderRung) SetDefaultBitrate(val string) {
+	p.DefaultBitrate = val
+}
+func (p *LadderRung) SetVideoBitrate(val string) {
+	p.VideoBitrate = val
+}
+func (p *LadderRung) SetMaxBitrate(val string) {
+	p.MaxBitrate = val
+}
+func (p *LadderRung) SetSkipped(val string) {
+	p.Skipped = val
+}
+
+func (p *LadderRung) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("LadderRung(%+v)", *p)
+}
+
+var fieldIDToName_LadderRung = map[int16]string{
+	1: "name",
+	2: "height",
+	3: "probe_bitrate",
+	4: "default_bitrate",
+	5: "video_bitrate",
+	6: "max_bitrate",
+	7: "skipped",
+}
+
+type GetTranscodeStatusResp struct {
+	Code           int32                `thrift:"code,1" frugal:"1,default,i32" json:"code"`
+	Msg            string               `thrift:"msg,2" frugal:"2,default,string" json:"msg"`
+	Status         string               `thrift:"status,3" frugal:"3,default,string" json:"status"`
+	Progress       int32                `thrift:"progress,4" frugal:"4,default,i32" json:"progress"`
+	CompletedUrls  []string             `thrift:"completed_urls,5" frugal:"5,default,list<string>" json:"completed_urls"`
+	Renditions     []*RenditionProgress `thrift:"renditions,6" frugal:"6,default,list<RenditionProgress>" json:"renditions"`
+	Speed          float64              `thrift:"speed,7" frugal:"7,default,double" json:"speed"`
+	EtaSeconds     int64                `thrift:"eta_seconds,8" frugal:"8,default,i64" json:"eta_seconds"`
+	Error          string               `thrift:"error,9" frugal:"9,default,string" json:"error"`
+	LastStderr     string               `thrift:"last_stderr,10" frugal:"10,default,string" json:"last_stderr"`
+	PerTitle       bool                 `thrift:"per_title,11" frugal:"11,default,bool" json:"per_title"`
+	Ladder         []*LadderRung        `thrift:"ladder,12" frugal:"12,default,list<LadderRung>" json:"ladder"`
+	QualityMetrics bool                 `thrift:"quality_metrics,13" frugal:"13,default,bool" json:"quality_metrics"`
+}
+
+func NewGetTranscodeStatusResp() *GetTranscodeStatusResp {
+	return &GetTranscodeStatusResp{}
+}
+
+func (p *GetTranscodeStatusResp) InitDefault() {
+}
+
+func (p *GetTranscodeStatusResp) GetCode() (v int32) {
+	return p.Code
+}
+
+func (p *GetTranscodeStatusResp) GetMsg() (v string) {
+	return p.Msg
+}
+
+func (p *GetTranscodeStatusResp) GetStatus() (v string) {
+	return p.Status
+}
+
+func (p *GetTranscodeStatusResp) GetProgress() (v int32) {
+	return p.Progress
+}
+
+func (p *GetTranscodeStatusResp) GetCompletedUrls() (v []string) {
+	return p.CompletedUrls
+}
+
+func (p *GetTranscodeStatusResp) GetRenditions() (v []*RenditionProgress) {
+	return p.Renditions
+}
+
+func (p *GetTranscodeStatusResp) GetSpeed() (v float64) {
+	return p.Speed
+}
+
+func (p *GetTranscodeStatusResp) GetEtaSeconds() (v int64) {
+	return p.EtaSeconds
+}
+
+func (p *GetTranscodeStatusResp) GetError() (v string) {
+	return p.Error
+}
+
+func (p *GetTranscodeStatusResp) GetLastStderr() (v string) {
+	return p.LastStderr
+}
+
+func (p *GetTranscodeStatusResp) GetPerTitle() (v bool) {
+	return p.PerTitle
+}
+
+func (p *GetTranscodeStatusResp) GetLadder() (v []*LadderRung) {
+	return p.Ladder
+}
+
+func (p *GetTranscodeStatusResp) GetQualityMetrics() (v bool) {
+	return p.QualityMetrics
+}
+func (p *GetTranscodeStatusResp) SetCode(val int32) {
+	p.Code = val
+}
+func (p *GetTranscodeStatusResp) SetMsg(val string) {
+	p.Msg = val
+}
+func (p *GetTranscodeStatusResp) SetStatus(val string) {
+	p.Status = val
+}
+func (p *GetTranscodeStatusResp) SetProgress(val int32) {
+	p.Progress = val
+}
+func (p *GetTranscodeStatusResp) SetCompletedUrls(val []string) {
+	p.CompletedUrls = val
+}
+func (p *GetTranscodeStatusResp) SetRenditions(val []*RenditionProgress) {
+	p.Renditions = val
+}
+func (p *GetTranscodeStatusResp) SetSpeed(val float64) {
+	p.Speed = val
+}
+func (p *GetTranscodeStatusResp) SetEtaSeconds(val int64) {
+	p.EtaSeconds = val
+}
+func (p *GetTranscodeStatusResp) SetError(val string) {
+	p.Error = val
+}
+func (p *GetTranscodeStatusResp) SetLastStderr(val string) {
+	p.LastStderr = val
+}
+func (p *GetTranscodeStatusResp) SetPerTitle(val bool) {
+	p.PerTitle = val
+}
+func (p *GetTranscodeStatusResp) SetLadder(val []*LadderRung) {
+	p.Ladder = val
+}
+func (p *GetTranscodeStatusResp) SetQualityMetrics(val bool) {
+	p.QualityMetrics = val
+}
+
+func (p *GetTranscodeStatusResp) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("GetTranscodeStatusResp(%+v)", *p)
+}
+
+var fieldIDToName_GetTranscodeStatusResp = map[int16]string{
+	1:  "code",
+	2:  "msg",
+	3:  "status",
+	4:  "progress",
+	5:  "completed_urls",
+	6:  "renditions",
+	7:  "speed",
+	8:  "eta_seconds",
+	9:  "error",
+	10: "last_stderr",
+	11: "per_title",
+	12: "ladder",
+	13: "quality_metrics",
+}
+
+type CancelTranscodeReq struct {
+	TaskId string `thrift:"task_id,1" frugal:"1,default,string" json:"task_id"`
+	UserId string `thrift:"user_id,2" frugal:"2,default,string" json:"user_id"`
+}
+
+func NewCancelTranscodeReq() *CancelTranscodeReq {
+	return &CancelTranscodeReq{}
+}
+
+func (p *CancelTranscodeReq) InitDefault() {
+}
+
+func (p *CancelTranscodeReq) GetTaskId() (v string) {
+	return p.TaskId
+}
+
+func (p *CancelTranscodeReq) GetUserId() (v string) {
+	return p.UserId
+}
+func (p *CancelTranscodeReq) SetTaskId(val string) {
+	p.TaskId = val
+}
+func (p *CancelTranscodeReq) SetUserId(val string) {
+	p.UserId = val
+}
+
+func (p *CancelTranscodeReq) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("CancelTranscodeReq(%+v)", *p)
+}
+
+var fieldIDToName_CancelTranscodeReq = map[int16]string{
+	1: "task_id",
+	2: "user_id",
+}
+
+type CancelTranscodeResp struct {
+	Code   int32  `thrift:"code,1" frugal:"1,default,i32" json:"code"`
+	Msg    string `thrift:"msg,2" frugal:"2,default,string" json:"msg"`
+	Status string `thrift:"status,3" frugal:"3,default,string" json:"status"`
+}
+
+func NewCancelTranscodeResp() *CancelTranscodeResp {
+	return &CancelTranscodeResp{}
+}
+
+func (p *CancelTranscodeResp) InitDefault() {
+}
+
+func (p *CancelTranscodeResp) GetCode() (v int32) {
+	return p.Code
+}
+
+func (p *CancelTranscodeResp) GetMsg() (v string) {
+	return p.Msg
+}
+
+func (p *CancelTranscodeResp) GetStatus() (v string) {
+	return p.Status
+}
+func (p *CancelTranscodeResp) SetCode(val int32) {
+	p.Code = val
+}
+func (p *CancelTranscodeResp) SetMsg(val string) {
+	p.Msg = val
+}
+func (p *CancelTranscodeResp) SetStatus(val string) {
+	p.Status = val
+}
+
+func (p *CancelTranscodeResp) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("CancelTranscodeResp(%+v)", *p)
+}
+
+var fieldIDToName_CancelTranscodeResp = map[int16]string{
+	1: "code",
+	2: "msg",
+	3: "status",
+}
+
+type RetryTranscodeReq struct {
+	TaskId string `thrift:"task_id,1" frugal:"1,default,string" json:"task_id"`
+	UserId string `thrift:"user_id,2" frugal:"2,default,string" json:"user_id"`
+}
+
+func NewRetryTranscodeReq() *RetryTranscodeReq {
+	return &RetryTranscodeReq{}
+}
+
+func (p *RetryTranscodeReq) InitDefault() {
+}
+
+func (p *RetryTranscodeReq) GetTaskId() (v string) {
+	return p.TaskId
+}
+
+func (p *RetryTranscodeReq) GetUserId() (v string) {
+	return p.UserId
+}
+func (p *RetryTranscodeReq) SetTaskId(val string) {
+	p.TaskId = val
+}
+func (p *RetryTranscodeReq) SetUserId(val string) {
+	p.UserId = val
+}
+
+func (p *RetryTranscodeReq) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("RetryTranscodeReq(%+v)", *p)
+}
+
+var fieldIDToName_RetryTranscodeReq = map[int16]string{
+	1: "task_id",
+	2: "user_id",
+}
+
+type RetryTranscodeResp struct {
+	Code   int32  `thrift:"code,1" frugal:"1,default,i32" json:"code"`
+	Msg    string `thrift:"msg,2" frugal:"2,default,string" json:"msg"`
+	Status string `thrift:"status,3" frugal:"3,default,string" json:"status"`
+}
+
+func NewRetryTranscodeResp() *RetryTranscodeResp {
+	return &RetryTranscodeResp{}
+}
+
+func (p *RetryTranscodeResp) InitDefault() {
+}
+
+func (p *RetryTranscodeResp) GetCode() (v int32) {
+	return p.Code
+}
+
+func (p *RetryTranscodeResp) GetMsg() (v string) {
+	return p.Msg
+}
+
+func (p *RetryTranscodeResp) GetStatus() (v string) {
+	return p.Status
+}
+func (p *RetryTranscodeResp) SetCode(val int32) {
+	p.Code = val
+}
+func (p *RetryTranscodeResp) SetMsg(val string) {
+	p.Msg = val
+}
+func (p *RetryTranscodeResp) SetStatus(val string) {
+	p.Status = val
+}
+
+func (p *RetryTranscodeResp) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("RetryTranscodeResp(%+v)", *p)
+}
+
+var fieldIDToName_RetryTranscodeResp = map[int16]string{
+	1: "code",
+	2: "msg",
+	3: "status",
+}
+
+type TranscodeProfile struct {
+	Name            string `thrift:"name,1" frugal:"1,default,string" json:"name"`
+	Codec           string `thrift:"codec,2" frugal:"2,default,string" json:"codec"`
+	RateControl     string `thrift:"rate_control,3" frugal:"3,default,string" json:"rate_control"`
+	Container       string `thrift:"container,4" frugal:"4,default,string" json:"container"`
+	Width           int32  `thrift:"width,5" frugal:"5,default,i32" json:"width"`
+	Height          int32  `thrift:"height,6" frugal:"6,default,i32" json:"height"`
+	VideoBitrate    string `thrift:"video_bitrate,7" frugal:"7,default,string" json:"video_bitrate"`
+	MaxBitrate      string `thrift:"max_bitrate,8" frugal:"8,default,string" json:"max_bitrate"`
+	Crf             int32  `thrift:"crf,9" frugal:"9,default,i32" json:"crf"`
+	Preset          string `thrift:"preset,10" frugal:"10,default,string" json:"preset"`
+	AudioCodec      string `thrift:"audio_codec,11" frugal:"11,default,string" json:"audio_codec"`
+	AudioBitrate    string `thrift:"audio_bitrate,12" frugal:"12,default,string" json:"audio_bitrate"`
+	AudioChannels   int32  `thrift:"audio_channels,13" frugal:"13,default,i32" json:"audio_channels"`
+	AudioSampleRate int32  `thrift:"audio_sample_rate,14" frugal:"14,default,i32" json:"audio_sample_rate"`
+	Builtin         bool   `thrift:"builtin,15" frugal:"15,default,bool" json:"builtin"`
+	FitMode         string `thrift:"fit_mode,16" frugal:"16,default,string" json:"fit_mode"`
+}
+
+func NewTranscodeProfile() *TranscodeProfile {
+	return &TranscodeProfile{}
+}
+
+func (p *TranscodeProfile) InitDefault() {
+}
+
+func (p *TranscodeProfile) GetName() (v string) {
+	return p.Name
+}
+
+func (p *TranscodeProfile) GetCodec() (v string) {
+	return p.Codec
+}
+
+func (p *TranscodeProfile) GetRateControl() (v string) {
+	return p.RateControl
+}
+
+func (p *TranscodeProfile) GetContainer() (v string) {
+	return p.Container
+}
+
+func (p *TranscodeProfile) GetWidth() (v int32) {
+	return p.Width
+}
+
+func (p *TranscodeProfile) GetHeight() (v int32) {
+	return p.Height
+}
+
+func (p *TranscodeProfile) GetVideoBitrate() (v string) {
+	return p.VideoBitrate
+}
+
+func (p *TranscodeProfile) GetMaxBitrate() (v string) {
+	return p.MaxBitrate
+}
+
+func (p *TranscodeProfile) GetCrf() (v int32) {
+	return p.Crf
+}
+
+func (p *TranscodeProfile) GetPreset() (v string) {
+	return p.Preset
+}
+
+func (p *TranscodeProfile) GetAudioCodec() (v string) {
+	return p.AudioCodec
+}
+
+func (p *TranscodeProfile) GetAudioBitrate() (v string) {
+	return p.AudioBitrate
+}
+
+func (p *TranscodeProfile) GetAudioChannels() (v int32) {
+	return p.AudioChannels
+}
+
+func (p *TranscodeProfile) GetAudioSampleRate() (v int32) {
+	return p.AudioSampleRate
+}
+
+func (p *TranscodeProfile) GetBuiltin() (v bool) {
+	return p.Builtin
+}
+
+func (p *TranscodeProfile) GetFitMode() (v string) {
+	return p.FitMode
+}
+func (p *TranscodeProfile) SetName(val string) {
+	p.Name = val
+}
+func (p *TranscodeProfile) SetCodec(val string) {
+	p.Codec = val
+}
+func (p *TranscodeProfile) SetRateControl(val string) {
+	p.RateControl = val
+}
+func (p *TranscodeProfile) SetContainer(val string) {
+	p.Container = val
+}
+func (p *TranscodeProfile) SetWidth(val int32) {
+	p.Width = val
+}
+func (p *TranscodeProfile) SetHeight(val int32) {
+	p.Height = val
+}
+func (p *TranscodeProfile) SetVideoBitrate(val string) {
+	p.VideoBitrate = val
+}
+func (p *TranscodeProfile) SetMaxBitrate(val string) {
+	p.MaxBitrate = val
+}
+func (p *TranscodeProfile) SetCrf(val int32) {
+	p.Crf = val
+}
+func (p *TranscodeProfile) SetPreset(val string) {
+	p.Preset = val
+}
+func (p *TranscodeProfile) SetAudioCodec(val string) {
+	p.AudioCodec = val
+}
+func (p *TranscodeProfile) SetAudioBitrate(val string) {
+	p.AudioBitrate = val
+}
+func (p *TranscodeProfile) SetAudioChannels(val int32) {
+	p.AudioChannels = val
+}
+func (p *TranscodeProfile) SetAudioSampleRate(val int32) {
+	p.AudioSampleRate = val
+}
+func (p *TranscodeProfile) SetBuiltin(val bool) {
+	p.Builtin = val
+}
+func (p *TranscodeProfile) SetFitMode(val string) {
+	p.FitMode = val
+}
+
+func (p *TranscodeProfile) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("TranscodeProfile(%+v)", *p)
+}
+
+var fieldIDToName_TranscodeProfile = map[int16]string{
+	1:  "name",
+	2:  "codec",
+	3:  "rate_control",
+	4:  "container",
+	5:  "width",
+	6:  "height",
+	7:  "video_bitrate",
+	8:  "max_bitrate",
+	9:  "crf",
+	10: "preset",
+	11: "audio_codec",
+	12: "audio_bitrate",
+	13: "audio_channels",
+	14: "audio_sample_rate",
+	15: "builtin",
+	16: "fit_mode",
+}
+
+type ListTranscodeProfilesReq struct {
+}
+
+func NewListTranscodeProfilesReq() *ListTranscodeProfilesReq {
+	return &ListTranscodeProfilesReq{}
+}
+
+func (p *ListTranscodeProfilesReq) InitDefault() {
+}
+
+func (p *ListTranscodeProfilesReq) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("ListTranscodeProfilesReq(%+v)", *p)
+}
+
+var fieldIDToName_ListTranscodeProfilesReq = map[int16]string{}
+
+type ListTranscodeProfilesResp struct {
+	Code     int32               `thrift:"code,1" frugal:"1,default,i32" json:"code"`
+	Msg      string              `thrift:"msg,2" frugal:"2,default,string" json:"msg"`
+	Profiles []*TranscodeProfile `thrift:"profiles,3" frugal:"3,default,list<TranscodeProfile>" json:"profiles"`
+}
+
+func NewListTranscodeProfilesResp() *ListTranscodeProfilesResp {
+	return &ListTranscodeProfilesResp{}
+}
+
+func (p *ListTranscodeProfilesResp) InitDefault() {
+}
+
+func (p *ListTranscodeProfilesResp) GetCode() (v int32) {
+	return p.Code
+}
+
+func (p *ListTranscodeProfilesResp) GetMsg() (v string) {
+	return p.Msg
+}
+
+func (p *ListTranscodeProfilesResp) GetProfiles() (v []*TranscodeProfile) {
+	return p.Profiles
+}
+func (p *ListTranscodeProfilesResp) SetCode(val int32) {
+	p.Code = val
+}
+func (p *ListTranscodeProfilesResp) SetMsg(val string) {
+	p.Msg = val
+}
+func (p *ListTranscodeProfilesResp) SetProfiles(val []*TranscodeProfile) {
+	p.Profiles = val
+}
+
+func (p *ListTranscodeProfilesResp) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("ListTranscodeProfilesResp(%+v)", *p)
+}
+
+var fieldIDToName_ListTranscodeProfilesResp = map[int16]string{
+	1: "code",
+	2: "msg",
+	3: "profiles",
+}
+
+type SaveTranscodeProfileReq struct {
+	Profile *TranscodeProfile `thrift:"profile,1" frugal:"1,default,TranscodeProfile" json:"profile"`
+}
+
+func NewSaveTranscodeProfileReq() *SaveTranscodeProfileReq {
+	return &SaveTranscodeProfileReq{}
+}
+
+func (p *SaveTranscodeProfileReq) InitDefault() {
+}
+
+var SaveTranscodeProfileReq_Profile_DEFAULT *TranscodeProfile
+
+func (p *SaveTranscodeProfileReq) GetProfile() (v *TranscodeProfile) {
+	if !p.IsSetProfile() {
+		return SaveTranscodeProfileReq_Profile_DEFAULT
+	}
+	return p.Profile
+}
+func (p *SaveTranscodeProfileReq) SetProfile(val *TranscodeProfile) {
+	p.Profile = val
+}
+
+func (p *SaveTranscodeProfileReq) IsSetProfile() bool {
+	return p.Profile != nil
+}
+
+func (p *SaveTranscodeProfileReq) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("SaveTranscodeProfileReq(%+v)", *p)
+}
+
+var fieldIDToName_SaveTranscodeProfileReq = map[int16]string{
+	1: "profile",
+}
+
+type SaveTranscodeProfileResp struct {
+	Code    int32             `thrift:"code,1" frugal:"1,default,i32" json:"code"`
+	Msg     string            `thrift:"msg,2" frugal:"2,default,string" json:"msg"`
+	Profile *TranscodeProfile `thrift:"profile,3" frugal:"3,default,TranscodeProfile" json:"profile"`
+}
+
+func NewSaveTranscodeProfileResp() *SaveTranscodeProfileResp {
+	return &SaveTranscodeProfileResp{}
+}
+
+func (p *SaveTranscodeProfileResp) InitDefault() {
+}
+
+func (p *SaveTranscodeProfileResp) GetCode() (v int32) {
+	return p.Code
+}
+
+func (p *SaveTranscodeProfileResp) GetMsg() (v string) {
+	return p.Msg
+}
+
+var SaveTranscodeProfileResp_Profile_DEFAULT *TranscodeProfile
+
+func (p *SaveTranscodeProfileResp) GetProfile() (v *TranscodeProfile) {
+	if !p.IsSetProfile() {
+		return SaveTranscodeProfileResp_Profile_DEFAULT
+	}
+	return p.Profile
+}
+func (p *SaveTranscodeProfileResp) SetCode(val int32) {
+	p.Code = val
+}
+func (p *SaveTranscodeProfileResp) SetMsg(val string) {
+	p.Msg = val
+}
+func (p *SaveTranscodeProfileResp) SetProfile(val *TranscodeProfile) {
+	p.Profile = val
+}
+
+func (p *SaveTranscodeProfileResp) IsSetProfile() bool {
+	return p.Profile != nil
+}
+
+func (p *SaveTranscodeProfileResp) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("SaveTranscodeProfileResp(%+v)", *p)
+}
+
+var fieldIDToName_SaveTranscodeProfileResp = map[int16]string{
+	1: "code",
+	2: "msg",
+	3: "profile",
+}
+
+type DeleteTranscodeProfileReq struct {
+	Name string `thrift:"name,1" frugal:"1,default,string" json:"name"`
+}
+
+func NewDeleteTranscodeProfileReq() *DeleteTranscodeProfileReq {
+	return &DeleteTranscodeProfileReq{}
+}
+
+func (p *DeleteTranscodeProfileReq) InitDefault() {
+}
+
+func (p *DeleteTranscodeProfileReq) GetName() (v string) {
+	return p.Name
+}
+func (p *DeleteTranscodeProfileReq) SetName(val string) {
+	p.Name = val
+}
+
+func (p *DeleteTranscodeProfileReq) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("DeleteTranscodeProfileReq(%+v)", *p)
+}
+
+var fieldIDToName_DeleteTranscodeProfileReq = map[int16]string{
+	1: "name",
+}
+
+type DeleteTranscodeProfileResp struct {
+	Code int32  `thrift:"code,1" frugal:"1,default,i32" json:"code"`
+	Msg  string `thrift:"msg,2" frugal:"2,default,string" json:"msg"`
+}
+
+func NewDeleteTranscodeProfileResp() *DeleteTranscodeProfileResp {
+	return &DeleteTranscodeProfileResp{}
+}
+
+func (p *DeleteTranscodeProfileResp) InitDefault() {
+}
+
+func (p *DeleteTranscodeProfileResp) GetCode() (v int32) {
+	return p.Code
+}
+
+func (p *DeleteTranscodeProfileResp) GetMsg() (v string) {
+	return p.Msg
+}
+func (p *DeleteTranscodeProfileResp) SetCode(val int32) {
+	p.Code = val
+}
+func (p *DeleteTranscodeProfileResp) SetMsg(val string) {
+	p.Msg = val
+}
+
+func (p *DeleteTranscodeProfileResp) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("DeleteTranscodeProfileResp(%+v)", *p)
+}
+
+var fieldIDToName_DeleteTranscodeProfileResp = map[int16]string{
+	1: "code",
+	2: "msg",
+}
+
+type ProcessingPolicy struct {
+	Steps              []string `thrift:"steps,1" frugal:"1,default,list<string>" json:"steps"`
+	Profiles           []string `thrift:"profiles,2" frugal:"2,default,list<string>" json:"profiles"`
+	PerTitle           bool     `thrift:"per_title,3" frugal:"3,default,bool" json:"per_title"`
+	QualityMetrics     bool     `thrift:"quality_metrics,4" frugal:"4,default,bool" json:"quality_metrics"`
+	RequireVideo       bool     `thrift:"require_video,5" frugal:"5,default,bool" json:"require_video"`
+	MaxDurationSeconds int32    `thrift:"max_duration_seconds,6" frugal:"6,default,i32" json:"max_duration_seconds"`
+	MinShortSide       int32    `thrift:"min_short_side,7" frugal:"7,default,i32" json:"min_short_side"`
+	MaxShortSide       int32    `thrift:"max_short_side,8" frugal:"8,default,i32" json:"max_short_side"`
+	Enabled            bool     `thrift:"enabled,9" frugal:"9,default,bool" json:"enabled"`
+}
+
+func NewProcessingPolicy() *ProcessingPolicy {
+	return &ProcessingPolicy{}
+}
+
+func (p *ProcessingPolicy) InitDefault() {
+}
+
+func (p *ProcessingPolicy) GetSteps() (v []string) {
+	return p.Steps
+}
+
+func (p *ProcessingPolicy) GetProfiles() (v []string) {
+	return p.Profiles
+}
+
+func (p *ProcessingPolicy) GetPerTitle() (v bool) {
+	return p.PerTitle
+}
+
+func (p *ProcessingPolicy) GetQualityMetrics() (v bool) {
+	return p.QualityMetrics
+}
+
+func (p *ProcessingPolicy) GetRequireVideo() (v bool) {
+	return p.RequireVideo
+}
+
+func (p *ProcessingPolicy) GetMaxDurationSeconds() (v int32) {
+	return p.MaxDurationSeconds
+}
+
+func (p *ProcessingPolicy) GetMinShortSide() (v int32) {
+	return p.MinShortSide
+}
+
+func (p *ProcessingPolicy) GetMaxShortSide() (v int32) {
+	return p.MaxShortSide
+}
+
+func (p *ProcessingPolicy) GetEnabled() (v bool) {
+	return p.Enabled
+}
+func (p *ProcessingPolicy) SetSteps(val []string) {
+	p.Steps = val
+}
+func (p *ProcessingPolicy) SetProfiles(val []string) {
+	p.Profiles = val
+}
+func (p *ProcessingPolicy) SetPerTitle(val bool) {
+	p.PerTitle = val
+}
+func (p *ProcessingPolicy) SetQualityMetrics(val bool) {
+	p.QualityMetrics = val
+}
+func (p *ProcessingPolicy) SetRequireVideo(val bool) {
+	p.RequireVideo = val
+}
+func (p *ProcessingPolicy) SetMaxDurationSeconds(val int32) {
+	p.MaxDurationSeconds = val
+}
+func (p *ProcessingPolicy) SetMinShortSide(val int32) {
+	p.MinShortSide = val
+}
+func (p *ProcessingPolicy) SetMaxShortSide(val int32) {
+	p.MaxShortSide = val
+}
+func (p *ProcessingPolicy) SetEnabled(val bool) {
+	p.Enabled = val
+}
+
+func (p *ProcessingPolicy) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("ProcessingPolicy(%+v)", *p)
+}
+
+var fieldIDToName_ProcessingPolicy = map[int16]string{
+	1: "steps",
+	2: "profiles",
+	3: "per_title",
+	4: "quality_metrics",
+	5: "require_video",
+	6: "max_duration_seconds",
+	7: "min_short_side",
+	8: "max_short_side",
+	9: "enabled",
+}
+
+type GetProcessingPolicyReq struct {
+	UserId   string `thrift:"user_id,1" frugal:"1,default,string" json:"user_id"`
+	Platform bool   `thrift:"platform,2" frugal:"2,default,bool" json:"platform"`
+}
+
+func NewGetProcessingPolicyReq() *GetProcessingPolicyReq {
+	return &GetProcessingPolicyReq{}
+}
+
+func (p *GetProcessingPolicyReq) InitDefault() {
+}
+
+func (p *GetProcessingPolicyReq) GetUserId() (v string) {
+	return p.UserId
+}
+
+func (p *GetProcessingPolicyReq) GetPlatform() (v bool) {
+	return p.Platform
+}
+func (p *GetProcessingPolicyReq) SetUserId(val string) {
+	p.UserId = val
+}
+func (p *GetProcessingPolicyReq) SetPlatform(val bool) {
+	p.Platform = val
+}
+
+func (p *GetProcessingPolicyReq) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("GetProcessingPolicyReq(%+v)", *p)
+}
+
+var fieldIDToName_GetProcessingPolicyReq = map[int16]string{
+	1: "user_id",
+	2: "platform",
+}
+
+type GetProcessingPolicyResp struct {
+	Code   int32             `thrift:"code,1" frugal:"1,default,i32" json:"code"`
+	Msg    string            `thrift:"msg,2" frugal:"2,default,string" json:"msg"`
+	Policy *ProcessingPolicy `thrift:"policy,3" frugal:"3,default,ProcessingPolicy" json:"policy"`
+	Scope  string            `thrift:"scope,4" frugal:"4,default,string" json:"scope"`
+}
+
+func NewGetProcessingPolicyResp() *GetProcessingPolicyResp {
+	return &GetProcessingPolicyResp{}
+}
+
+func (p *GetProcessingPolicyResp) InitDefault() {
+}
+
+func (p *GetProcessingPolicyResp) GetCode() (v int32) {
+	return p.Code
+}
+
+func (p *GetProcessingPolicyResp) GetMsg() (v string) {
+	return p.Msg
+}
+
+var GetProcessingPolicyResp_Policy_DEFAULT *ProcessingPolicy
+
+func (p *GetProcessingPolicyResp) GetPolicy() (v *ProcessingPolicy) {
+	if !p.IsSetPolicy() {
+		return GetProcessingPolicyResp_Policy_DEFAULT
+	}
+	return p.Policy
+}
+
+func (p *GetProcessingPolicyResp) GetScope() (v string) {
+	return p.Scope
+}
+func (p *GetProcessingPolicyResp) SetCode(val int32) {
+	p.Code = val
+}
+func (p *GetProcessingPolicyResp) SetMsg(val string) {
+	p.Msg = val
+}
+func (p *GetProcessingPolicyResp) SetPolicy(val *ProcessingPolicy) {
+	p.Policy = val
+}
+func (p *GetProcessingPolicyResp) SetScope(val string) {
+	p.Scope = val
+}
+
+func (p *GetProcessingPolicyResp) IsSetPolicy() bool {
+	return p.Policy != nil
+}
+
+func (p *GetProcessingPolicyResp) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("GetProcessingPolicyResp(%+v)", *p)
+}
+
+var fieldIDToName_GetProcessingPolicyResp = map[int16]string{
+	1: "code",
+	2: "msg",
+	3: "policy",
+	4: "scope",
+}
+
+type SaveProcessingPolicyReq struct {
+	UserId   string            `thrift:"user_id,1" frugal:"1,default,string" json:"user_id"`
+	Platform bool              `thrift:"platform,2" frugal:"2,default,bool" json:"platform"`
+	Policy   *ProcessingPolicy `thrift:"policy,3" frugal:"3,default,ProcessingPolicy" json:"policy"`
+}
+
+func NewSaveProcessingPolicyReq() *SaveProcessingPolicyReq {
+	return &SaveProcessingPolicyReq{}
+}
+
+func (p *SaveProcessingPolicyReq) InitDefault() {
+}
+
+func (p *SaveProcessingPolicyReq) GetUserId() (v string) {
+	return p.UserId
+}
+
+func (p *SaveProcessingPolicyReq) GetPlatform() (v bool) {
+	return p.Platform
+}
+
+var SaveProcessingPolicyReq_Policy_DEFAULT *ProcessingPolicy
+
+func (p *SaveProcessingPolicyReq) GetPolicy() (v *ProcessingPolicy) {
+	if !p.IsSetPolicy() {
+		return SaveProcessingPolicyReq_Policy_DEFAULT
+	}
+	return p.Policy
+}
+func (p *SaveProcessingPolicyReq) SetUserId(val string) {
+	p.UserId = val
+}
+func (p *SaveProcessingPolicyReq) SetPlatform(val bool) {
+	p.Platform = val
+}
+func (p *SaveProcessingPolicyReq) SetPolicy(val *ProcessingPolicy) {
+	p.Policy = val
+}
+
+func (p *SaveProcessingPolicyReq) IsSetPolicy() bool {
+	return p.Policy != nil
+}
+
+func (p *SaveProcessingPolicyReq) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("SaveProcessingPolicyReq(%+v)", *p)
+}
+
+var fieldIDToName_SaveProcessingPolicyReq = map[int16]string{
+	1: "user_id",
+	2: "platform",
+	3: "policy",
+}
+
+type SaveProcessingPolicyResp struct {
+	Code   int32             `thrift:"code,1" frugal:"1,default,i32" json:"code"`
+	Msg    string            `thrift:"msg,2" frugal:"2,default,string" json:"msg"`
+	Policy *ProcessingPolicy `thrift:"policy,3" frugal:"3,default,ProcessingPolicy" json:"policy"`
+}
+
+func NewSaveProcessingPolicyResp() *SaveProcessingPolicyResp {
+	return &SaveProcessingPolicyResp{}
+}
+
+func (p *SaveProcessingPolicyResp) InitDefault() {
+}
+
+func (p *SaveProcessingPolicyResp) GetCode() (v int32) {
+	return p.Code
+}
+
+func (p *SaveProcessingPolicyResp) GetMsg() (v string) {
+	return p.Msg
+}
+
+var SaveProcessingPolicyResp_Policy_DEFAULT *ProcessingPolicy
+
+func (p *SaveProcessingPolicyResp) GetPolicy() (v *ProcessingPolicy) {
+	if !p.IsSetPolicy() {
+		return SaveProcessingPolicyResp_Policy_DEFAULT
+	}
+	return p.Policy
+}
+func (p *SaveProcessingPolicyResp) SetCode(val int32) {
+	p.Code = val
+}
+func (p *SaveProcessingPolicyResp) SetMsg(val string) {
+	p.Msg = val
+}
+func (p *SaveProcessingPolicyResp) SetPolicy(val *ProcessingPolicy) {
+	p.Policy = val
+}
+
+func (p *SaveProcessingPolicyResp) IsSetPolicy() bool {
+	return p.Policy != nil
+}
+
+func (p *SaveProcessingPolicyResp) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("SaveProcessingPolicyResp(%+v)", *p)
+}
+
+var fieldIDToName_SaveProcessingPolicyResp = map[int16]string{
+	1: "code",
+	2: "msg",
+	3: "policy",
+}
+
+type DeleteProcessingPolicyReq struct {
+	UserId   string `thrift:"user_id,1" frugal:"1,default,string" json:"user_id"`
+	Platform bool   `thrift:"platform,2" frugal:"2,default,bool" json:"platform"`
+}
+
+func NewDeleteProcessingPolicyReq() *DeleteProcessingPolicyReq {
+	return &DeleteProcessingPolicyReq{}
+}
+
+func (p *DeleteProcessingPolicyReq) InitDefault() {
+}
+
+func (p *DeleteProcessingPolicyReq) GetUserId() (v string) {
+	return p.UserId
+}
+
+func (p *DeleteProcessingPolicyReq) GetPlatform() (v bool) {
+	return p.Platform
+}
+func (p *DeleteProcessingPolicyReq) SetUserId(val string) {
+	p.UserId = val
+}
+func (p *DeleteProcessingPolicyReq) SetPlatform(val bool) {
+	p.Platform = val
+}
+
+func (p *DeleteProcessingPolicyReq) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("DeleteProcessingPolicyReq(%+v)", *p)
+}
+
+var fieldIDToName_DeleteProcessingPolicyReq = map[int16]string{
+	1: "user_id",
+	2: "platform",
+}
+
+type DeleteProcessingPolicyResp struct {
+	Code int32  `thrift:"code,1" frugal:"1,default,i32" json:"code"`
+	Msg  string `thrift:"msg,2" frugal:"2,default,string" json:"msg"`
+}
+
+func NewDeleteProcessingPolicyResp() *DeleteProcessingPolicyResp {
+	return &DeleteProcessingPolicyResp{}
+}
+
+func (p *DeleteProcessingPolicyResp) InitDefault() {
+}
+
+func (p *DeleteProcessingPolicyResp) GetCode() (v int32) {
+	return p.Code
+}
+
+func (p *DeleteProcessingPolicyResp) GetMsg() (v string) {
+	return p.Msg
+}
+func (p *DeleteProcessingPolicyResp) SetCode(val int32) {
+	p.Code = val
+}
+func (p *DeleteProcessingPolicyResp) SetMsg(val string) {
+	p.Msg = val
+}
+
+func (p *DeleteProcessingPolicyResp) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("DeleteProcessingPolicyResp(%+v)", *p)
+}
+
+var fieldIDToName_DeleteProcessingPolicyResp = map[int16]string{
+	1: "code",
+	2: "msg",
+}
+
+type PipelineStep struct {
+	Name       string `thrift:"name,1" frugal:"1,default,string" json:"name"`
+	Status     string `thrift:"status,2" frugal:"2,default,string" json:"status"`
+	Error      string `thrift:"error,3" frugal:"3,default,string" json:"error"`
+	Attempts   int32  `thrift:"attempts,4" frugal:"4,default,i32" json:"attempts"`
+	Output     string `thrift:"output,5" frugal:"5,default,string" json:"output"`
+	StartedAt  int64  `thrift:"started_at,6" frugal:"6,default,i64" json:"started_at"`
+	FinishedAt int64  `thrift:"finished_at,7" frugal:"7,default,i64" json:"finished_at"`
+}
+
+func NewPipelineStep() *PipelineStep {
+	return &PipelineStep{}
+}
+
+func (p *PipelineStep) InitDefault() {
+}
+
+func (p *PipelineStep) GetName() (v string) {
+	return p.Name
+}
+
+func (p *PipelineStep) GetStatus() (v string) {
+	return p.Status
+}
+
+func (p *PipelineStep) GetError() (v string) {
+	return p.Error
+}
+
+func (p *PipelineStep) GetAttempts() (v int32) {
+	return p.Attempts
+}
+
+func (p *PipelineStep) GetOutput() (v string) {
+	return p.Output
+}
+
+func (p *PipelineStep) GetStartedAt() (v int64) {
+	return p.StartedAt
+}
+
+func (p *PipelineStep) GetFinishedAt() (v int64) {
+	return p.FinishedAt
+}
+func (p *PipelineStep) SetName(val string) {
+	p.Name = val
+}
+func (p *PipelineStep) SetStatus(val string) {
+	p.Status = val
+}
+func (p *PipelineStep) SetError(val string) {
+	p.Error = val
+}
+func (p *PipelineStep) SetAttempts(val int32) {
+	p.Attempts = val
+}
+func (p *PipelineStep) SetOutput(val string) {
+	p.Output = val
+}
+func (p *PipelineStep) SetStartedAt(val int64) {
+	p.StartedAt = val
+}
+func (p *PipelineStep) SetFinishedAt(val int64) {
+	p.FinishedAt = val
+}
+
+func (p *PipelineStep) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("PipelineStep(%+v)", *p)
+}
+
+var fieldIDToName_PipelineStep = map[int16]string{
+	1: "name",
+	2: "status",
+	3: "error",
+	4: "attempts",
+	5: "output",
+	6: "started_at",
+	7: "finished_at",
+}
+
+type GetPipelineReq struct {
+	FileHash   string `thrift:"file_hash,1" frugal:"1,default,string" json:"file_hash"`
+	UserId     string `thrift:"user_id,2" frugal:"2,default,string" json:"user_id"`
+	PipelineId string `thrift:"pipeline_id,3" frugal:"3,default,string" json:"pipeline_id"`
+}
+
+func NewGetPipelineReq() *GetPipelineReq {
+	return &GetPipelineReq{}
+}
+
+func (p *GetPipelineReq) InitDefault() {
+}
+
+func (p *GetPipelineReq) GetFileHash() (v string) {
+	return p.FileHash
+}
+
+func (p *GetPipelineReq) GetUserId() (v string) {
+	return p.UserId
+}
+
+func (p *GetPipelineReq) GetPipelineId() (v string) {
+	return p.PipelineId
+}
+func (p *GetPipelineReq) SetFileHash(val string) {
+	p.FileHash = val
+}
+func (p *GetPipelineReq) SetUserId(val string) {
+	p.UserId = val
+}
+func (p *GetPipelineReq) SetPipelineId(val string) {
+	p.PipelineId = val
+}
+
+func (p *GetPipelineReq) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("GetPipelineReq(%+v)", *p)
+}
+
+var fieldIDToName_GetPipelineReq = map[int16]string{
+	1: "file_hash",
+	2: "user_id",
+	3: "pipeline_id",
+}
+
+type GetPipelineResp struct {
+	Code       int32             `thrift:"code,1" frugal:"1,default,i32" json:"code"`
+	Msg        string            `thrift:"msg,2" frugal:"2,default,string" json:"msg"`
+	PipelineId string            `thrift:"pipeline_id,3" frugal:"3,default,string" json:"pipeline_id"`
+	FileHash   string            `thrift:"file_hash,4" frugal:"4,default,string" json:"file_hash"`
+	Status     string            `thrift:"status,5" frugal:"5,default,string" json:"status"`
+	Error      string            `thrift:"error,6" frugal:"6,default,string" json:"error"`
+	Steps      []*PipelineStep   `thrift:"steps,7" frugal:"7,default,list<PipelineStep>" json:"steps"`
+	Policy     *ProcessingPolicy `thrift:"policy,8" frugal:"8,default,ProcessingPolicy" json:"policy"`
+	CreatedAt  int64             `thrift:"created_at,9" frugal:"9,default,i64" json:"created_at"`
+}
+
+func NewGetPipelineResp() *GetPipelineResp {
+	return &GetPipelineResp{}
+}
+
+func (p *GetPipelineResp) InitDefault() {
+}
+
+func (p *GetPipelineResp) GetCode() (v int32) {
+	return p.Code
+}
+
+func (p *GetPipelineResp) GetMsg() (v string) {
+	return p.Msg
+}
+
+func (p *GetPipelineResp) GetPipelineId() (v string) {
+	return p.PipelineId
+}
+
+func (p *GetPipelineResp) GetFileHash() (v string) {
+	return p.FileHash
+}
+
+func (p *GetPipelineResp) GetStatus() (v string) {
+	return p.Status
+}
+
+func (p *GetPipelineResp) GetError() (v string) {
+	return p.Error
+}
+
+func (p *GetPipelineResp) GetSteps() (v []*PipelineStep) {
+	return p.Steps
+}
+
+var GetPipelineResp_Policy_DEFAULT *ProcessingPolicy
+
+func (p *GetPipelineResp) GetPolicy() (v *ProcessingPolicy) {
+	if !p.IsSetPolicy() {
+		return GetPipelineResp_Policy_DEFAULT
+	}
+	return p.Policy
+}
+
+func (p *GetPipelineResp) GetCreatedAt() (v int64) {
+	return p.CreatedAt
+}
+func (p *GetPipelineResp) SetCode(val int32) {
+	p.Code = val
+}
+func (p *GetPipelineResp) SetMsg(val string) {
+	p.Msg = val
+}
+func (p *GetPipelineResp) SetPipelineId(val string) {
+	p.PipelineId = val
+}
+func (p *GetPipelineResp) SetFileHash(val string) {
+	p.FileHash = val
+}
+func (p *GetPipelineResp) SetStatus(val string) {
+	p.Status = val
+}
+func (p *GetPipelineResp) SetError(val string) {
+	p.Error = val
+}
+func (p *GetPipelineResp) SetSteps(val []*PipelineStep) {
+	p.Steps = val
+}
+func (p *GetPipelineResp) SetPolicy(val *ProcessingPolicy) {
+	p.Policy = val
+}
+func (p *GetPipelineResp) SetCreatedAt(val int64) {
+	p.CreatedAt = val
+}
+
+func (p *GetPipelineResp) IsSetPolicy() bool {
+	return p.Policy != nil
+}
+
+func (p *GetPipelineResp) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("GetPipelineResp(%+v)", *p)
+}
+
+var fieldIDToName_GetPipelineResp = map[int16]string{
+	1: "code",
+	2: "msg",
+	3: "pipeline_id",
+	4: "file_hash",
+	5: "status",
+	6: "error",
+	7: "steps",
+	8: "policy",
+	9: "created_at",
+}
+
+type RetryPipelineReq struct {
+	PipelineId string `thrift:"pipeline_id,1" frugal:"1,default,string" json:"pipeline_id"`
+	UserId     string `thrift:"user_id,2" frugal:"2,default,string" json:"user_id"`
+	Step       string `thrift:"step,3" frugal:"3,default,string" json:"step"`
+}
+
+func NewRetryPipelineReq() *RetryPipelineReq {
+	return &RetryPipelineReq{}
+}
+
+func (p *RetryPipelineReq) InitDefault() {
+}
+
+func (p *RetryPipelineReq) GetPipelineId() (v string) {
+	return p.PipelineId
+}
+
+func (p *RetryPipelineReq) GetUserId() (v string) {
+	return p.UserId
+}
+
+func (p *RetryPipelineReq) GetStep() (v string) {
+	return p.Step
+}
+func (p *RetryPipelineReq) SetPipelineId(val string) {
+	p.PipelineId = val
+}
+func (p *RetryPipelineReq) SetUserId(val string) {
+	p.UserId = val
+}
+func (p *RetryPipelineReq) SetStep(val string) {
+	p.Step = val
+}
+
+func (p *RetryPipelineReq) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("RetryPipelineReq(%+v)", *p)
+}
+
+var fieldIDToName_RetryPipelineReq = map[int16]string{
+	1: "pipeline_id",
+	2: "user_id",
+	3: "step",
+}
+
+type RetryPipelineResp struct {
+	Code   int32  `thrift:"code,1" frugal:"1,default,i32" json:"code"`
+	Msg    string `thrift:"msg,2" frugal:"2,default,string" json:"msg"`
+	Status string `thrift:"status,3" frugal:"3,default,string" json:"status"`
+}
+
+func NewRetryPipelineResp() *RetryPipelineResp {
+	return &RetryPipelineResp{}
+}
+
+func (p *RetryPipelineResp) InitDefault() {
+}
+
+func (p *RetryPipelineResp) GetCode() (v int32) {
+	return p.Code
+}
+
+func (p *RetryPipelineResp) GetMsg() (v string) {
+	return p.Msg
+}
+
+func (p *RetryPipelineResp) GetStatus() (v string) {
+	return p.Status
+}
+func (p *RetryPipelineResp) SetCode(val int32) {
+	p.Code = val
+}
+func (p *RetryPipelineResp) SetMsg(val string) {
+	p.Msg = val
+}
+func (p *RetryPipelineResp) SetStatus(val string) {
+	p.Status = val
+}
+
+func (p *RetryPipelineResp) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("RetryPipelineResp(%+v)", *p)
+}
+
+var fieldIDToName_RetryPipelineResp = map[int16]string{
+	1: "code",
+	2: "msg",
+	3: "status",
+}
+
+type DeleteVideoReq struct {
+	FileHash string `thrift:"file_hash,1" frugal:"1,default,string" json:"file_hash"`
+	UserId   string `thrift:"user_id,2" frugal:"2,default,string" json:"user_id"`
+}
+
+func NewDeleteVideoReq() *DeleteVideoReq {
+	return &DeleteVideoReq{}
+}
+
+func (p *DeleteVideoReq) InitDefault() {
+}
+
+func (p *DeleteVideoReq) GetFileHash() (v string) {
+	return p.FileHash
+}
+
+func (p *DeleteVideoReq) GetUserId() (v string) {
+	return p.UserId
+}
+func (p *DeleteVideoReq) SetFileHash(val string) {
+	p.FileHash = val
+}
+func (p *DeleteVideoReq) SetUserId(val string) {
+	p.UserId = val
+}
+
+func (p *DeleteVideoReq) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("DeleteVideoReq(%+v)", *p)
+}
+
+var fieldIDToName_DeleteVideoReq = map[int16]string{
+	1: "file_hash",
+	2: "user_id",
+}
+
+type DeleteVideoResp struct {
+	Code int32  `thrift:"code,1" frugal:"1,default,i32" json:"code"`
+	Msg  string `thrift:"msg,2" frugal:"2,default,string" json:"msg"`
+}
+
+func NewDeleteVideoResp() *DeleteVideoResp {
+	return &DeleteVideoResp{}
+}
+
+func (p *DeleteVideoResp) InitDefault() {
+}
+
+func (p *DeleteVideoResp) GetCode() (v int32) {
+	return p.Code
+}
+
+func (p *DeleteVideoResp) GetMsg() (v string) {
+	return p.Msg
+}
+func (p *DeleteVideoResp) SetCode(val int32) {
+	p.Code = val
+}
+func (p *DeleteVideoResp) SetMsg(val string) {
+	p.Msg = val
+}
+
+func (p *DeleteVideoResp) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("DeleteVideoResp(%+v)", *p)
+}
+
+var fieldIDToName_DeleteVideoResp = map[int16]string{
+	1: "code",
+	2: "msg",
+}
+
+type VideoService interface {
+	InitUpload(ctx context.Context, req *InitUploadReq) (r *InitUploadResp, err error)
+
+	UploadChunk(ctx context.Context, req *UploadChunkReq) (r *UploadChunkResp, err error)
+
+	UploadChunkStream(stream VideoService_UploadChunkStreamServer) (err error)
+
+	MergeFile(ctx context.Context, req *MergeFileReq) (r *MergeFileResp, err error)
+
+	GetMergeStatus(ctx context.Context, req *GetMergeStatusReq) (r *GetMergeStatusResp, err error)
+
+	DownloadChunk(ctx context.Context, req *DownloadChunkReq) (r *DownloadChunkResp, err error)
+
+	DownloadStream(req *DownloadStreamReq, stream VideoService_DownloadStreamServer) (err error)
+
+	GetVideoInfo(ctx context.Context, req *GetVideoInfoReq) (r *GetVideoInfoResp, err error)
+
+	GetPlaybackFile(ctx context.Context, req *GetPlaybackFileReq) (r *GetPlaybackFileResp, err error)
+
+	GetThumbnailFile(ctx context.Context, req *GetThumbnailFileReq) (r *GetThumbnailFileResp, err error)
+
+	SetPoster(ctx context.Context, req *SetPosterReq) (r *SetPosterResp, err error)
+
+	Transcode(ctx context.Context, req *TranscodeReq) (r *TranscodeResp, err error)
+
+	GetTranscodeStatus(ctx context.Context, req *GetTranscodeStatusReq) (r *GetTranscodeStatusResp, err error)
+
+	CancelTranscode(ctx context.Context, req *CancelTranscodeReq) (r *CancelTranscodeResp, err error)
+
+	RetryTranscode(ctx context.Context, req *RetryTranscodeReq) (r *RetryTranscodeResp, err error)
+
+	ListTranscodeProfiles(ctx context.Context, req *ListTranscodeProfilesReq) (r *ListTranscodeProfilesResp, err error)
+
+	SaveTranscodeProfile(ctx context.Context, req *SaveTranscodeProfileReq) (r *SaveTranscodeProfileResp, err error)
+
+	DeleteTranscodeProfile(ctx context.Context, req *DeleteTranscodeProfileReq) (r *DeleteTranscodeProfileResp, err error)
+
+	DeleteVideo(ctx context.Context, req *DeleteVideoReq) (r *DeleteVideoResp, err error)
+
+	GetProcessingPolicy(ctx context.Context, req *GetProcessingPolicyReq) (r *GetProcessingPolicyResp, err error)
+
+	SaveProcessingPolicy(ctx context.Context, req *SaveProcessingPolicyReq) (r *SaveProcessingPolicyResp, err error)
+
+	DeleteProcessingPolicy(ctx context.Context, req *DeleteProcessingPolicyReq) (r *DeleteProcessingPolicyResp, err error)
+
+	GetPipeline(ctx context.Context, req *GetPipelineReq) (r *GetPipelineResp, err error)
+
+	RetryPipeline(ctx context.Context, req *RetryPipelineReq) (r *RetryPipelineResp, err error)
+}
+
+type VideoServiceInitUploadArgs struct {
+	Req *InitUploadReq `thrift:"req,1" frugal:"1,default,InitUploadReq" json:"req"`
+}
+
+func NewVideoServiceInitUploadArgs() *VideoServiceInitUploadArgs {
+	return &VideoServiceInitUploadArgs{}
+}
+
+func (p *VideoServiceInitUploadArgs) InitDefault() {
+}
+
+var VideoServiceInitUploadArgs_Req_DEFAULT *InitUploadReq
+
+func (p *VideoServiceInitUploadArgs) GetReq() (v *InitUploadReq) {
+	if !p.IsSetReq() {
+		return VideoServiceInitUploadArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *VideoServiceInitUploadArgs) SetReq(val *InitUploadReq) {
+	p.Req = val
+}
+
+func (p *VideoServiceInitUploadArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *VideoServiceInitUploadArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceInitUploadArgs(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceInitUploadArgs = map[int16]string{
+	1: "req",
+}
+
+type VideoServiceInitUploadResult struct {
+	Success *InitUploadResp `thrift:"success,0,optional" frugal:"0,optional,InitUploadResp" json:"success,omitempty"`
+}
+
+func NewVideoServiceInitUploadResult() *VideoServiceInitUploadResult {
+	return &VideoServiceInitUploadResult{}
+}
+
+func (p *VideoServiceInitUploadResult) InitDefault() {
+}
+
+var VideoServiceInitUploadResult_Success_DEFAULT *InitUploadResp
+
+func (p *VideoServiceInitUploadResult) GetSuccess() (v *InitUploadResp) {
+	if !p.IsSetSuccess() {
+		return VideoServiceInitUploadResult_Success_DEFAULT
+	}
+	return p.Success
+}
+func (p *VideoServiceInitUploadResult) SetSuccess(x interface{}) {
+	p.Success = x.(*InitUploadResp)
+}
+
+func (p *VideoServiceInitUploadResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
+
+func (p *VideoServiceInitUploadResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceInitUploadResult(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceInitUploadResult = map[int16]string{
+	0: "success",
+}
+
+type VideoServiceUploadChunkArgs struct {
+	Req *UploadChunkReq `thrift:"req,1" frugal:"1,default,UploadChunkReq" json:"req"`
+}
+
+func NewVideoServiceUploadChunkArgs() *VideoServiceUploadChunkArgs {
+	return &VideoServiceUploadChunkArgs{}
+}
+
+func (p *VideoServiceUploadChunkArgs) InitDefault() {
+}
+
+var VideoServiceUploadChunkArgs_Req_DEFAULT *UploadChunkReq
+
+func (p *VideoServiceUploadChunkArgs) GetReq() (v *UploadChunkReq) {
+	if !p.IsSetReq() {
+		return VideoServiceUploadChunkArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *VideoServiceUploadChunkArgs) SetReq(val *UploadChunkReq) {
+	p.Req = val
+}
+
+func (p *VideoServiceUploadChunkArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *VideoServiceUploadChunkArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceUploadChunkArgs(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceUploadChunkArgs = map[int16]string{
+	1: "req",
+}
+
+type VideoServiceUploadChunkResult struct {
+	Success *UploadChunkResp `thrift:"success,0,optional" frugal:"0,optional,UploadChunkResp" json:"success,omitempty"`
+}
+
+func NewVideoServiceUploadChunkResult() *VideoServiceUploadChunkResult {
+	return &VideoServiceUploadChunkResult{}
+}
+
+func (p *VideoServiceUploadChunkResult) InitDefault() {
+}
+
+var VideoServiceUploadChunkResult_Success_DEFAULT *UploadChunkResp
+
+func (p *VideoServiceUploadChunkResult) GetSuccess() (v *UploadChunkResp) {
+	if !p.IsSetSuccess() {
+		return VideoServiceUploadChunkResult_Success_DEFAULT
+	}
+	return p.Success
+}
+func (p *VideoServiceUploadChunkResult) SetSuccess(x interface{}) {
+	p.Success = x.(*UploadChunkResp)
+}
+
+func (p *VideoServiceUploadChunkResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
+
+func (p *VideoServiceUploadChunkResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceUploadChunkResult(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceUploadChunkResult = map[int16]string{
+	0: "success",
+}
+
+type VideoServiceUploadChunkStreamArgs struct {
+	Req *UploadChunkFrame `thrift:"req,1" frugal:"1,default,UploadChunkFrame" json:"req"`
+}
+
+func NewVideoServiceUploadChunkStreamArgs() *VideoServiceUploadChunkStreamArgs {
+	return &VideoServiceUploadChunkStreamArgs{}
+}
+
+func (p *VideoServiceUploadChunkStreamArgs) InitDefault() {
+}
+
+var VideoServiceUploadChunkStreamArgs_Req_DEFAULT *UploadChunkFrame
+
+func (p *VideoServiceUploadChunkStreamArgs) GetReq() (v *UploadChunkFrame) {
+	if !p.IsSetReq() {
+		return VideoServiceUploadChunkStreamArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *VideoServiceUploadChunkStreamArgs) SetReq(val *UploadChunkFrame) {
+	p.Req = val
+}
+
+func (p *VideoServiceUploadChunkStreamArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *VideoServiceUploadChunkStreamArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceUploadChunkStreamArgs(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceUploadChunkStreamArgs = map[int16]string{
+	1: "req",
+}
+
+type VideoServiceUploadChunkStreamResult struct {
+	Success *UploadChunkResp `thrift:"success,0,optional" frugal:"0,optional,UploadChunkResp" json:"success,omitempty"`
+}
+
+func NewVideoServiceUploadChunkStreamResult() *VideoServiceUploadChunkStreamResult {
+	return &VideoServiceUploadChunkStreamResult{}
+}
+
+func (p *VideoServiceUploadChunkStreamResult) InitDefault() {
+}
+
+var VideoServiceUploadChunkStreamResult_Success_DEFAULT *UploadChunkResp
+
+func (p *VideoServiceUploadChunkStreamResult) GetSuccess() (v *UploadChunkResp) {
+	if !p.IsSetSuccess() {
+		return VideoServiceUploadChunkStreamResult_Success_DEFAULT
+	}
+	return p.Success
+}
+func (p *VideoServiceUploadChunkStreamResult) SetSuccess(x interface{}) {
+	p.Success = x.(*UploadChunkResp)
+}
+
+func (p *VideoServiceUploadChunkStreamResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
+
+func (p *VideoServiceUploadChunkStreamResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceUploadChunkStreamResult(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceUploadChunkStreamResult = map[int16]string{
+	0: "success",
+}
+
+type VideoService_UploadChunkStreamServer interface {
+	streaming.Stream
+
+	Recv() (*UploadChunkFrame, error)
+
+	SendAndClose(*UploadChunkResp) error
+}
+
+type VideoServiceMergeFileArgs struct {
+	Req *MergeFileReq `thrift:"req,1" frugal:"1,default,MergeFileReq" json:"req"`
+}
+
+func NewVideoServiceMergeFileArgs() *VideoServiceMergeFileArgs {
+	return &VideoServiceMergeFileArgs{}
+}
+
+func (p *VideoServiceMergeFileArgs) InitDefault() {
+}
+
+var VideoServiceMergeFileArgs_Req_DEFAULT *MergeFileReq
+
+func (p *VideoServiceMergeFileArgs) GetReq() (v *MergeFileReq) {
+	if !p.IsSetReq() {
+		return VideoServiceMergeFileArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *VideoServiceMergeFileArgs) SetReq(val *MergeFileReq) {
+	p.Req = val
+}
+
+func (p *VideoServiceMergeFileArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *VideoServiceMergeFileArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceMergeFileArgs(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceMergeFileArgs = map[int16]string{
+	1: "req",
+}
+
+type VideoServiceMergeFileResult struct {
+	Success *MergeFileResp `thrift:"success,0,optional" frugal:"0,optional,MergeFileResp" json:"success,omitempty"`
+}
+
+func NewVideoServiceMergeFileResult() *VideoServiceMergeFileResult {
+	return &VideoServiceMergeFileResult{}
+}
+
+func (p *VideoServiceMergeFileResult) InitDefault() {
+}
+
+var VideoServiceMergeFileResult_Success_DEFAULT *MergeFileResp
+
+func (p *VideoServiceMergeFileResult) GetSuccess() (v *MergeFileResp) {
+	if !p.IsSetSuccess() {
+		return VideoServiceMergeFileResult_Success_DEFAULT
+	}
+	return p.Success
+}
+func (p *VideoServiceMergeFileResult) SetSuccess(x interface{}) {
+	p.Success = x.(*MergeFileResp)
+}
+
+func (p *VideoServiceMergeFileResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
+
+func (p *VideoServiceMergeFileResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceMergeFileResult(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceMergeFileResult = map[int16]string{
+	0: "success",
+}
+
+type VideoServiceGetMergeStatusArgs struct {
+	Req *GetMergeStatusReq `thrift:"req,1" frugal:"1,default,GetMergeStatusReq" json:"req"`
+}
+
+func NewVideoServiceGetMergeStatusArgs() *VideoServiceGetMergeStatusArgs {
+	return &VideoServiceGetMergeStatusArgs{}
+}
+
+func (p *VideoServiceGetMergeStatusArgs) InitDefault() {
+}
+
+var VideoServiceGetMergeStatusArgs_Req_DEFAULT *GetMergeStatusReq
+
+func (p *VideoServiceGetMergeStatusArgs) GetReq() (v *GetMergeStatusReq) {
+	if !p.IsSetReq() {
+		return VideoServiceGetMergeStatusArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *VideoServiceGetMergeStatusArgs) SetReq(val *GetMergeStatusReq) {
+	p.Req = val
+}
+
+func (p *VideoServiceGetMergeStatusArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *VideoServiceGetMergeStatusArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceGetMergeStatusArgs(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceGetMergeStatusArgs = map[int16]string{
+	1: "req",
+}
+
+type VideoServiceGetMergeStatusResult struct {
+	Success *GetMergeStatusResp `thrift:"success,0,optional" frugal:"0,optional,GetMergeStatusResp" json:"success,omitempty"`
+}
+
+func NewVideoServiceGetMergeStatusResult() *VideoServiceGetMergeStatusResult {
+	return &VideoServiceGetMergeStatusResult{}
+}
+
+func (p *VideoServiceGetMergeStatusResult) InitDefault() {
+}
+
+var VideoServiceGetMergeStatusResult_Success_DEFAULT *GetMergeStatusResp
+
+func (p *VideoServiceGetMergeStatusResult) GetSuccess() (v *GetMergeStatusResp) {
+	if !p.IsSetSuccess() {
+		return VideoServiceGetMergeStatusResult_Success_DEFAULT
+	}
+	return p.Success
+}
+func (p *VideoServiceGetMergeStatusResult) SetSuccess(x interface{}) {
+	p.Success = x.(*GetMergeStatusResp)
+}
+
+func (p *VideoServiceGetMergeStatusResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
+
+func (p *VideoServiceGetMergeStatusResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceGetMergeStatusResult(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceGetMergeStatusResult = map[int16]string{
+	0: "success",
+}
+
+type VideoServiceDownloadChunkArgs struct {
+	Req *DownloadChunkReq `thrift:"req,1" frugal:"1,default,DownloadChunkReq" json:"req"`
+}
+
+func NewVideoServiceDownloadChunkArgs() *VideoServiceDownloadChunkArgs {
+	return &VideoServiceDownloadChunkArgs{}
+}
+
+func (p *VideoServiceDownloadChunkArgs) InitDefault() {
+}
+
+var VideoServiceDownloadChunkArgs_Req_DEFAULT *DownloadChunkReq
+
+func (p *VideoServiceDownloadChunkArgs) GetReq() (v *DownloadChunkReq) {
+	if !p.IsSetReq() {
+		return VideoServiceDownloadChunkArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *VideoServiceDownloadChunkArgs) SetReq(val *DownloadChunkReq) {
+	p.Req = val
+}
+
+func (p *VideoServiceDownloadChunkArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *VideoServiceDownloadChunkArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceDownloadChunkArgs(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceDownloadChunkArgs = map[int16]string{
+	1: "req",
+}
+
+type VideoServiceDownloadChunkResult struct {
+	Success *DownloadChunkResp `thrift:"success,0,optional" frugal:"0,optional,DownloadChunkResp" json:"success,omitempty"`
+}
+
+func NewVideoServiceDownloadChunkResult() *VideoServiceDownloadChunkResult {
+	return &VideoServiceDownloadChunkResult{}
+}
+
+func (p *VideoServiceDownloadChunkResult) InitDefault() {
+}
+
+var VideoServiceDownloadChunkResult_Success_DEFAULT *DownloadChunkResp
+
+func (p *VideoServiceDownloadChunkResult) GetSuccess() (v *DownloadChunkResp) {
+	if !p.IsSetSuccess() {
+		return VideoServiceDownloadChunkResult_Success_DEFAULT
+	}
+	return p.Success
+}
+func (p *VideoServiceDownloadChunkResult) SetSuccess(x interface{}) {
+	p.Success = x.(*DownloadChunkResp)
+}
+
+func (p *VideoServiceDownloadChunkResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
+
+func (p *VideoServiceDownloadChunkResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceDownloadChunkResult(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceDownloadChunkResult = map[int16]string{
+	0: "success",
+}
+
+type VideoServiceDownloadStreamArgs struct {
+	Req *DownloadStreamReq `thrift:"req,1" frugal:"1,default,DownloadStreamReq" json:"req"`
+}
+
+func NewVideoServiceDownloadStreamArgs() *VideoServiceDownloadStreamArgs {
+	return &VideoServiceDownloadStreamArgs{}
+}
+
+func (p *VideoServiceDownloadStreamArgs) InitDefault() {
+}
+
+var VideoServiceDownloadStreamArgs_Req_DEFAULT *DownloadStreamReq
+
+func (p *VideoServiceDownloadStreamArgs) GetReq() (v *DownloadStreamReq) {
+	if !p.IsSetReq() {
+		return VideoServiceDownloadStreamArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *VideoServiceDownloadStreamArgs) SetReq(val *DownloadStreamReq) {
+	p.Req = val
+}
+
+func (p *VideoServiceDownloadStreamArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *VideoServiceDownloadStreamArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceDownloadStreamArgs(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceDownloadStreamArgs = map[int16]string{
+	1: "req",
+}
+
+type VideoServiceDownloadStreamResult struct {
+	Success *DownloadFrame `thrift:"success,0,optional" frugal:"0,optional,DownloadFrame" json:"success,omitempty"`
+}
+
+func NewVideoServiceDownloadStreamResult() *VideoServiceDownloadStreamResult {
+	return &VideoServiceDownloadStreamResult{}
+}
+
+func (p *VideoServiceDownloadStreamResult) InitDefault() {
+}
+
+var VideoServiceDownloadStreamResult_Success_DEFAULT *DownloadFrame
+
+func (p *VideoServiceDownloadStreamResult) GetSuccess() (v *DownloadFrame) {
+	if !p.IsSetSuccess() {
+		return VideoServiceDownloadStreamResult_Success_DEFAULT
+	}
+	return p.Success
+}
+func (p *VideoServiceDownloadStreamResult) SetSuccess(x interface{}) {
+	p.Success = x.(*DownloadFrame)
+}
+
+func (p *VideoServiceDownloadStreamResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
+
+func (p *VideoServiceDownloadStreamResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceDownloadStreamResult(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceDownloadStreamResult = map[int16]string{
+	0: "success",
+}
+
+type VideoService_DownloadStreamServer interface {
+	streaming.Stream
+
+	Send(*DownloadFrame) error
+}
+
+type VideoServiceGetVideoInfoArgs struct {
+	Req *GetVideoInfoReq `thrift:"req,1" frugal:"1,default,GetVideoInfoReq" json:"req"`
+}
+
+func NewVideoServiceGetVideoInfoArgs() *VideoServiceGetVideoInfoArgs {
+	return &VideoServiceGetVideoInfoArgs{}
+}
+
+func (p *VideoServiceGetVideoInfoArgs) InitDefault() {
+}
+
+var VideoServiceGetVideoInfoArgs_Req_DEFAULT *GetVideoInfoReq
+
+func (p *VideoServiceGetVideoInfoArgs) GetReq() (v *GetVideoInfoReq) {
+	if !p.IsSetReq() {
+		return VideoServiceGetVideoInfoArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *VideoServiceGetVideoInfoArgs) SetReq(val *GetVideoInfoReq) {
+	p.Req = val
+}
+
+func (p *VideoServiceGetVideoInfoArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *VideoServiceGetVideoInfoArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceGetVideoInfoArgs(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceGetVideoInfoArgs = map[int16]string{
+	1: "req",
+}
+
+type VideoServiceGetVideoInfoResult struct {
+	Success *GetVideoInfoResp `thrift:"success,0,optional" frugal:"0,optional,GetVideoInfoResp" json:"success,omitempty"`
+}
+
+func NewVideoServiceGetVideoInfoResult() *VideoServiceGetVideoInfoResult {
+	return &VideoServiceGetVideoInfoResult{}
+}
+
+func (p *VideoServiceGetVideoInfoResult) InitDefault() {
+}
+
+var VideoServiceGetVideoInfoResult_Success_DEFAULT *GetVideoInfoResp
+
+func (p *VideoServiceGetVideoInfoResult) GetSuccess() (v *GetVideoInfoResp) {
+	if !p.IsSetSuccess() {
+		return VideoServiceGetVideoInfoResult_Success_DEFAULT
+	}
+	return p.Success
+}
+func (p *VideoServiceGetVideoInfoResult) SetSuccess(x interface{}) {
+	p.Success = x.(*GetVideoInfoResp)
+}
+
+func (p *VideoServiceGetVideoInfoResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
+
+func (p *VideoServiceGetVideoInfoResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceGetVideoInfoResult(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceGetVideoInfoResult = map[int16]string{
+	0: "success",
+}
+
+type VideoServiceGetPlaybackFileArgs struct {
+	Req *GetPlaybackFileReq `thrift:"req,1" frugal:"1,default,GetPlaybackFileReq" json:"req"`
+}
+
+func NewVideoServiceGetPlaybackFileArgs() *VideoServiceGetPlaybackFileArgs {
+	return &VideoServiceGetPlaybackFileArgs{}
+}
+
+func (p *VideoServiceGetPlaybackFileArgs) InitDefault() {
+}
+
+var VideoServiceGetPlaybackFileArgs_Req_DEFAULT *GetPlaybackFileReq
+
+func (p *VideoServiceGetPlaybackFileArgs) GetReq() (v *GetPlaybackFileReq) {
+	if !p.IsSetReq() {
+		return VideoServiceGetPlaybackFileArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *VideoServiceGetPlaybackFileArgs) SetReq(val *GetPlaybackFileReq) {
+	p.Req = val
+}
+
+func (p *VideoServiceGetPlaybackFileArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *VideoServiceGetPlaybackFileArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceGetPlaybackFileArgs(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceGetPlaybackFileArgs = map[int16]string{
+	1: "req",
+}
+
+type VideoServiceGetPlaybackFileResult struct {
+	Success *GetPlaybackFileResp `thrift:"success,0,optional" frugal:"0,optional,GetPlaybackFileResp" json:"success,omitempty"`
+}
+
+func NewVideoServiceGetPlaybackFileResult() *VideoServiceGetPlaybackFileResult {
+	return &VideoServiceGetPlaybackFileResult{}
+}
+
+func (p *VideoServiceGetPlaybackFileResult) InitDefault() {
+}
+
+var VideoServiceGetPlaybackFileResult_Success_DEFAULT *GetPlaybackFileResp
+
+func (p *VideoServiceGetPlaybackFileResult) GetSuccess() (v *GetPlaybackFileResp) {
+	if !p.IsSetSuccess() {
+		return VideoServiceGetPlaybackFileResult_Success_DEFAULT
+	}
+	return p.Success
+}
+func (p *VideoServiceGetPlaybackFileResult) SetSuccess(x interface{}) {
+	p.Success = x.(*GetPlaybackFileResp)
+}
+
+func (p *VideoServiceGetPlaybackFileResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
+
+func (p *VideoServiceGetPlaybackFileResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceGetPlaybackFileResult(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceGetPlaybackFileResult = map[int16]string{
+	0: "success",
+}
+
+type VideoServiceGetThumbnailFileArgs struct {
+	Req *GetThumbnailFileReq `thrift:"req,1" frugal:"1,default,GetThumbnailFileReq" json:"req"`
+}
+
+func NewVideoServiceGetThumbnailFileArgs() *VideoServiceGetThumbnailFileArgs {
+	return &VideoServiceGetThumbnailFileArgs{}
+}
+
+func (p *VideoServiceGetThumbnailFileArgs) InitDefault() {
+}
+
+var VideoServiceGetThumbnailFileArgs_Req_DEFAULT *GetThumbnailFileReq
+
+func (p *VideoServiceGetThumbnailFileArgs) GetReq() (v *GetThumbnailFileReq) {
+	if !p.IsSetReq() {
+		return VideoServiceGetThumbnailFileArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *VideoServiceGetThumbnailFileArgs) SetReq(val *GetThumbnailFileReq) {
+	p.Req = val
+}
+
+func (p *VideoServiceGetThumbnailFileArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *VideoServiceGetThumbnailFileArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceGetThumbnailFileArgs(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceGetThumbnailFileArgs = map[int16]string{
+	1: "req",
+}
+
+type VideoServiceGetThumbnailFileResult struct {
+	Success *GetThumbnailFileResp `thrift:"success,0,optional" frugal:"0,optional,GetThumbnailFileResp" json:"success,omitempty"`
+}
+
+func NewVideoServiceGetThumbnailFileResult() *VideoServiceGetThumbnailFileResult {
+	return &VideoServiceGetThumbnailFileResult{}
+}
+
+func (p *VideoServiceGetThumbnailFileResult) InitDefault() {
+}
+
+var VideoServiceGetThumbnailFileResult_Success_DEFAULT *GetThumbnailFileResp
+
+func (p *VideoServiceGetThumbnailFileResult) GetSuccess() (v *GetThumbnailFileResp) {
+	if !p.IsSetSuccess() {
+		return VideoServiceGetThumbnailFileResult_Success_DEFAULT
+	}
+	return p.Success
+}
+func (p *VideoServiceGetThumbnailFileResult) SetSuccess(x interface{}) {
+	p.Success = x.(*GetThumbnailFileResp)
+}
+
+func (p *VideoServiceGetThumbnailFileResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
+
+func (p *VideoServiceGetThumbnailFileResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceGetThumbnailFileResult(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceGetThumbnailFileResult = map[int16]string{
+	0: "success",
+}
+
+type VideoServiceSetPosterArgs struct {
+	Req *SetPosterReq `thrift:"req,1" frugal:"1,default,SetPosterReq" json:"req"`
+}
+
+func NewVideoServiceSetPosterArgs() *VideoServiceSetPosterArgs {
+	return &VideoServiceSetPosterArgs{}
+}
+
+func (p *VideoServiceSetPosterArgs) InitDefault() {
+}
+
+var VideoServiceSetPosterArgs_Req_DEFAULT *SetPosterReq
+
+func (p *VideoServiceSetPosterArgs) GetReq() (v *SetPosterReq) {
+	if !p.IsSetReq() {
+		return VideoServiceSetPosterArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *VideoServiceSetPosterArgs) SetReq(val *SetPosterReq) {
+	p.Req = val
+}
+
+func (p *VideoServiceSetPosterArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *VideoServiceSetPosterArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceSetPosterArgs(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceSetPosterArgs = map[int16]string{
+	1: "req",
+}
+
+type VideoServiceSetPosterResult struct {
+	Success *SetPosterResp `thrift:"success,0,optional" frugal:"0,optional,SetPosterResp" json:"success,omitempty"`
+}
+
+func NewVideoServiceSetPosterResult() *VideoServiceSetPosterResult {
+	return &VideoServiceSetPosterResult{}
+}
+
+func (p *VideoServiceSetPosterResult) InitDefault() {
+}
+
+var VideoServiceSetPosterResult_Success_DEFAULT *SetPosterResp
+
+func (p *VideoServiceSetPosterResult) GetSuccess() (v *SetPosterResp) {
+	if !p.IsSetSuccess() {
+		return VideoServiceSetPosterResult_Success_DEFAULT
+	}
+	return p.Success
+}
+func (p *VideoServiceSetPosterResult) SetSuccess(x interface{}) {
+	p.Success = x.(*SetPosterResp)
+}
+
+func (p *VideoServiceSetPosterResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
+
+func (p *VideoServiceSetPosterResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceSetPosterResult(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceSetPosterResult = map[int16]string{
+	0: "success",
+}
+
+type VideoServiceTranscodeArgs struct {
+	Req *TranscodeReq `thrift:"req,1" frugal:"1,default,TranscodeReq" json:"req"`
+}
+
+func NewVideoServiceTranscodeArgs() *VideoServiceTranscodeArgs {
+	return &VideoServiceTranscodeArgs{}
+}
+
+func (p *VideoServiceTranscodeArgs) InitDefault() {
+}
+
+var VideoServiceTranscodeArgs_Req_DEFAULT *TranscodeReq
+
+func (p *VideoServiceTranscodeArgs) GetReq() (v *TranscodeReq) {
+	if !p.IsSetReq() {
+		return VideoServiceTranscodeArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *VideoServiceTranscodeArgs) SetReq(val *TranscodeReq) {
+	p.Req = val
+}
+
+func (p *VideoServiceTranscodeArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *VideoServiceTranscodeArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceTranscodeArgs(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceTranscodeArgs = map[int16]string{
+	1: "req",
+}
+
+type VideoServiceTranscodeResult struct {
+	Success *TranscodeResp `thrift:"success,0,optional" frugal:"0,optional,TranscodeResp" json:"success,omitempty"`
+}
+
+func NewVideoServiceTranscodeResult() *VideoServiceTranscodeResult {
+	return &VideoServiceTranscodeResult{}
+}
+
+func (p *VideoServiceTranscodeResult) InitDefault() {
+}
+
+var VideoServiceTranscodeResult_Success_DEFAULT *TranscodeResp
+
+func (p *VideoServiceTranscodeResult) GetSuccess() (v *TranscodeResp) {
+	if !p.IsSetSuccess() {
+		return VideoServiceTranscodeResult_Success_DEFAULT
+	}
+	return p.Success
+}
+func (p *VideoServiceTranscodeResult) SetSuccess(x interface{}) {
+	p.Success = x.(*TranscodeResp)
+}
+
+func (p *VideoServiceTranscodeResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
+
+func (p *VideoServiceTranscodeResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceTranscodeResult(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceTranscodeResult = map[int16]string{
+	0: "success",
+}
+
+type VideoServiceGetTranscodeStatusArgs struct {
+	Req *GetTranscodeStatusReq `thrift:"req,1" frugal:"1,default,GetTranscodeStatusReq" json:"req"`
+}
+
+func NewVideoServiceGetTranscodeStatusArgs() *VideoServiceGetTranscodeStatusArgs {
+	return &VideoServiceGetTranscodeStatusArgs{}
+}
+
+func (p *VideoServiceGetTranscodeStatusArgs) InitDefault() {
+}
+
+var VideoServiceGetTranscodeStatusArgs_Req_DEFAULT *GetTranscodeStatusReq
+
+func (p *VideoServiceGetTranscodeStatusArgs) GetReq() (v *GetTranscodeStatusReq) {
+	if !p.IsSetReq() {
+		return VideoServiceGetTranscodeStatusArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *VideoServiceGetTranscodeStatusArgs) SetReq(val *GetTranscodeStatusReq) {
+	p.Req = val
+}
+
+func (p *VideoServiceGetTranscodeStatusArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *VideoServiceGetTranscodeStatusArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceGetTranscodeStatusArgs(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceGetTranscodeStatusArgs = map[int16]string{
+	1: "req",
+}
+
+type VideoServiceGetTranscodeStatusResult struct {
+	Success *GetTranscodeStatusResp `thrift:"success,0,optional" frugal:"0,optional,GetTranscodeStatusResp" json:"success,omitempty"`
+}
+
+func NewVideoServiceGetTranscodeStatusResult() *VideoServiceGetTranscodeStatusResult {
+	return &VideoServiceGetTranscodeStatusResult{}
+}
+
+func (p *VideoServiceGetTranscodeStatusResult) InitDefault() {
+}
+
+var VideoServiceGetTranscodeStatusResult_Success_DEFAULT *GetTranscodeStatusResp
+
+func (p *VideoServiceGetTranscodeStatusResult) GetSuccess() (v *GetTranscodeStatusResp) {
+	if !p.IsSetSuccess() {
+		return VideoServiceGetTranscodeStatusResult_Success_DEFAULT
+	}
+	return p.Success
+}
+func (p *VideoServiceGetTranscodeStatusResult) SetSuccess(x interface{}) {
+	p.Success = x.(*GetTranscodeStatusResp)
+}
+
+func (p *VideoServiceGetTranscodeStatusResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
+
+func (p *VideoServiceGetTranscodeStatusResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceGetTranscodeStatusResult(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceGetTranscodeStatusResult = map[int16]string{
+	0: "success",
+}
+
+type VideoServiceCancelTranscodeArgs struct {
+	Req *CancelTranscodeReq `thrift:"req,1" frugal:"1,default,CancelTranscodeReq" json:"req"`
+}
+
+func NewVideoServiceCancelTranscodeArgs() *VideoServiceCancelTranscodeArgs {
+	return &VideoServiceCancelTranscodeArgs{}
+}
+
+func (p *VideoServiceCancelTranscodeArgs) InitDefault() {
+}
+
+var VideoServiceCancelTranscodeArgs_Req_DEFAULT *CancelTranscodeReq
+
+func (p *VideoServiceCancelTranscodeArgs) GetReq() (v *CancelTranscodeReq) {
+	if !p.IsSetReq() {
+		return VideoServiceCancelTranscodeArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *VideoServiceCancelTranscodeArgs) SetReq(val *CancelTranscodeReq) {
+	p.Req = val
+}
+
+func (p *VideoServiceCancelTranscodeArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *VideoServiceCancelTranscodeArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceCancelTranscodeArgs(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceCancelTranscodeArgs = map[int16]string{
+	1: "req",
+}
+
+type VideoServiceCancelTranscodeResult struct {
+	Success *CancelTranscodeResp `thrift:"success,0,optional" frugal:"0,optional,CancelTranscodeResp" json:"success,omitempty"`
+}
+
+func NewVideoServiceCancelTranscodeResult() *VideoServiceCancelTranscodeResult {
+	return &VideoServiceCancelTranscodeResult{}
+}
+
+func (p *VideoServiceCancelTranscodeResult) InitDefault() {
+}
+
+var VideoServiceCancelTranscodeResult_Success_DEFAULT *CancelTranscodeResp
+
+func (p *VideoServiceCancelTranscodeResult) GetSuccess() (v *CancelTranscodeResp) {
+	if !p.IsSetSuccess() {
+		return VideoServiceCancelTranscodeResult_Success_DEFAULT
+	}
+	return p.Success
+}
+func (p *VideoServiceCancelTranscodeResult) SetSuccess(x interface{}) {
+	p.Success = x.(*CancelTranscodeResp)
+}
+
+func (p *VideoServiceCancelTranscodeResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
+
+func (p *VideoServiceCancelTranscodeResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceCancelTranscodeResult(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceCancelTranscodeResult = map[int16]string{
+	0: "success",
+}
+
+type VideoServiceRetryTranscodeArgs struct {
+	Req *RetryTranscodeReq `thrift:"req,1" frugal:"1,default,RetryTranscodeReq" json:"req"`
+}
+
+func NewVideoServiceRetryTranscodeArgs() *VideoServiceRetryTranscodeArgs {
+	return &VideoServiceRetryTranscodeArgs{}
+}
+
+func (p *VideoServiceRetryTranscodeArgs) InitDefault() {
+}
+
+var VideoServiceRetryTranscodeArgs_Req_DEFAULT *RetryTranscodeReq
+
+func (p *VideoServiceRetryTranscodeArgs) GetReq() (v *RetryTranscodeReq) {
+	if !p.IsSetReq() {
+		return VideoServiceRetryTranscodeArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *VideoServiceRetryTranscodeArgs) SetReq(val *RetryTranscodeReq) {
+	p.Req = val
+}
+
+func (p *VideoServiceRetryTranscodeArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *VideoServiceRetryTranscodeArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceRetryTranscodeArgs(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceRetryTranscodeArgs = map[int16]string{
+	1: "req",
+}
+
+type VideoServiceRetryTranscodeResult struct {
+	Success *RetryTranscodeResp `thrift:"success,0,optional" frugal:"0,optional,RetryTranscodeResp" json:"success,omitempty"`
+}
+
+func NewVideoServiceRetryTranscodeResult() *VideoServiceRetryTranscodeResult {
+	return &VideoServiceRetryTranscodeResult{}
+}
+
+func (p *VideoServiceRetryTranscodeResult) InitDefault() {
+}
+
+var VideoServiceRetryTranscodeResult_Success_DEFAULT *RetryTranscodeResp
+
+func (p *VideoServiceRetryTranscodeResult) GetSuccess() (v *RetryTranscodeResp) {
+	if !p.IsSetSuccess() {
+		return VideoServiceRetryTranscodeResult_Success_DEFAULT
+	}
+	return p.Success
+}
+func (p *VideoServiceRetryTranscodeResult) SetSuccess(x interface{}) {
+	p.Success = x.(*RetryTranscodeResp)
+}
+
+func (p *VideoServiceRetryTranscodeResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
+
+func (p *VideoServiceRetryTranscodeResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceRetryTranscodeResult(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceRetryTranscodeResult = map[int16]string{
+	0: "success",
+}
+
+type VideoServiceListTranscodeProfilesArgs struct {
+	Req *ListTranscodeProfilesReq `thrift:"req,1" frugal:"1,default,ListTranscodeProfilesReq" json:"req"`
+}
+
+func NewVideoServiceListTranscodeProfilesArgs() *VideoServiceListTranscodeProfilesArgs {
+	return &VideoServiceListTranscodeProfilesArgs{}
+}
+
+func (p *VideoServiceListTranscodeProfilesArgs) InitDefault() {
+}
+
+var VideoServiceListTranscodeProfilesArgs_Req_DEFAULT *ListTranscodeProfilesReq
+
+func (p *VideoServiceListTranscodeProfilesArgs) GetReq() (v *ListTranscodeProfilesReq) {
+	if !p.IsSetReq() {
+		return VideoServiceListTranscodeProfilesArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *VideoServiceListTranscodeProfilesArgs) SetReq(val *ListTranscodeProfilesReq) {
+	p.Req = val
+}
+
+func (p *VideoServiceListTranscodeProfilesArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *VideoServiceListTranscodeProfilesArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceListTranscodeProfilesArgs(%+v)", *p)
+}
+
+var fieldIDToName_VideoServiceListTranscodeProfilesArgs = map[int16]string{
+	1: "req",
+}
+
+type VideoServiceListTranscodeProfilesResult struct {
+	Success *ListTranscodeProfilesResp `thrift:"success,0,optional" frugal:"0,optional,ListTranscodeProfilesResp" json:"success,omitempty"`
+}
+
+func NewVideoServiceListTranscodeProfilesResult() *VideoServiceListTranscodeProfilesResult {
+	return &VideoServiceListTranscodeProfilesResult{}
+}
+
+func (p *VideoServiceListTranscodeProfilesResult) InitDefault() {
+}
+
+var VideoServiceListTranscodeProfilesResult_Success_DEFAULT *ListTranscodeProfilesResp
+
+func (p *VideoServiceListTranscodeProfilesResult) GetSuccess() (v *ListTranscodeProfilesResp) {
+	if !p.IsSetSuccess() {
+		return VideoServiceListTranscodeProfilesResult_Success_DEFAULT
+	}
+	return p.Success
+}
+func (p *VideoServiceListTranscodeProfilesResult) SetSuccess(x interface{}) {
+	p.Success = x.(*ListTranscodeProfilesResp)
 }
 
-func (p *GetTranscodeStatusResp) InitDefault() {
+func (p *VideoServiceListTranscodeProfilesResult) IsSetSuccess() bool {
+	return p.Success != nil
 }
 
-func (p *GetTranscodeStatusResp) GetCode() (v int32) {
-	return p.Code
+func (p *VideoServiceListTranscodeProfilesResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceListTranscodeProfilesResult(%+v)", *p)
 }
 
-func (p *GetTranscodeStatusResp) GetMsg() (v string) {
-	return p.Msg
+var fieldIDToName_VideoServiceListTranscodeProfilesResult = map[int16]string{
+	0: "success",
 }
 
-func (p *GetTranscodeStatusResp) GetStatus() (v string) {
-	return p.Status
+type VideoServiceSaveTranscodeProfileArgs struct {
+	Req *SaveTranscodeProfileReq `thrift:"req,1" frugal:"1,default,SaveTranscodeProfileReq" json:"req"`
 }
 
-func (p *GetTranscodeStatusResp) GetProgress() (v int32) {
-	return p.Progress
+func NewVideoServiceSaveTranscodeProfileArgs() *VideoServiceSaveTranscodeProfileArgs {
+	return &VideoServiceSaveTranscodeProfileArgs{}
 }
 
-func (p *GetTranscodeStatusResp) GetCompletedUrls() (v []string) {
-	return p.CompletedUrls
+func (p *VideoServiceSaveTranscodeProfileArgs) InitDefault() {
 }
-func (p *GetTranscodeStatusResp) SetCode(val int32) {
-	p.Code = val
-}
-func (p *GetTranscodeStatusResp) SetMsg(val string) {
-	p.Msg = val
-}
-func (p *GetTranscodeStatusResp) SetStatus(val string) {
-	p.Status = val
+
+var VideoServiceSaveTranscodeProfileArgs_Req_DEFAULT *SaveTranscodeProfileReq
+
+func (p *VideoServiceSaveTranscodeProfileArgs) GetReq() (v *SaveTranscodeProfileReq) {
+	if !p.IsSetReq() {
+		return VideoServiceSaveTranscodeProfileArgs_Req_DEFAULT
+	}
+	return p.Req
 }
-func (p *GetTranscodeStatusResp) SetProgress(val int32) {
-	p.Progress = val
+func (p *VideoServiceSaveTranscodeProfileArgs) SetReq(val *SaveTranscodeProfileReq) {
+	p.Req = val
 }
-func (p *GetTranscodeStatusResp) SetCompletedUrls(val []string) {
-	p.CompletedUrls = val
+
+func (p *VideoServiceSaveTranscodeProfileArgs) IsSetReq() bool {
+	return p.Req != nil
 }
 
-func (p *GetTranscodeStatusResp) String() string {
+func (p *VideoServiceSaveTranscodeProfileArgs) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("GetTranscodeStatusResp(%+v)", *p)
+	return fmt.Sprintf("VideoServiceSaveTranscodeProfileArgs(%+v)", *p)
 }
 
-var fieldIDToName_GetTranscodeStatusResp = map[int16]string{
-	1: "code",
-	2: "msg",
-	3: "status",
-	4: "progress",
-	5: "completed_urls",
+var fieldIDToName_VideoServiceSaveTranscodeProfileArgs = map[int16]string{
+	1: "req",
 }
 
-type VideoService interface {
-	InitUpload(ctx context.Context, req *InitUploadReq) (r *InitUploadResp, err error)
+type VideoServiceSaveTranscodeProfileResult struct {
+	Success *SaveTranscodeProfileResp `thrift:"success,0,optional" frugal:"0,optional,SaveTranscodeProfileResp" json:"success,omitempty"`
+}
 
-	UploadChunk(ctx context.Context, req *UploadChunkReq) (r *UploadChunkResp, err error)
+func NewVideoServiceSaveTranscodeProfileResult() *VideoServiceSaveTranscodeProfileResult {
+	return &VideoServiceSaveTranscodeProfileResult{}
+}
 
-	MergeFile(ctx context.Context, req *MergeFileReq) (r *MergeFileResp, err error)
+func (p *VideoServiceSaveTranscodeProfileResult) InitDefault() {
+}
 
-	DownloadChunk(ctx context.Context, req *DownloadChunkReq) (r *DownloadChunkResp, err error)
+var VideoServiceSaveTranscodeProfileResult_Success_DEFAULT *SaveTranscodeProfileResp
 
-	GetVideoInfo(ctx context.Context, req *GetVideoInfoReq) (r *GetVideoInfoResp, err error)
+func (p *VideoServiceSaveTranscodeProfileResult) GetSuccess() (v *SaveTranscodeProfileResp) {
+	if !p.IsSetSuccess() {
+		return VideoServiceSaveTranscodeProfileResult_Success_DEFAULT
+	}
+	return p.Success
+}
+func (p *VideoServiceSaveTranscodeProfileResult) SetSuccess(x interface{}) {
+	p.Success = x.(*SaveTranscodeProfileResp)
+}
 
-	Transcode(ctx context.Context, req *TranscodeReq) (r *TranscodeResp, err error)
+func (p *VideoServiceSaveTranscodeProfileResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
 
-	GetTranscodeStatus(ctx context.Context, req *GetTranscodeStatusReq) (r *GetTranscodeStatusResp, err error)
+func (p *VideoServiceSaveTranscodeProfileResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("VideoServiceSaveTranscodeProfileResult(%+v)", *p)
 }
 
-type VideoServiceInitUploadArgs struct {
-	Req *InitUploadReq `thrift:"req,1" frugal:"1,default,InitUploadReq" json:"req"`
+var fieldIDToName_VideoServiceSaveTranscodeProfileResult = map[int16]string{
+	0: "success",
 }
 
-func NewVideoServiceInitUploadArgs() *VideoServiceInitUploadArgs {
-	return &VideoServiceInitUploadArgs{}
+type VideoServiceDeleteTranscodeProfileArgs struct {
+	Req *DeleteTranscodeProfileReq `thrift:"req,1" frugal:"1,default,DeleteTranscodeProfileReq" json:"req"`
 }
 
-func (p *VideoServiceInitUploadArgs) InitDefault() {
+func NewVideoServiceDeleteTranscodeProfileArgs() *VideoServiceDeleteTranscodeProfileArgs {
+	return &VideoServiceDeleteTranscodeProfileArgs{}
 }
 
-var VideoServiceInitUploadArgs_Req_DEFAULT *InitUploadReq
+func (p *VideoServiceDeleteTranscodeProfileArgs) InitDefault() {
+}
 
-func (p *VideoServiceInitUploadArgs) GetReq() (v *InitUploadReq) {
+var VideoServiceDeleteTranscodeProfileArgs_Req_DEFAULT *DeleteTranscodeProfileReq
+
+func (p *VideoServiceDeleteTranscodeProfileArgs) GetReq() (v *DeleteTranscodeProfileReq) {
 	if !p.IsSetReq() {
-		return VideoServiceInitUploadArgs_Req_DEFAULT
+		return VideoServiceDeleteTranscodeProfileArgs_Req_DEFAULT
 	}
 	return p.Req
 }
-func (p *VideoServiceInitUploadArgs) SetReq(val *InitUploadReq) {
+func (p *VideoServiceDeleteTranscodeProfileArgs) SetReq(val *DeleteTranscodeProfileReq) {
 	p.Req = val
 }
 
-func (p *VideoServiceInitUploadArgs) IsSetReq() bool {
+func (p *VideoServiceDeleteTranscodeProfileArgs) IsSetReq() bool {
 	return p.Req != nil
 }
 
-func (p *VideoServiceInitUploadArgs) String() string {
+func (p *VideoServiceDeleteTranscodeProfileArgs) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("VideoServiceInitUploadArgs(%+v)", *p)
+	return fmt.Sprintf("VideoServiceDeleteTranscodeProfileArgs(%+v)", *p)
 }
 
-var fieldIDToName_VideoServiceInitUploadArgs = map[int16]string{
+var fieldIDToName_VideoServiceDeleteTranscodeProfileArgs = map[int16]string{
 	1: "req",
 }
 
-type VideoServiceInitUploadResult struct {
-	Success *InitUploadResp `thrift:"success,0,optional" frugal:"0,optional,InitUploadResp" json:"success,omitempty"`
+type VideoServiceDeleteTranscodeProfileResult struct {
+	Success *DeleteTranscodeProfileResp `thrift:"success,0,optional" frugal:"0,optional,DeleteTranscodeProfileResp" json:"success,omitempty"`
 }
 
-func NewVideoServiceInitUploadResult() *VideoServiceInitUploadResult {
-	return &VideoServiceInitUploadResult{}
+func NewVideoServiceDeleteTranscodeProfileResult() *VideoServiceDeleteTranscodeProfileResult {
+	return &VideoServiceDeleteTranscodeProfileResult{}
 }
 
-func (p *VideoServiceInitUploadResult) InitDefault() {
+func (p *VideoServiceDeleteTranscodeProfileResult) InitDefault() {
 }
 
-var VideoServiceInitUploadResult_Success_DEFAULT *InitUploadResp
+var VideoServiceDeleteTranscodeProfileResult_Success_DEFAULT *DeleteTranscodeProfileResp
 
-func (p *VideoServiceInitUploadResult) GetSuccess() (v *InitUploadResp) {
+func (p *VideoServiceDeleteTranscodeProfileResult) GetSuccess() (v *DeleteTranscodeProfileResp) {
 	if !p.IsSetSuccess() {
-		return VideoServiceInitUploadResult_Success_DEFAULT
+		return VideoServiceDeleteTranscodeProfileResult_Success_DEFAULT
 	}
 	return p.Success
 }
-func (p *VideoServiceInitUploadResult) SetSuccess(x interface{}) {
-	p.Success = x.(*InitUploadResp)
+func (p *VideoServiceDeleteTranscodeProfileResult) SetSuccess(x interface{}) {
+	p.Success = x.(*DeleteTranscodeProfileResp)
 }
 
-func (p *VideoServiceInitUploadResult) IsSetSuccess() bool {
+func (p *VideoServiceDeleteTranscodeProfileResult) IsSetSuccess() bool {
 	return p.Success != nil
 }
 
-func (p *VideoServiceInitUploadResult) String() string {
+func (p *VideoServiceDeleteTranscodeProfileResult) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("VideoServiceInitUploadResult(%+v)", *p)
+	return fmt.Sprintf("VideoServiceDeleteTranscodeProfileResult(%+v)", *p)
 }
 
-var fieldIDToName_VideoServiceInitUploadResult = map[int16]string{
+var fieldIDToName_VideoServiceDeleteTranscodeProfileResult = map[int16]string{
 	0: "success",
 }
 
-type VideoServiceUploadChunkArgs struct {
-	Req *UploadChunkReq `thrift:"req,1" frugal:"1,default,UploadChunkReq" json:"req"`
+type VideoServiceDeleteVideoArgs struct {
+	Req *DeleteVideoReq `thrift:"req,1" frugal:"1,default,DeleteVideoReq" json:"req"`
 }
 
-func NewVideoServiceUploadChunkArgs() *VideoServiceUploadChunkArgs {
-	return &VideoServiceUploadChunkArgs{}
+func NewVideoServiceDeleteVideoArgs() *VideoServiceDeleteVideoArgs {
+	return &VideoServiceDeleteVideoArgs{}
 }
 
-func (p *VideoServiceUploadChunkArgs) InitDefault() {
+func (p *VideoServiceDeleteVideoArgs) InitDefault() {
 }
 
-var VideoServiceUploadChunkArgs_Req_DEFAULT *UploadChunkReq
+var VideoServiceDeleteVideoArgs_Req_DEFAULT *DeleteVideoReq
 
-func (p *VideoServiceUploadChunkArgs) GetReq() (v *UploadChunkReq) {
+func (p *VideoServiceDeleteVideoArgs) GetReq() (v *DeleteVideoReq) {
 	if !p.IsSetReq() {
-		return VideoServiceUploadChunkArgs_Req_DEFAULT
+		return VideoServiceDeleteVideoArgs_Req_DEFAULT
 	}
 	return p.Req
 }
-func (p *VideoServiceUploadChunkArgs) SetReq(val *UploadChunkReq) {
+func (p *VideoServiceDeleteVideoArgs) SetReq(val *DeleteVideoReq) {
 	p.Req = val
 }
 
-func (p *VideoServiceUploadChunkArgs) IsSetReq() bool {
+func (p *VideoServiceDeleteVideoArgs) IsSetReq() bool {
 	return p.Req != nil
 }
 
-func (p *VideoServiceUploadChunkArgs) String() string {
+func (p *VideoServiceDeleteVideoArgs) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("VideoServiceUploadChunkArgs(%+v)", *p)
+	return fmt.Sprintf("VideoServiceDeleteVideoArgs(%+v)", *p)
 }
 
-var fieldIDToName_VideoServiceUploadChunkArgs = map[int16]string{
+var fieldIDToName_VideoServiceDeleteVideoArgs = map[int16]string{
 	1: "req",
 }
 
-type VideoServiceUploadChunkResult struct {
-	Success *UploadChunkResp `thrift:"success,0,optional" frugal:"0,optional,UploadChunkResp" json:"success,omitempty"`
+type VideoServiceDeleteVideoResult struct {
+	Success *DeleteVideoResp `thrift:"success,0,optional" frugal:"0,optional,DeleteVideoResp" json:"success,omitempty"`
 }
 
-func NewVideoServiceUploadChunkResult() *VideoServiceUploadChunkResult {
-	return &VideoServiceUploadChunkResult{}
+func NewVideoServiceDeleteVideoResult() *VideoServiceDeleteVideoResult {
+	return &VideoServiceDeleteVideoResult{}
 }
 
-func (p *VideoServiceUploadChunkResult) InitDefault() {
+func (p *VideoServiceDeleteVideoResult) InitDefault() {
 }
 
-var VideoServiceUploadChunkResult_Success_DEFAULT *UploadChunkResp
+var VideoServiceDeleteVideoResult_Success_DEFAULT *DeleteVideoResp
 
-func (p *VideoServiceUploadChunkResult) GetSuccess() (v *UploadChunkResp) {
+func (p *VideoServiceDeleteVideoResult) GetSuccess() (v *DeleteVideoResp) {
 	if !p.IsSetSuccess() {
-		return VideoServiceUploadChunkResult_Success_DEFAULT
+		return VideoServiceDeleteVideoResult_Success_DEFAULT
 	}
 	return p.Success
 }
-func (p *VideoServiceUploadChunkResult) SetSuccess(x interface{}) {
-	p.Success = x.(*UploadChunkResp)
+func (p *VideoServiceDeleteVideoResult) SetSuccess(x interface{}) {
+	p.Success = x.(*DeleteVideoResp)
 }
 
-func (p *VideoServiceUploadChunkResult) IsSetSuccess() bool {
+func (p *VideoServiceDeleteVideoResult) IsSetSuccess() bool {
 	return p.Success != nil
 }
 
-func (p *VideoServiceUploadChunkResult) String() string {
+func (p *VideoServiceDeleteVideoResult) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("VideoServiceUploadChunkResult(%+v)", *p)
+	return fmt.Sprintf("VideoServiceDeleteVideoResult(%+v)", *p)
 }
 
-var fieldIDToName_VideoServiceUploadChunkResult = map[int16]string{
+var fieldIDToName_VideoServiceDeleteVideoResult = map[int16]string{
 	0: "success",
 }
 
-type VideoServiceMergeFileArgs struct {
-	Req *MergeFileReq `thrift:"req,1" frugal:"1,default,MergeFileReq" json:"req"`
+type VideoServiceGetProcessingPolicyArgs struct {
+	Req *GetProcessingPolicyReq `thrift:"req,1" frugal:"1,default,GetProcessingPolicyReq" json:"req"`
 }
 
-func NewVideoServiceMergeFileArgs() *VideoServiceMergeFileArgs {
-	return &VideoServiceMergeFileArgs{}
+func NewVideoServiceGetProcessingPolicyArgs() *VideoServiceGetProcessingPolicyArgs {
+	return &VideoServiceGetProcessingPolicyArgs{}
 }
 
-func (p *VideoServiceMergeFileArgs) InitDefault() {
+func (p *VideoServiceGetProcessingPolicyArgs) InitDefault() {
 }
 
-var VideoServiceMergeFileArgs_Req_DEFAULT *MergeFileReq
+var VideoServiceGetProcessingPolicyArgs_Req_DEFAULT *GetProcessingPolicyReq
 
-func (p *VideoServiceMergeFileArgs) GetReq() (v *MergeFileReq) {
+func (p *VideoServiceGetProcessingPolicyArgs) GetReq() (v *GetProcessingPolicyReq) {
 	if !p.IsSetReq() {
-		return VideoServiceMergeFileArgs_Req_DEFAULT
+		return VideoServiceGetProcessingPolicyArgs_Req_DEFAULT
 	}
 	return p.Req
 }
-func (p *VideoServiceMergeFileArgs) SetReq(val *MergeFileReq) {
+func (p *VideoServiceGetProcessingPolicyArgs) SetReq(val *GetProcessingPolicyReq) {
 	p.Req = val
 }
 
-func (p *VideoServiceMergeFileArgs) IsSetReq() bool {
+func (p *VideoServiceGetProcessingPolicyArgs) IsSetReq() bool {
 	return p.Req != nil
 }
 
-func (p *VideoServiceMergeFileArgs) String() string {
+func (p *VideoServiceGetProcessingPolicyArgs) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("VideoServiceMergeFileArgs(%+v)", *p)
+	return fmt.Sprintf("VideoServiceGetProcessingPolicyArgs(%+v)", *p)
 }
 
-var fieldIDToName_VideoServiceMergeFileArgs = map[int16]string{
+var fieldIDToName_VideoServiceGetProcessingPolicyArgs = map[int16]string{
 	1: "req",
 }
 
-type VideoServiceMergeFileResult struct {
-	Success *MergeFileResp `thrift:"success,0,optional" frugal:"0,optional,MergeFileResp" json:"success,omitempty"`
+type VideoServiceGetProcessingPolicyResult struct {
+	Success *GetProcessingPolicyResp `thrift:"success,0,optional" frugal:"0,optional,GetProcessingPolicyResp" json:"success,omitempty"`
 }
 
-func NewVideoServiceMergeFileResult() *VideoServiceMergeFileResult {
-	return &VideoServiceMergeFileResult{}
+func NewVideoServiceGetProcessingPolicyResult() *VideoServiceGetProcessingPolicyResult {
+	return &VideoServiceGetProcessingPolicyResult{}
 }
 
-func (p *VideoServiceMergeFileResult) InitDefault() {
+func (p *VideoServiceGetProcessingPolicyResult) InitDefault() {
 }
 
-var VideoServiceMergeFileResult_Success_DEFAULT *MergeFileResp
+var VideoServiceGetProcessingPolicyResult_Success_DEFAULT *GetProcessingPolicyResp
 
-func (p *VideoServiceMergeFileResult) GetSuccess() (v *MergeFileResp) {
+func (p *VideoServiceGetProcessingPolicyResult) GetSuccess() (v *GetProcessingPolicyResp) {
 	if !p.IsSetSuccess() {
-		return VideoServiceMergeFileResult_Success_DEFAULT
+		return VideoServiceGetProcessingPolicyResult_Success_DEFAULT
 	}
 	return p.Success
 }
-func (p *VideoServiceMergeFileResult) SetSuccess(x interface{}) {
-	p.Success = x.(*MergeFileResp)
+func (p *VideoServiceGetProcessingPolicyResult) SetSuccess(x interface{}) {
+	p.Success = x.(*GetProcessingPolicyResp)
 }
 
-func (p *VideoServiceMergeFileResult) IsSetSuccess() bool {
+func (p *VideoServiceGetProcessingPolicyResult) IsSetSuccess() bool {
 	return p.Success != nil
 }
 
-func (p *VideoServiceMergeFileResult) String() string {
+func (p *VideoServiceGetProcessingPolicyResult) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("VideoServiceMergeFileResult(%+v)", *p)
+	return fmt.Sprintf("VideoServiceGetProcessingPolicyResult(%+v)", *p)
 }
 
-var fieldIDToName_VideoServiceMergeFileResult = map[int16]string{
+var fieldIDToName_VideoServiceGetProcessingPolicyResult = map[int16]string{
 	0: "success",
 }
 
-type VideoServiceDownloadChunkArgs struct {
-	Req *DownloadChunkReq `thrift:"req,1" frugal:"1,default,DownloadChunkReq" json:"req"`
+type VideoServiceSaveProcessingPolicyArgs struct {
+	Req *SaveProcessingPolicyReq `thrift:"req,1" frugal:"1,default,SaveProcessingPolicyReq" json:"req"`
 }
 
-func NewVideoServiceDownloadChunkArgs() *VideoServiceDownloadChunkArgs {
-	return &VideoServiceDownloadChunkArgs{}
+func NewVideoServiceSaveProcessingPolicyArgs() *VideoServiceSaveProcessingPolicyArgs {
+	return &VideoServiceSaveProcessingPolicyArgs{}
 }
 
-func (p *VideoServiceDownloadChunkArgs) InitDefault() {
+func (p *VideoServiceSaveProcessingPolicyArgs) InitDefault() {
 }
 
-var VideoServiceDownloadChunkArgs_Req_DEFAULT *DownloadChunkReq
+var VideoServiceSaveProcessingPolicyArgs_Req_DEFAULT *SaveProcessingPolicyReq
 
-func (p *VideoServiceDownloadChunkArgs) GetReq() (v *DownloadChunkReq) {
+func (p *VideoServiceSaveProcessingPolicyArgs) GetReq() (v *SaveProcessingPolicyReq) {
 	if !p.IsSetReq() {
-		return VideoServiceDownloadChunkArgs_Req_DEFAULT
+		return VideoServiceSaveProcessingPolicyArgs_Req_DEFAULT
 	}
 	return p.Req
 }
-func (p *VideoServiceDownloadChunkArgs) SetReq(val *DownloadChunkReq) {
+func (p *VideoServiceSaveProcessingPolicyArgs) SetReq(val *SaveProcessingPolicyReq) {
 	p.Req = val
 }
 
-func (p *VideoServiceDownloadChunkArgs) IsSetReq() bool {
+func (p *VideoServiceSaveProcessingPolicyArgs) IsSetReq() bool {
 	return p.Req != nil
 }
 
-func (p *VideoServiceDownloadChunkArgs) String() string {
+func (p *VideoServiceSaveProcessingPolicyArgs) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("VideoServiceDownloadChunkArgs(%+v)", *p)
+	return fmt.Sprintf("VideoServiceSaveProcessingPolicyArgs(%+v)", *p)
 }
 
-var fieldIDToName_VideoServiceDownloadChunkArgs = map[int16]string{
+var fieldIDToName_VideoServiceSaveProcessingPolicyArgs = map[int16]string{
 	1: "req",
 }
 
-type VideoServiceDownloadChunkResult struct {
-	Success *DownloadChunkResp `thrift:"success,0,optional" frugal:"0,optional,DownloadChunkResp" json:"success,omitempty"`
+type VideoServiceSaveProcessingPolicyResult struct {
+	Success *SaveProcessingPolicyResp `thrift:"success,0,optional" frugal:"0,optional,SaveProcessingPolicyResp" json:"success,omitempty"`
 }
 
-func NewVideoServiceDownloadChunkResult() *VideoServiceDownloadChunkResult {
-	return &VideoServiceDownloadChunkResult{}
+func NewVideoServiceSaveProcessingPolicyResult() *VideoServiceSaveProcessingPolicyResult {
+	return &VideoServiceSaveProcessingPolicyResult{}
 }
 
-func (p *VideoServiceDownloadChunkResult) InitDefault() {
+func (p *VideoServiceSaveProcessingPolicyResult) InitDefault() {
 }
 
-var VideoServiceDownloadChunkResult_Success_DEFAULT *DownloadChunkResp
+var VideoServiceSaveProcessingPolicyResult_Success_DEFAULT *SaveProcessingPolicyResp
 
-func (p *VideoServiceDownloadChunkResult) GetSuccess() (v *DownloadChunkResp) {
+func (p *VideoServiceSaveProcessingPolicyResult) GetSuccess() (v *SaveProcessingPolicyResp) {
 	if !p.IsSetSuccess() {
-		return VideoServiceDownloadChunkResult_Success_DEFAULT
+		return VideoServiceSaveProcessingPolicyResult_Success_DEFAULT
 	}
 	return p.Success
 }
-func (p *VideoServiceDownloadChunkResult) SetSuccess(x interface{}) {
-	p.Success = x.(*DownloadChunkResp)
+func (p *VideoServiceSaveProcessingPolicyResult) SetSuccess(x interface{}) {
+	p.Success = x.(*SaveProcessingPolicyResp)
 }
 
-func (p *VideoServiceDownloadChunkResult) IsSetSuccess() bool {
+func (p *VideoServiceSaveProcessingPolicyResult) IsSetSuccess() bool {
 	return p.Success != nil
 }
 
-func (p *VideoServiceDownloadChunkResult) String() string {
+func (p *VideoServiceSaveProcessingPolicyResult) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("VideoServiceDownloadChunkResult(%+v)", *p)
+	return fmt.Sprintf("VideoServiceSaveProcessingPolicyResult(%+v)", *p)
 }
 
-var fieldIDToName_VideoServiceDownloadChunkResult = map[int16]string{
+var fieldIDToName_VideoServiceSaveProcessingPolicyResult = map[int16]string{
 	0: "success",
 }
 
-type VideoServiceGetVideoInfoArgs struct {
-	Req *GetVideoInfoReq `thrift:"req,1" frugal:"1,default,GetVideoInfoReq" json:"req"`
+type VideoServiceDeleteProcessingPolicyArgs struct {
+	Req *DeleteProcessingPolicyReq `thrift:"req,1" frugal:"1,default,DeleteProcessingPolicyReq" json:"req"`
 }
 
-func NewVideoServiceGetVideoInfoArgs() *VideoServiceGetVideoInfoArgs {
-	return &VideoServiceGetVideoInfoArgs{}
+func NewVideoServiceDeleteProcessingPolicyArgs() *VideoServiceDeleteProcessingPolicyArgs {
+	return &VideoServiceDeleteProcessingPolicyArgs{}
 }
 
-func (p *VideoServiceGetVideoInfoArgs) InitDefault() {
+func (p *VideoServiceDeleteProcessingPolicyArgs) InitDefault() {
 }
 
-var VideoServiceGetVideoInfoArgs_Req_DEFAULT *GetVideoInfoReq
+var VideoServiceDeleteProcessingPolicyArgs_Req_DEFAULT *DeleteProcessingPolicyReq
 
-func (p *VideoServiceGetVideoInfoArgs) GetReq() (v *GetVideoInfoReq) {
+func (p *VideoServiceDeleteProcessingPolicyArgs) GetReq() (v *DeleteProcessingPolicyReq) {
 	if !p.IsSetReq() {
-		return VideoServiceGetVideoInfoArgs_Req_DEFAULT
+		return VideoServiceDeleteProcessingPolicyArgs_Req_DEFAULT
 	}
 	return p.Req
 }
-func (p *VideoServiceGetVideoInfoArgs) SetReq(val *GetVideoInfoReq) {
+func (p *VideoServiceDeleteProcessingPolicyArgs) SetReq(val *DeleteProcessingPolicyReq) {
 	p.Req = val
 }
 
-func (p *VideoServiceGetVideoInfoArgs) IsSetReq() bool {
+func (p *VideoServiceDeleteProcessingPolicyArgs) IsSetReq() bool {
 	return p.Req != nil
 }
 
-func (p *VideoServiceGetVideoInfoArgs) String() string {
+func (p *VideoServiceDeleteProcessingPolicyArgs) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("VideoServiceGetVideoInfoArgs(%+v)", *p)
+	return fmt.Sprintf("VideoServiceDeleteProcessingPolicyArgs(%+v)", *p)
 }
 
-var fieldIDToName_VideoServiceGetVideoInfoArgs = map[int16]string{
+var fieldIDToName_VideoServiceDeleteProcessingPolicyArgs = map[int16]string{
 	1: "req",
 }
 
-type VideoServiceGetVideoInfoResult struct {
-	Success *GetVideoInfoResp `thrift:"success,0,optional" frugal:"0,optional,GetVideoInfoResp" json:"success,omitempty"`
+type VideoServiceDeleteProcessingPolicyResult struct {
+	Success *DeleteProcessingPolicyResp `thrift:"success,0,optional" frugal:"0,optional,DeleteProcessingPolicyResp" json:"success,omitempty"`
 }
 
-func NewVideoServiceGetVideoInfoResult() *VideoServiceGetVideoInfoResult {
-	return &VideoServiceGetVideoInfoResult{}
+func NewVideoServiceDeleteProcessingPolicyResult() *VideoServiceDeleteProcessingPolicyResult {
+	return &VideoServiceDeleteProcessingPolicyResult{}
 }
 
-func (p *VideoServiceGetVideoInfoResult) InitDefault() {
+func (p *VideoServiceDeleteProcessingPolicyResult) InitDefault() {
 }
 
-var VideoServiceGetVideoInfoResult_Success_DEFAULT *GetVideoInfoResp
+var VideoServiceDeleteProcessingPolicyResult_Success_DEFAULT *DeleteProcessingPolicyResp
 
-func (p *VideoServiceGetVideoInfoResult) GetSuccess() (v *GetVideoInfoResp) {
+func (p *VideoServiceDeleteProcessingPolicyResult) GetSuccess() (v *DeleteProcessingPolicyResp) {
 	if !p.IsSetSuccess() {
-		return VideoServiceGetVideoInfoResult_Success_DEFAULT
+		return VideoServiceDeleteProcessingPolicyResult_Success_DEFAULT
 	}
 	return p.Success
 }
-func (p *VideoServiceGetVideoInfoResult) SetSuccess(x interface{}) {
-	p.Success = x.(*GetVideoInfoResp)
+func (p *VideoServiceDeleteProcessingPolicyResult) SetSuccess(x interface{}) {
+	p.Success = x.(*DeleteProcessingPolicyResp)
 }
 
-func (p *VideoServiceGetVideoInfoResult) IsSetSuccess() bool {
+func (p *VideoServiceDeleteProcessingPolicyResult) IsSetSuccess() bool {
 	return p.Success != nil
 }
 
-func (p *VideoServiceGetVideoInfoResult) String() string {
+func (p *VideoServiceDeleteProcessingPolicyResult) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("VideoServiceGetVideoInfoResult(%+v)", *p)
+	return fmt.Sprintf("VideoServiceDeleteProcessingPolicyResult(%+v)", *p)
 }
 
-var fieldIDToName_VideoServiceGetVideoInfoResult = map[int16]string{
+var fieldIDToName_VideoServiceDeleteProcessingPolicyResult = map[int16]string{
 	0: "success",
 }
 
-type VideoServiceTranscodeArgs struct {
-	Req *TranscodeReq `thrift:"req,1" frugal:"1,default,TranscodeReq" json:"req"`
+type VideoServiceGetPipelineArgs struct {
+	Req *GetPipelineReq `thrift:"req,1" frugal:"1,default,GetPipelineReq" json:"req"`
 }
 
-func NewVideoServiceTranscodeArgs() *VideoServiceTranscodeArgs {
-	return &VideoServiceTranscodeArgs{}
+func NewVideoServiceGetPipelineArgs() *VideoServiceGetPipelineArgs {
+	return &VideoServiceGetPipelineArgs{}
 }
 
-func (p *VideoServiceTranscodeArgs) InitDefault() {
+func (p *VideoServiceGetPipelineArgs) InitDefault() {
 }
 
-var VideoServiceTranscodeArgs_Req_DEFAULT *TranscodeReq
+var VideoServiceGetPipelineArgs_Req_DEFAULT *GetPipelineReq
 
-func (p *VideoServiceTranscodeArgs) GetReq() (v *TranscodeReq) {
+func (p *VideoServiceGetPipelineArgs) GetReq() (v *GetPipelineReq) {
 	if !p.IsSetReq() {
-		return VideoServiceTranscodeArgs_Req_DEFAULT
+		return VideoServiceGetPipelineArgs_Req_DEFAULT
 	}
 	return p.Req
 }
-func (p *VideoServiceTranscodeArgs) SetReq(val *TranscodeReq) {
+func (p *VideoServiceGetPipelineArgs) SetReq(val *GetPipelineReq) {
 	p.Req = val
 }
 
-func (p *VideoServiceTranscodeArgs) IsSetReq() bool {
+func (p *VideoServiceGetPipelineArgs) IsSetReq() bool {
 	return p.Req != nil
 }
 
-func (p *VideoServiceTranscodeArgs) String() string {
+func (p *VideoServiceGetPipelineArgs) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("VideoServiceTranscodeArgs(%+v)", *p)
+	return fmt.Sprintf("VideoServiceGetPipelineArgs(%+v)", *p)
 }
 
-var fieldIDToName_VideoServiceTranscodeArgs = map[int16]string{
+var fieldIDToName_VideoServiceGetPipelineArgs = map[int16]string{
 	1: "req",
 }
 
-type VideoServiceTranscodeResult struct {
-	Success *TranscodeResp `thrift:"success,0,optional" frugal:"0,optional,TranscodeResp" json:"success,omitempty"`
+type VideoServiceGetPipelineResult struct {
+	Success *GetPipelineResp `thrift:"success,0,optional" frugal:"0,optional,GetPipelineResp" json:"success,omitempty"`
 }
 
-func NewVideoServiceTranscodeResult() *VideoServiceTranscodeResult {
-	return &VideoServiceTranscodeResult{}
+func NewVideoServiceGetPipelineResult() *VideoServiceGetPipelineResult {
+	return &VideoServiceGetPipelineResult{}
 }
 
-func (p *VideoServiceTranscodeResult) InitDefault() {
+func (p *VideoServiceGetPipelineResult) InitDefault() {
 }
 
-var VideoServiceTranscodeResult_Success_DEFAULT *TranscodeResp
+var VideoServiceGetPipelineResult_Success_DEFAULT *GetPipelineResp
 
-func (p *VideoServiceTranscodeResult) GetSuccess() (v *TranscodeResp) {
+func (p *VideoServiceGetPipelineResult) GetSuccess() (v *GetPipelineResp) {
 	if !p.IsSetSuccess() {
-		return VideoServiceTranscodeResult_Success_DEFAULT
+		return VideoServiceGetPipelineResult_Success_DEFAULT
 	}
 	return p.Success
 }
-func (p *VideoServiceTranscodeResult) SetSuccess(x interface{}) {
-	p.Success = x.(*TranscodeResp)
+func (p *VideoServiceGetPipelineResult) SetSuccess(x interface{}) {
+	p.Success = x.(*GetPipelineResp)
 }
 
-func (p *VideoServiceTranscodeResult) IsSetSuccess() bool {
+func (p *VideoServiceGetPipelineResult) IsSetSuccess() bool {
 	return p.Success != nil
 }
 
-func (p *VideoServiceTranscodeResult) String() string {
+func (p *VideoServiceGetPipelineResult) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("VideoServiceTranscodeResult(%+v)", *p)
+	return fmt.Sprintf("VideoServiceGetPipelineResult(%+v)", *p)
 }
 
-var fieldIDToName_VideoServiceTranscodeResult = map[int16]string{
+var fieldIDToName_VideoServiceGetPipelineResult = map[int16]string{
 	0: "success",
 }
 
-type VideoServiceGetTranscodeStatusArgs struct {
-	Req *GetTranscodeStatusReq `thrift:"req,1" frugal:"1,default,GetTranscodeStatusReq" json:"req"`
+type VideoServiceRetryPipelineArgs struct {
+	Req *RetryPipelineReq `thrift:"req,1" frugal:"1,default,RetryPipelineReq" json:"req"`
 }
 
-func NewVideoServiceGetTranscodeStatusArgs() *VideoServiceGetTranscodeStatusArgs {
-	return &VideoServiceGetTranscodeStatusArgs{}
+func NewVideoServiceRetryPipelineArgs() *VideoServiceRetryPipelineArgs {
+	return &VideoServiceRetryPipelineArgs{}
 }
 
-func (p *VideoServiceGetTranscodeStatusArgs) InitDefault() {
+func (p *VideoServiceRetryPipelineArgs) InitDefault() {
 }
 
-var VideoServiceGetTranscodeStatusArgs_Req_DEFAULT *GetTranscodeStatusReq
+var VideoServiceRetryPipelineArgs_Req_DEFAULT *RetryPipelineReq
 
-func (p *VideoServiceGetTranscodeStatusArgs) GetReq() (v *GetTranscodeStatusReq) {
+func (p *VideoServiceRetryPipelineArgs) GetReq() (v *RetryPipelineReq) {
 	if !p.IsSetReq() {
-		return VideoServiceGetTranscodeStatusArgs_Req_DEFAULT
+		return VideoServiceRetryPipelineArgs_Req_DEFAULT
 	}
 	return p.Req
 }
-func (p *VideoServiceGetTranscodeStatusArgs) SetReq(val *GetTranscodeStatusReq) {
+func (p *VideoServiceRetryPipelineArgs) SetReq(val *RetryPipelineReq) {
 	p.Req = val
 }
 
-func (p *VideoServiceGetTranscodeStatusArgs) IsSetReq() bool {
+func (p *VideoServiceRetryPipelineArgs) IsSetReq() bool {
 	return p.Req != nil
 }
 
-func (p *VideoServiceGetTranscodeStatusArgs) String() string {
+func (p *VideoServiceRetryPipelineArgs) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("VideoServiceGetTranscodeStatusArgs(%+v)", *p)
+	return fmt.Sprintf("VideoServiceRetryPipelineArgs(%+v)", *p)
 }
 
-var fieldIDToName_VideoServiceGetTranscodeStatusArgs = map[int16]string{
+var fieldIDToName_VideoServiceRetryPipelineArgs = map[int16]string{
 	1: "req",
 }
 
-type VideoServiceGetTranscodeStatusResult struct {
-	Success *GetTranscodeStatusResp `thrift:"success,0,optional" frugal:"0,optional,GetTranscodeStatusResp" json:"success,omitempty"`
+type VideoServiceRetryPipelineResult struct {
+	Success *RetryPipelineResp `thrift:"success,0,optional" frugal:"0,optional,RetryPipelineResp" json:"success,omitempty"`
 }
 
-func NewVideoServiceGetTranscodeStatusResult() *VideoServiceGetTranscodeStatusResult {
-	return &VideoServiceGetTranscodeStatusResult{}
+func NewVideoServiceRetryPipelineResult() *VideoServiceRetryPipelineResult {
+	return &VideoServiceRetryPipelineResult{}
 }
 
-func (p *VideoServiceGetTranscodeStatusResult) InitDefault() {
+func (p *VideoServiceRetryPipelineResult) InitDefault() {
 }
 
-var VideoServiceGetTranscodeStatusResult_Success_DEFAULT *GetTranscodeStatusResp
+var VideoServiceRetryPipelineResult_Success_DEFAULT *RetryPipelineResp
 
-func (p *VideoServiceGetTranscodeStatusResult) GetSuccess() (v *GetTranscodeStatusResp) {
+func (p *VideoServiceRetryPipelineResult) GetSuccess() (v *RetryPipelineResp) {
 	if !p.IsSetSuccess() {
-		return VideoServiceGetTranscodeStatusResult_Success_DEFAULT
+		return VideoServiceRetryPipelineResult_Success_DEFAULT
 	}
 	return p.Success
 }
-func (p *VideoServiceGetTranscodeStatusResult) SetSuccess(x interface{}) {
-	p.Success = x.(*GetTranscodeStatusResp)
+func (p *VideoServiceRetryPipelineResult) SetSuccess(x interface{}) {
+	p.Success = x.(*RetryPipelineResp)
 }
 
-func (p *VideoServiceGetTranscodeStatusResult) IsSetSuccess() bool {
+func (p *VideoServiceRetryPipelineResult) IsSetSuccess() bool {
 	return p.Success != nil
 }
 
-func (p *VideoServiceGetTranscodeStatusResult) String() string {
+func (p *VideoServiceRetryPipelineResult) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("VideoServiceGetTranscodeStatusResult(%+v)", *p)
+	return fmt.Sprintf("VideoServiceRetryPipelineResult(%+v)", *p)
 }
 
-var fieldIDToName_VideoServiceGetTranscodeStatusResult = map[int16]string{
+var fieldIDToName_VideoServiceRetryPipelineResult = map[int16]string{
 	0: "success",
 }
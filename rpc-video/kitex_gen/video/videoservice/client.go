@@ -6,6 +6,10 @@ import (
 	"context"
 	client "github.com/cloudwego/kitex/client"
 	callopt "github.com/cloudwego/kitex/client/callopt"
+	streamcall "github.com/cloudwego/kitex/client/callopt/streamcall"
+	streamclient "github.com/cloudwego/kitex/client/streamclient"
+	streaming "github.com/cloudwego/kitex/pkg/streaming"
+	transport "github.com/cloudwego/kitex/transport"
 	video "video-platform-microservice/rpc-video/kitex_gen/video"
 )
 
@@ -14,10 +18,42 @@ type Client interface {
 	InitUpload(ctx context.Context, req *video.InitUploadReq, callOptions ...callopt.Option) (r *video.InitUploadResp, err error)
 	UploadChunk(ctx context.Context, req *video.UploadChunkReq, callOptions ...callopt.Option) (r *video.UploadChunkResp, err error)
 	MergeFile(ctx context.Context, req *video.MergeFileReq, callOptions ...callopt.Option) (r *video.MergeFileResp, err error)
+	GetMergeStatus(ctx context.Context, req *video.GetMergeStatusReq, callOptions ...callopt.Option) (r *video.GetMergeStatusResp, err error)
 	DownloadChunk(ctx context.Context, req *video.DownloadChunkReq, callOptions ...callopt.Option) (r *video.DownloadChunkResp, err error)
 	GetVideoInfo(ctx context.Context, req *video.GetVideoInfoReq, callOptions ...callopt.Option) (r *video.GetVideoInfoResp, err error)
+	GetPlaybackFile(ctx context.Context, req *video.GetPlaybackFileReq, callOptions ...callopt.Option) (r *video.GetPlaybackFileResp, err error)
+	GetThumbnailFile(ctx context.Context, req *video.GetThumbnailFileReq, callOptions ...callopt.Option) (r *video.GetThumbnailFileResp, err error)
+	SetPoster(ctx context.Context, req *video.SetPosterReq, callOptions ...callopt.Option) (r *video.SetPosterResp, err error)
 	Transcode(ctx context.Context, req *video.TranscodeReq, callOptions ...callopt.Option) (r *video.TranscodeResp, err error)
 	GetTranscodeStatus(ctx context.Context, req *video.GetTranscodeStatusReq, callOptions ...callopt.Option) (r *video.GetTranscodeStatusResp, err error)
+	CancelTranscode(ctx context.Context, req *video.CancelTranscodeReq, callOptions ...callopt.Option) (r *video.CancelTranscodeResp, err error)
+	RetryTranscode(ctx context.Context, req *video.RetryTranscodeReq, callOptions ...callopt.Option) (r *video.RetryTranscodeResp, err error)
+	ListTranscodeProfiles(ctx context.Context, req *video.ListTranscodeProfilesReq, callOptions ...callopt.Option) (r *video.ListTranscodeProfilesResp, err error)
+	SaveTranscodeProfile(ctx context.Context, req *video.SaveTranscodeProfileReq, callOptions ...callopt.Option) (r *video.SaveTranscodeProfileResp, err error)
+	DeleteTranscodeProfile(ctx context.Context, req *video.DeleteTranscodeProfileReq, callOptions ...callopt.Option) (r *video.DeleteTranscodeProfileResp, err error)
+	DeleteVideo(ctx context.Context, req *video.DeleteVideoReq, callOptions ...callopt.Option) (r *video.DeleteVideoResp, err error)
+	GetProcessingPolicy(ctx context.Context, req *video.GetProcessingPolicyReq, callOptions ...callopt.Option) (r *video.GetProcessingPolicyResp, err error)
+	SaveProcessingPolicy(ctx context.Context, req *video.SaveProcessingPolicyReq, callOptions ...callopt.Option) (r *video.SaveProcessingPolicyResp, err error)
+	DeleteProcessingPolicy(ctx context.Context, req *video.DeleteProcessingPolicyReq, callOptions ...callopt.Option) (r *video.DeleteProcessingPolicyResp, err error)
+	GetPipeline(ctx context.Context, req *video.GetPipelineReq, callOptions ...callopt.Option) (r *video.GetPipelineResp, err error)
+	RetryPipeline(ctx context.Context, req *video.RetryPipelineReq, callOptions ...callopt.Option) (r *video.RetryPipelineResp, err error)
+}
+
+// StreamClient is designed to provide Interface for Streaming APIs.
+type StreamClient interface {
+	UploadChunkStream(ctx context.Context, callOptions ...streamcall.Option) (stream VideoService_UploadChunkStreamClient, err error)
+	DownloadStream(ctx context.Context, req *video.DownloadStreamReq, callOptions ...streamcall.Option) (stream VideoService_DownloadStreamClient, err error)
+}
+
+type VideoService_UploadChunkStreamClient interface {
+	streaming.Stream
+	Send(*video.UploadChunkFrame) error
+	CloseAndRecv() (*video.UploadChunkResp, error)
+}
+
+type VideoService_DownloadStreamClient interface {
+	streaming.Stream
+	Recv() (*video.DownloadFrame, error)
 }
 
 // NewClient creates a client for the service defined in IDL.
@@ -64,6 +100,11 @@ func (p *kVideoServiceClient) MergeFile(ctx context.Context, req *video.MergeFil
 	return p.kClient.MergeFile(ctx, req)
 }
 
+func (p *kVideoServiceClient) GetMergeStatus(ctx context.Context, req *video.GetMergeStatusReq, callOptions ...callopt.Option) (r *video.GetMergeStatusResp, err error) {
+	ctx = client.NewCtxWithCallOptions(ctx, callOptions)
+	return p.kClient.GetMergeStatus(ctx, req)
+}
+
 func (p *kVideoServiceClient) DownloadChunk(ctx context.Context, req *video.DownloadChunkReq, callOptions ...callopt.Option) (r *video.DownloadChunkResp, err error) {
 	ctx = client.NewCtxWithCallOptions(ctx, callOptions)
 	return p.kClient.DownloadChunk(ctx, req)
@@ -74,6 +115,21 @@ func (p *kVideoServiceClient) GetVideoInfo(ctx context.Context, req *video.GetVi
 	return p.kClient.GetVideoInfo(ctx, req)
 }
 
+func (p *kVideoServiceClient) GetPlaybackFile(ctx context.Context, req *video.GetPlaybackFileReq, callOptions ...callopt.Option) (r *video.GetPlaybackFileResp, err error) {
+	ctx = client.NewCtxWithCallOptions(ctx, callOptions)
+	return p.kClient.GetPlaybackFile(ctx, req)
+}
+
+func (p *kVideoServiceClient) GetThumbnailFile(ctx context.Context, req *video.GetThumbnailFileReq, callOptions ...callopt.Option) (r *video.GetThumbnailFileResp, err error) {
+	ctx = client.NewCtxWithCallOptions(ctx, callOptions)
+	return p.kClient.GetThumbnailFile(ctx, req)
+}
+
+func (p *kVideoServiceClient) SetPoster(ctx context.Context, req *video.SetPosterReq, callOptions ...callopt.Option) (r *video.SetPosterResp, err error) {
+	ctx = client.NewCtxWithCallOptions(ctx, callOptions)
+	return p.kClient.SetPoster(ctx, req)
+}
+
 func (p *kVideoServiceClient) Transcode(ctx context.Context, req *video.TranscodeReq, callOptions ...callopt.Option) (r *video.TranscodeResp, err error) {
 	ctx = client.NewCtxWithCallOptions(ctx, callOptions)
 	return p.kClient.Transcode(ctx, req)
@@ -83,3 +139,98 @@ func (p *kVideoServiceClient) GetTranscodeStatus(ctx context.Context, req *video
 	ctx = client.NewCtxWithCallOptions(ctx, callOptions)
 	return p.kClient.GetTranscodeStatus(ctx, req)
 }
+
+func (p *kVideoServiceClient) CancelTranscode(ctx context.Context, req *video.CancelTranscodeReq, callOptions ...callopt.Option) (r *video.CancelTranscodeResp, err error) {
+	ctx = client.NewCtxWithCallOptions(ctx, callOptions)
+	return p.kClient.CancelTranscode(ctx, req)
+}
+
+func (p *kVideoServiceClient) RetryTranscode(ctx context.Context, req *video.RetryTranscodeReq, callOptions ...callopt.Option) (r *video.RetryTranscodeResp, err error) {
+	ctx = client.NewCtxWithCallOptions(ctx, callOptions)
+	return p.kClient.RetryTranscode(ctx, req)
+}
+
+func (p *kVideoServiceClient) ListTranscodeProfiles(ctx context.Context, req *video.ListTranscodeProfilesReq, callOptions ...callopt.Option) (r *video.ListTranscodeProfilesResp, err error) {
+	ctx = client.NewCtxWithCallOptions(ctx, callOptions)
+	return p.kClient.ListTranscodeProfiles(ctx, req)
+}
+
+func (p *kVideoServiceClient) SaveTranscodeProfile(ctx context.Context, req *video.SaveTranscodeProfileReq, callOptions ...callopt.Option) (r *video.SaveTranscodeProfileResp, err error) {
+	ctx = client.NewCtxWithCallOptions(ctx, callOptions)
+	return p.kClient.SaveTranscodeProfile(ctx, req)
+}
+
+func (p *kVideoServiceClient) DeleteTranscodeProfile(ctx context.Context, req *video.DeleteTranscodeProfileReq, callOptions ...callopt.Option) (r *video.DeleteTranscodeProfileResp, err error) {
+	ctx = client.NewCtxWithCallOptions(ctx, callOptions)
+	return p.kClient.DeleteTranscodeProfile(ctx, req)
+}
+
+func (p *kVideoServiceClient) DeleteVideo(ctx context.Context, req *video.DeleteVideoReq, callOptions ...callopt.Option) (r *video.DeleteVideoResp, err error) {
+	ctx = client.NewCtxWithCallOptions(ctx, callOptions)
+	return p.kClient.DeleteVideo(ctx, req)
+}
+
+func (p *kVideoServiceClient) GetProcessingPolicy(ctx context.Context, req *video.GetProcessingPolicyReq, callOptions ...callopt.Option) (r *video.GetProcessingPolicyResp, err error) {
+	ctx = client.NewCtxWithCallOptions(ctx, callOptions)
+	return p.kClient.GetProcessingPolicy(ctx, req)
+}
+
+func (p *kVideoServiceClient) SaveProcessingPolicy(ctx context.Context, req *video.SaveProcessingPolicyReq, callOptions ...callopt.Option) (r *video.SaveProcessingPolicyResp, err error) {
+	ctx = client.NewCtxWithCallOptions(ctx, callOptions)
+	return p.kClient.SaveProcessingPolicy(ctx, req)
+}
+
+func (p *kVideoServiceClient) DeleteProcessingPolicy(ctx context.Context, req *video.DeleteProcessingPolicyReq, callOptions ...callopt.Option) (r *video.DeleteProcessingPolicyResp, err error) {
+	ctx = client.NewCtxWithCallOptions(ctx, callOptions)
+	return p.kClient.DeleteProcessingPolicy(ctx, req)
+}
+
+func (p *kVideoServiceClient) GetPipeline(ctx context.Context, req *video.GetPipelineReq, callOptions ...callopt.Option) (r *video.GetPipelineResp, err error) {
+	ctx = client.NewCtxWithCallOptions(ctx, callOptions)
+	return p.kClient.GetPipeline(ctx, req)
+}
+
+func (p *kVideoServiceClient) RetryPipeline(ctx context.Context, req *video.RetryPipelineReq, callOptions ...callopt.Option) (r *video.RetryPipelineResp, err error) {
+	ctx = client.NewCtxWithCallOptions(ctx, callOptions)
+	return p.kClient.RetryPipeline(ctx, req)
+}
+
+// NewStreamClient creates a stream client for the service's streaming APIs defined in IDL.
+func NewStreamClient(destService string, opts ...streamclient.Option) (StreamClient, error) {
+	var options []client.Option
+	options = append(options, client.WithDestService(destService))
+	options = append(options, client.WithTransportProtocol(transport.GRPC))
+	options = append(options, streamclient.GetClientOptions(opts)...)
+
+	kc, err := client.NewClient(serviceInfoForStreamClient(), options...)
+	if err != nil {
+		return nil, err
+	}
+	return &kVideoServiceStreamClient{
+		kClient: newServiceClient(kc),
+	}, nil
+}
+
+// MustNewStreamClient creates a stream client for the service's streaming APIs defined in IDL.
+// It panics if any error occurs.
+func MustNewStreamClient(destService string, opts ...streamclient.Option) StreamClient {
+	kc, err := NewStreamClient(destService, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return kc
+}
+
+type kVideoServiceStreamClient struct {
+	*kClient
+}
+
+func (p *kVideoServiceStreamClient) UploadChunkStream(ctx context.Context, callOptions ...streamcall.Option) (stream VideoService_UploadChunkStreamClient, err error) {
+	ctx = client.NewCtxWithCallOptions(ctx, streamcall.GetCallOptions(callOptions))
+	return p.kClient.UploadChunkStream(ctx)
+}
+
+func (p *kVideoServiceStreamClient) DownloadStream(ctx context.Context, req *video.DownloadStreamReq, callOptions ...streamcall.Option) (stream VideoService_DownloadStreamClient, err error) {
+	ctx = client.NewCtxWithCallOptions(ctx, streamcall.GetCallOptions(callOptions))
+	return p.kClient.DownloadStream(ctx, req)
+}
@@ -5,8 +5,11 @@ import (
 "log"
 "net"
 "strings"
+"time"
 
 "video-platform-microservice/rpc-video/internal/db"
+"video-platform-microservice/rpc-video/internal/merge"
+"video-platform-microservice/rpc-video/internal/pipeline"
 "video-platform-microservice/rpc-video/internal/storage"
 "video-platform-microservice/rpc-video/internal/transcode"
 video "video-platform-microservice/rpc-video/kitex_gen/video/videoservice"
@@ -42,6 +45,9 @@ log.Fatalf("❌ 数据库初始化失败: %v", err)
 if err := db.Init(); err != nil {
 log.Fatalf("❌ 数据库表初始化失败: %v", err)
 }
+if err := transcode.EnsureBuiltinProfiles(); err != nil {
+log.Fatalf("❌ 内置编码配置初始化失败: %v", err)
+}
 
 // 初始化 Redis
 if err := redis.InitRedis(); err != nil {
@@ -54,8 +60,37 @@ if err := storage.InitStorage(); err != nil {
 log.Fatalf("❌ 存储初始化失败: %v", err)
 }
 
-// 初始化转码管理器（2个工作协程）
-transcode.InitTranscodeManager(2)
+// 为记录存储位置之前的文件和转码输出补全 storage_key、存储后端与大小
+if err := db.BackfillStorageLocations(storage.BackendName()); err != nil {
+log.Fatalf("❌ 补全文件存储位置失败: %v", err)
+}
+if err := transcode.BackfillRenditionLocations(); err != nil {
+log.Fatalf("❌ 补全转码输出存储位置失败: %v", err)
+}
+
+// 初始化处理流程管理器，恢复重启前未完成的流程（合并完成后会按处理策略创建流程，需先于合并管理器初始化）
+if err := pipeline.InitPipelineManager(); err != nil {
+log.Fatalf("❌ 处理流程管理器初始化失败: %v", err)
+}
+
+// 初始化合并管理器（2个工作协程），恢复重启前未完成的合并任务
+if err := merge.InitMergeManager(2); err != nil {
+log.Fatalf("❌ 合并管理器初始化失败: %v", err)
+}
+
+// 补齐缺少封面和缩略图的视频（中断的生成任务以及功能上线前上传的视频）
+go transcode.ResumeThumbnails()
+
+// 初始化转码管理器：embedded 模式在本进程内转码，standalone 模式由独立的 transcode-worker 执行
+tcfg, err := transcode.LoadConfig()
+if err != nil {
+log.Fatalf("❌ 转码配置加载失败: %v", err)
+}
+if tcfg.Mode == transcode.ModeEmbedded {
+transcode.InitTranscodeManager(tcfg)
+} else {
+log.Printf("转码模式: %s，转码任务由 transcode-worker 执行", tcfg.Mode)
+}
 
 // 获取服务端口
 port := cfg.RPCPort
@@ -84,6 +119,7 @@ fmt.Println("✅ Etcd 注册成功")
 fmt.Println("✅ 转码服务已启动")
 
 err = svr.Run()
+transcode.Shutdown(30 * time.Second)
 if err != nil {
 log.Fatalf("❌ 服务启动失败: %v", err)
 }
@@ -9,6 +9,7 @@ import (
 "math/rand"
 "mime/multipart"
 "net/http"
+"os"
 "sync"
 "time"
 )
@@ -17,6 +18,9 @@ const GatewayURL = "http://localhost:8080"
 
 var httpClient = &http.Client{Timeout: 60 * time.Second}
 
+// runID 在一次运行内固定，保证用户名和文件哈希在多次运行之间不冲突、在同一次运行内可预测
+var runID = time.Now().UnixNano() % 1000000000
+
 func registerAndLogin(username, password string) (token string, userID int, err error) {
 registerData := map[string]string{"username": username, "password": password}
 body, _ := json.Marshal(registerData)
@@ -47,7 +51,7 @@ return loginResp.Token, loginResp.UserID, nil
 }
 
 // 高强度并发测试 - 100个goroutine同时注册
-func testRaceConditionRegistration() {
+func testRaceConditionRegistration() error {
 fmt.Println("\n🔍 测试1: 高强度并发注册（100个goroutine）")
 start := time.Now()
 
@@ -59,7 +63,7 @@ for i := 0; i < numGoroutines; i++ {
 wg.Add(1)
 go func(id int) {
 defer wg.Done()
-username := fmt.Sprintf("race%d_%d", time.Now().Unix()%10000, id)
+username := fmt.Sprintf("race%d_%d", runID, id)
 token, userID, err := registerAndLogin(username, "Test123456")
 if err == nil && token != "" && userID > 0 {
 successMap.Store(id, true)
@@ -75,17 +79,20 @@ successCount++
 return true
 })
 
+if successCount != numGoroutines {
+return fmt.Errorf("注册成功 %d/%d", successCount, numGoroutines)
+}
 fmt.Printf("✅ 完成: %d/%d 成功, 耗时: %v\n", successCount, numGoroutines, time.Since(start))
+return nil
 }
 
 // 测试同时多个用户上传同一文件
-func testRaceConditionSameFile() {
+func testRaceConditionSameFile() error {
 fmt.Println("\n🔍 测试2: 多用户同时上传同一文件（检查RefCount）")
 start := time.Now()
 
 // 生成共享文件hash
-timestamp := fmt.Sprintf("%d", time.Now().UnixNano())
-sharedFileHash := fmt.Sprintf("%x", md5.Sum([]byte(timestamp)))
+sharedFileHash := fmt.Sprintf("%x", md5.Sum([]byte(fmt.Sprintf("shared_%d", runID))))
 
 var wg sync.WaitGroup
 numUsers := 20
@@ -96,9 +103,10 @@ wg.Add(1)
 go func(id int) {
 defer wg.Done()
 
-username := fmt.Sprintf("shared%d_%d", time.Now().Unix()%10000, id)
+username := fmt.Sprintf("shared%d_%d", runID, id)
 token, _, err := registerAndLogin(username, "Test123456")
 if err != nil {
+initResults.Store(id, 0)
 return
 }
 
@@ -135,74 +143,101 @@ successCount++
 return true
 })
 
+if successCount != numUsers {
+return fmt.Errorf("初始化成功 %d/%d", successCount, numUsers)
+}
 fmt.Printf("✅ 完成: %d/%d 成功初始化同一文件, 耗时: %v\n", successCount, numUsers, time.Since(start))
 fmt.Println("   （数据库应正确维护RefCount，无数据竞争）")
+return nil
+}
+
+// postJSON 发送带认证的 JSON 请求并解析响应
+func postJSON(token, path string, data interface{}) (int, map[string]interface{}, error) {
+body, _ := json.Marshal(data)
+req, _ := http.NewRequest("POST", GatewayURL+path, bytes.NewBuffer(body))
+req.Header.Set("Content-Type", "application/json")
+req.Header.Set("Authorization", "Bearer "+token)
+resp, err := httpClient.Do(req)
+if err != nil {
+return 0, nil, err
+}
+defer resp.Body.Close()
+var result map[string]interface{}
+json.NewDecoder(resp.Body).Decode(&result)
+return resp.StatusCode, result, nil
 }
 
-// 测试同一用户并发上传多个分片
-func testRaceConditionMultipleChunks() {
-fmt.Println("\n🔍 测试3: 单用户并发上传多个分片")
+// uploadChunk 上传一个分片，返回 HTTP 状态码
+func uploadChunk(token, fileHash string, index int, data []byte) (int, error) {
+var buf bytes.Buffer
+writer := multipart.NewWriter(&buf)
+writer.WriteField("file_hash", fileHash)
+writer.WriteField("index", fmt.Sprintf("%d", index))
+writer.WriteField("chunk_hash", fmt.Sprintf("%x", md5.Sum(data)))
+writer.WriteField("chunk_size", fmt.Sprintf("%d", len(data)))
+
+part, _ := writer.CreateFormFile("chunk", "chunk")
+part.Write(data)
+writer.Close()
+
+req, _ := http.NewRequest("POST", GatewayURL+"/api/video/upload_chunk", &buf)
+req.Header.Set("Content-Type", writer.FormDataContentType())
+req.Header.Set("Authorization", "Bearer "+token)
+
+resp, err := httpClient.Do(req)
+if err != nil {
+return 0, err
+}
+resp.Body.Close()
+return resp.StatusCode, nil
+}
+
+// 测试同一用户并发上传多个分片，然后并发合并
+func testRaceConditionMultipleChunks() error {
+fmt.Println("\n🔍 测试3: 单用户并发上传多个分片 + 并发合并")
 start := time.Now()
 
-username := fmt.Sprintf("chunks%d", time.Now().Unix()%10000)
+username := fmt.Sprintf("chunks%d", runID)
 token, _, err := registerAndLogin(username, "Test123456")
 if err != nil {
-fmt.Println("❌ 准备失败:", err)
-return
+return fmt.Errorf("准备失败: %v", err)
 }
 
-timestamp := fmt.Sprintf("%d", time.Now().UnixNano())
-fileHash := fmt.Sprintf("%x", md5.Sum([]byte(timestamp)))
+// 分片内容由固定种子生成，文件哈希为真实的整体 MD5，合并时的完整性校验可以通过
+numChunks := 50
+chunks := make([][]byte, numChunks)
+rng := rand.New(rand.NewSource(runID))
+whole := md5.New()
+for i := range chunks {
+chunks[i] = make([]byte, 10240)
+rng.Read(chunks[i])
+whole.Write(chunks[i])
+}
+fileHash := fmt.Sprintf("%x", whole.Sum(nil))
 
 // 初始化
 initData := map[string]interface{}{
 "file_hash": fileHash,
 "filename":  "multi_chunks.mp4",
-"file_size": 10240000,
+"file_size": numChunks * 10240,
 "width":     1920,
 "height":    1080,
 }
-body, _ := json.Marshal(initData)
-req, _ := http.NewRequest("POST", GatewayURL+"/api/video/init", bytes.NewBuffer(body))
-req.Header.Set("Content-Type", "application/json")
-req.Header.Set("Authorization", "Bearer "+token)
-httpClient.Do(req)
+if status, _, err := postJSON(token, "/api/video/init", initData); err != nil || status != http.StatusOK {
+return fmt.Errorf("初始化失败: status=%d, err=%v", status, err)
+}
 
 // 并发上传50个分片
 var wg sync.WaitGroup
-numChunks := 50
 uploaded := sync.Map{}
 
 for i := 0; i < numChunks; i++ {
 wg.Add(1)
 go func(index int) {
 defer wg.Done()
-
-chunkData := make([]byte, 10240)
-rand.Read(chunkData)
-
-var buf bytes.Buffer
-writer := multipart.NewWriter(&buf)
-writer.WriteField("file_hash", fileHash)
-writer.WriteField("index", fmt.Sprintf("%d", index))
-
-part, _ := writer.CreateFormFile("chunk", "chunk")
-part.Write(chunkData)
-writer.Close()
-
-req, _ := http.NewRequest("POST", GatewayURL+"/api/video/upload_chunk", &buf)
-req.Header.Set("Content-Type", writer.FormDataContentType())
-req.Header.Set("Authorization", "Bearer "+token)
-
-resp, err := httpClient.Do(req)
-if err == nil {
-var result map[string]interface{}
-json.NewDecoder(resp.Body).Decode(&result)
-if int(result["code"].(float64)) == 200 {
+if status, err := uploadChunk(token, fileHash, index, chunks[index]); err == nil && status == http.StatusOK {
 uploaded.Store(index, true)
 }
-resp.Body.Close()
-}
 }(i)
 }
 
@@ -213,12 +248,84 @@ uploaded.Range(func(_, _ interface{}) bool {
 successCount++
 return true
 })
+if successCount != numChunks {
+return fmt.Errorf("分片上传成功 %d/%d", successCount, numChunks)
+}
+fmt.Printf("   %d/%d 分片成功上传\n", successCount, numChunks)
+
+// 10 个并发合并请求：只能创建一个合并任务，其余请求返回同一任务或 409"合并进行中"
+numMerges := 10
+jobIDs := sync.Map{}
+conflicts := 0
+var mu sync.Mutex
+var mergeErr error
+mergeData := map[string]interface{}{
+"file_hash":    fileHash,
+"filename":     "multi_chunks.mp4",
+"total_chunks": numChunks,
+}
+
+for i := 0; i < numMerges; i++ {
+wg.Add(1)
+go func() {
+defer wg.Done()
+status, result, err := postJSON(token, "/api/video/merge", mergeData)
+mu.Lock()
+defer mu.Unlock()
+switch {
+case err != nil:
+mergeErr = err
+case status == http.StatusConflict:
+conflicts++
+case status == http.StatusOK && result["job_id"] != nil && result["job_id"] != "":
+jobIDs.Store(result["job_id"].(string), true)
+default:
+mergeErr = fmt.Errorf("合并请求失败: status=%d, msg=%v", status, result["msg"])
+}
+}()
+}
 
-fmt.Printf("✅ 完成: %d/%d 分片成功上传, 耗时: %v\n", successCount, numChunks, time.Since(start))
+wg.Wait()
+if mergeErr != nil {
+return mergeErr
+}
+
+var jobID string
+jobCount := 0
+jobIDs.Range(func(k, _ interface{}) bool {
+jobID = k.(string)
+jobCount++
+return true
+})
+if jobCount != 1 {
+return fmt.Errorf("期望只创建 1 个合并任务，实际 %d 个", jobCount)
+}
+fmt.Printf("   %d 个并发合并请求 -> 1 个合并任务 (%s), %d 个返回合并进行中\n", numMerges, jobID, conflicts)
+
+// 等待合并完成
+for i := 0; i < 60; i++ {
+resp, err := httpClient.Get(GatewayURL + "/api/video/merge/status?job_id=" + jobID)
+if err != nil {
+return err
+}
+var status map[string]interface{}
+json.NewDecoder(resp.Body).Decode(&status)
+resp.Body.Close()
+
+switch status["status"] {
+case "completed":
+fmt.Printf("✅ 完成: 合并成功 %v, 耗时: %v\n", status["url"], time.Since(start))
+return nil
+case "failed":
+return fmt.Errorf("合并失败: %v", status["error"])
+}
+time.Sleep(500 * time.Millisecond)
+}
+return fmt.Errorf("合并超时: %s", jobID)
 }
 
 // 测试极端并发：200个goroutine混合操作
-func testExtremeConcurrency() {
+func testExtremeConcurrency() error {
 fmt.Println("\n🔍 测试4: 极端并发混合操作（200 goroutines）")
 start := time.Now()
 
@@ -234,35 +341,30 @@ defer wg.Done()
 opType := id % 3
 switch opType {
 case 0: // 注册
-username := fmt.Sprintf("ext%d_%d", time.Now().UnixNano()%100000, id)
+username := fmt.Sprintf("ext%d_%d", runID, id)
 _, _, err := registerAndLogin(username, "Test123456")
 if err == nil {
 operationCount.Store(fmt.Sprintf("reg_%d", id), true)
 }
 case 1: // 视频初始化
-username := fmt.Sprintf("ext%d_%d", time.Now().UnixNano()%100000, id)
+username := fmt.Sprintf("ext%d_%d", runID, id)
 token, _, err := registerAndLogin(username, "Test123456")
 if err == nil {
-ts := fmt.Sprintf("%d_%d", time.Now().UnixNano(), id)
 initData := map[string]interface{}{
-"file_hash": fmt.Sprintf("%x", md5.Sum([]byte(ts))),
+"file_hash": fmt.Sprintf("%x", md5.Sum([]byte(fmt.Sprintf("%d_%d", runID, id)))),
 "filename":  fmt.Sprintf("extreme_%d.mp4", id),
 "file_size": 1024000,
 }
-body, _ := json.Marshal(initData)
-req, _ := http.NewRequest("POST", GatewayURL+"/api/video/init", bytes.NewBuffer(body))
-req.Header.Set("Content-Type", "application/json")
-req.Header.Set("Authorization", "Bearer "+token)
-resp, err := httpClient.Do(req)
-if err == nil {
+if status, _, err := postJSON(token, "/api/video/init", initData); err == nil && status == http.StatusOK {
 operationCount.Store(fmt.Sprintf("init_%d", id), true)
-resp.Body.Close()
 }
 }
 case 2: // 健康检查
 resp, err := httpClient.Get(GatewayURL + "/api/health")
 if err == nil {
+if resp.StatusCode == http.StatusOK {
 operationCount.Store(fmt.Sprintf("health_%d", id), true)
+}
 resp.Body.Close()
 }
 }
@@ -277,8 +379,12 @@ successCount++
 return true
 })
 
+if successCount != numOperations {
+return fmt.Errorf("操作成功 %d/%d", successCount, numOperations)
+}
 fmt.Printf("✅ 完成: %d/%d 操作成功, 耗时: %v\n", successCount, numOperations, time.Since(start))
 fmt.Printf("   平均操作耗时: %v\n", time.Since(start)/time.Duration(numOperations))
+return nil
 }
 
 func main() {
@@ -288,12 +394,26 @@ fmt.Println("========================================")
 fmt.Println("提示: 使用 'go run -race test_race.go' 可检测数据竞争")
 fmt.Println()
 
-testRaceConditionRegistration()
-testRaceConditionSameFile()
-testRaceConditionMultipleChunks()
-testExtremeConcurrency()
+tests := []func() error{
+testRaceConditionRegistration,
+testRaceConditionSameFile,
+testRaceConditionMultipleChunks,
+testExtremeConcurrency,
+}
+failed := 0
+for _, test := range tests {
+if err := test(); err != nil {
+fmt.Printf("❌ 失败: %v\n", err)
+failed++
+}
+}
 
 	fmt.Println("\n" + strings.Repeat("=", 60))
+if failed > 0 {
+fmt.Printf("❌ %d/%d 个并发测试失败\n", failed, len(tests))
+	fmt.Println(strings.Repeat("=", 60))
+os.Exit(1)
+}
 fmt.Println("✅ 所有并发测试完成，无死锁或明显问题")
 	fmt.Println(strings.Repeat("=", 60))
 }
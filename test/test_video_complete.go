@@ -27,6 +27,8 @@ URL             string   `json:"url,omitempty"`
 FinishedChunks  []string `json:"finished_chunks,omitempty"`
 TranscodeStatus string   `json:"transcode_status,omitempty"`
 TaskID          string   `json:"task_id,omitempty"`
+JobID           string   `json:"job_id,omitempty"`
+Phase           string   `json:"phase,omitempty"`
 Progress        int32    `json:"progress,omitempty"`
 }
 
@@ -196,6 +198,27 @@ defer resp.Body.Close()
 
 var result Response
 json.NewDecoder(resp.Body).Decode(&result)
+if result.JobID == "" {
+return &result
+}
+
+// 合并为异步任务，轮询直到完成
+for i := 0; i < 60; i++ {
+statusResp, err := http.Get(fmt.Sprintf("%s/api/video/merge/status?job_id=%s", gatewayURL, result.JobID))
+if err != nil {
+fmt.Printf("❌ 查询合并状态失败: %v\n", err)
+return nil
+}
+var status Response
+json.NewDecoder(statusResp.Body).Decode(&status)
+statusResp.Body.Close()
+
+fmt.Printf("   合并状态: %s (%s)\n", status.Status, status.Phase)
+if status.Status == "completed" || status.Status == "failed" {
+return &status
+}
+time.Sleep(time.Second)
+}
 return &result
 }
 